@@ -36,6 +36,15 @@ func main() {
 	defer db.Close()
 	log.Println("✅ Database connected successfully")
 
+	// Tenant scoping relies on row-level security, which superusers and BYPASSRLS roles skip
+	isolated, err := database.CheckTenantIsolation(context.Background(), db.Pool)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if !isolated {
+		log.Fatal("❌ Database role bypasses row-level security, so tenants would not be isolated; connect as a role without SUPERUSER or BYPASSRLS (see scripts/app_role.sql)")
+	}
+
 	// Run migrations
 	log.Println("📦 Running database migrations...")
 	if err := database.RunMigrations(db.Pool); err != nil {
@@ -43,8 +52,13 @@ func main() {
 	}
 	log.Println("✅ Migrations completed successfully")
 
-	// Setup routes
-	handler := routes.SetupRoutes(db.Pool)
+	// Setup routes and background jobs
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	if cfg.OperatorAPIKey == "" {
+		log.Println("⚠️  OPERATOR_API_KEY is not set; only requests acting for a tenant are served")
+	}
+	handler := routes.SetupRoutes(jobsCtx, db.Pool, cfg.OperatorAPIKey)
 
 	// Create server
 	addr := fmt.Sprintf("%s:%s", cfg.ServerHost, cfg.ServerPort)
@@ -81,6 +95,7 @@ func main() {
 	<-quit
 
 	log.Println("🛑 Shutting down server...")
+	stopJobs()
 
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
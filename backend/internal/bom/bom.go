@@ -0,0 +1,262 @@
+// Package bom builds bills of materials for design workspaces and live plant and prices them
+// from the price catalog.
+package bom
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"spectra-backend/internal/geo"
+	"spectra-backend/internal/gis"
+	"spectra-backend/internal/models"
+	"spectra-backend/internal/topology"
+)
+
+// defaultCapacityPorts is the port count a drafted node gets when it does not name one
+const defaultCapacityPorts = 8
+
+// Builder tallies nodes, cables and splices into bill of materials lines
+type Builder struct {
+	lines map[lineKey]*models.BOMLine
+}
+
+type lineKey struct {
+	category models.BOMCategory
+	spec     string
+}
+
+// NewBuilder creates an empty Builder
+func NewBuilder() *Builder {
+	return &Builder{lines: map[lineKey]*models.BOMLine{}}
+}
+
+// AddCable adds meters of a cable type and core count
+func (b *Builder) AddCable(cableType models.CableType, coreCount int, meters float64) {
+	spec := fmt.Sprintf("%s-%d", cableType, coreCount)
+	b.add(models.BOMCategoryCable, spec, fmt.Sprintf("%s cable, %d cores", cableType, coreCount), "m", meters)
+}
+
+// AddNode adds a node by type and model. An ODP also adds a 1:N splitter for its N ports.
+// Customer premises are not plant and are skipped.
+func (b *Builder) AddNode(nodeType models.NodeType, model *string, capacityPorts int) {
+	var category models.BOMCategory
+	switch nodeType {
+	case models.NodeTypeOLT:
+		category = models.BOMCategoryOLT
+	case models.NodeTypeODC:
+		category = models.BOMCategoryODC
+	case models.NodeTypeODP:
+		category = models.BOMCategoryODP
+	case models.NodeTypeClosure:
+		category = models.BOMCategoryClosure
+	case models.NodeTypePole:
+		category = models.BOMCategoryPole
+	default:
+		return
+	}
+
+	spec := ""
+	description := string(nodeType) + " (model unspecified)"
+	if model != nil && strings.TrimSpace(*model) != "" {
+		spec = strings.TrimSpace(*model)
+		description = string(nodeType) + " " + spec
+	}
+	b.add(category, spec, description, "pcs", 1)
+
+	if nodeType == models.NodeTypeODP && capacityPorts > 0 {
+		ratio := fmt.Sprintf("1:%d", capacityPorts)
+		b.add(models.BOMCategorySplitter, ratio, "Splitter "+ratio, "pcs", 1)
+	}
+}
+
+// AddSplices adds fusion splices
+func (b *Builder) AddSplices(count int) {
+	if count > 0 {
+		b.add(models.BOMCategorySplice, "", "Fusion splice", "pcs", float64(count))
+	}
+}
+
+func (b *Builder) add(category models.BOMCategory, spec, description, unit string, quantity float64) {
+	key := lineKey{category, spec}
+	line := b.lines[key]
+	if line == nil {
+		line = &models.BOMLine{Category: category, Spec: spec, Description: description, Unit: unit}
+		b.lines[key] = line
+	}
+	line.Quantity += quantity
+}
+
+// Lines returns the tallied lines in category order, then by spec
+func (b *Builder) Lines() []models.BOMLine {
+	order := map[models.BOMCategory]int{}
+	for i, c := range models.BOMCategories {
+		order[c] = i
+	}
+
+	lines := make([]models.BOMLine, 0, len(b.lines))
+	for _, line := range b.lines {
+		l := *line
+		if l.Unit == "m" {
+			l.Quantity = math.Round(l.Quantity*100) / 100
+		}
+		lines = append(lines, l)
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].Category != lines[j].Category {
+			return order[lines[i].Category] < order[lines[j].Category]
+		}
+		return specLess(lines[i].Spec, lines[j].Spec)
+	})
+	return lines
+}
+
+// specLess orders specs naturally so that ADSS-12 comes before ADSS-144
+func specLess(a, b string) bool {
+	ap, an := splitTrailingNumber(a)
+	bp, bn := splitTrailingNumber(b)
+	if ap != bp || an == bn {
+		return a < b
+	}
+	return an < bn
+}
+
+func splitTrailingNumber(s string) (string, int) {
+	i := len(s)
+	for i > 0 && s[i-1] >= '0' && s[i-1] <= '9' {
+		i--
+	}
+	n := 0
+	for _, r := range s[i:] {
+		n = n*10 + int(r-'0')
+	}
+	return s[:i], n
+}
+
+// FromDesign tallies the items drafted in a workspace
+func FromDesign(items []models.DesignItem) *Builder {
+	b := NewBuilder()
+	splices := 0
+	for _, item := range items {
+		switch item.ItemType {
+		case models.DesignItemNode:
+			ports := defaultCapacityPorts
+			if item.Node.CapacityPorts != nil {
+				ports = *item.Node.CapacityPorts
+			}
+			b.AddNode(item.Node.Type, item.Node.Model, ports)
+		case models.DesignItemCable:
+			meters := geo.PathLengthMeters(item.Cable.PathCoordinates)
+			if item.Cable.LengthMeter != nil {
+				meters = *item.Cable.LengthMeter
+			}
+			b.AddCable(item.Cable.Type, item.Cable.CoreCount, meters)
+		case models.DesignItemSplice:
+			if isSplice(item.Splice.InputType, item.Splice.OutputType) {
+				splices++
+			}
+		}
+	}
+	b.AddSplices(splices)
+	return b
+}
+
+// FromGraph tallies the live plant inside a bounding box: the nodes in it, the full length of
+// every cable whose path crosses it, and the splices made at nodes in it
+func FromGraph(g *topology.Graph, box *gis.BBox) *Builder {
+	b := NewBuilder()
+	inside := map[int64]bool{}
+	for id, n := range g.Nodes {
+		if box.Contains(n.Longitude, n.Latitude) {
+			inside[id] = true
+			b.AddNode(n.Type, n.Model, n.CapacityPorts)
+		}
+	}
+
+	for id, c := range g.Cables {
+		path := g.CablePath(id)
+		if len(path) < 2 || !box.Intersects(path) {
+			continue
+		}
+		meters := geo.PathLengthMeters(path)
+		if c.LengthMeter != nil {
+			meters = *c.LengthMeter
+		}
+		b.AddCable(c.Type, c.CoreCount, meters)
+	}
+
+	splices := 0
+	for _, c := range g.Connections {
+		if c.LocationNodeID != nil && inside[*c.LocationNodeID] && isSplice(c.InputType, c.OutputType) {
+			splices++
+		}
+	}
+	b.AddSplices(splices)
+	return b
+}
+
+// isSplice reports whether a connection joins fiber, rather than patching port to port
+func isSplice(input, output models.ConnectionType) bool {
+	return input == models.ConnectionTypeCore || output == models.ConnectionTypeCore
+}
+
+// Price prices the lines from the catalog. A line takes the catalog item with its category and
+// spec, or else the item of its category with an empty spec; lines with neither stay unpriced.
+func Price(lines []models.BOMLine, catalog []models.PriceCatalogItem) *models.BillOfMaterials {
+	prices := map[lineKey]*models.PriceCatalogItem{}
+	for i := range catalog {
+		item := &catalog[i]
+		prices[lineKey{item.Category, item.Spec}] = item
+	}
+
+	bill := &models.BillOfMaterials{
+		Lines:       lines,
+		Totals:      map[string]float64{},
+		GeneratedAt: time.Now(),
+	}
+	for i := range bill.Lines {
+		line := &bill.Lines[i]
+		item := prices[lineKey{line.Category, line.Spec}]
+		if item == nil {
+			item = prices[lineKey{line.Category, ""}]
+		}
+		if item == nil {
+			bill.Unpriced++
+			continue
+		}
+		total := math.Round(line.Quantity*item.UnitPrice*100) / 100
+		line.CatalogItemID = &item.ID
+		line.UnitPrice = &item.UnitPrice
+		line.Currency = &item.Currency
+		line.Total = &total
+		bill.Totals[item.Currency] += total
+	}
+	return bill
+}
+
+// Header is the header row of an exported bill of materials
+var Header = []string{"Category", "Spec", "Description", "Quantity", "Unit", "Unit Price", "Currency", "Total"}
+
+// Rows flattens a bill of materials into export rows, followed by one total row per currency
+func Rows(bill *models.BillOfMaterials) [][]interface{} {
+	rows := make([][]interface{}, 0, len(bill.Lines)+len(bill.Totals))
+	for _, line := range bill.Lines {
+		row := []interface{}{string(line.Category), line.Spec, line.Description, line.Quantity, line.Unit, nil, nil, nil}
+		if line.UnitPrice != nil {
+			row[5], row[6], row[7] = *line.UnitPrice, *line.Currency, *line.Total
+		}
+		rows = append(rows, row)
+	}
+
+	currencies := make([]string, 0, len(bill.Totals))
+	for currency := range bill.Totals {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	for _, currency := range currencies {
+		rows = append(rows, []interface{}{"TOTAL", nil, nil, nil, nil, nil, currency, bill.Totals[currency]})
+	}
+	return rows
+}
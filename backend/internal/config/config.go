@@ -26,6 +26,9 @@ type Config struct {
 
 	// Redis
 	RedisURL string
+
+	// OperatorAPIKey authenticates operator requests; operator access is disabled when empty
+	OperatorAPIKey string
 }
 
 // Load reads configuration from environment variables
@@ -37,13 +40,15 @@ func Load() (*Config, error) {
 		DatabaseURL:  getEnv("DATABASE_URL", ""),
 		DatabaseHost: getEnv("DATABASE_HOST", "localhost"),
 		DatabasePort: getEnv("DATABASE_PORT", "5432"),
-		DatabaseUser: getEnv("DATABASE_USER", "postgres"),
+		DatabaseUser: getEnv("DATABASE_USER", "spectra_app"),
 		DatabasePass: getEnv("DATABASE_PASSWORD", ""),
 		DatabaseName: getEnv("DATABASE_NAME", "spectra"),
 		ServerPort:   getEnv("SERVER_PORT", "8080"),
 		ServerHost:   getEnv("SERVER_HOST", "0.0.0.0"),
 		Environment:  getEnv("ENVIRONMENT", "development"),
 		RedisURL:     getEnv("REDIS_URL", "redis://localhost:6379"),
+
+		OperatorAPIKey: getEnv("OPERATOR_API_KEY", ""),
 	}
 
 	// Validate required fields
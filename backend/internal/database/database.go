@@ -27,6 +27,9 @@ func New(databaseURL string) (*DB, error) {
 	config.MaxConnIdleTime = 30 * time.Minute
 	config.HealthCheckPeriod = 1 * time.Minute
 
+	// Scope each connection to the tenant of the context acquiring it
+	config.PrepareConn = prepareTenantConn
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
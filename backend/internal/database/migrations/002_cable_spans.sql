@@ -0,0 +1,39 @@
+-- Migration: 002_cable_spans.sql
+-- Description: Ordered cable spans over intermediate poles/closures and core breakouts
+-- =====================================================
+-- CABLE_SPANS TABLE (Physical route of a cable, node to node)
+-- =====================================================
+CREATE TABLE IF NOT EXISTS cable_spans (
+    id BIGSERIAL PRIMARY KEY,
+    cable_id BIGINT NOT NULL REFERENCES cables(id) ON DELETE CASCADE,
+    from_node_id BIGINT NOT NULL REFERENCES nodes(id) ON DELETE RESTRICT,
+    to_node_id BIGINT NOT NULL REFERENCES nodes(id) ON DELETE RESTRICT,
+    sequence INT NOT NULL CHECK (sequence > 0),
+    span_length_meter FLOAT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(cable_id, sequence),
+    CHECK (from_node_id <> to_node_id)
+);
+CREATE TRIGGER trigger_update_cable_spans_timestamp BEFORE
+UPDATE ON cable_spans FOR EACH ROW EXECUTE FUNCTION update_timestamp();
+-- =====================================================
+-- CABLE_CORE_BREAKOUTS TABLE (Cores cut at an intermediate closure)
+-- Cores without a breakout at a closure pass through it express (unspliced)
+-- =====================================================
+CREATE TABLE IF NOT EXISTS cable_core_breakouts (
+    id BIGSERIAL PRIMARY KEY,
+    core_id BIGINT NOT NULL REFERENCES cable_cores(id) ON DELETE CASCADE,
+    node_id BIGINT NOT NULL REFERENCES nodes(id) ON DELETE CASCADE,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(core_id, node_id)
+);
+-- =====================================================
+-- INDEXES
+-- =====================================================
+CREATE INDEX IF NOT EXISTS idx_cable_spans_cable ON cable_spans(cable_id);
+CREATE INDEX IF NOT EXISTS idx_cable_spans_from ON cable_spans(from_node_id);
+CREATE INDEX IF NOT EXISTS idx_cable_spans_to ON cable_spans(to_node_id);
+CREATE INDEX IF NOT EXISTS idx_core_breakouts_core ON cable_core_breakouts(core_id);
+CREATE INDEX IF NOT EXISTS idx_core_breakouts_node ON cable_core_breakouts(node_id);
@@ -0,0 +1,29 @@
+-- Migration: 003_color_schemes.sql
+-- Description: Custom fiber color-coding schemes and per-cable scheme selection
+-- Built-in schemes (TIA-598, IEC-60304) live in code; this table holds custom ones
+-- =====================================================
+-- COLOR_SCHEMES TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS color_schemes (
+    id BIGSERIAL PRIMARY KEY,
+    code VARCHAR(50) UNIQUE NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    unit VARCHAR(10) NOT NULL DEFAULT 'TUBE' CHECK (unit IN ('TUBE', 'RIBBON')),
+    fibers_per_tube INT NOT NULL CHECK (fibers_per_tube > 0),
+    tube_colors JSONB NOT NULL,
+    fiber_colors JSONB NOT NULL,
+    stripe_markers JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TRIGGER trigger_update_color_schemes_timestamp BEFORE
+UPDATE ON color_schemes FOR EACH ROW EXECUTE FUNCTION update_timestamp();
+-- =====================================================
+-- CABLES: selected scheme
+-- =====================================================
+ALTER TABLE cables
+ADD COLUMN IF NOT EXISTS color_scheme VARCHAR(50) NOT NULL DEFAULT 'TIA-598';
+-- Striped colors such as "Turquoise / Double Dashed Black" exceed 30 characters
+ALTER TABLE cable_cores ALTER COLUMN tube_color TYPE VARCHAR(60);
+ALTER TABLE cable_cores ALTER COLUMN core_color TYPE VARCHAR(60);
+CREATE INDEX IF NOT EXISTS idx_cables_color_scheme ON cables(color_scheme);
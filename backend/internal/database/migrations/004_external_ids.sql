@@ -0,0 +1,13 @@
+-- Migration: 004_external_ids.sql
+-- Description: Identifiers from external systems so repeated bulk imports update instead of duplicating
+-- =====================================================
+-- NODES / CABLES: external_id
+-- =====================================================
+ALTER TABLE nodes
+ADD COLUMN IF NOT EXISTS external_id VARCHAR(100);
+ALTER TABLE cables
+ADD COLUMN IF NOT EXISTS external_id VARCHAR(100);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_nodes_external_id ON nodes(external_id)
+WHERE external_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cables_external_id ON cables(external_id)
+WHERE external_id IS NOT NULL;
@@ -0,0 +1,32 @@
+-- Migration: 005_reservations.sql
+-- Description: Who holds a reserved core or port, why, and until when
+-- =====================================================
+-- RESERVATIONS TABLE (Planner holds on cores and ports)
+-- Ports have no table of their own, so element_id is not a foreign key
+-- =====================================================
+CREATE TABLE IF NOT EXISTS reservations (
+    id BIGSERIAL PRIMARY KEY,
+    element_type VARCHAR(10) NOT NULL CHECK (element_type IN ('CORE', 'PORT')),
+    element_id BIGINT NOT NULL,
+    owner VARCHAR(100) NOT NULL,
+    reason TEXT,
+    work_order VARCHAR(100),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE' CHECK (
+        status IN ('ACTIVE', 'RELEASED', 'EXPIRED')
+    ),
+    released_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TRIGGER trigger_update_reservations_timestamp BEFORE
+UPDATE ON reservations FOR EACH ROW EXECUTE FUNCTION update_timestamp();
+-- =====================================================
+-- INDEXES
+-- =====================================================
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reservations_active_element ON reservations(element_type, element_id)
+WHERE status = 'ACTIVE';
+CREATE INDEX IF NOT EXISTS idx_reservations_expiry ON reservations(expires_at)
+WHERE status = 'ACTIVE';
+CREATE INDEX IF NOT EXISTS idx_reservations_owner ON reservations(owner);
+CREATE INDEX IF NOT EXISTS idx_reservations_work_order ON reservations(work_order);
@@ -0,0 +1,52 @@
+-- Migration: 006_otdr_traces.sql
+-- Description: OTDR measurements (SR-4731 .sor files) attached to cable cores
+-- =====================================================
+-- OTDR_TRACES TABLE (One shot of a core from one of its ends)
+-- =====================================================
+CREATE TABLE IF NOT EXISTS otdr_traces (
+    id BIGSERIAL PRIMARY KEY,
+    core_id BIGINT NOT NULL REFERENCES cable_cores(id) ON DELETE CASCADE,
+    from_node_id BIGINT NOT NULL REFERENCES nodes(id) ON DELETE CASCADE,
+    file_name VARCHAR(255),
+    sor_version INT NOT NULL CHECK (sor_version IN (1, 2)),
+    wavelength_nm FLOAT NOT NULL,
+    pulse_width_ns INT,
+    group_index FLOAT NOT NULL,
+    fiber_length_meter FLOAT NOT NULL,
+    total_loss_db FLOAT NOT NULL,
+    orl_db FLOAT,
+    acquired_at TIMESTAMP WITH TIME ZONE,
+    supplier VARCHAR(100),
+    otdr_model VARCHAR(100),
+    otdr_serial VARCHAR(100),
+    cable_label VARCHAR(100),
+    fiber_label VARCHAR(100),
+    location_a VARCHAR(255),
+    location_b VARCHAR(255),
+    operator VARCHAR(100),
+    slack_pct FLOAT NOT NULL DEFAULT 2 CHECK (slack_pct >= 0),
+    node_slack_meter FLOAT NOT NULL DEFAULT 0 CHECK (node_slack_meter >= 0),
+    raw_file BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+-- =====================================================
+-- OTDR_EVENTS TABLE (Key events found on a trace)
+-- =====================================================
+CREATE TABLE IF NOT EXISTS otdr_events (
+    id BIGSERIAL PRIMARY KEY,
+    trace_id BIGINT NOT NULL REFERENCES otdr_traces(id) ON DELETE CASCADE,
+    event_number INT NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('SPLICE', 'CONNECTOR', 'END')),
+    code VARCHAR(10) NOT NULL,
+    distance_meter FLOAT NOT NULL,
+    loss_db FLOAT NOT NULL,
+    reflectance_db FLOAT,
+    attenuation_db_per_km FLOAT,
+    comment TEXT,
+    UNIQUE(trace_id, event_number)
+);
+-- =====================================================
+-- INDEXES
+-- =====================================================
+CREATE INDEX IF NOT EXISTS idx_otdr_traces_core ON otdr_traces(core_id);
+CREATE INDEX IF NOT EXISTS idx_otdr_events_trace ON otdr_events(trace_id);
@@ -0,0 +1,77 @@
+-- Migration: 007_tickets.sql
+-- Description: Trouble tickets with links to plant and customers, comments and status history
+-- =====================================================
+-- TICKETS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS tickets (
+    id BIGSERIAL PRIMARY KEY,
+    title VARCHAR(200) NOT NULL,
+    description TEXT,
+    category VARCHAR(50),
+    priority VARCHAR(20) NOT NULL DEFAULT 'MEDIUM' CHECK (
+        priority IN ('CRITICAL', 'HIGH', 'MEDIUM', 'LOW')
+    ),
+    status VARCHAR(20) NOT NULL DEFAULT 'OPEN' CHECK (
+        status IN ('OPEN', 'ASSIGNED', 'IN_PROGRESS', 'RESOLVED', 'CLOSED')
+    ),
+    assigned_team VARCHAR(100),
+    assignee VARCHAR(100),
+    reported_by VARCHAR(100),
+    resolution TEXT,
+    response_due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    resolution_due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    responded_at TIMESTAMP WITH TIME ZONE,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    closed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TRIGGER trigger_update_tickets_timestamp BEFORE
+UPDATE ON tickets FOR EACH ROW EXECUTE FUNCTION update_timestamp();
+-- =====================================================
+-- TICKET_LINKS TABLE (Nodes, cables, customers and alarms a ticket is about)
+-- Alarms come from the NMS, so element_id is not a foreign key
+-- =====================================================
+CREATE TABLE IF NOT EXISTS ticket_links (
+    id BIGSERIAL PRIMARY KEY,
+    ticket_id BIGINT NOT NULL REFERENCES tickets(id) ON DELETE CASCADE,
+    element_type VARCHAR(20) NOT NULL CHECK (
+        element_type IN ('NODE', 'CABLE', 'CUSTOMER', 'ALARM')
+    ),
+    element_id BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(ticket_id, element_type, element_id)
+);
+-- =====================================================
+-- TICKET_COMMENTS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS ticket_comments (
+    id BIGSERIAL PRIMARY KEY,
+    ticket_id BIGINT NOT NULL REFERENCES tickets(id) ON DELETE CASCADE,
+    author VARCHAR(100) NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+-- =====================================================
+-- TICKET_STATUS_HISTORY TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS ticket_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    ticket_id BIGINT NOT NULL REFERENCES tickets(id) ON DELETE CASCADE,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    changed_by VARCHAR(100),
+    note TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+-- =====================================================
+-- INDEXES
+-- =====================================================
+CREATE INDEX IF NOT EXISTS idx_tickets_status ON tickets(status);
+CREATE INDEX IF NOT EXISTS idx_tickets_queue ON tickets(priority, resolution_due_at)
+WHERE status NOT IN ('RESOLVED', 'CLOSED');
+CREATE INDEX IF NOT EXISTS idx_tickets_team ON tickets(assigned_team);
+CREATE INDEX IF NOT EXISTS idx_tickets_assignee ON tickets(assignee);
+CREATE INDEX IF NOT EXISTS idx_ticket_links_element ON ticket_links(element_type, element_id);
+CREATE INDEX IF NOT EXISTS idx_ticket_comments_ticket ON ticket_comments(ticket_id);
+CREATE INDEX IF NOT EXISTS idx_ticket_history_ticket ON ticket_status_history(ticket_id);
@@ -0,0 +1,98 @@
+-- Migration: 008_work_orders.sql
+-- Description: Planned installation and maintenance jobs with checklists, materials and design changes
+-- =====================================================
+-- WORK_ORDERS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS work_orders (
+    id BIGSERIAL PRIMARY KEY,
+    code VARCHAR(100) NOT NULL UNIQUE,
+    title VARCHAR(200) NOT NULL,
+    description TEXT,
+    type VARCHAR(20) NOT NULL CHECK (
+        type IN ('INSTALLATION', 'EXPANSION', 'RESPLICE', 'MAINTENANCE')
+    ),
+    status VARCHAR(20) NOT NULL DEFAULT 'PLANNED' CHECK (
+        status IN ('PLANNED', 'IN_PROGRESS', 'COMPLETED', 'CANCELLED')
+    ),
+    crew VARCHAR(100),
+    scheduled_start TIMESTAMP WITH TIME ZONE,
+    scheduled_end TIMESTAMP WITH TIME ZONE,
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    completed_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (scheduled_end IS NULL OR scheduled_start IS NULL OR scheduled_end >= scheduled_start)
+);
+CREATE TRIGGER trigger_update_work_orders_timestamp BEFORE
+UPDATE ON work_orders FOR EACH ROW EXECUTE FUNCTION update_timestamp();
+-- =====================================================
+-- WORK_ORDER_TASKS TABLE (Checklist)
+-- =====================================================
+CREATE TABLE IF NOT EXISTS work_order_tasks (
+    id BIGSERIAL PRIMARY KEY,
+    work_order_id BIGINT NOT NULL REFERENCES work_orders(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    description TEXT NOT NULL,
+    done BOOLEAN NOT NULL DEFAULT FALSE,
+    done_by VARCHAR(100),
+    done_at TIMESTAMP WITH TIME ZONE
+);
+-- =====================================================
+-- WORK_ORDER_MATERIALS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS work_order_materials (
+    id BIGSERIAL PRIMARY KEY,
+    work_order_id BIGINT NOT NULL REFERENCES work_orders(id) ON DELETE CASCADE,
+    item VARCHAR(100) NOT NULL,
+    quantity DECIMAL(12, 2) NOT NULL CHECK (quantity > 0),
+    unit VARCHAR(20) NOT NULL,
+    notes TEXT
+);
+-- =====================================================
+-- WORK_ORDER_ASSETS TABLE (Nodes, cables and customers the job targets)
+-- =====================================================
+CREATE TABLE IF NOT EXISTS work_order_assets (
+    id BIGSERIAL PRIMARY KEY,
+    work_order_id BIGINT NOT NULL REFERENCES work_orders(id) ON DELETE CASCADE,
+    element_type VARCHAR(20) NOT NULL CHECK (
+        element_type IN ('NODE', 'CABLE', 'CUSTOMER')
+    ),
+    element_id BIGINT NOT NULL,
+    UNIQUE(work_order_id, element_type, element_id)
+);
+-- =====================================================
+-- WORK_ORDER_CHANGES TABLE (Design changes applied on completion)
+-- connection_id is not a foreign key: removed splices no longer exist once applied
+-- =====================================================
+CREATE TABLE IF NOT EXISTS work_order_changes (
+    id BIGSERIAL PRIMARY KEY,
+    work_order_id BIGINT NOT NULL REFERENCES work_orders(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    change_type VARCHAR(20) NOT NULL CHECK (
+        change_type IN ('ADD_SPLICE', 'REMOVE_SPLICE', 'SET_CORE_STATUS')
+    ),
+    location_node_id BIGINT REFERENCES nodes(id) ON DELETE SET NULL,
+    input_type VARCHAR(10) CHECK (input_type IN ('CORE', 'PORT')),
+    input_id BIGINT,
+    output_type VARCHAR(10) CHECK (output_type IN ('CORE', 'PORT')),
+    output_id BIGINT,
+    loss_db DECIMAL(5, 2),
+    connection_id BIGINT,
+    core_id BIGINT REFERENCES cable_cores(id) ON DELETE CASCADE,
+    core_status VARCHAR(20) CHECK (
+        core_status IN ('VACANT', 'USED', 'RESERVED', 'DAMAGED')
+    ),
+    notes TEXT,
+    applied_at TIMESTAMP WITH TIME ZONE
+);
+-- =====================================================
+-- INDEXES
+-- =====================================================
+CREATE INDEX IF NOT EXISTS idx_work_orders_status ON work_orders(status);
+CREATE INDEX IF NOT EXISTS idx_work_orders_schedule ON work_orders(scheduled_start, scheduled_end);
+CREATE INDEX IF NOT EXISTS idx_work_orders_crew ON work_orders(crew);
+CREATE INDEX IF NOT EXISTS idx_work_order_tasks_order ON work_order_tasks(work_order_id);
+CREATE INDEX IF NOT EXISTS idx_work_order_materials_order ON work_order_materials(work_order_id);
+CREATE INDEX IF NOT EXISTS idx_work_order_assets_element ON work_order_assets(element_type, element_id);
+CREATE INDEX IF NOT EXISTS idx_work_order_changes_order ON work_order_changes(work_order_id);
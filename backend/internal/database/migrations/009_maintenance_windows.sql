@@ -0,0 +1,53 @@
+-- Migration: 009_maintenance_windows.sql
+-- Description: Planned maintenance windows, the assets they take down and the customers they affect
+-- =====================================================
+-- MAINTENANCE_WINDOWS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS maintenance_windows (
+    id BIGSERIAL PRIMARY KEY,
+    title VARCHAR(200) NOT NULL,
+    description TEXT,
+    work_order_id BIGINT REFERENCES work_orders(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'SCHEDULED' CHECK (
+        status IN ('SCHEDULED', 'ACTIVE', 'COMPLETED', 'CANCELLED')
+    ),
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    started_at TIMESTAMP WITH TIME ZONE,
+    ended_at TIMESTAMP WITH TIME ZONE,
+    created_by VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_at > starts_at)
+);
+CREATE TRIGGER trigger_update_maintenance_windows_timestamp BEFORE
+UPDATE ON maintenance_windows FOR EACH ROW EXECUTE FUNCTION update_timestamp();
+-- =====================================================
+-- MAINTENANCE_WINDOW_ASSETS TABLE (Nodes and cables flagged MAINTENANCE during the window)
+-- =====================================================
+CREATE TABLE IF NOT EXISTS maintenance_window_assets (
+    id BIGSERIAL PRIMARY KEY,
+    window_id BIGINT NOT NULL REFERENCES maintenance_windows(id) ON DELETE CASCADE,
+    element_type VARCHAR(10) NOT NULL CHECK (element_type IN ('NODE', 'CABLE')),
+    element_id BIGINT NOT NULL,
+    previous_status VARCHAR(20),
+    UNIQUE(window_id, element_type, element_id)
+);
+-- =====================================================
+-- MAINTENANCE_WINDOW_CUSTOMERS TABLE (Downstream customers computed from the topology)
+-- =====================================================
+CREATE TABLE IF NOT EXISTS maintenance_window_customers (
+    window_id BIGINT NOT NULL REFERENCES maintenance_windows(id) ON DELETE CASCADE,
+    customer_id BIGINT NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    PRIMARY KEY (window_id, customer_id)
+);
+-- =====================================================
+-- INDEXES
+-- =====================================================
+CREATE INDEX IF NOT EXISTS idx_maintenance_windows_status ON maintenance_windows(status);
+CREATE INDEX IF NOT EXISTS idx_maintenance_windows_due ON maintenance_windows(starts_at)
+WHERE status = 'SCHEDULED';
+CREATE INDEX IF NOT EXISTS idx_maintenance_windows_ending ON maintenance_windows(ends_at)
+WHERE status = 'ACTIVE';
+CREATE INDEX IF NOT EXISTS idx_maintenance_window_assets_element ON maintenance_window_assets(element_type, element_id);
+CREATE INDEX IF NOT EXISTS idx_maintenance_window_customers_customer ON maintenance_window_customers(customer_id);
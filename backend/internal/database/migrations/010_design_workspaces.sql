@@ -0,0 +1,47 @@
+-- Migration: 010_design_workspaces.sql
+-- Description: Design workspaces where planned nodes, cables, customers and splices are drafted,
+-- reviewed and merged into the live inventory as a unit
+-- =====================================================
+-- DESIGN_WORKSPACES TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS design_workspaces (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(200) NOT NULL,
+    description TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'DRAFT' CHECK (
+        status IN ('DRAFT', 'SUBMITTED', 'APPROVED', 'MERGED', 'DISCARDED')
+    ),
+    created_by VARCHAR(100),
+    submitted_by VARCHAR(100),
+    submitted_at TIMESTAMP WITH TIME ZONE,
+    reviewed_by VARCHAR(100),
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    review_note TEXT,
+    merged_by VARCHAR(100),
+    merged_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TRIGGER trigger_update_design_workspaces_timestamp BEFORE
+UPDATE ON design_workspaces FOR EACH ROW EXECUTE FUNCTION update_timestamp();
+-- =====================================================
+-- DESIGN_ITEMS TABLE (Drafted elements, kept as create-request payloads until merged)
+-- =====================================================
+CREATE TABLE IF NOT EXISTS design_items (
+    id BIGSERIAL PRIMARY KEY,
+    workspace_id BIGINT NOT NULL REFERENCES design_workspaces(id) ON DELETE CASCADE,
+    item_type VARCHAR(10) NOT NULL CHECK (
+        item_type IN ('NODE', 'CABLE', 'CUSTOMER', 'SPLICE')
+    ),
+    payload JSONB NOT NULL,
+    live_id BIGINT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TRIGGER trigger_update_design_items_timestamp BEFORE
+UPDATE ON design_items FOR EACH ROW EXECUTE FUNCTION update_timestamp();
+-- =====================================================
+-- INDEXES
+-- =====================================================
+CREATE INDEX IF NOT EXISTS idx_design_workspaces_status ON design_workspaces(status);
+CREATE INDEX IF NOT EXISTS idx_design_items_workspace ON design_items(workspace_id, item_type);
@@ -0,0 +1,30 @@
+-- Migration: 011_price_catalog.sql
+-- Description: Editable unit prices used to cost bills of materials
+-- =====================================================
+-- PRICE_CATALOG TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS price_catalog (
+    id BIGSERIAL PRIMARY KEY,
+    category VARCHAR(20) NOT NULL CHECK (
+        category IN (
+            'CABLE',
+            'OLT',
+            'ODC',
+            'ODP',
+            'CLOSURE',
+            'SPLITTER',
+            'SPLICE',
+            'POLE'
+        )
+    ),
+    spec VARCHAR(100) NOT NULL DEFAULT '',
+    description TEXT,
+    unit VARCHAR(20) NOT NULL DEFAULT 'pcs',
+    unit_price DECIMAL(14, 2) NOT NULL CHECK (unit_price >= 0),
+    currency VARCHAR(3) NOT NULL DEFAULT 'IDR',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(category, spec)
+);
+CREATE TRIGGER trigger_update_price_catalog_timestamp BEFORE
+UPDATE ON price_catalog FOR EACH ROW EXECUTE FUNCTION update_timestamp();
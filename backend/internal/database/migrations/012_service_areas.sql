@@ -0,0 +1,26 @@
+-- Migration: 012_service_areas.sql
+-- Description: Hierarchical service areas (region, district, cluster) and the area each node falls in
+-- =====================================================
+-- SERVICE_AREAS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS service_areas (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    level VARCHAR(20) NOT NULL CHECK (level IN ('REGION', 'DISTRICT', 'CLUSTER')),
+    parent_id BIGINT REFERENCES service_areas(id) ON DELETE RESTRICT,
+    polygon JSONB NOT NULL,
+    maintenance_team VARCHAR(100),
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((level = 'REGION') = (parent_id IS NULL))
+);
+CREATE INDEX IF NOT EXISTS idx_service_areas_parent ON service_areas(parent_id);
+CREATE TRIGGER trigger_update_service_areas_timestamp BEFORE
+UPDATE ON service_areas FOR EACH ROW EXECUTE FUNCTION update_timestamp();
+-- =====================================================
+-- NODES: area_id (deepest service area containing the node, assigned by the application)
+-- =====================================================
+ALTER TABLE nodes
+ADD COLUMN IF NOT EXISTS area_id BIGINT REFERENCES service_areas(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_nodes_area ON nodes(area_id);
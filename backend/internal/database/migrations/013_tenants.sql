@@ -0,0 +1,192 @@
+-- Migration: 013_tenants.sql
+-- Description: Tenants (partner ISPs or business units) and row-level security scoping every table to the
+-- tenant of the session. The API sets spectra.tenant_id on each connection it uses; an empty setting is the
+-- operator, who sees everything. Rows with no tenant_id belong to the operator.
+-- =====================================================
+-- TENANTS TABLE
+-- =====================================================
+CREATE TABLE IF NOT EXISTS tenants (
+    id BIGSERIAL PRIMARY KEY,
+    code VARCHAR(50) UNIQUE NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    contact_email VARCHAR(100),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TRIGGER trigger_update_tenants_timestamp BEFORE
+UPDATE ON tenants FOR EACH ROW EXECUTE FUNCTION update_timestamp();
+-- current_tenant_id is the tenant of the session, or NULL for the operator
+CREATE OR REPLACE FUNCTION current_tenant_id() RETURNS BIGINT AS $$
+SELECT NULLIF(current_setting('spectra.tenant_id', true), '')::BIGINT;
+$$ LANGUAGE sql STABLE;
+-- =====================================================
+-- SHARED INFRASTRUCTURE: nodes, cables, maintenance windows and service areas without a tenant are
+-- visible to and usable by every tenant; a tenant's own rows are private to it. Only the operator or the
+-- owning tenant can delete a row, and no tenant can hand a row to another.
+-- =====================================================
+ALTER TABLE nodes
+ADD COLUMN IF NOT EXISTS tenant_id BIGINT REFERENCES tenants(id) ON DELETE RESTRICT DEFAULT current_tenant_id();
+CREATE INDEX IF NOT EXISTS idx_nodes_tenant ON nodes(tenant_id);
+ALTER TABLE nodes ENABLE ROW LEVEL SECURITY;
+ALTER TABLE nodes FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_shared ON nodes FOR ALL
+USING (current_tenant_id() IS NULL OR tenant_id IS NULL OR tenant_id = current_tenant_id());
+CREATE POLICY tenant_owner_delete ON nodes AS RESTRICTIVE FOR DELETE
+USING (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+ALTER TABLE cables
+ADD COLUMN IF NOT EXISTS tenant_id BIGINT REFERENCES tenants(id) ON DELETE RESTRICT DEFAULT current_tenant_id();
+CREATE INDEX IF NOT EXISTS idx_cables_tenant ON cables(tenant_id);
+ALTER TABLE cables ENABLE ROW LEVEL SECURITY;
+ALTER TABLE cables FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_shared ON cables FOR ALL
+USING (current_tenant_id() IS NULL OR tenant_id IS NULL OR tenant_id = current_tenant_id());
+CREATE POLICY tenant_owner_delete ON cables AS RESTRICTIVE FOR DELETE
+USING (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+ALTER TABLE maintenance_windows
+ADD COLUMN IF NOT EXISTS tenant_id BIGINT REFERENCES tenants(id) ON DELETE RESTRICT DEFAULT current_tenant_id();
+CREATE INDEX IF NOT EXISTS idx_maintenance_windows_tenant ON maintenance_windows(tenant_id);
+ALTER TABLE maintenance_windows ENABLE ROW LEVEL SECURITY;
+ALTER TABLE maintenance_windows FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_shared ON maintenance_windows FOR ALL
+USING (current_tenant_id() IS NULL OR tenant_id IS NULL OR tenant_id = current_tenant_id());
+CREATE POLICY tenant_owner_delete ON maintenance_windows AS RESTRICTIVE FOR DELETE
+USING (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+ALTER TABLE service_areas
+ADD COLUMN IF NOT EXISTS tenant_id BIGINT REFERENCES tenants(id) ON DELETE RESTRICT DEFAULT current_tenant_id();
+CREATE INDEX IF NOT EXISTS idx_service_areas_tenant ON service_areas(tenant_id);
+ALTER TABLE service_areas ENABLE ROW LEVEL SECURITY;
+ALTER TABLE service_areas FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_shared ON service_areas FOR ALL
+USING (current_tenant_id() IS NULL OR tenant_id IS NULL OR tenant_id = current_tenant_id());
+CREATE POLICY tenant_owner_delete ON service_areas AS RESTRICTIVE FOR DELETE
+USING (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+-- =====================================================
+-- CONNECTIONS: every splice and port assignment is visible so that shared ports are never handed out
+-- twice, but only the operator or the owning tenant can change or delete one
+-- =====================================================
+ALTER TABLE connections
+ADD COLUMN IF NOT EXISTS tenant_id BIGINT REFERENCES tenants(id) ON DELETE RESTRICT DEFAULT current_tenant_id();
+CREATE INDEX IF NOT EXISTS idx_connections_tenant ON connections(tenant_id);
+ALTER TABLE connections ENABLE ROW LEVEL SECURITY;
+ALTER TABLE connections FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_read ON connections FOR SELECT USING (true);
+CREATE POLICY tenant_insert ON connections FOR INSERT
+WITH CHECK (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+CREATE POLICY tenant_update ON connections FOR UPDATE
+USING (current_tenant_id() IS NULL OR tenant_id IS NULL OR tenant_id = current_tenant_id())
+WITH CHECK (current_tenant_id() IS NULL OR tenant_id IS NULL OR tenant_id = current_tenant_id());
+CREATE POLICY tenant_delete ON connections FOR DELETE
+USING (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+-- =====================================================
+-- TENANT-OWNED: cores, customers, reservations, OTDR traces, tickets, work orders and design workspaces
+-- are visible only to their tenant. Cores without a tenant are the operator's spare fibers.
+-- =====================================================
+ALTER TABLE cable_cores
+ADD COLUMN IF NOT EXISTS tenant_id BIGINT REFERENCES tenants(id) ON DELETE RESTRICT DEFAULT current_tenant_id();
+CREATE INDEX IF NOT EXISTS idx_cable_cores_tenant ON cable_cores(tenant_id);
+ALTER TABLE cable_cores ENABLE ROW LEVEL SECURITY;
+ALTER TABLE cable_cores FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_owned ON cable_cores FOR ALL
+USING (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+ALTER TABLE customers
+ADD COLUMN IF NOT EXISTS tenant_id BIGINT REFERENCES tenants(id) ON DELETE RESTRICT DEFAULT current_tenant_id();
+CREATE INDEX IF NOT EXISTS idx_customers_tenant ON customers(tenant_id);
+ALTER TABLE customers ENABLE ROW LEVEL SECURITY;
+ALTER TABLE customers FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_owned ON customers FOR ALL
+USING (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+ALTER TABLE reservations
+ADD COLUMN IF NOT EXISTS tenant_id BIGINT REFERENCES tenants(id) ON DELETE RESTRICT DEFAULT current_tenant_id();
+CREATE INDEX IF NOT EXISTS idx_reservations_tenant ON reservations(tenant_id);
+ALTER TABLE reservations ENABLE ROW LEVEL SECURITY;
+ALTER TABLE reservations FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_owned ON reservations FOR ALL
+USING (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+ALTER TABLE otdr_traces
+ADD COLUMN IF NOT EXISTS tenant_id BIGINT REFERENCES tenants(id) ON DELETE RESTRICT DEFAULT current_tenant_id();
+CREATE INDEX IF NOT EXISTS idx_otdr_traces_tenant ON otdr_traces(tenant_id);
+ALTER TABLE otdr_traces ENABLE ROW LEVEL SECURITY;
+ALTER TABLE otdr_traces FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_owned ON otdr_traces FOR ALL
+USING (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+ALTER TABLE tickets
+ADD COLUMN IF NOT EXISTS tenant_id BIGINT REFERENCES tenants(id) ON DELETE RESTRICT DEFAULT current_tenant_id();
+CREATE INDEX IF NOT EXISTS idx_tickets_tenant ON tickets(tenant_id);
+ALTER TABLE tickets ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tickets FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_owned ON tickets FOR ALL
+USING (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+ALTER TABLE work_orders
+ADD COLUMN IF NOT EXISTS tenant_id BIGINT REFERENCES tenants(id) ON DELETE RESTRICT DEFAULT current_tenant_id();
+CREATE INDEX IF NOT EXISTS idx_work_orders_tenant ON work_orders(tenant_id);
+ALTER TABLE work_orders ENABLE ROW LEVEL SECURITY;
+ALTER TABLE work_orders FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_owned ON work_orders FOR ALL
+USING (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+ALTER TABLE design_workspaces
+ADD COLUMN IF NOT EXISTS tenant_id BIGINT REFERENCES tenants(id) ON DELETE RESTRICT DEFAULT current_tenant_id();
+CREATE INDEX IF NOT EXISTS idx_design_workspaces_tenant ON design_workspaces(tenant_id);
+ALTER TABLE design_workspaces ENABLE ROW LEVEL SECURITY;
+ALTER TABLE design_workspaces FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_owned ON design_workspaces FOR ALL
+USING (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+-- =====================================================
+-- CHILD TABLES: visible exactly when their parent row is
+-- =====================================================
+ALTER TABLE cable_spans ENABLE ROW LEVEL SECURITY;
+ALTER TABLE cable_spans FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_parent ON cable_spans FOR ALL
+USING (EXISTS (SELECT 1 FROM cables p WHERE p.id = cable_spans.cable_id));
+ALTER TABLE cable_core_breakouts ENABLE ROW LEVEL SECURITY;
+ALTER TABLE cable_core_breakouts FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_parent ON cable_core_breakouts FOR ALL
+USING (EXISTS (SELECT 1 FROM cable_cores p WHERE p.id = cable_core_breakouts.core_id));
+ALTER TABLE otdr_events ENABLE ROW LEVEL SECURITY;
+ALTER TABLE otdr_events FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_parent ON otdr_events FOR ALL
+USING (EXISTS (SELECT 1 FROM otdr_traces p WHERE p.id = otdr_events.trace_id));
+ALTER TABLE ticket_links ENABLE ROW LEVEL SECURITY;
+ALTER TABLE ticket_links FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_parent ON ticket_links FOR ALL
+USING (EXISTS (SELECT 1 FROM tickets p WHERE p.id = ticket_links.ticket_id));
+ALTER TABLE ticket_comments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE ticket_comments FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_parent ON ticket_comments FOR ALL
+USING (EXISTS (SELECT 1 FROM tickets p WHERE p.id = ticket_comments.ticket_id));
+ALTER TABLE ticket_status_history ENABLE ROW LEVEL SECURITY;
+ALTER TABLE ticket_status_history FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_parent ON ticket_status_history FOR ALL
+USING (EXISTS (SELECT 1 FROM tickets p WHERE p.id = ticket_status_history.ticket_id));
+ALTER TABLE work_order_tasks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE work_order_tasks FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_parent ON work_order_tasks FOR ALL
+USING (EXISTS (SELECT 1 FROM work_orders p WHERE p.id = work_order_tasks.work_order_id));
+ALTER TABLE work_order_materials ENABLE ROW LEVEL SECURITY;
+ALTER TABLE work_order_materials FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_parent ON work_order_materials FOR ALL
+USING (EXISTS (SELECT 1 FROM work_orders p WHERE p.id = work_order_materials.work_order_id));
+ALTER TABLE work_order_assets ENABLE ROW LEVEL SECURITY;
+ALTER TABLE work_order_assets FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_parent ON work_order_assets FOR ALL
+USING (EXISTS (SELECT 1 FROM work_orders p WHERE p.id = work_order_assets.work_order_id));
+ALTER TABLE work_order_changes ENABLE ROW LEVEL SECURITY;
+ALTER TABLE work_order_changes FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_parent ON work_order_changes FOR ALL
+USING (EXISTS (SELECT 1 FROM work_orders p WHERE p.id = work_order_changes.work_order_id));
+ALTER TABLE maintenance_window_assets ENABLE ROW LEVEL SECURITY;
+ALTER TABLE maintenance_window_assets FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_parent ON maintenance_window_assets FOR ALL
+USING (EXISTS (SELECT 1 FROM maintenance_windows p WHERE p.id = maintenance_window_assets.window_id));
+ALTER TABLE maintenance_window_customers ENABLE ROW LEVEL SECURITY;
+ALTER TABLE maintenance_window_customers FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_parent ON maintenance_window_customers FOR ALL
+USING (EXISTS (SELECT 1 FROM maintenance_windows p WHERE p.id = maintenance_window_customers.window_id));
+ALTER TABLE design_items ENABLE ROW LEVEL SECURITY;
+ALTER TABLE design_items FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_parent ON design_items FOR ALL
+USING (EXISTS (SELECT 1 FROM design_workspaces p WHERE p.id = design_items.workspace_id));
+-- A shared window lists only the tenant's own affected customers
+CREATE POLICY tenant_customer ON maintenance_window_customers AS RESTRICTIVE FOR SELECT
+USING (EXISTS (SELECT 1 FROM customers c WHERE c.id = maintenance_window_customers.customer_id));
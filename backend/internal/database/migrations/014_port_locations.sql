@@ -0,0 +1,27 @@
+-- Migration: 014_port_locations.sql
+-- Description: Ports have no table of their own and are numbered from 1 on each node, so a port is identified
+-- by the node it sits on together with its number. Connections to a port must name their location, and port
+-- reservations record the node. Existing rows without one are left as they are.
+-- =====================================================
+-- CONNECTIONS: ports are located
+-- =====================================================
+ALTER TABLE connections DROP CONSTRAINT IF EXISTS chk_connections_port_location;
+ALTER TABLE connections
+ADD CONSTRAINT chk_connections_port_location CHECK (
+        (input_type <> 'PORT' AND output_type <> 'PORT') OR location_node_id IS NOT NULL
+    ) NOT VALID;
+-- =====================================================
+-- RESERVATIONS: port reservations name the node
+-- =====================================================
+ALTER TABLE reservations
+ADD COLUMN IF NOT EXISTS location_node_id BIGINT REFERENCES nodes(id) ON DELETE CASCADE;
+ALTER TABLE reservations DROP CONSTRAINT IF EXISTS chk_reservations_port_location;
+ALTER TABLE reservations
+ADD CONSTRAINT chk_reservations_port_location CHECK (
+        element_type <> 'PORT' OR location_node_id IS NOT NULL
+    ) NOT VALID;
+DROP INDEX IF EXISTS idx_reservations_active_element;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reservations_active_element ON reservations(element_type, COALESCE(location_node_id, 0), element_id)
+WHERE status = 'ACTIVE';
+CREATE INDEX IF NOT EXISTS idx_reservations_location ON reservations(location_node_id)
+WHERE location_node_id IS NOT NULL;
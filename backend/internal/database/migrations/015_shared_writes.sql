@@ -0,0 +1,71 @@
+-- Migration: 015_shared_writes.sql
+-- Description: Shared nodes, cables, maintenance windows and service areas are read-only to tenants. The policy
+-- that made them visible also served as the write check, so a tenant could change a shared row or give one of
+-- its own rows away. Tenants now only write rows of their own; the few writes a tenant makes to shared plant
+-- go through functions that run them as the operator.
+-- =====================================================
+-- SHARED INFRASTRUCTURE: visible to all, written by the operator or the owning tenant
+-- =====================================================
+DROP POLICY IF EXISTS tenant_shared ON nodes;
+DROP POLICY IF EXISTS tenant_owner_delete ON nodes;
+CREATE POLICY tenant_shared ON nodes FOR SELECT
+USING (current_tenant_id() IS NULL OR tenant_id IS NULL OR tenant_id = current_tenant_id());
+CREATE POLICY tenant_insert ON nodes FOR INSERT
+WITH CHECK (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+CREATE POLICY tenant_update ON nodes FOR UPDATE
+USING (current_tenant_id() IS NULL OR tenant_id = current_tenant_id())
+WITH CHECK (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+CREATE POLICY tenant_delete ON nodes FOR DELETE
+USING (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+DROP POLICY IF EXISTS tenant_shared ON cables;
+DROP POLICY IF EXISTS tenant_owner_delete ON cables;
+CREATE POLICY tenant_shared ON cables FOR SELECT
+USING (current_tenant_id() IS NULL OR tenant_id IS NULL OR tenant_id = current_tenant_id());
+CREATE POLICY tenant_insert ON cables FOR INSERT
+WITH CHECK (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+CREATE POLICY tenant_update ON cables FOR UPDATE
+USING (current_tenant_id() IS NULL OR tenant_id = current_tenant_id())
+WITH CHECK (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+CREATE POLICY tenant_delete ON cables FOR DELETE
+USING (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+DROP POLICY IF EXISTS tenant_shared ON maintenance_windows;
+DROP POLICY IF EXISTS tenant_owner_delete ON maintenance_windows;
+CREATE POLICY tenant_shared ON maintenance_windows FOR SELECT
+USING (current_tenant_id() IS NULL OR tenant_id IS NULL OR tenant_id = current_tenant_id());
+CREATE POLICY tenant_insert ON maintenance_windows FOR INSERT
+WITH CHECK (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+CREATE POLICY tenant_update ON maintenance_windows FOR UPDATE
+USING (current_tenant_id() IS NULL OR tenant_id = current_tenant_id())
+WITH CHECK (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+CREATE POLICY tenant_delete ON maintenance_windows FOR DELETE
+USING (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+DROP POLICY IF EXISTS tenant_shared ON service_areas;
+DROP POLICY IF EXISTS tenant_owner_delete ON service_areas;
+CREATE POLICY tenant_shared ON service_areas FOR SELECT
+USING (current_tenant_id() IS NULL OR tenant_id IS NULL OR tenant_id = current_tenant_id());
+CREATE POLICY tenant_insert ON service_areas FOR INSERT
+WITH CHECK (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+CREATE POLICY tenant_update ON service_areas FOR UPDATE
+USING (current_tenant_id() IS NULL OR tenant_id = current_tenant_id())
+WITH CHECK (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+CREATE POLICY tenant_delete ON service_areas FOR DELETE
+USING (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+-- =====================================================
+-- OPERATOR FUNCTIONS: each clears the tenant for its own duration only
+-- =====================================================
+-- lock_node locks a node whoever owns it, so that drops to a shared ODP, which tenants cannot update,
+-- still take their ports one at a time
+CREATE OR REPLACE FUNCTION lock_node(node BIGINT) RETURNS VOID AS $$ BEGIN
+PERFORM 1 FROM nodes WHERE id = node FOR UPDATE;
+END;
+$$ LANGUAGE plpgsql SET spectra.tenant_id = '';
+-- add_used_port counts one more used port on a node whoever owns it
+CREATE OR REPLACE FUNCTION add_used_port(node BIGINT) RETURNS VOID AS $$ BEGIN
+UPDATE nodes SET used_ports = used_ports + 1 WHERE id = node;
+END;
+$$ LANGUAGE plpgsql SET spectra.tenant_id = '';
+-- foreign_core_count counts the cores of a cable that do not belong to tenant, or 0 when tenant is NULL
+-- (the operator, who sees them all)
+CREATE OR REPLACE FUNCTION foreign_core_count(cable BIGINT, tenant BIGINT) RETURNS BIGINT AS $$
+SELECT COUNT(*) FROM cable_cores WHERE tenant IS NOT NULL AND cable_id = cable AND tenant_id IS DISTINCT FROM tenant;
+$$ LANGUAGE sql STABLE SET spectra.tenant_id = '';
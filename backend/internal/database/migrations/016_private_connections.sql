@@ -0,0 +1,33 @@
+-- Migration: 016_private_connections.sql
+-- Description: A tenant's splices and port assignments are private to it, like its cores; shared connections
+-- stay visible to all but only the operator changes them. Whether a port is free is still known across tenants
+-- through ports_in_use, so shared ports are not handed out twice.
+-- =====================================================
+-- CONNECTIONS: own and shared rows only
+-- =====================================================
+DROP POLICY IF EXISTS tenant_read ON connections;
+DROP POLICY IF EXISTS tenant_update ON connections;
+CREATE POLICY tenant_read ON connections FOR SELECT
+USING (current_tenant_id() IS NULL OR tenant_id IS NULL OR tenant_id = current_tenant_id());
+CREATE POLICY tenant_update ON connections FOR UPDATE
+USING (current_tenant_id() IS NULL OR tenant_id = current_tenant_id())
+WITH CHECK (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+-- ports_in_use lists the ports of a node that have a connection or an active reservation, whoever made it
+CREATE OR REPLACE FUNCTION ports_in_use(node BIGINT) RETURNS SETOF BIGINT AS $$
+SELECT input_id FROM connections WHERE location_node_id = node AND input_type = 'PORT'
+UNION
+SELECT output_id FROM connections WHERE location_node_id = node AND output_type = 'PORT'
+UNION
+SELECT element_id FROM reservations WHERE location_node_id = node AND element_type = 'PORT' AND status = 'ACTIVE';
+$$ LANGUAGE sql STABLE SET spectra.tenant_id = '';
+-- =====================================================
+-- DROP NOTES: name the drop cable rather than the customer
+-- =====================================================
+UPDATE connections c
+SET notes = 'Drop cable #' || cc.cable_id
+FROM cable_cores cc
+    JOIN cables k ON k.id = cc.cable_id
+WHERE c.notes LIKE 'Drop to %'
+    AND c.output_type = 'CORE'
+    AND c.output_id = cc.id
+    AND k.type = 'DROP';
@@ -0,0 +1,24 @@
+-- Migration: 017_tenant_keys.sql
+-- Description: External IDs, ONT serials and work order codes are unique per tenant rather than globally. A
+-- global key told a tenant that another tenant used a value, and let an import keyed on it reach a row of
+-- someone else. Rows without a tenant (the operator's) share one namespace.
+-- =====================================================
+-- NODES / CABLES: external_id
+-- =====================================================
+DROP INDEX IF EXISTS idx_nodes_external_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_nodes_external_id ON nodes(external_id, COALESCE(tenant_id, 0))
+WHERE external_id IS NOT NULL;
+DROP INDEX IF EXISTS idx_cables_external_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cables_external_id ON cables(external_id, COALESCE(tenant_id, 0))
+WHERE external_id IS NOT NULL;
+-- =====================================================
+-- CUSTOMERS: ont_sn
+-- =====================================================
+ALTER TABLE customers DROP CONSTRAINT IF EXISTS customers_ont_sn_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_customers_ont_sn ON customers(ont_sn, COALESCE(tenant_id, 0))
+WHERE ont_sn IS NOT NULL;
+-- =====================================================
+-- WORK_ORDERS: code
+-- =====================================================
+ALTER TABLE work_orders DROP CONSTRAINT IF EXISTS work_orders_code_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_work_orders_code ON work_orders(code, COALESCE(tenant_id, 0));
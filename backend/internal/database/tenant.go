@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// tenantSetting is the session setting row-level security policies read the tenant from
+const tenantSetting = "spectra.tenant_id"
+
+type tenantKey struct{}
+
+type operatorKey struct{}
+
+// WithTenant scopes a context to a tenant. Every query run with it, or with a context derived from
+// it, only sees the rows row-level security lets that tenant see.
+func WithTenant(ctx context.Context, tenantID int64) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantID returns the tenant a context is scoped to. Contexts without a tenant act for the operator.
+func TenantID(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(tenantKey{}).(int64)
+	return id, ok
+}
+
+// WithOperator marks a context as belonging to a caller that authenticated as the operator
+func WithOperator(ctx context.Context) context.Context {
+	return context.WithValue(ctx, operatorKey{}, true)
+}
+
+// IsOperator reports whether a context belongs to an authenticated operator and is not scoped to a tenant
+func IsOperator(ctx context.Context) bool {
+	if _, ok := TenantID(ctx); ok {
+		return false
+	}
+	operator, _ := ctx.Value(operatorKey{}).(bool)
+	return operator
+}
+
+// prepareTenantConn sets the tenant of the acquiring context on a connection before it is used,
+// clearing whatever tenant the connection's previous user had
+func prepareTenantConn(ctx context.Context, conn *pgx.Conn) (bool, error) {
+	value := ""
+	if id, ok := TenantID(ctx); ok {
+		value = strconv.FormatInt(id, 10)
+	}
+	if _, err := conn.Exec(ctx, "SELECT set_config($1, $2, false)", tenantSetting, value); err != nil {
+		return false, fmt.Errorf("failed to set tenant: %w", err)
+	}
+	return true, nil
+}
+
+// CheckTenantIsolation reports whether row-level security applies to the connected role.
+// Superusers and roles with BYPASSRLS see every tenant's rows regardless of scoping.
+func CheckTenantIsolation(ctx context.Context, pool *pgxpool.Pool) (bool, error) {
+	var bypass bool
+	err := pool.QueryRow(ctx, "SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user").Scan(&bypass)
+	if err != nil {
+		return false, fmt.Errorf("failed to check database role: %w", err)
+	}
+	return !bypass, nil
+}
@@ -0,0 +1,240 @@
+package geo
+
+import (
+	"math"
+	"sort"
+)
+
+// EarthRadiusMeters is the mean Earth radius used for distance calculations
+const EarthRadiusMeters = 6371000.0
+
+// HaversineMeters returns the great-circle distance in meters between two points
+func HaversineMeters(lat1, lng1, lat2, lng2 float64) float64 {
+	dLat := toRadians(lat2 - lat1)
+	dLng := toRadians(lng2 - lng1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+
+	return EarthRadiusMeters * c
+}
+
+// PathLengthMeters returns the length in meters of a [[lng, lat], ...] path
+func PathLengthMeters(path [][]float64) float64 {
+	total := 0.0
+	for i := 1; i < len(path); i++ {
+		if len(path[i-1]) < 2 || len(path[i]) < 2 {
+			continue
+		}
+		total += HaversineMeters(path[i-1][1], path[i-1][0], path[i][1], path[i][0])
+	}
+	return total
+}
+
+// toRadians converts degrees to radians
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// PathPosition describes where a point projects onto a [[lng, lat], ...] path
+type PathPosition struct {
+	SegmentIndex        int       // Index of the path vertex the projected point follows
+	Point               []float64 // Projected point as [lng, lat]
+	DistanceAlongMeters float64   // Distance from the start of the path to the projected point
+	OffsetMeters        float64   // Distance from the original point to the projected point
+}
+
+// NearestPointOnPath projects a point onto the closest segment of a path
+// Segments are treated as straight lines in a local equirectangular projection, which is
+// accurate enough for the short spans found in access networks
+func NearestPointOnPath(path [][]float64, lat, lng float64) (PathPosition, bool) {
+	best := PathPosition{}
+	found := false
+	along := 0.0
+
+	for i := 1; i < len(path); i++ {
+		a, b := path[i-1], path[i]
+		if len(a) < 2 || len(b) < 2 {
+			continue
+		}
+
+		// Local planar coordinates in meters relative to a
+		cosLat := math.Cos(toRadians(a[1]))
+		bx := toRadians(b[0]-a[0]) * cosLat * EarthRadiusMeters
+		by := toRadians(b[1]-a[1]) * EarthRadiusMeters
+		px := toRadians(lng-a[0]) * cosLat * EarthRadiusMeters
+		py := toRadians(lat-a[1]) * EarthRadiusMeters
+
+		t := 0.0
+		if lenSq := bx*bx + by*by; lenSq > 0 {
+			t = (px*bx + py*by) / lenSq
+		}
+		t = math.Max(0, math.Min(1, t))
+
+		point := []float64{a[0] + (b[0]-a[0])*t, a[1] + (b[1]-a[1])*t}
+		offset := HaversineMeters(lat, lng, point[1], point[0])
+		segmentLength := HaversineMeters(a[1], a[0], b[1], b[0])
+
+		if !found || offset < best.OffsetMeters {
+			best = PathPosition{
+				SegmentIndex:        i - 1,
+				Point:               point,
+				DistanceAlongMeters: along + segmentLength*t,
+				OffsetMeters:        offset,
+			}
+			found = true
+		}
+
+		along += segmentLength
+	}
+
+	return best, found
+}
+
+// SplitPath splits a path at a projected position, duplicating the split point on both halves
+func SplitPath(path [][]float64, pos PathPosition) ([][]float64, [][]float64) {
+	first := make([][]float64, 0, pos.SegmentIndex+2)
+	first = append(first, path[:pos.SegmentIndex+1]...)
+	if !samePoint(first[len(first)-1], pos.Point) {
+		first = append(first, pos.Point)
+	}
+
+	second := [][]float64{pos.Point}
+	for _, p := range path[pos.SegmentIndex+1:] {
+		if len(second) == 1 && samePoint(p, pos.Point) {
+			continue
+		}
+		second = append(second, p)
+	}
+
+	return first, second
+}
+
+// PointAlongPath returns the [lng, lat] point a distance in meters from the start of a path,
+// clamped to its ends
+func PointAlongPath(path [][]float64, meters float64) []float64 {
+	if len(path) == 0 {
+		return nil
+	}
+	along := 0.0
+	for i := 1; i < len(path); i++ {
+		a, b := path[i-1], path[i]
+		if len(a) < 2 || len(b) < 2 {
+			continue
+		}
+		segmentLength := HaversineMeters(a[1], a[0], b[1], b[0])
+		if along+segmentLength >= meters && segmentLength > 0 {
+			t := math.Max(0, (meters-along)/segmentLength)
+			return []float64{a[0] + (b[0]-a[0])*t, a[1] + (b[1]-a[1])*t}
+		}
+		along += segmentLength
+	}
+	if meters <= 0 {
+		return path[0]
+	}
+	return path[len(path)-1]
+}
+
+// SubPath returns the part of a path between two distances in meters from its start
+func SubPath(path [][]float64, startMeters, endMeters float64) [][]float64 {
+	if len(path) < 2 || endMeters < startMeters {
+		return nil
+	}
+	sub := [][]float64{PointAlongPath(path, startMeters)}
+	along := 0.0
+	for i := 1; i < len(path); i++ {
+		a, b := path[i-1], path[i]
+		if len(a) < 2 || len(b) < 2 {
+			continue
+		}
+		along += HaversineMeters(a[1], a[0], b[1], b[0])
+		if along > startMeters && along < endMeters {
+			sub = append(sub, b)
+		}
+	}
+	if end := PointAlongPath(path, endMeters); !samePoint(sub[len(sub)-1], end) {
+		sub = append(sub, end)
+	}
+	return sub
+}
+
+// samePoint reports whether two [lng, lat] points are identical
+func samePoint(a, b []float64) bool {
+	return len(a) >= 2 && len(b) >= 2 && a[0] == b[0] && a[1] == b[1]
+}
+
+// ConvexHull returns the convex hull of [lng, lat] points as a closed counter-clockwise ring
+// (Andrew's monotone chain on planar coordinates). Fewer than three distinct points give nil.
+func ConvexHull(points [][]float64) [][]float64 {
+	pts := make([][]float64, 0, len(points))
+	for _, p := range points {
+		if len(p) >= 2 {
+			pts = append(pts, []float64{p[0], p[1]})
+		}
+	}
+	sort.Slice(pts, func(i, j int) bool {
+		if pts[i][0] != pts[j][0] {
+			return pts[i][0] < pts[j][0]
+		}
+		return pts[i][1] < pts[j][1]
+	})
+
+	cross := func(o, a, b []float64) float64 {
+		return (a[0]-o[0])*(b[1]-o[1]) - (a[1]-o[1])*(b[0]-o[0])
+	}
+
+	hull := make([][]float64, 0, 2*len(pts))
+	for _, p := range pts {
+		for len(hull) >= 2 && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	lower := len(hull) + 1
+	for i := len(pts) - 2; i >= 0; i-- {
+		for len(hull) >= lower && cross(hull[len(hull)-2], hull[len(hull)-1], pts[i]) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, pts[i])
+	}
+
+	// The last point repeats the first, closing the ring
+	if len(hull) < 4 {
+		return nil
+	}
+	return hull
+}
+
+// Circle approximates a circle of the given radius around a point as a closed counter-clockwise
+// [lng, lat] ring with the given number of segments
+func Circle(lat, lng, radiusMeters float64, segments int) [][]float64 {
+	if segments < 3 {
+		segments = 3
+	}
+	dLat := radiusMeters / EarthRadiusMeters * 180 / math.Pi
+	dLng := dLat / math.Cos(toRadians(lat))
+
+	ring := make([][]float64, 0, segments+1)
+	for i := 0; i < segments; i++ {
+		angle := 2 * math.Pi * float64(i) / float64(segments)
+		ring = append(ring, []float64{lng + dLng*math.Cos(angle), lat + dLat*math.Sin(angle)})
+	}
+	return append(ring, []float64{ring[0][0], ring[0][1]})
+}
+
+// PointInPolygon reports whether a point lies inside a [lng, lat] ring (even-odd rule on planar
+// coordinates). The ring may be open or closed; points on an edge may fall either way.
+func PointInPolygon(lng, lat float64, ring [][]float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if len(a) < 2 || len(b) < 2 {
+			continue
+		}
+		if (a[1] > lat) != (b[1] > lat) && lng < (b[0]-a[0])*(lat-a[1])/(b[1]-a[1])+a[0] {
+			inside = !inside
+		}
+	}
+	return inside
+}
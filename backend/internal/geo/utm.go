@@ -0,0 +1,110 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// WGS84 ellipsoid and UTM constants
+const (
+	wgs84A        = 6378137.0
+	wgs84F        = 1 / 298.257223563
+	utmScale      = 0.9996
+	utmFalseEast  = 500000.0
+	utmFalseNorth = 10000000.0
+)
+
+// UTMZone identifies a WGS 84 / UTM zone
+type UTMZone struct {
+	Number int  // 1-60
+	North  bool // Northern hemisphere
+}
+
+// String formats the zone as e.g. "48S"
+func (z UTMZone) String() string {
+	if z.North {
+		return fmt.Sprintf("%dN", z.Number)
+	}
+	return fmt.Sprintf("%dS", z.Number)
+}
+
+// EPSG returns the EPSG code of the zone (326xx north, 327xx south)
+func (z UTMZone) EPSG() int {
+	if z.North {
+		return 32600 + z.Number
+	}
+	return 32700 + z.Number
+}
+
+// CentralMeridian returns the zone's central meridian in degrees
+func (z UTMZone) CentralMeridian() float64 {
+	return float64(z.Number-1)*6 - 180 + 3
+}
+
+// UTMZoneFor returns the standard UTM zone containing a point (the Norway/Svalbard exceptions are ignored)
+func UTMZoneFor(lat, lng float64) UTMZone {
+	number := int(math.Floor((lng+180)/6)) + 1
+	if number > 60 {
+		number = 60
+	}
+	if number < 1 {
+		number = 1
+	}
+	return UTMZone{Number: number, North: lat >= 0}
+}
+
+// ParseUTMZone parses zones written as "48S", "48N" or "48" (north)
+func ParseUTMZone(s string) (UTMZone, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	zone := UTMZone{North: true}
+	switch {
+	case strings.HasSuffix(s, "S"):
+		zone.North = false
+		s = strings.TrimSuffix(s, "S")
+	case strings.HasSuffix(s, "N"):
+		s = strings.TrimSuffix(s, "N")
+	}
+	number, err := strconv.Atoi(s)
+	if err != nil || number < 1 || number > 60 {
+		return zone, fmt.Errorf("invalid UTM zone %q", s)
+	}
+	zone.Number = number
+	return zone, nil
+}
+
+// ToUTM projects a WGS 84 point to easting and northing in meters in the given zone,
+// using the transverse Mercator series from Snyder's "Map Projections: A Working Manual"
+func ToUTM(lat, lng float64, zone UTMZone) (float64, float64) {
+	e2 := wgs84F * (2 - wgs84F)
+	e4 := e2 * e2
+	e6 := e4 * e2
+	ep2 := e2 / (1 - e2)
+
+	phi := toRadians(lat)
+	lambda := toRadians(lng - zone.CentralMeridian())
+
+	sinPhi, cosPhi, tanPhi := math.Sin(phi), math.Cos(phi), math.Tan(phi)
+	n := wgs84A / math.Sqrt(1-e2*sinPhi*sinPhi)
+	t := tanPhi * tanPhi
+	c := ep2 * cosPhi * cosPhi
+	a := cosPhi * lambda
+
+	m := wgs84A * ((1-e2/4-3*e4/64-5*e6/256)*phi -
+		(3*e2/8+3*e4/32+45*e6/1024)*math.Sin(2*phi) +
+		(15*e4/256+45*e6/1024)*math.Sin(4*phi) -
+		(35*e6/3072)*math.Sin(6*phi))
+
+	easting := utmScale*n*(a+(1-t+c)*math.Pow(a, 3)/6+
+		(5-18*t+t*t+72*c-58*ep2)*math.Pow(a, 5)/120) + utmFalseEast
+
+	northing := utmScale * (m + n*tanPhi*(a*a/2+
+		(5-t+9*c+4*c*c)*math.Pow(a, 4)/24+
+		(61-58*t+t*t+600*c-330*ep2)*math.Pow(a, 6)/720))
+	if !zone.North {
+		northing += utmFalseNorth
+	}
+
+	return easting, northing
+}
@@ -0,0 +1,326 @@
+// Package geojson streams GeoJSON FeatureCollections and maps their features to plant import items.
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"spectra-backend/internal/models"
+)
+
+// Feature is a single GeoJSON feature; coordinates are decoded lazily by geometry type
+type Feature struct {
+	Type       string                 `json:"type"`
+	ID         json.RawMessage        `json:"id,omitempty"`
+	Geometry   *Geometry              `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// Geometry is a GeoJSON geometry with undecoded coordinates
+type Geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// Stream reads a FeatureCollection and calls fn for each feature in order,
+// decoding one feature at a time so large collections are never held in memory
+func Stream(r io.Reader, fn func(index int, f *Feature) error) error {
+	dec := json.NewDecoder(r)
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	sawFeatures := false
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("invalid GeoJSON: %w", err)
+		}
+		key, _ := token.(string)
+
+		switch key {
+		case "type":
+			var collectionType string
+			if err := dec.Decode(&collectionType); err != nil {
+				return fmt.Errorf("invalid GeoJSON type: %w", err)
+			}
+			if collectionType != "FeatureCollection" {
+				return fmt.Errorf("expected a FeatureCollection, got %q", collectionType)
+			}
+		case "features":
+			sawFeatures = true
+			if err := expectDelim(dec, '['); err != nil {
+				return err
+			}
+			for index := 0; dec.More(); index++ {
+				var feature Feature
+				if err := dec.Decode(&feature); err != nil {
+					return fmt.Errorf("invalid feature at index %d: %w", index, err)
+				}
+				if err := fn(index, &feature); err != nil {
+					return err
+				}
+			}
+			if err := expectDelim(dec, ']'); err != nil {
+				return err
+			}
+		default:
+			// Skip members such as "crs", "bbox" or "name"
+			var skipped json.RawMessage
+			if err := dec.Decode(&skipped); err != nil {
+				return fmt.Errorf("invalid GeoJSON member %q: %w", key, err)
+			}
+		}
+	}
+
+	if !sawFeatures {
+		return fmt.Errorf("FeatureCollection has no features member")
+	}
+	return nil
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("invalid GeoJSON: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("invalid GeoJSON: expected %q", want)
+	}
+	return nil
+}
+
+// Ref identifies a feature in import reports: "#index" plus its external ID or name when present
+func (f *Feature) Ref(index int) string {
+	ref := "#" + strconv.Itoa(index)
+	if id := f.ExternalID(); id != nil {
+		return ref + " " + *id
+	}
+	if name, ok := f.String("name"); ok {
+		return ref + " " + name
+	}
+	return ref
+}
+
+// ExternalID returns the "external_id" property, falling back to the feature id
+func (f *Feature) ExternalID() *string {
+	if id, ok := f.String("external_id"); ok && id != "" {
+		return &id
+	}
+	if len(f.ID) > 0 && string(f.ID) != "null" {
+		var id interface{}
+		if err := json.Unmarshal(f.ID, &id); err == nil {
+			if s := stringify(id); s != "" {
+				return &s
+			}
+		}
+	}
+	return nil
+}
+
+// String returns a property as a string; numbers are formatted
+func (f *Feature) String(key string) (string, bool) {
+	value, ok := f.Properties[key]
+	if !ok || value == nil {
+		return "", false
+	}
+	return strings.TrimSpace(stringify(value)), true
+}
+
+// Int returns a property as an integer, accepting numbers and numeric strings
+func (f *Feature) Int(key string) (int64, bool, error) {
+	value, ok := f.Properties[key]
+	if !ok || value == nil {
+		return 0, false, nil
+	}
+	switch v := value.(type) {
+	case float64:
+		if v != float64(int64(v)) {
+			return 0, true, fmt.Errorf("%s must be an integer", key)
+		}
+		return int64(v), true, nil
+	case string:
+		n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			return 0, true, fmt.Errorf("%s must be an integer", key)
+		}
+		return n, true, nil
+	}
+	return 0, true, fmt.Errorf("%s must be an integer", key)
+}
+
+// Float returns a property as a float, accepting numbers and numeric strings
+func (f *Feature) Float(key string) (float64, bool, error) {
+	value, ok := f.Properties[key]
+	if !ok || value == nil {
+		return 0, false, nil
+	}
+	switch v := value.(type) {
+	case float64:
+		return v, true, nil
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, true, fmt.Errorf("%s must be a number", key)
+		}
+		return n, true, nil
+	}
+	return 0, true, fmt.Errorf("%s must be a number", key)
+}
+
+func stringify(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return ""
+}
+
+// ToImportNode maps a Point feature to a node. Properties: name, type (required), address,
+// capacity_ports, model, status, external_id.
+func ToImportNode(index int, f *Feature) (*models.ImportNode, error) {
+	var coords []float64
+	if err := json.Unmarshal(f.Geometry.Coordinates, &coords); err != nil || len(coords) < 2 {
+		return nil, fmt.Errorf("invalid Point coordinates")
+	}
+	lng, lat := coords[0], coords[1]
+	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return nil, fmt.Errorf("coordinates out of range")
+	}
+
+	typeValue, _ := f.String("type")
+	nodeType := models.NodeType(strings.ToUpper(typeValue))
+	if !nodeType.IsValid() {
+		return nil, fmt.Errorf("invalid node type %q", typeValue)
+	}
+
+	name, _ := f.String("name")
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	req := models.CreateNodeRequest{
+		Name:      name,
+		Type:      nodeType,
+		Latitude:  lat,
+		Longitude: lng,
+	}
+	if status, ok := f.String("status"); ok {
+		req.Status = models.NodeStatus(strings.ToUpper(status))
+		if !req.Status.IsValid() {
+			return nil, fmt.Errorf("invalid node status %q", status)
+		}
+	}
+	capacity, ok, err := f.Int("capacity_ports")
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		if capacity < 0 {
+			return nil, fmt.Errorf("capacity_ports must not be negative")
+		}
+		ports := int(capacity)
+		req.CapacityPorts = &ports
+	}
+	if address, ok := f.String("address"); ok && address != "" {
+		req.Address = &address
+	}
+	if model, ok := f.String("model"); ok && model != "" {
+		req.Model = &model
+	}
+
+	return &models.ImportNode{Ref: f.Ref(index), ExternalID: f.ExternalID(), Node: req}, nil
+}
+
+// ToImportCable maps a LineString feature to a cable. Properties: name, type (required),
+// core_count (required), length_meter, color_hex, color_scheme, status, external_id, and the ends as
+// origin_node_id/dest_node_id or origin_external_id/dest_external_id.
+func ToImportCable(index int, f *Feature) (*models.ImportCable, error) {
+	var path [][]float64
+	if err := json.Unmarshal(f.Geometry.Coordinates, &path); err != nil || len(path) < 2 {
+		return nil, fmt.Errorf("invalid LineString coordinates")
+	}
+	for _, c := range path {
+		if len(c) < 2 || c[1] < -90 || c[1] > 90 || c[0] < -180 || c[0] > 180 {
+			return nil, fmt.Errorf("coordinates out of range")
+		}
+	}
+
+	typeValue, _ := f.String("type")
+	cableType := models.CableType(strings.ToUpper(typeValue))
+	if !cableType.IsValid() {
+		return nil, fmt.Errorf("invalid cable type %q", typeValue)
+	}
+
+	coreCount, ok, err := f.Int("core_count")
+	if err != nil {
+		return nil, err
+	}
+	if !ok || coreCount < 1 || coreCount > 288 {
+		return nil, fmt.Errorf("core_count must be between 1 and 288")
+	}
+
+	req := models.CreateCableRequest{
+		Type:            cableType,
+		CoreCount:       int(coreCount),
+		PathCoordinates: path,
+	}
+	if name, ok := f.String("name"); ok && name != "" {
+		req.Name = &name
+	}
+	if status, ok := f.String("status"); ok {
+		req.Status = models.CableStatus(strings.ToUpper(status))
+		if !req.Status.IsValid() {
+			return nil, fmt.Errorf("invalid cable status %q", status)
+		}
+	}
+	length, ok, err := f.Float("length_meter")
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		req.LengthMeter = &length
+	}
+	colorHex := models.CableTypeColors[cableType]
+	if value, ok := f.String("color_hex"); ok && value != "" {
+		colorHex = value
+	}
+	req.ColorHex = &colorHex
+	if scheme, ok := f.String("color_scheme"); ok && scheme != "" {
+		req.ColorScheme = &scheme
+	}
+
+	for _, end := range []struct {
+		key    string
+		target **int64
+	}{
+		{"origin_node_id", &req.OriginNodeID},
+		{"dest_node_id", &req.DestNodeID},
+	} {
+		id, ok, err := f.Int(end.key)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			*end.target = &id
+		}
+	}
+
+	cable := &models.ImportCable{Ref: f.Ref(index), ExternalID: f.ExternalID(), Cable: req}
+	if id, ok := f.String("origin_external_id"); ok && id != "" {
+		cable.OriginExternalID = &id
+	}
+	if id, ok := f.String("dest_external_id"); ok && id != "" {
+		cable.DestExternalID = &id
+	}
+
+	return cable, nil
+}
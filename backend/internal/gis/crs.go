@@ -0,0 +1,80 @@
+package gis
+
+import (
+	"fmt"
+
+	"spectra-backend/internal/geo"
+)
+
+// CRS is the coordinate reference system layers are written in
+type CRS struct {
+	// SRSID is the EPSG code
+	SRSID int
+	Name  string
+	// WKT is the OGC definition used by GeoPackage
+	WKT string
+	// ESRIWKT is the definition written to Shapefile .prj files
+	ESRIWKT string
+
+	utm *geo.UTMZone
+}
+
+const wgs84GeogCS = `GEOGCS["WGS 84",DATUM["WGS_1984",SPHEROID["WGS 84",6378137,298.257223563,AUTHORITY["EPSG","7030"]],AUTHORITY["EPSG","6326"]],PRIMEM["Greenwich",0,AUTHORITY["EPSG","8901"]],UNIT["degree",0.0174532925199433,AUTHORITY["EPSG","9122"]]`
+
+const esriWGS84GeogCS = `GEOGCS["GCS_WGS_1984",DATUM["D_WGS_1984",SPHEROID["WGS_1984",6378137.0,298.257223563]],PRIMEM["Greenwich",0.0],UNIT["Degree",0.0174532925199433]]`
+
+// WGS84 returns EPSG:4326, the CRS plant coordinates are stored in
+func WGS84() *CRS {
+	return &CRS{
+		SRSID:   4326,
+		Name:    "WGS 84",
+		WKT:     wgs84GeogCS + `,AUTHORITY["EPSG","4326"]]`,
+		ESRIWKT: esriWGS84GeogCS,
+	}
+}
+
+// UTM returns the WGS 84 / UTM CRS of a zone (EPSG:326xx or 327xx)
+func UTM(zone geo.UTMZone) *CRS {
+	falseNorthing := 0
+	if !zone.North {
+		falseNorthing = 10000000
+	}
+	name := fmt.Sprintf("WGS 84 / UTM zone %s", zone)
+	meridian := zone.CentralMeridian()
+
+	return &CRS{
+		SRSID: zone.EPSG(),
+		Name:  name,
+		WKT: fmt.Sprintf(`PROJCS["%s",%s],PROJECTION["Transverse_Mercator"],`+
+			`PARAMETER["latitude_of_origin",0],PARAMETER["central_meridian",%g],PARAMETER["scale_factor",0.9996],`+
+			`PARAMETER["false_easting",500000],PARAMETER["false_northing",%d],UNIT["metre",1,AUTHORITY["EPSG","9001"]],`+
+			`AXIS["Easting",EAST],AXIS["Northing",NORTH],AUTHORITY["EPSG","%d"]]`,
+			name, wgs84GeogCS, meridian, falseNorthing, zone.EPSG()),
+		ESRIWKT: fmt.Sprintf(`PROJCS["WGS_1984_UTM_Zone_%s",%s,PROJECTION["Transverse_Mercator"],`+
+			`PARAMETER["False_Easting",500000.0],PARAMETER["False_Northing",%d.0],PARAMETER["Central_Meridian",%g],`+
+			`PARAMETER["Scale_Factor",0.9996],PARAMETER["Latitude_Of_Origin",0.0],UNIT["Meter",1.0]]`,
+			zone, esriWGS84GeogCS, falseNorthing, meridian),
+		utm: &zone,
+	}
+}
+
+// Project converts a WGS 84 position to x, y in this CRS
+func (c *CRS) Project(lng, lat float64) (float64, float64) {
+	if c.utm == nil {
+		return lng, lat
+	}
+	return geo.ToUTM(lat, lng, *c.utm)
+}
+
+// CenterUTMZone returns the UTM zone of the center of the layers' combined extent
+func CenterUTMZone(layers []*Layer) geo.UTMZone {
+	var env envelope
+	for _, layer := range layers {
+		for _, f := range layer.Features {
+			for _, c := range f.Coordinates {
+				env.extend(c[0], c[1])
+			}
+		}
+	}
+	return geo.UTMZoneFor((env.minY+env.maxY)/2, (env.minX+env.maxX)/2)
+}
@@ -0,0 +1,225 @@
+package gis
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// GeoPackage header values (OGC 12-128r18, version 1.3)
+const (
+	gpkgApplicationID = 0x47504B47 // "GPKG"
+	gpkgUserVersion   = 10300
+	gpkgDateTime      = "2006-01-02T15:04:05.000Z"
+)
+
+// WKB geometry type codes
+const (
+	wkbPoint      = 1
+	wkbLineString = 2
+	wkbPolygon    = 3
+)
+
+var gpkgCoreTables = []string{
+	`CREATE TABLE gpkg_spatial_ref_sys (
+		srs_name TEXT NOT NULL,
+		srs_id INTEGER PRIMARY KEY,
+		organization TEXT NOT NULL,
+		organization_coordsys_id INTEGER NOT NULL,
+		definition TEXT NOT NULL,
+		description TEXT
+	)`,
+	`CREATE TABLE gpkg_contents (
+		table_name TEXT NOT NULL PRIMARY KEY,
+		data_type TEXT NOT NULL,
+		identifier TEXT UNIQUE,
+		description TEXT DEFAULT '',
+		last_change DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ','now')),
+		min_x DOUBLE,
+		min_y DOUBLE,
+		max_x DOUBLE,
+		max_y DOUBLE,
+		srs_id INTEGER,
+		CONSTRAINT fk_gc_r_srs_id FOREIGN KEY (srs_id) REFERENCES gpkg_spatial_ref_sys(srs_id)
+	)`,
+	`CREATE TABLE gpkg_geometry_columns (
+		table_name TEXT NOT NULL,
+		column_name TEXT NOT NULL,
+		geometry_type_name TEXT NOT NULL,
+		srs_id INTEGER NOT NULL,
+		z TINYINT NOT NULL,
+		m TINYINT NOT NULL,
+		CONSTRAINT pk_geom_cols PRIMARY KEY (table_name, column_name),
+		CONSTRAINT uk_gc_table_name UNIQUE (table_name),
+		CONSTRAINT fk_gc_tn FOREIGN KEY (table_name) REFERENCES gpkg_contents(table_name),
+		CONSTRAINT fk_gc_srs FOREIGN KEY (srs_id) REFERENCES gpkg_spatial_ref_sys(srs_id)
+	)`,
+}
+
+// WriteGeoPackage creates a GeoPackage file at path with one feature table per layer.
+// The file must not exist yet.
+func WriteGeoPackage(ctx context.Context, path string, layers []*Layer, crs *CRS) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("failed to create GeoPackage: %w", err)
+	}
+	defer db.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin GeoPackage transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	statements := append([]string{
+		fmt.Sprintf("PRAGMA application_id = %d", gpkgApplicationID),
+		fmt.Sprintf("PRAGMA user_version = %d", gpkgUserVersion),
+	}, gpkgCoreTables...)
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to create GeoPackage tables: %w", err)
+		}
+	}
+
+	// The two undefined systems are required by the specification
+	srsRows := [][]interface{}{
+		{"Undefined cartesian SRS", -1, "NONE", -1, "undefined", "undefined cartesian coordinate reference system"},
+		{"Undefined geographic SRS", 0, "NONE", 0, "undefined", "undefined geographic coordinate reference system"},
+		{"WGS 84 geodetic", 4326, "EPSG", 4326, WGS84().WKT, "longitude/latitude coordinates in decimal degrees on the WGS 84 spheroid"},
+	}
+	if crs.SRSID != 4326 {
+		srsRows = append(srsRows, []interface{}{crs.Name, crs.SRSID, "EPSG", crs.SRSID, crs.WKT, nil})
+	}
+	for _, row := range srsRows {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO gpkg_spatial_ref_sys (srs_name, srs_id, organization, organization_coordsys_id, definition, description)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, row...)
+		if err != nil {
+			return fmt.Errorf("failed to register spatial reference system: %w", err)
+		}
+	}
+
+	for _, layer := range layers {
+		if err := writeGeoPackageLayer(ctx, tx, layer, crs); err != nil {
+			return fmt.Errorf("failed to write layer %s: %w", layer.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit GeoPackage: %w", err)
+	}
+	return nil
+}
+
+func writeGeoPackageLayer(ctx context.Context, tx *sql.Tx, layer *Layer, crs *CRS) error {
+	columns := []string{"fid INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL", "geom " + string(layer.GeometryType)}
+	names := []string{"geom"}
+	for _, f := range layer.Fields {
+		columns = append(columns, quoteIdentifier(f.Name)+" "+gpkgColumnType(f.Type))
+		names = append(names, quoteIdentifier(f.Name))
+	}
+	table := quoteIdentifier(layer.Name)
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (%s)", table, strings.Join(columns, ", "))); err != nil {
+		return err
+	}
+
+	var minX, minY, maxX, maxY interface{}
+	if x0, y0, x1, y1, ok := layer.Extent(crs); ok {
+		minX, minY, maxX, maxY = x0, y0, x1, y1
+	}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO gpkg_contents (table_name, data_type, identifier, last_change, min_x, min_y, max_x, max_y, srs_id)
+		VALUES (?, 'features', ?, ?, ?, ?, ?, ?, ?)
+	`, layer.Name, layer.Name, time.Now().UTC().Format(gpkgDateTime), minX, minY, maxX, maxY, crs.SRSID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO gpkg_geometry_columns (table_name, column_name, geometry_type_name, srs_id, z, m)
+		VALUES (?, 'geom', ?, ?, 0, 0)
+	`, layer.Name, string(layer.GeometryType), crs.SRSID)
+	if err != nil {
+		return err
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ")
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(names, ", "), placeholders))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, feature := range layer.Features {
+		args := []interface{}{encodeGeoPackageGeometry(layer.GeometryType, feature.Coordinates, crs)}
+		for _, value := range feature.Values {
+			if t, ok := value.(time.Time); ok {
+				value = t.UTC().Format(gpkgDateTime)
+			}
+			args = append(args, value)
+		}
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeGeoPackageGeometry encodes a GeoPackage binary geometry: the "GP" header with the SRS id and,
+// for lines and polygons, the xy envelope, followed by little-endian WKB. Missing geometries are stored as NULL.
+func encodeGeoPackageGeometry(geometryType GeometryType, coordinates [][]float64, crs *CRS) interface{} {
+	if len(coordinates) == 0 {
+		return nil
+	}
+	points, env := projectFeature(geometryType, coordinates, crs)
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("GP")
+	buf.WriteByte(0) // version 1
+	if geometryType == GeometryPoint {
+		buf.WriteByte(0x01) // little endian, no envelope
+		binary.Write(buf, binary.LittleEndian, int32(crs.SRSID))
+		buf.WriteByte(1)
+		binary.Write(buf, binary.LittleEndian, uint32(wkbPoint))
+		binary.Write(buf, binary.LittleEndian, points[0])
+		return buf.Bytes()
+	}
+
+	buf.WriteByte(0x03) // little endian, xy envelope
+	binary.Write(buf, binary.LittleEndian, int32(crs.SRSID))
+	binary.Write(buf, binary.LittleEndian, [4]float64{env.minX, env.maxX, env.minY, env.maxY})
+	buf.WriteByte(1)
+	if geometryType == GeometryPolygon {
+		binary.Write(buf, binary.LittleEndian, uint32(wkbPolygon))
+		binary.Write(buf, binary.LittleEndian, uint32(1)) // outer ring only
+	} else {
+		binary.Write(buf, binary.LittleEndian, uint32(wkbLineString))
+	}
+	binary.Write(buf, binary.LittleEndian, uint32(len(points)))
+	binary.Write(buf, binary.LittleEndian, points)
+	return buf.Bytes()
+}
+
+func gpkgColumnType(t FieldType) string {
+	switch t {
+	case FieldInteger:
+		return "INTEGER"
+	case FieldReal:
+		return "REAL"
+	case FieldBoolean:
+		return "BOOLEAN"
+	case FieldDateTime:
+		return "DATETIME"
+	}
+	return "TEXT"
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
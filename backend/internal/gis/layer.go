@@ -0,0 +1,195 @@
+// Package gis writes plant data as GIS vector layers (GeoPackage and ESRI Shapefile).
+package gis
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// GeometryType is the geometry of every feature in a layer
+type GeometryType string
+
+const (
+	GeometryPoint      GeometryType = "POINT"
+	GeometryLineString GeometryType = "LINESTRING"
+	GeometryPolygon    GeometryType = "POLYGON"
+)
+
+// FieldType is the attribute type of a layer field
+type FieldType string
+
+const (
+	FieldString   FieldType = "STRING"
+	FieldInteger  FieldType = "INTEGER"
+	FieldReal     FieldType = "REAL"
+	FieldBoolean  FieldType = "BOOLEAN"
+	FieldDateTime FieldType = "DATETIME"
+)
+
+// Field is an attribute column of a layer
+type Field struct {
+	Name string
+	Type FieldType
+}
+
+// Feature is a geometry with one value per layer field.
+// Values are string, int64, float64, bool, time.Time or nil.
+type Feature struct {
+	// Coordinates are [lng, lat] positions; points have exactly one, polygons list their outer ring
+	Coordinates [][]float64
+	Values      []interface{}
+}
+
+// Layer is a named feature collection with a fixed attribute schema
+type Layer struct {
+	Name         string
+	GeometryType GeometryType
+	Fields       []Field
+	Features     []Feature
+}
+
+// NewStructLayer creates a layer whose schema is derived from the json tags of a model struct,
+// followed by the extra fields. Slices, maps and nested structs (other than time.Time) are skipped.
+func NewStructLayer(name string, geometryType GeometryType, model interface{}, extra ...Field) *Layer {
+	layer := &Layer{Name: name, GeometryType: geometryType}
+	for _, f := range structFields(reflect.TypeOf(model)) {
+		layer.Fields = append(layer.Fields, Field{Name: f.name, Type: f.fieldType})
+	}
+	layer.Fields = append(layer.Fields, extra...)
+	return layer
+}
+
+// AddStruct appends a feature whose values are read from a model struct of the layer's type,
+// followed by the values of the extra fields
+func (l *Layer) AddStruct(coordinates [][]float64, model interface{}, extra ...interface{}) {
+	v := reflect.Indirect(reflect.ValueOf(model))
+	var values []interface{}
+	for _, f := range structFields(v.Type()) {
+		values = append(values, fieldValue(v.Field(f.index)))
+	}
+	l.Features = append(l.Features, Feature{Coordinates: coordinates, Values: append(values, extra...)})
+}
+
+// Extent returns the bounding box of the layer's features as minX, minY, maxX, maxY after projection
+func (l *Layer) Extent(crs *CRS) (float64, float64, float64, float64, bool) {
+	var env envelope
+	for _, f := range l.Features {
+		for _, c := range f.Coordinates {
+			x, y := crs.Project(c[0], c[1])
+			env.extend(x, y)
+		}
+	}
+	return env.minX, env.minY, env.maxX, env.maxY, env.valid
+}
+
+// projectFeature projects a feature's positions into the CRS, closing the ring of a polygon
+func projectFeature(geometryType GeometryType, coordinates [][]float64, crs *CRS) ([][2]float64, envelope) {
+	var env envelope
+	points := make([][2]float64, 0, len(coordinates)+1)
+	for _, c := range coordinates {
+		x, y := crs.Project(c[0], c[1])
+		env.extend(x, y)
+		points = append(points, [2]float64{x, y})
+	}
+	if geometryType == GeometryPolygon && len(points) > 0 && points[0] != points[len(points)-1] {
+		points = append(points, points[0])
+	}
+	return points, env
+}
+
+// ringArea returns the signed area of a closed ring: positive when it runs counterclockwise
+func ringArea(ring [][2]float64) float64 {
+	area := 0.0
+	for i := 0; i+1 < len(ring); i++ {
+		area += ring[i][0]*ring[i+1][1] - ring[i+1][0]*ring[i][1]
+	}
+	return area / 2
+}
+
+type structField struct {
+	index     int
+	name      string
+	fieldType FieldType
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func structFields(t reflect.Type) []structField {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if !sf.IsExported() || name == "" || name == "-" {
+			continue
+		}
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		var fieldType FieldType
+		switch {
+		case ft == timeType:
+			fieldType = FieldDateTime
+		case ft.Kind() == reflect.String:
+			fieldType = FieldString
+		case ft.Kind() == reflect.Bool:
+			fieldType = FieldBoolean
+		case ft.Kind() >= reflect.Int && ft.Kind() <= reflect.Uint64:
+			fieldType = FieldInteger
+		case ft.Kind() == reflect.Float32 || ft.Kind() == reflect.Float64:
+			fieldType = FieldReal
+		default:
+			continue
+		}
+		fields = append(fields, structField{index: i, name: name, fieldType: fieldType})
+	}
+	return fields
+}
+
+func fieldValue(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Type() == timeType {
+		return v.Interface().(time.Time)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+	return nil
+}
+
+// envelope accumulates a bounding box
+type envelope struct {
+	minX, minY, maxX, maxY float64
+	valid                  bool
+}
+
+func (e *envelope) extend(x, y float64) {
+	if !e.valid {
+		e.minX, e.minY, e.maxX, e.maxY, e.valid = x, y, x, y, true
+		return
+	}
+	e.minX = min(e.minX, x)
+	e.minY = min(e.minY, y)
+	e.maxX = max(e.maxX, x)
+	e.maxY = max(e.maxY, y)
+}
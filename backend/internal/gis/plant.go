@@ -0,0 +1,143 @@
+package gis
+
+import (
+	"math"
+
+	"spectra-backend/internal/models"
+)
+
+// Plant layer names
+const (
+	LayerNodes     = "nodes"
+	LayerCables    = "cables"
+	LayerCustomers = "customers"
+	LayerAreas     = "areas"
+)
+
+// PlantLayerNames lists the exportable layers in the order they are written
+var PlantLayerNames = []string{LayerNodes, LayerCables, LayerCustomers, LayerAreas}
+
+// BBox is a WGS 84 bounding box
+type BBox struct {
+	MinLng, MinLat, MaxLng, MaxLat float64
+}
+
+// Contains reports whether a position lies inside the box
+func (b *BBox) Contains(lng, lat float64) bool {
+	return lng >= b.MinLng && lng <= b.MaxLng && lat >= b.MinLat && lat <= b.MaxLat
+}
+
+// Intersects reports whether the extent of a line overlaps the box
+func (b *BBox) Intersects(coordinates [][]float64) bool {
+	var env envelope
+	for _, c := range coordinates {
+		env.extend(c[0], c[1])
+	}
+	return env.valid && env.minX <= b.MaxLng && env.maxX >= b.MinLng && env.minY <= b.MaxLat && env.maxY >= b.MinLat
+}
+
+// PlantFilter narrows the exported features; nil or empty criteria match everything
+type PlantFilter struct {
+	BBox *BBox
+	// Status applies to nodes and cables
+	Status         map[string]bool
+	CustomerStatus map[string]bool
+}
+
+func (f *PlantFilter) statusMatches(status string) bool {
+	return len(f.Status) == 0 || f.Status[status]
+}
+
+// NodeLayer builds the point layer of nodes
+func NodeLayer(nodes []models.Node, filter *PlantFilter) *Layer {
+	layer := NewStructLayer(LayerNodes, GeometryPoint, models.Node{})
+	for i := range nodes {
+		n := &nodes[i]
+		if !filter.statusMatches(string(n.Status)) || (filter.BBox != nil && !filter.BBox.Contains(n.Longitude, n.Latitude)) {
+			continue
+		}
+		layer.AddStruct([][]float64{{n.Longitude, n.Latitude}}, n)
+	}
+	return layer
+}
+
+// CableLayer builds the line layer of cables with their core utilization. Cables without a drawn
+// path are exported as a straight line between their end nodes, or skipped when those are unknown.
+func CableLayer(cables []models.Cable, nodes []models.Node, usedCores map[int64]int, filter *PlantFilter) *Layer {
+	nodeByID := make(map[int64]*models.Node, len(nodes))
+	for i := range nodes {
+		nodeByID[nodes[i].ID] = &nodes[i]
+	}
+
+	layer := NewStructLayer(LayerCables, GeometryLineString, models.Cable{},
+		Field{Name: "used_cores", Type: FieldInteger},
+		Field{Name: "utilization_pct", Type: FieldReal},
+	)
+	for i := range cables {
+		c := &cables[i]
+		if !filter.statusMatches(string(c.Status)) {
+			continue
+		}
+
+		path := c.PathCoordinates
+		if len(path) < 2 && c.OriginNodeID != nil && c.DestNodeID != nil {
+			origin, dest := nodeByID[*c.OriginNodeID], nodeByID[*c.DestNodeID]
+			if origin != nil && dest != nil {
+				path = [][]float64{{origin.Longitude, origin.Latitude}, {dest.Longitude, dest.Latitude}}
+			}
+		}
+		if len(path) < 2 || (filter.BBox != nil && !filter.BBox.Intersects(path)) {
+			continue
+		}
+
+		used := usedCores[c.ID]
+		utilization := 0.0
+		if c.CoreCount > 0 {
+			utilization = math.Round(float64(used)/float64(c.CoreCount)*1000) / 10
+		}
+		layer.AddStruct(path, c, int64(used), utilization)
+	}
+	return layer
+}
+
+// CustomerLayer builds the point layer of customers, placed at the node they are connected to.
+// Customers without a node have no location and are skipped.
+func CustomerLayer(customers []models.Customer, nodes []models.Node, filter *PlantFilter) *Layer {
+	nodeByID := make(map[int64]*models.Node, len(nodes))
+	for i := range nodes {
+		nodeByID[nodes[i].ID] = &nodes[i]
+	}
+
+	layer := NewStructLayer(LayerCustomers, GeometryPoint, models.Customer{},
+		Field{Name: "node_name", Type: FieldString},
+	)
+	for i := range customers {
+		c := &customers[i]
+		if len(filter.CustomerStatus) > 0 && !filter.CustomerStatus[string(c.CurrentStatus)] {
+			continue
+		}
+		if c.NodeID == nil || nodeByID[*c.NodeID] == nil {
+			continue
+		}
+		node := nodeByID[*c.NodeID]
+		if filter.BBox != nil && !filter.BBox.Contains(node.Longitude, node.Latitude) {
+			continue
+		}
+		layer.AddStruct([][]float64{{node.Longitude, node.Latitude}}, c, node.Name)
+	}
+	return layer
+}
+
+// AreaLayer builds the polygon layer of service areas. Areas with fewer than three corners have no
+// surface and are skipped; the bounding box keeps the areas whose outline overlaps it.
+func AreaLayer(areas []models.ServiceArea, filter *PlantFilter) *Layer {
+	layer := NewStructLayer(LayerAreas, GeometryPolygon, models.ServiceArea{})
+	for i := range areas {
+		a := &areas[i]
+		if len(a.Polygon) < 3 || (filter.BBox != nil && !filter.BBox.Intersects(a.Polygon)) {
+			continue
+		}
+		layer.AddStruct(a.Polygon, a)
+	}
+	return layer
+}
@@ -0,0 +1,288 @@
+package gis
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Shapefile shape types
+const (
+	shapeNull     = 0
+	shapePoint    = 1
+	shapePolyLine = 3
+	shapePolygon  = 5
+)
+
+// dbfMaxFieldName is the longest field name a dBASE header can hold
+const dbfMaxFieldName = 10
+
+// WriteShapefileZip writes each layer as a Shapefile (.shp, .shx, .dbf, .prj, .cpg) into a zip archive
+func WriteShapefileZip(w io.Writer, layers []*Layer, crs *CRS) error {
+	zw := zip.NewWriter(w)
+	modified := time.Now()
+	for _, layer := range layers {
+		files, err := encodeShapefile(layer, crs)
+		if err != nil {
+			return fmt.Errorf("failed to encode layer %s: %w", layer.Name, err)
+		}
+		for _, ext := range []string{"shp", "shx", "dbf", "prj", "cpg"} {
+			f, err := zw.CreateHeader(&zip.FileHeader{
+				Name:     layer.Name + "." + ext,
+				Method:   zip.Deflate,
+				Modified: modified,
+			})
+			if err != nil {
+				return err
+			}
+			if _, err := f.Write(files[ext]); err != nil {
+				return err
+			}
+		}
+	}
+	return zw.Close()
+}
+
+func encodeShapefile(layer *Layer, crs *CRS) (map[string][]byte, error) {
+	shapeType := shapePoint
+	switch layer.GeometryType {
+	case GeometryLineString:
+		shapeType = shapePolyLine
+	case GeometryPolygon:
+		shapeType = shapePolygon
+	}
+
+	var records [][]byte
+	var extent envelope
+	for _, f := range layer.Features {
+		record, env := encodeShape(shapeType, f.Coordinates, crs)
+		records = append(records, record)
+		if env.valid {
+			extent.extend(env.minX, env.minY)
+			extent.extend(env.maxX, env.maxY)
+		}
+	}
+
+	// Offsets and lengths are counted in 16-bit words
+	shpLength := 100
+	for _, record := range records {
+		shpLength += 8 + len(record)
+	}
+	shx := new(bytes.Buffer)
+	shp := new(bytes.Buffer)
+	writeShapeHeader(shp, shapeType, shpLength, extent)
+	writeShapeHeader(shx, shapeType, 100+8*len(records), extent)
+
+	offset := 100
+	for i, record := range records {
+		binary.Write(shp, binary.BigEndian, int32(i+1))
+		binary.Write(shp, binary.BigEndian, int32(len(record)/2))
+		shp.Write(record)
+
+		binary.Write(shx, binary.BigEndian, int32(offset/2))
+		binary.Write(shx, binary.BigEndian, int32(len(record)/2))
+		offset += 8 + len(record)
+	}
+
+	dbf, err := encodeDBF(layer)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string][]byte{
+		"shp": shp.Bytes(),
+		"shx": shx.Bytes(),
+		"dbf": dbf,
+		"prj": []byte(crs.ESRIWKT),
+		"cpg": []byte("UTF-8"),
+	}, nil
+}
+
+func writeShapeHeader(buf *bytes.Buffer, shapeType, length int, extent envelope) {
+	binary.Write(buf, binary.BigEndian, int32(9994))
+	buf.Write(make([]byte, 20))
+	binary.Write(buf, binary.BigEndian, int32(length/2))
+	binary.Write(buf, binary.LittleEndian, int32(1000))
+	binary.Write(buf, binary.LittleEndian, int32(shapeType))
+	binary.Write(buf, binary.LittleEndian, [8]float64{extent.minX, extent.minY, extent.maxX, extent.maxY})
+}
+
+// encodeShape encodes a record's content: a point, a single-part polyline or polygon, or a null
+// shape when there are no coordinates. Polygon rings are written clockwise as the format requires.
+func encodeShape(shapeType int, coordinates [][]float64, crs *CRS) ([]byte, envelope) {
+	buf := new(bytes.Buffer)
+	if len(coordinates) == 0 {
+		binary.Write(buf, binary.LittleEndian, int32(shapeNull))
+		return buf.Bytes(), envelope{}
+	}
+
+	geometryType := GeometryLineString
+	if shapeType == shapePolygon {
+		geometryType = GeometryPolygon
+	}
+	points, env := projectFeature(geometryType, coordinates, crs)
+	if shapeType == shapePolygon && ringArea(points) > 0 {
+		for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+			points[i], points[j] = points[j], points[i]
+		}
+	}
+
+	binary.Write(buf, binary.LittleEndian, int32(shapeType))
+	if shapeType == shapePoint {
+		binary.Write(buf, binary.LittleEndian, points[0])
+		return buf.Bytes(), env
+	}
+
+	binary.Write(buf, binary.LittleEndian, [4]float64{env.minX, env.minY, env.maxX, env.maxY})
+	binary.Write(buf, binary.LittleEndian, int32(1))
+	binary.Write(buf, binary.LittleEndian, int32(len(points)))
+	binary.Write(buf, binary.LittleEndian, int32(0))
+	binary.Write(buf, binary.LittleEndian, points)
+	return buf.Bytes(), env
+}
+
+// dbfField is a dBASE column descriptor
+type dbfField struct {
+	name     string
+	kind     byte
+	length   int
+	decimals int
+}
+
+// encodeDBF writes the attribute table as dBASE III. Strings are UTF-8 (declared by the .cpg file),
+// date-times are ISO 8601 strings and names are cut to 10 characters.
+func encodeDBF(layer *Layer) ([]byte, error) {
+	names := dbfFieldNames(layer.Fields)
+	fields := make([]dbfField, len(layer.Fields))
+	for i, f := range layer.Fields {
+		field := dbfField{name: names[i]}
+		switch f.Type {
+		case FieldInteger:
+			field.kind, field.length = 'N', 18
+		case FieldReal:
+			field.kind, field.length, field.decimals = 'N', 24, 8
+		case FieldBoolean:
+			field.kind, field.length = 'L', 1
+		case FieldDateTime:
+			field.kind, field.length = 'C', len(time.RFC3339)
+		default:
+			field.kind, field.length = 'C', 1
+			for _, feature := range layer.Features {
+				if s, ok := feature.Values[i].(string); ok {
+					field.length = min(max(field.length, len(s)), 254)
+				}
+			}
+		}
+		fields[i] = field
+	}
+
+	recordLength := 1
+	for _, f := range fields {
+		recordLength += f.length
+	}
+	headerLength := 32 + 32*len(fields) + 1
+
+	buf := new(bytes.Buffer)
+	now := time.Now()
+	buf.Write([]byte{0x03, byte(now.Year() - 1900), byte(now.Month()), byte(now.Day())})
+	binary.Write(buf, binary.LittleEndian, uint32(len(layer.Features)))
+	binary.Write(buf, binary.LittleEndian, uint16(headerLength))
+	binary.Write(buf, binary.LittleEndian, uint16(recordLength))
+	buf.Write(make([]byte, 20))
+
+	for _, f := range fields {
+		name := make([]byte, 11)
+		copy(name, f.name)
+		buf.Write(name)
+		buf.WriteByte(f.kind)
+		buf.Write(make([]byte, 4))
+		buf.WriteByte(byte(f.length))
+		buf.WriteByte(byte(f.decimals))
+		buf.Write(make([]byte, 14))
+	}
+	buf.WriteByte(0x0D)
+
+	for _, feature := range layer.Features {
+		if len(feature.Values) != len(fields) {
+			return nil, fmt.Errorf("feature has %d values for %d fields", len(feature.Values), len(fields))
+		}
+		buf.WriteByte(' ')
+		for i, f := range fields {
+			buf.WriteString(dbfValue(f, feature.Values[i]))
+		}
+	}
+	buf.WriteByte(0x1A)
+
+	return buf.Bytes(), nil
+}
+
+// dbfValue formats a value to exactly the field's width; nulls are blank
+func dbfValue(f dbfField, value interface{}) string {
+	var s string
+	switch v := value.(type) {
+	case nil:
+		if f.kind == 'L' {
+			return "?"
+		}
+		return strings.Repeat(" ", f.length)
+	case string:
+		s = v
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	case float64:
+		s = strconv.FormatFloat(v, 'f', f.decimals, 64)
+	case bool:
+		if v {
+			return "T"
+		}
+		return "F"
+	case time.Time:
+		s = v.UTC().Format(time.RFC3339)
+	default:
+		s = fmt.Sprint(v)
+	}
+
+	if len(s) > f.length {
+		if f.kind == 'N' {
+			return strings.Repeat("*", f.length)
+		}
+		s = truncateUTF8(s, f.length)
+	}
+	if f.kind == 'N' {
+		return strings.Repeat(" ", f.length-len(s)) + s
+	}
+	return s + strings.Repeat(" ", f.length-len(s))
+}
+
+// dbfFieldNames cuts field names to the dBASE limit, numbering any that collide after cutting
+func dbfFieldNames(fields []Field) []string {
+	names := make([]string, len(fields))
+	used := map[string]bool{}
+	for i, f := range fields {
+		name := f.Name
+		if len(name) > dbfMaxFieldName {
+			name = name[:dbfMaxFieldName]
+		}
+		for n := 1; used[strings.ToUpper(name)]; n++ {
+			suffix := "_" + strconv.Itoa(n)
+			name = f.Name[:min(len(f.Name), dbfMaxFieldName-len(suffix))] + suffix
+		}
+		used[strings.ToUpper(name)] = true
+		names[i] = name
+	}
+	return names
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a character
+func truncateUTF8(s string, n int) string {
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"spectra-backend/internal/models"
+	"spectra-backend/internal/repository"
+)
+
+// AreaHandler handles HTTP requests for service areas
+type AreaHandler struct {
+	repo *repository.AreaRepository
+}
+
+// NewAreaHandler creates a new AreaHandler
+func NewAreaHandler(repo *repository.AreaRepository) *AreaHandler {
+	return &AreaHandler{repo: repo}
+}
+
+// Create handles POST /api/areas
+// Body: {"name", "level", "parent_id", "polygon": [[lng, lat], ...], "maintenance_team", "description"}
+// Every node is reassigned to the deepest area containing it
+func (h *AreaHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateServiceAreaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	req.Level = models.AreaLevel(strings.ToUpper(string(req.Level)))
+
+	area, err := h.repo.Create(r.Context(), &req)
+	if err != nil {
+		h.respondAreaError(w, err, "Failed to create service area: ")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, models.SuccessResponse(area, "Service area created successfully"))
+}
+
+// GetByID handles GET /api/areas/{id}
+func (h *AreaHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid service area ID")
+		return
+	}
+
+	area, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get service area: "+err.Error())
+		return
+	}
+
+	if area == nil {
+		respondError(w, http.StatusNotFound, "Service area not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(area, ""))
+}
+
+// List handles GET /api/areas
+// Query params: level, parent_id, search, limit, offset
+func (h *AreaHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter := &models.ServiceAreaFilter{}
+	query := r.URL.Query()
+
+	if levelParam := query.Get("level"); levelParam != "" {
+		level := models.AreaLevel(strings.ToUpper(levelParam))
+		filter.Level = &level
+	}
+	parentID, err := parseIDParam(r, "parent_id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.ParentID = parentID
+	if search := query.Get("search"); search != "" {
+		filter.Search = &search
+	}
+	filter.Limit = parseIntParam(r, "limit", 0)
+	filter.Offset = parseIntParam(r, "offset", 0)
+
+	areas, total, err := h.repo.List(r.Context(), filter)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list service areas: "+err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.NewPaginatedResponse(areas, total, filter.Limit, filter.Offset))
+}
+
+// Update handles PUT /api/areas/{id}
+// Nodes are reassigned when the polygon or parent changes
+func (h *AreaHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid service area ID")
+		return
+	}
+
+	var req models.UpdateServiceAreaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	area, err := h.repo.Update(r.Context(), id, &req)
+	if err != nil {
+		h.respondAreaError(w, err, "Failed to update service area: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(area, "Service area updated successfully"))
+}
+
+// Delete handles DELETE /api/areas/{id}
+// Areas with sub-areas cannot be deleted
+func (h *AreaHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid service area ID")
+		return
+	}
+
+	if err := h.repo.Delete(r.Context(), id); err != nil {
+		h.respondAreaError(w, err, "Failed to delete service area: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(nil, "Service area deleted successfully"))
+}
+
+// Stats handles GET /api/areas/{id}/stats
+// Totals nodes, ports, cables, customers and open tickets over the area and its sub-areas
+func (h *AreaHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid service area ID")
+		return
+	}
+
+	stats, err := h.repo.Stats(r.Context(), id)
+	if err != nil {
+		h.respondAreaError(w, err, "Failed to get service area stats: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(stats, ""))
+}
+
+// Assign handles POST /api/areas/assign
+// Reassigns every node to the deepest area containing it, e.g. after a bulk import
+func (h *AreaHandler) Assign(w http.ResponseWriter, r *http.Request) {
+	assignment, err := h.repo.Assign(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to assign service areas: "+err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(assignment, "Service areas assigned successfully"))
+}
+
+func (h *AreaHandler) respondAreaError(w http.ResponseWriter, err error, prefix string) {
+	switch {
+	case errors.Is(err, repository.ErrServiceAreaNotFound):
+		respondError(w, http.StatusNotFound, "Service area not found")
+	case errors.Is(err, repository.ErrServiceAreaInUse):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, repository.ErrInvalidServiceArea):
+		respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, prefix+err.Error())
+	}
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"spectra-backend/internal/bom"
+	"spectra-backend/internal/models"
+	"spectra-backend/internal/repository"
+	"spectra-backend/internal/tabular"
+	"spectra-backend/internal/topology"
+)
+
+// BOMHandler handles HTTP requests for bills of materials and the price catalog
+type BOMHandler struct {
+	catalogRepo   *repository.PriceCatalogRepository
+	workspaceRepo *repository.WorkspaceRepository
+	topology      *topology.Service
+}
+
+// NewBOMHandler creates a new BOMHandler
+func NewBOMHandler(catalogRepo *repository.PriceCatalogRepository, workspaceRepo *repository.WorkspaceRepository, topologyService *topology.Service) *BOMHandler {
+	return &BOMHandler{catalogRepo: catalogRepo, workspaceRepo: workspaceRepo, topology: topologyService}
+}
+
+// Generate handles GET /api/bom
+// Query params: workspace_id (the items drafted in a design workspace) or
+// bbox=minLng,minLat,maxLng,maxLat (the live plant in the box), and format=json|csv|xlsx
+func (h *BOMHandler) Generate(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	format := strings.ToLower(query.Get("format"))
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" && format != "xlsx" {
+		respondError(w, http.StatusBadRequest, "format must be json, csv or xlsx")
+		return
+	}
+
+	workspaceParam, bboxParam := query.Get("workspace_id"), query.Get("bbox")
+	if (workspaceParam == "") == (bboxParam == "") {
+		respondError(w, http.StatusBadRequest, "Either workspace_id or bbox is required")
+		return
+	}
+
+	var builder *bom.Builder
+	var workspaceID *int64
+	var bbox []float64
+	name := "spectra-bom"
+	if workspaceParam != "" {
+		id, err := strconv.ParseInt(workspaceParam, 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid workspace ID")
+			return
+		}
+		workspace, err := h.workspaceRepo.GetByID(r.Context(), id)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to get workspace: "+err.Error())
+			return
+		}
+		if workspace == nil {
+			respondError(w, http.StatusNotFound, "Workspace not found")
+			return
+		}
+		builder = bom.FromDesign(workspace.Items)
+		workspaceID = &id
+		name += "-workspace-" + workspaceParam
+	} else {
+		box, ok := parseBBox(bboxParam)
+		if !ok {
+			respondError(w, http.StatusBadRequest, "Invalid bbox, expected minLng,minLat,maxLng,maxLat")
+			return
+		}
+		g, err := h.topology.Graph(r.Context())
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to load topology: "+err.Error())
+			return
+		}
+		builder = bom.FromGraph(g, box)
+		bbox = []float64{box.MinLng, box.MinLat, box.MaxLng, box.MaxLat}
+	}
+
+	catalog, err := h.catalogRepo.List(r.Context(), nil)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get price catalog: "+err.Error())
+		return
+	}
+	bill := bom.Price(builder.Lines(), catalog)
+	bill.WorkspaceID = workspaceID
+	bill.BBox = bbox
+
+	if format == "json" {
+		respondJSON(w, http.StatusOK, models.SuccessResponse(bill, ""))
+		return
+	}
+
+	// Encode into a buffer first so an encoding failure can still be reported as JSON
+	var buf bytes.Buffer
+	contentType := "text/csv"
+	if format == "xlsx" {
+		err = tabular.WriteXLSX(&buf, "BOM", bom.Header, bom.Rows(bill))
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	} else {
+		err = tabular.WriteCSV(&buf, bom.Header, bom.Rows(bill))
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to export bill of materials: "+err.Error())
+		return
+	}
+
+	filename := name + "-" + time.Now().Format("20060102") + "." + format
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// ListCatalog handles GET /api/price-catalog
+// Query params: category
+func (h *BOMHandler) ListCatalog(w http.ResponseWriter, r *http.Request) {
+	var category *models.BOMCategory
+	if categoryParam := r.URL.Query().Get("category"); categoryParam != "" {
+		c := models.BOMCategory(strings.ToUpper(categoryParam))
+		category = &c
+	}
+
+	items, err := h.catalogRepo.List(r.Context(), category)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list price catalog: "+err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(items, ""))
+}
+
+// CreateCatalogItem handles POST /api/price-catalog
+func (h *BOMHandler) CreateCatalogItem(w http.ResponseWriter, r *http.Request) {
+	var req models.CreatePriceCatalogItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	req.Category = models.BOMCategory(strings.ToUpper(string(req.Category)))
+
+	item, err := h.catalogRepo.Create(r.Context(), &req)
+	if err != nil {
+		h.respondCatalogError(w, err, "Failed to create price catalog item: ")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, models.SuccessResponse(item, "Price catalog item created successfully"))
+}
+
+// UpdateCatalogItem handles PUT /api/price-catalog/{id}
+func (h *BOMHandler) UpdateCatalogItem(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid price catalog item ID")
+		return
+	}
+
+	var req models.UpdatePriceCatalogItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	item, err := h.catalogRepo.Update(r.Context(), id, &req)
+	if err != nil {
+		h.respondCatalogError(w, err, "Failed to update price catalog item: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(item, "Price catalog item updated successfully"))
+}
+
+// DeleteCatalogItem handles DELETE /api/price-catalog/{id}
+func (h *BOMHandler) DeleteCatalogItem(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid price catalog item ID")
+		return
+	}
+
+	if err := h.catalogRepo.Delete(r.Context(), id); err != nil {
+		h.respondCatalogError(w, err, "Failed to delete price catalog item: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(nil, "Price catalog item deleted successfully"))
+}
+
+func (h *BOMHandler) respondCatalogError(w http.ResponseWriter, err error, prefix string) {
+	switch {
+	case errors.Is(err, repository.ErrPriceCatalogItemNotFound):
+		respondError(w, http.StatusNotFound, "Price catalog item not found")
+	case errors.Is(err, repository.ErrPriceCatalogItemExists):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, repository.ErrInvalidPriceCatalogItem):
+		respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, prefix+err.Error())
+	}
+}
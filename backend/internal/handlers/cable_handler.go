@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -39,6 +40,10 @@ func (h *CableHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	cable, err := h.repo.Create(r.Context(), &req)
 	if err != nil {
+		if errors.Is(err, repository.ErrColorSchemeNotFound) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		respondError(w, http.StatusInternalServerError, "Failed to create cable: "+err.Error())
 		return
 	}
@@ -91,6 +96,12 @@ func (h *CableHandler) List(w http.ResponseWriter, r *http.Request) {
 			filter.DestNodeID = &id
 		}
 	}
+	areaID, err := parseIDParam(r, "area_id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.AreaID = areaID
 	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
 		if limit, err := strconv.Atoi(limitParam); err == nil {
 			filter.Limit = limit
@@ -127,7 +138,21 @@ func (h *CableHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 	cable, err := h.repo.Update(r.Context(), id, &req)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "Failed to update cable: "+err.Error())
+		var resizeErr *repository.CoreResizeError
+		switch {
+		case errors.As(err, &resizeErr):
+			respondJSON(w, http.StatusConflict, models.Response{
+				Success: false,
+				Error:   err.Error(),
+				Data:    resizeErr.Report,
+			})
+		case errors.Is(err, repository.ErrInvalidCoreCount):
+			respondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, repository.ErrForeignCores):
+			respondError(w, http.StatusConflict, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "Failed to update cable: "+err.Error())
+		}
 		return
 	}
 
@@ -213,8 +238,15 @@ func (h *CableHandler) UpdateCore(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetGeoJSON handles GET /api/geojson/cables
+// Query params: area_id
 func (h *CableHandler) GetGeoJSON(w http.ResponseWriter, r *http.Request) {
-	features, err := h.repo.GetAllAsGeoJSON(r.Context())
+	areaID, err := parseIDParam(r, "area_id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	features, err := h.repo.GetAllAsGeoJSON(r.Context(), areaID)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to get cables as GeoJSON: "+err.Error())
 		return
@@ -229,3 +261,65 @@ func (h *CableHandler) GetGeoJSON(w http.ResponseWriter, r *http.Request) {
 	fc := models.NewGeoJSONFeatureCollection(interfaceFeatures)
 	respondJSON(w, http.StatusOK, fc)
 }
+
+// Split handles POST /api/cables/{id}/split
+func (h *CableHandler) Split(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid cable ID")
+		return
+	}
+
+	var req models.SplitCableRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	result, err := h.repo.Split(r.Context(), id, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrCableNotFound):
+			respondError(w, http.StatusNotFound, "Cable not found")
+		case errors.Is(err, repository.ErrInvalidSplit):
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "Failed to split cable: "+err.Error())
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(result, "Cable split successfully"))
+}
+
+// PreviewResize handles GET /api/cables/{id}/resize-preview?core_count=
+func (h *CableHandler) PreviewResize(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid cable ID")
+		return
+	}
+
+	coreCount := parseIntParam(r, "core_count", 0)
+	if coreCount <= 0 {
+		respondError(w, http.StatusBadRequest, "core_count is required")
+		return
+	}
+
+	report, err := h.repo.PreviewResize(r.Context(), id, coreCount)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrCableNotFound):
+			respondError(w, http.StatusNotFound, "Cable not found")
+		case errors.Is(err, repository.ErrInvalidCoreCount):
+			respondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, repository.ErrForeignCores):
+			respondError(w, http.StatusConflict, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "Failed to preview core resize: "+err.Error())
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(report, ""))
+}
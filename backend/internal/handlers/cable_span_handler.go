@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"spectra-backend/internal/models"
+	"spectra-backend/internal/repository"
+)
+
+// CableSpanHandler handles HTTP requests for cable spans and core breakouts
+type CableSpanHandler struct {
+	repo *repository.CableSpanRepository
+}
+
+// NewCableSpanHandler creates a new CableSpanHandler
+func NewCableSpanHandler(repo *repository.CableSpanRepository) *CableSpanHandler {
+	return &CableSpanHandler{repo: repo}
+}
+
+// GetRoute handles GET /api/cables/{id}/spans
+func (h *CableSpanHandler) GetRoute(w http.ResponseWriter, r *http.Request) {
+	cableID, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid cable ID")
+		return
+	}
+
+	route, err := h.repo.GetRoute(r.Context(), cableID)
+	if err != nil {
+		respondSpanError(w, err, "Failed to get cable route")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(route, ""))
+}
+
+// ReplaceSpans handles PUT /api/cables/{id}/spans
+func (h *CableSpanHandler) ReplaceSpans(w http.ResponseWriter, r *http.Request) {
+	cableID, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid cable ID")
+		return
+	}
+
+	var req models.ReplaceCableSpansRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	route, err := h.repo.ReplaceSpans(r.Context(), cableID, &req)
+	if err != nil {
+		respondSpanError(w, err, "Failed to update cable spans")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(route, "Cable spans updated successfully"))
+}
+
+// ListBreakouts handles GET /api/cables/{id}/cores/{coreId}/breakouts
+func (h *CableSpanHandler) ListBreakouts(w http.ResponseWriter, r *http.Request) {
+	cableID, coreID, ok := parseCableCoreIDs(w, r)
+	if !ok {
+		return
+	}
+
+	breakouts, err := h.repo.ListBreakouts(r.Context(), cableID, coreID)
+	if err != nil {
+		respondSpanError(w, err, "Failed to list core breakouts")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(breakouts, ""))
+}
+
+// CreateBreakout handles POST /api/cables/{id}/cores/{coreId}/breakouts
+func (h *CableSpanHandler) CreateBreakout(w http.ResponseWriter, r *http.Request) {
+	cableID, coreID, ok := parseCableCoreIDs(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CreateCoreBreakoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if req.NodeID == 0 {
+		respondError(w, http.StatusBadRequest, "NodeID is required")
+		return
+	}
+
+	breakout, err := h.repo.CreateBreakout(r.Context(), cableID, coreID, &req)
+	if err != nil {
+		respondSpanError(w, err, "Failed to create core breakout")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, models.SuccessResponse(breakout, "Core breakout created successfully"))
+}
+
+// DeleteBreakout handles DELETE /api/cables/{id}/cores/{coreId}/breakouts/{breakoutId}
+func (h *CableSpanHandler) DeleteBreakout(w http.ResponseWriter, r *http.Request) {
+	cableID, coreID, ok := parseCableCoreIDs(w, r)
+	if !ok {
+		return
+	}
+
+	breakoutID, err := getIDFromPathAt(r, 6)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid breakout ID")
+		return
+	}
+
+	if err := h.repo.DeleteBreakout(r.Context(), cableID, coreID, breakoutID); err != nil {
+		if errors.Is(err, repository.ErrBreakoutNotFound) {
+			respondError(w, http.StatusNotFound, "Breakout not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to delete core breakout: "+err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(nil, "Core breakout deleted successfully"))
+}
+
+// GetCoreSegments handles GET /api/cables/{id}/cores/{coreId}/segments
+func (h *CableSpanHandler) GetCoreSegments(w http.ResponseWriter, r *http.Request) {
+	cableID, coreID, ok := parseCableCoreIDs(w, r)
+	if !ok {
+		return
+	}
+
+	segments, err := h.repo.GetCoreSegments(r.Context(), cableID, coreID)
+	if err != nil {
+		respondSpanError(w, err, "Failed to get core segments")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(segments, ""))
+}
+
+// parseCableCoreIDs extracts the cable and core IDs from /api/cables/{id}/cores/{coreId}/...
+func parseCableCoreIDs(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	cableID, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid cable ID")
+		return 0, 0, false
+	}
+
+	coreID, err := getIDFromPathAt(r, 4)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid core ID")
+		return 0, 0, false
+	}
+
+	return cableID, coreID, true
+}
+
+// respondSpanError maps span repository errors to HTTP status codes
+func respondSpanError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrCableNotFound):
+		respondError(w, http.StatusNotFound, "Cable not found")
+	case errors.Is(err, repository.ErrCoreNotFound):
+		respondError(w, http.StatusNotFound, "Cable core not found")
+	case errors.Is(err, repository.ErrInvalidSpans), errors.Is(err, repository.ErrInvalidBreakout):
+		respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
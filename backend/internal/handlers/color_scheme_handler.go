@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"spectra-backend/internal/models"
+	"spectra-backend/internal/repository"
+)
+
+// ColorSchemeHandler handles HTTP requests for fiber color schemes
+type ColorSchemeHandler struct {
+	repo *repository.ColorSchemeRepository
+}
+
+// NewColorSchemeHandler creates a new ColorSchemeHandler
+func NewColorSchemeHandler(repo *repository.ColorSchemeRepository) *ColorSchemeHandler {
+	return &ColorSchemeHandler{repo: repo}
+}
+
+// List handles GET /api/color-schemes
+func (h *ColorSchemeHandler) List(w http.ResponseWriter, r *http.Request) {
+	schemes, err := h.repo.List(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list color schemes: "+err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(schemes, ""))
+}
+
+// GetByCode handles GET /api/color-schemes/{code}
+func (h *ColorSchemeHandler) GetByCode(w http.ResponseWriter, r *http.Request) {
+	scheme, err := h.repo.Get(r.Context(), r.PathValue("code"))
+	if err != nil {
+		respondColorSchemeError(w, err, "Failed to get color scheme")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(scheme, ""))
+}
+
+// Preview handles GET /api/color-schemes/{code}/preview?core_count=
+func (h *ColorSchemeHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	coreCount := parseIntParam(r, "core_count", 0)
+	if coreCount <= 0 || coreCount > repository.MaxCoreCount {
+		respondError(w, http.StatusBadRequest, "core_count must be between 1 and 288")
+		return
+	}
+
+	scheme, err := h.repo.Get(r.Context(), r.PathValue("code"))
+	if err != nil {
+		respondColorSchemeError(w, err, "Failed to get color scheme")
+		return
+	}
+
+	cores := scheme.GenerateCores(0, coreCount)
+	for i := range cores {
+		cores[i].TubeNumber = scheme.TubeNumber(cores[i].CoreIndex)
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(cores, ""))
+}
+
+// Create handles POST /api/color-schemes
+func (h *ColorSchemeHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateColorSchemeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if req.Code == "" {
+		respondError(w, http.StatusBadRequest, "Code is required")
+		return
+	}
+	if req.Name == "" {
+		respondError(w, http.StatusBadRequest, "Name is required")
+		return
+	}
+
+	scheme, err := h.repo.Create(r.Context(), &req)
+	if err != nil {
+		respondColorSchemeError(w, err, "Failed to create color scheme")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, models.SuccessResponse(scheme, "Color scheme created successfully"))
+}
+
+// Update handles PUT /api/color-schemes/{code}
+func (h *ColorSchemeHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var req models.UpdateColorSchemeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	scheme, err := h.repo.Update(r.Context(), r.PathValue("code"), &req)
+	if err != nil {
+		respondColorSchemeError(w, err, "Failed to update color scheme")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(scheme, "Color scheme updated successfully"))
+}
+
+// Delete handles DELETE /api/color-schemes/{code}
+func (h *ColorSchemeHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.repo.Delete(r.Context(), r.PathValue("code")); err != nil {
+		respondColorSchemeError(w, err, "Failed to delete color scheme")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(nil, "Color scheme deleted successfully"))
+}
+
+// ApplyToCable handles PUT /api/cables/{id}/color-scheme
+func (h *ColorSchemeHandler) ApplyToCable(w http.ResponseWriter, r *http.Request) {
+	cableID, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid cable ID")
+		return
+	}
+
+	var req models.ApplyColorSchemeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if req.ColorScheme == "" {
+		respondError(w, http.StatusBadRequest, "ColorScheme is required")
+		return
+	}
+
+	cores, err := h.repo.ApplyToCable(r.Context(), cableID, req.ColorScheme)
+	if err != nil {
+		respondColorSchemeError(w, err, "Failed to apply color scheme")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(cores, "Color scheme applied successfully"))
+}
+
+// respondColorSchemeError maps color scheme repository errors to HTTP status codes
+func respondColorSchemeError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrColorSchemeNotFound):
+		respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, repository.ErrCableNotFound):
+		respondError(w, http.StatusNotFound, "Cable not found")
+	case errors.Is(err, repository.ErrInvalidColorScheme):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrColorSchemeInUse):
+		respondError(w, http.StatusConflict, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, message+": "+err.Error())
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -44,6 +45,10 @@ func (h *ConnectionHandler) Create(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, "OutputID is required")
 		return
 	}
+	if req.HasUnlocatedPort() {
+		respondError(w, http.StatusBadRequest, "LocationNodeID is required for PORT endpoints, whose numbers are local to a node")
+		return
+	}
 
 	connection, err := h.repo.Create(r.Context(), &req)
 	if err != nil {
@@ -104,6 +109,12 @@ func (h *ConnectionHandler) List(w http.ResponseWriter, r *http.Request) {
 			filter.OutputID = &id
 		}
 	}
+	areaID, err := parseIDParam(r, "area_id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.AreaID = areaID
 	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
 		if limit, err := strconv.Atoi(limitParam); err == nil {
 			filter.Limit = limit
@@ -177,3 +188,40 @@ func (h *ConnectionHandler) GetByLocation(w http.ResponseWriter, r *http.Request
 
 	respondJSON(w, http.StatusOK, models.SuccessResponse(connections, ""))
 }
+
+// TraceCore handles GET /api/connections/trace/{coreId}
+// Query param from_node_id picks the end of the core to start at, which matters when the core is
+// broken out into several segments; the default is the core's first segment
+func (h *ConnectionHandler) TraceCore(w http.ResponseWriter, r *http.Request) {
+	coreID, err := getIDFromPath(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid core ID")
+		return
+	}
+
+	var fromNodeID *int64
+	if fromParam := r.URL.Query().Get("from_node_id"); fromParam != "" {
+		id, err := strconv.ParseInt(fromParam, 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid from_node_id")
+			return
+		}
+		fromNodeID = &id
+	}
+
+	trace, err := h.repo.TraceCore(r.Context(), coreID, fromNodeID)
+	if err != nil {
+		if errors.Is(err, repository.ErrCoreNotFound) {
+			respondError(w, http.StatusNotFound, "Cable core not found")
+			return
+		}
+		if errors.Is(err, repository.ErrInvalidBreakout) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to trace core: "+err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(trace, ""))
+}
@@ -81,6 +81,12 @@ func (h *CustomerHandler) List(w http.ResponseWriter, r *http.Request) {
 	if searchParam := r.URL.Query().Get("search"); searchParam != "" {
 		filter.Search = &searchParam
 	}
+	areaID, err := parseIDParam(r, "area_id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.AreaID = areaID
 	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
 		if limit, err := strconv.Atoi(limitParam); err == nil {
 			filter.Limit = limit
@@ -150,8 +156,16 @@ func (h *CustomerHandler) Delete(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetLOS handles GET /api/customers/los
+// Customers inside an active maintenance window carry maintenance_window_id;
+// suppress_maintenance=true leaves them out; area_id keeps the customers of a service area
 func (h *CustomerHandler) GetLOS(w http.ResponseWriter, r *http.Request) {
-	customers, err := h.repo.GetLOSCustomers(r.Context())
+	areaID, err := parseIDParam(r, "area_id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	customers, err := h.repo.GetLOSCustomers(r.Context(), parseBoolParam(r, "suppress_maintenance", false), areaID)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to get LOS customers: "+err.Error())
 		return
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"spectra-backend/internal/models"
+	"spectra-backend/internal/repository"
+	"spectra-backend/internal/topology"
+)
+
+// DropHandler handles HTTP requests for designing drop cables
+type DropHandler struct {
+	repo     *repository.DropRepository
+	topology *topology.Service
+}
+
+// NewDropHandler creates a new DropHandler
+func NewDropHandler(repo *repository.DropRepository, topologyService *topology.Service) *DropHandler {
+	return &DropHandler{repo: repo, topology: topologyService}
+}
+
+// Preview handles POST /api/drops/preview
+// Body: {"odp_id", "customer_node_id", "max_span_meters", "port", "core_count", "name"}
+// Proposes the drop route, port and cable without creating anything
+func (h *DropHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	design, ok := h.design(w, r)
+	if !ok {
+		return
+	}
+	respondJSON(w, http.StatusOK, models.SuccessResponse(design, ""))
+}
+
+// Commit handles POST /api/drops
+// Accepts the same body as Preview and creates the designed DROP cable, connected to the ODP port
+func (h *DropHandler) Commit(w http.ResponseWriter, r *http.Request) {
+	design, ok := h.design(w, r)
+	if !ok {
+		return
+	}
+
+	committed, err := h.repo.Commit(r.Context(), design)
+	if err != nil {
+		h.respondDropError(w, err, "Failed to create drop: ")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, models.SuccessResponse(committed, "Drop cable created successfully"))
+}
+
+func (h *DropHandler) design(w http.ResponseWriter, r *http.Request) (*models.DropDesign, bool) {
+	var req models.DropDesignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return nil, false
+	}
+	if req.ODPID == 0 || req.CustomerNodeID == 0 {
+		respondError(w, http.StatusBadRequest, "odp_id and customer_node_id are required")
+		return nil, false
+	}
+	if req.MaxSpanMeters < 0 || req.CoreCount < 0 || req.CoreCount > 288 {
+		respondError(w, http.StatusBadRequest, "max_span_meters cannot be negative and core_count must be between 1 and 288")
+		return nil, false
+	}
+
+	g, err := h.topology.Graph(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to load topology: "+err.Error())
+		return nil, false
+	}
+
+	inUse, err := h.repo.PortsInUse(r.Context(), req.ODPID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get ports in use: "+err.Error())
+		return nil, false
+	}
+
+	design, err := g.DesignDrop(&req, inUse)
+	if err != nil {
+		h.respondDropError(w, err, "Failed to design drop: ")
+		return nil, false
+	}
+	return design, true
+}
+
+func (h *DropHandler) respondDropError(w http.ResponseWriter, err error, prefix string) {
+	var unknown *topology.UnknownElementsError
+	switch {
+	case errors.As(err, &unknown):
+		respondError(w, http.StatusNotFound, "Unknown elements: "+strings.Join(unknown.Missing, ", "))
+	case errors.Is(err, repository.ErrNoFreePort),
+		errors.Is(err, repository.ErrPortInUse),
+		errors.Is(err, repository.ErrCustomerHasDrop):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, repository.ErrInvalidDropDesign):
+		respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, prefix+err.Error())
+	}
+}
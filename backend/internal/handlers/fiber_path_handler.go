@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"spectra-backend/internal/models"
+	"spectra-backend/internal/repository"
+	"spectra-backend/internal/topology"
+)
+
+// FiberPathHandler handles HTTP requests for routing new links over vacant cores
+type FiberPathHandler struct {
+	service         *topology.Service
+	reservationRepo *repository.ReservationRepository
+}
+
+// NewFiberPathHandler creates a new FiberPathHandler
+func NewFiberPathHandler(service *topology.Service, reservationRepo *repository.ReservationRepository) *FiberPathHandler {
+	return &FiberPathHandler{service: service, reservationRepo: reservationRepo}
+}
+
+// Find handles POST /api/fiber-paths
+// Body: {"from_node_id": 1, "to_node_id": 9, "metric": "DISTANCE|LOSS", "reserve": {"owner": "...", "work_order": "..."}}
+func (h *FiberPathHandler) Find(w http.ResponseWriter, r *http.Request) {
+	var req models.FiberPathRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	req.Metric = models.FiberPathMetric(strings.ToUpper(string(req.Metric)))
+	switch {
+	case req.FromNodeID == 0 || req.ToNodeID == 0:
+		respondError(w, http.StatusBadRequest, "from_node_id and to_node_id are required")
+		return
+	case req.FromNodeID == req.ToNodeID:
+		respondError(w, http.StatusBadRequest, "from_node_id and to_node_id must differ")
+		return
+	case req.Metric != "" && req.Metric != models.FiberPathMetricDistance && req.Metric != models.FiberPathMetricLoss:
+		respondError(w, http.StatusBadRequest, "metric must be DISTANCE or LOSS")
+		return
+	case req.AttenuationDBPerKm != nil && *req.AttenuationDBPerKm < 0, req.SpliceLossDB != nil && *req.SpliceLossDB < 0:
+		respondError(w, http.StatusBadRequest, "Losses must not be negative")
+		return
+	case req.Reserve != nil && strings.TrimSpace(req.Reserve.Owner) == "":
+		respondError(w, http.StatusBadRequest, "reserve.owner is required")
+		return
+	}
+
+	g, err := h.service.Graph(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to load topology: "+err.Error())
+		return
+	}
+
+	path, err := g.FindFiberPath(&req)
+	var unknown *topology.UnknownElementsError
+	switch {
+	case errors.As(err, &unknown):
+		respondError(w, http.StatusNotFound, "Unknown elements: "+strings.Join(unknown.Missing, ", "))
+		return
+	case errors.Is(err, topology.ErrNoFiberPath):
+		respondError(w, http.StatusNotFound, "No path of vacant cores joins the two nodes")
+		return
+	case err != nil:
+		respondError(w, http.StatusInternalServerError, "Failed to find fiber path: "+err.Error())
+		return
+	}
+
+	if req.Reserve != nil {
+		reservation := &models.CreateReservationRequest{ReservationDetails: *req.Reserve}
+		for _, id := range path.CoreIDs {
+			reservation.Elements = append(reservation.Elements, models.ReservationElement{Type: models.ConnectionTypeCore, ID: id})
+		}
+
+		reservations, err := h.reservationRepo.Create(r.Context(), reservation)
+		var conflict *repository.ReservationConflictError
+		switch {
+		case errors.As(err, &conflict):
+			// The graph was stale; reload it so a retry sees the taken cores
+			h.service.Invalidate()
+			respondJSON(w, http.StatusConflict, models.Response{
+				Success: false,
+				Error:   err.Error(),
+				Data:    path,
+			})
+			return
+		case errors.Is(err, repository.ErrInvalidReservation):
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		case err != nil:
+			respondError(w, http.StatusInternalServerError, "Failed to reserve cores: "+err.Error())
+			return
+		}
+		h.service.Invalidate()
+		path.Reservations = reservations
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(path, ""))
+}
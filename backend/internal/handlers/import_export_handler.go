@@ -0,0 +1,596 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"spectra-backend/internal/geo"
+	"spectra-backend/internal/geojson"
+	"spectra-backend/internal/gis"
+	"spectra-backend/internal/kml"
+	"spectra-backend/internal/models"
+	"spectra-backend/internal/repository"
+	"spectra-backend/internal/tabular"
+)
+
+// maxUploadBytes limits the size of imported files that are read into memory
+const maxUploadBytes = 64 << 20
+
+// maxStreamedUploadBytes limits the size of imported files that are spooled to disk and streamed
+const maxStreamedUploadBytes = 2 << 30
+
+// ImportExportHandler handles HTTP requests for bulk plant import and export
+type ImportExportHandler struct {
+	importRepo   *repository.ImportRepository
+	nodeRepo     *repository.NodeRepository
+	cableRepo    *repository.CableRepository
+	customerRepo *repository.CustomerRepository
+	areaRepo     *repository.AreaRepository
+}
+
+// NewImportExportHandler creates a new ImportExportHandler
+func NewImportExportHandler(importRepo *repository.ImportRepository, nodeRepo *repository.NodeRepository, cableRepo *repository.CableRepository, customerRepo *repository.CustomerRepository, areaRepo *repository.AreaRepository) *ImportExportHandler {
+	return &ImportExportHandler{importRepo: importRepo, nodeRepo: nodeRepo, cableRepo: cableRepo, customerRepo: customerRepo, areaRepo: areaRepo}
+}
+
+// ImportKML handles POST /api/import/kml
+// Accepts a KML or KMZ file as the raw body or as the multipart field "file".
+// Query params: dry_run, snap_tolerance (meters), status, default_node_type, default_cable_type,
+// default_core_count, prefix (repeatable "FAT:ODP" name prefix to node type mappings)
+func (h *ImportExportHandler) ImportKML(w http.ResponseWriter, r *http.Request) {
+	data, err := readUpload(w, r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	placemarks, err := kml.Decode(data)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	query := r.URL.Query()
+	status := strings.ToUpper(query.Get("status"))
+	if status == "" {
+		// Imported designs are plans until they are built
+		status = string(models.NodeStatusPlan)
+	}
+	if !models.NodeStatus(status).IsValid() {
+		respondError(w, http.StatusBadRequest, "Invalid status")
+		return
+	}
+	opts := kml.MappingOptions{
+		DefaultNodeType:  models.NodeType(strings.ToUpper(query.Get("default_node_type"))),
+		DefaultCableType: models.CableType(strings.ToUpper(query.Get("default_cable_type"))),
+		DefaultCoreCount: parseIntParam(r, "default_core_count", 12),
+		NodeStatus:       models.NodeStatus(status),
+		CableStatus:      models.CableStatus(status),
+		NamePrefixes:     map[string]models.NodeType{},
+	}
+	if opts.DefaultNodeType != "" && !opts.DefaultNodeType.IsValid() {
+		respondError(w, http.StatusBadRequest, "Invalid default_node_type")
+		return
+	}
+	if opts.DefaultCableType != "" && !opts.DefaultCableType.IsValid() {
+		respondError(w, http.StatusBadRequest, "Invalid default_cable_type")
+		return
+	}
+	for _, mapping := range query["prefix"] {
+		prefix, nodeType, ok := strings.Cut(mapping, ":")
+		if !ok || prefix == "" || !models.NodeType(strings.ToUpper(nodeType)).IsValid() {
+			respondError(w, http.StatusBadRequest, "Invalid prefix mapping "+mapping+", expected PREFIX:NODE_TYPE")
+			return
+		}
+		opts.NamePrefixes[prefix] = models.NodeType(strings.ToUpper(nodeType))
+	}
+
+	plan := kml.ToImportPlan(placemarks, opts)
+	report, err := h.importRepo.Apply(r.Context(), plan, models.ImportOptions{
+		DryRun:              parseBoolParam(r, "dry_run", false),
+		SnapToleranceMeters: parseFloatParam(r, "snap_tolerance", models.DefaultSnapToleranceMeters),
+	})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to import KML: "+err.Error())
+		return
+	}
+
+	message := "Import completed"
+	if report.DryRun {
+		message = "Dry run completed, nothing was saved"
+	}
+	respondJSON(w, http.StatusOK, models.SuccessResponse(report, message))
+}
+
+// ImportGeoJSON handles POST /api/import/geojson
+// Accepts a FeatureCollection of Points (nodes) and LineStrings (cables) as the raw body or the
+// multipart field "file". Features are upserted by their "external_id" property.
+// Query params: dry_run, snap_tolerance (meters)
+func (h *ImportExportHandler) ImportGeoJSON(w http.ResponseWriter, r *http.Request) {
+	// The upload is spooled to disk and streamed twice: nodes first, so cables can link to them
+	file, err := spoolUpload(w, r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	session, err := h.importRepo.Begin(r.Context(), "GeoJSON", models.ImportOptions{
+		DryRun:              parseBoolParam(r, "dry_run", false),
+		SnapToleranceMeters: parseFloatParam(r, "snap_tolerance", models.DefaultSnapToleranceMeters),
+	})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to import GeoJSON: "+err.Error())
+		return
+	}
+	defer session.Close(r.Context())
+
+	failed := func(index int, f *geojson.Feature, kind models.ImportItemKind, err error) {
+		session.Report(models.ImportItemResult{
+			Ref:        f.Ref(index),
+			Kind:       kind,
+			Action:     models.ImportActionError,
+			ExternalID: f.ExternalID(),
+			Message:    err.Error(),
+		})
+	}
+
+	err = geojson.Stream(file, func(index int, f *geojson.Feature) error {
+		if f.Geometry == nil {
+			failed(index, f, "", fmt.Errorf("feature has no geometry"))
+			return nil
+		}
+		switch f.Geometry.Type {
+		case "Point":
+			node, err := geojson.ToImportNode(index, f)
+			if err != nil {
+				failed(index, f, models.ImportItemNode, err)
+				return nil
+			}
+			return session.AddNode(r.Context(), node)
+		case "LineString":
+			return nil // Second pass
+		default:
+			failed(index, f, "", fmt.Errorf("%s geometry is not supported", f.Geometry.Type))
+			return nil
+		}
+	})
+	if err == nil {
+		if _, err = file.Seek(0, io.SeekStart); err == nil {
+			err = geojson.Stream(file, func(index int, f *geojson.Feature) error {
+				if f.Geometry == nil || f.Geometry.Type != "LineString" {
+					return nil
+				}
+				cable, err := geojson.ToImportCable(index, f)
+				if err != nil {
+					failed(index, f, models.ImportItemCable, err)
+					return nil
+				}
+				return session.AddCable(r.Context(), cable)
+			})
+		}
+	}
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Failed to import GeoJSON: "+err.Error())
+		return
+	}
+
+	report, err := session.Finish(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to import GeoJSON: "+err.Error())
+		return
+	}
+
+	message := "Import completed"
+	if report.DryRun {
+		message = "Dry run completed, nothing was saved"
+	}
+	respondJSON(w, http.StatusOK, models.SuccessResponse(report, message))
+}
+
+// ImportNodesSheet handles POST /api/import/nodes
+// Accepts a CSV or XLSX sheet with name, type, latitude, longitude and optional capacity_ports,
+// address, model and status columns. Rows are only created when every row is valid.
+// Query params: dry_run, sheet (XLSX sheet name), map (repeatable "field:Column Header")
+func (h *ImportExportHandler) ImportNodesSheet(w http.ResponseWriter, r *http.Request) {
+	table, overrides, ok := readSheet(w, r)
+	if !ok {
+		return
+	}
+
+	nodes, rejected, err := tabular.MapNodes(table, overrides)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.importRepo.ImportNodeRows(r.Context(), table.Format, nodes, rejected, parseBoolParam(r, "dry_run", false))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to import nodes: "+err.Error())
+		return
+	}
+	respondRowImport(w, report)
+}
+
+// ImportCustomersSheet handles POST /api/import/customers
+// Accepts a CSV or XLSX sheet with name and optional phone, email, ont_sn, odp (ODP name),
+// subscription_type and status columns. Rows are only created when every row is valid.
+// Query params: dry_run, sheet (XLSX sheet name), map (repeatable "field:Column Header")
+func (h *ImportExportHandler) ImportCustomersSheet(w http.ResponseWriter, r *http.Request) {
+	table, overrides, ok := readSheet(w, r)
+	if !ok {
+		return
+	}
+
+	customers, rejected, err := tabular.MapCustomers(table, overrides)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.importRepo.ImportCustomerRows(r.Context(), table.Format, customers, rejected, parseBoolParam(r, "dry_run", false))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to import customers: "+err.Error())
+		return
+	}
+	respondRowImport(w, report)
+}
+
+// readSheet reads the uploaded spreadsheet and the column mapping overrides, responding on failure
+func readSheet(w http.ResponseWriter, r *http.Request) (*tabular.Table, map[string]string, bool) {
+	overrides := map[string]string{}
+	for _, mapping := range r.URL.Query()["map"] {
+		field, column, ok := strings.Cut(mapping, ":")
+		if !ok || field == "" || column == "" {
+			respondError(w, http.StatusBadRequest, "Invalid column mapping "+mapping+", expected field:Column")
+			return nil, nil, false
+		}
+		overrides[strings.TrimSpace(field)] = strings.TrimSpace(column)
+	}
+
+	data, err := readUpload(w, r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return nil, nil, false
+	}
+
+	table, err := tabular.Read(data, r.URL.Query().Get("sheet"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return nil, nil, false
+	}
+	return table, overrides, true
+}
+
+// respondRowImport sends a row import report; a rejected import is 422 with the report as data
+func respondRowImport(w http.ResponseWriter, report *models.ImportReport) {
+	switch {
+	case report.Committed:
+		respondJSON(w, http.StatusOK, models.SuccessResponse(report, "Import completed"))
+	case report.Failed > 0 && !report.DryRun:
+		respondJSON(w, http.StatusUnprocessableEntity, models.Response{
+			Success: false,
+			Error:   fmt.Sprintf("%d rows are invalid; nothing was imported", report.Failed),
+			Data:    report,
+		})
+	default:
+		respondJSON(w, http.StatusOK, models.SuccessResponse(report, "Dry run completed, nothing was saved"))
+	}
+}
+
+// ExportKMZ handles GET /api/export/kmz
+// Use ?format=kml for an uncompressed KML document
+func (h *ImportExportHandler) ExportKMZ(w http.ResponseWriter, r *http.Request) {
+	nodes, err := h.nodeRepo.ListAll(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get nodes: "+err.Error())
+		return
+	}
+	cables, err := h.cableRepo.ListWithPaths(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get cables: "+err.Error())
+		return
+	}
+	usedCores, err := h.cableRepo.GetUsedCoreCounts(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get core usage: "+err.Error())
+		return
+	}
+
+	doc := kml.PlantDocument("SPECTRA Network Plant", nodes, cables, usedCores)
+	filename := "spectra-plant-" + time.Now().Format("20060102")
+
+	// Encode into a buffer first so an encoding failure can still be reported as JSON
+	var buf bytes.Buffer
+	contentType := "application/vnd.google-earth.kmz"
+	if strings.EqualFold(r.URL.Query().Get("format"), "kml") {
+		err = kml.Write(&buf, doc)
+		contentType = "application/vnd.google-earth.kml+xml"
+		filename += ".kml"
+	} else {
+		err = kml.WriteKMZ(&buf, doc)
+		filename += ".kmz"
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to export plant: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// ExportGeoPackage handles GET /api/export/gpkg
+// Query params: see plantLayers
+func (h *ImportExportHandler) ExportGeoPackage(w http.ResponseWriter, r *http.Request) {
+	layers, crs, ok := h.plantLayers(w, r)
+	if !ok {
+		return
+	}
+
+	// SQLite needs a real file; it is built in a private temporary directory and then streamed
+	dir, err := os.MkdirTemp("", "spectra-export-*")
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to export plant: "+err.Error())
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "plant.gpkg")
+	if err := gis.WriteGeoPackage(r.Context(), path, layers, crs); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to export plant: "+err.Error())
+		return
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to export plant: "+err.Error())
+		return
+	}
+	defer file.Close()
+
+	filename := "spectra-plant-" + time.Now().Format("20060102") + ".gpkg"
+	w.Header().Set("Content-Type", "application/geopackage+sqlite3")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, file)
+}
+
+// ExportShapefile handles GET /api/export/shapefile
+// Responds with a zip holding one Shapefile per layer. Query params: see plantLayers
+func (h *ImportExportHandler) ExportShapefile(w http.ResponseWriter, r *http.Request) {
+	layers, crs, ok := h.plantLayers(w, r)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := gis.WriteShapefileZip(&buf, layers, crs); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to export plant: "+err.Error())
+		return
+	}
+
+	filename := "spectra-plant-" + time.Now().Format("20060102") + "-shp.zip"
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// plantLayers loads the layers requested by the GIS export query, responding on failure.
+// Query params:
+//   - layers: comma-separated subset of nodes, cables, customers, areas (default all)
+//   - bbox: minLng,minLat,maxLng,maxLat
+//   - status: comma-separated node/cable statuses
+//   - customer_status: comma-separated customer statuses
+//   - utm_zone: reproject to a UTM zone such as "48S", or "auto" for the zone of the data's center;
+//     coordinates are EPSG:4326 longitude/latitude when omitted
+func (h *ImportExportHandler) plantLayers(w http.ResponseWriter, r *http.Request) ([]*gis.Layer, *gis.CRS, bool) {
+	query := r.URL.Query()
+
+	selected := map[string]bool{}
+	for _, name := range splitParam(query.Get("layers")) {
+		known := false
+		for _, layer := range gis.PlantLayerNames {
+			known = known || name == layer
+		}
+		if !known {
+			respondError(w, http.StatusBadRequest, "Unknown layer "+name)
+			return nil, nil, false
+		}
+		selected[name] = true
+	}
+	if len(selected) == 0 {
+		for _, layer := range gis.PlantLayerNames {
+			selected[layer] = true
+		}
+	}
+
+	filter := &gis.PlantFilter{Status: map[string]bool{}, CustomerStatus: map[string]bool{}}
+	if value := query.Get("bbox"); value != "" {
+		box, ok := parseBBox(value)
+		if !ok {
+			respondError(w, http.StatusBadRequest, "Invalid bbox, expected minLng,minLat,maxLng,maxLat")
+			return nil, nil, false
+		}
+		filter.BBox = box
+	}
+	for _, status := range splitParam(strings.ToUpper(query.Get("status"))) {
+		if !models.NodeStatus(status).IsValid() {
+			respondError(w, http.StatusBadRequest, "Invalid status "+status)
+			return nil, nil, false
+		}
+		filter.Status[status] = true
+	}
+	for _, status := range splitParam(strings.ToUpper(query.Get("customer_status"))) {
+		switch models.CustomerStatus(status) {
+		case models.CustomerStatusOnline, models.CustomerStatusOffline, models.CustomerStatusLOS, models.CustomerStatusPowerOff:
+			filter.CustomerStatus[status] = true
+		default:
+			respondError(w, http.StatusBadRequest, "Invalid customer_status "+status)
+			return nil, nil, false
+		}
+	}
+
+	// Nodes are always loaded: cables and customers take their locations from them
+	nodes, err := h.nodeRepo.ListAll(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get nodes: "+err.Error())
+		return nil, nil, false
+	}
+
+	var layers []*gis.Layer
+	if selected[gis.LayerNodes] {
+		layers = append(layers, gis.NodeLayer(nodes, filter))
+	}
+	if selected[gis.LayerCables] {
+		cables, err := h.cableRepo.ListWithPaths(r.Context())
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to get cables: "+err.Error())
+			return nil, nil, false
+		}
+		usedCores, err := h.cableRepo.GetUsedCoreCounts(r.Context())
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to get core usage: "+err.Error())
+			return nil, nil, false
+		}
+		layers = append(layers, gis.CableLayer(cables, nodes, usedCores, filter))
+	}
+	if selected[gis.LayerCustomers] {
+		customers, err := h.customerRepo.ListAll(r.Context())
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to get customers: "+err.Error())
+			return nil, nil, false
+		}
+		layers = append(layers, gis.CustomerLayer(customers, nodes, filter))
+	}
+	if selected[gis.LayerAreas] {
+		areas, err := h.areaRepo.ListAll(r.Context())
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to get service areas: "+err.Error())
+			return nil, nil, false
+		}
+		layers = append(layers, gis.AreaLayer(areas, filter))
+	}
+
+	crs := gis.WGS84()
+	switch zone := query.Get("utm_zone"); {
+	case zone == "":
+	case strings.EqualFold(zone, "auto"):
+		crs = gis.UTM(gis.CenterUTMZone(layers))
+	default:
+		utmZone, err := geo.ParseUTMZone(zone)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return nil, nil, false
+		}
+		crs = gis.UTM(utmZone)
+	}
+
+	return layers, crs, true
+}
+
+// parseBBox parses a minLng,minLat,maxLng,maxLat bounding box
+func parseBBox(value string) (*gis.BBox, bool) {
+	parts := strings.Split(value, ",")
+	var coords [4]float64
+	valid := len(parts) == 4
+	for i := 0; valid && i < 4; i++ {
+		var err error
+		coords[i], err = strconv.ParseFloat(strings.TrimSpace(parts[i]), 64)
+		valid = err == nil
+	}
+	if !valid || coords[0] > coords[2] || coords[1] > coords[3] {
+		return nil, false
+	}
+	return &gis.BBox{MinLng: coords[0], MinLat: coords[1], MaxLng: coords[2], MaxLat: coords[3]}, true
+}
+
+// splitParam splits a comma-separated query value, dropping blanks
+func splitParam(value string) []string {
+	var parts []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// spoolUpload copies an uploaded file (multipart field "file" or the raw body) to a temporary file
+// without buffering it in memory. The caller removes the file.
+func spoolUpload(w http.ResponseWriter, r *http.Request) (*os.File, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxStreamedUploadBytes)
+
+	var src io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			return nil, fmt.Errorf("invalid multipart body: %w", err)
+		}
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				return nil, fmt.Errorf("missing upload field \"file\"")
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid multipart body: %w", err)
+			}
+			if part.FormName() == "file" {
+				src = part
+				break
+			}
+		}
+	}
+
+	file, err := os.CreateTemp("", "spectra-import-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	n, err := io.Copy(file, src)
+	if err == nil && n == 0 {
+		err = fmt.Errorf("request body is empty")
+	}
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+
+	return file, nil
+}
+
+// readUpload reads an uploaded file from the multipart field "file" or, failing that, the raw body
+func readUpload(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadBytes)
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			return nil, fmt.Errorf("missing upload field \"file\": %w", err)
+		}
+		defer file.Close()
+		return io.ReadAll(file)
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("request body is empty")
+	}
+	return data, nil
+}
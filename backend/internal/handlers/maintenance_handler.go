@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"spectra-backend/internal/models"
+	"spectra-backend/internal/repository"
+	"spectra-backend/internal/topology"
+)
+
+// MaintenanceHandler handles HTTP requests for planned maintenance windows
+type MaintenanceHandler struct {
+	repo     *repository.MaintenanceRepository
+	topology *topology.Service
+}
+
+// NewMaintenanceHandler creates a new MaintenanceHandler
+func NewMaintenanceHandler(repo *repository.MaintenanceRepository, topologyService *topology.Service) *MaintenanceHandler {
+	return &MaintenanceHandler{repo: repo, topology: topologyService}
+}
+
+// Create handles POST /api/maintenance-windows
+// The downstream customer set is computed from the topology
+func (h *MaintenanceHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateMaintenanceWindowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	// Validate required fields
+	if strings.TrimSpace(req.Title) == "" {
+		respondError(w, http.StatusBadRequest, "Title is required")
+		return
+	}
+	if req.StartsAt.IsZero() || req.EndsAt.IsZero() {
+		respondError(w, http.StatusBadRequest, "starts_at and ends_at are required")
+		return
+	}
+	if len(req.Assets) == 0 {
+		respondError(w, http.StatusBadRequest, "At least one asset is required")
+		return
+	}
+
+	customerIDs, ok := h.customers(w, r, req.Assets)
+	if !ok {
+		return
+	}
+
+	window, err := h.repo.Create(r.Context(), &req, customerIDs)
+	if err != nil {
+		h.respondChangeError(w, err, "Failed to create maintenance window: ")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, models.SuccessResponse(window, "Maintenance window created successfully"))
+}
+
+// GetByID handles GET /api/maintenance-windows/{id}
+func (h *MaintenanceHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid maintenance window ID")
+		return
+	}
+
+	window, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get maintenance window: "+err.Error())
+		return
+	}
+
+	if window == nil {
+		respondError(w, http.StatusNotFound, "Maintenance window not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(window, ""))
+}
+
+// Customers handles GET /api/maintenance-windows/{id}/customers
+// Lists the affected customers with contact details for pre-notification
+func (h *MaintenanceHandler) Customers(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid maintenance window ID")
+		return
+	}
+
+	window, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get maintenance window: "+err.Error())
+		return
+	}
+
+	if window == nil {
+		respondError(w, http.StatusNotFound, "Maintenance window not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(window.Customers, ""))
+}
+
+// List handles GET /api/maintenance-windows
+// Query params: status, from and to (RFC 3339, windows overlapping the range), element_type and
+// element_id (windows covering an asset), customer_id, work_order_id, area_id, limit, offset
+func (h *MaintenanceHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter := &models.MaintenanceWindowFilter{}
+	query := r.URL.Query()
+
+	if statusParam := query.Get("status"); statusParam != "" {
+		status := models.MaintenanceStatus(strings.ToUpper(statusParam))
+		filter.Status = &status
+	}
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := query.Get(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				respondError(w, http.StatusBadRequest, param+" must be an RFC 3339 time")
+				return
+			}
+			*target = &t
+		}
+	}
+	if typeParam := query.Get("element_type"); typeParam != "" {
+		asset := &models.MaintenanceAssetRequest{ElementType: models.MaintenanceAssetType(strings.ToUpper(typeParam))}
+		id, err := strconv.ParseInt(query.Get("element_id"), 10, 64)
+		if err != nil || !asset.ElementType.IsValid() {
+			respondError(w, http.StatusBadRequest, "element_type must be node or cable with an element_id")
+			return
+		}
+		asset.ElementID = id
+		filter.Asset = asset
+	}
+	if customerParam := query.Get("customer_id"); customerParam != "" {
+		if id, err := strconv.ParseInt(customerParam, 10, 64); err == nil {
+			filter.CustomerID = &id
+		}
+	}
+	if workOrderParam := query.Get("work_order_id"); workOrderParam != "" {
+		if id, err := strconv.ParseInt(workOrderParam, 10, 64); err == nil {
+			filter.WorkOrderID = &id
+		}
+	}
+	areaID, err := parseIDParam(r, "area_id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.AreaID = areaID
+	filter.Limit = parseIntParam(r, "limit", 0)
+	filter.Offset = parseIntParam(r, "offset", 0)
+
+	windows, total, err := h.repo.List(r.Context(), filter)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list maintenance windows: "+err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.NewPaginatedResponse(windows, total, filter.Limit, filter.Offset))
+}
+
+// Update handles PUT /api/maintenance-windows/{id}
+// Only scheduled windows can be edited; new assets recompute the customer set
+func (h *MaintenanceHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid maintenance window ID")
+		return
+	}
+
+	var req models.UpdateMaintenanceWindowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	var customerIDs []int64
+	if req.Assets != nil {
+		var ok bool
+		if customerIDs, ok = h.customers(w, r, req.Assets); !ok {
+			return
+		}
+	}
+
+	window, err := h.repo.Update(r.Context(), id, &req, customerIDs)
+	if err != nil {
+		h.respondChangeError(w, err, "Failed to update maintenance window: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(window, "Maintenance window updated successfully"))
+}
+
+// Start handles POST /api/maintenance-windows/{id}/start
+// Starts a scheduled window ahead of its start time
+func (h *MaintenanceHandler) Start(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid maintenance window ID")
+		return
+	}
+
+	current, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get maintenance window: "+err.Error())
+		return
+	}
+	if current == nil {
+		respondError(w, http.StatusNotFound, "Maintenance window not found")
+		return
+	}
+
+	g, err := h.topology.Graph(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to load topology: "+err.Error())
+		return
+	}
+
+	window, err := h.repo.Start(r.Context(), id, g.MaintenanceCustomers(current.Assets))
+	if err != nil {
+		h.respondChangeError(w, err, "Failed to start maintenance window: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(window, "Maintenance window started successfully"))
+}
+
+// End handles POST /api/maintenance-windows/{id}/end
+// Ends an active window ahead of its end time and restores asset statuses
+func (h *MaintenanceHandler) End(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid maintenance window ID")
+		return
+	}
+
+	window, err := h.repo.End(r.Context(), id)
+	if err != nil {
+		h.respondChangeError(w, err, "Failed to end maintenance window: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(window, "Maintenance window ended successfully"))
+}
+
+// Cancel handles POST /api/maintenance-windows/{id}/cancel
+func (h *MaintenanceHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid maintenance window ID")
+		return
+	}
+
+	window, err := h.repo.Cancel(r.Context(), id)
+	if err != nil {
+		h.respondChangeError(w, err, "Failed to cancel maintenance window: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(window, "Maintenance window cancelled successfully"))
+}
+
+// customers computes the customers downstream of the assets, responding on failure
+func (h *MaintenanceHandler) customers(w http.ResponseWriter, r *http.Request, assets []models.MaintenanceAssetRequest) ([]int64, bool) {
+	g, err := h.topology.Graph(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to load topology: "+err.Error())
+		return nil, false
+	}
+
+	windowAssets := make([]models.MaintenanceAsset, 0, len(assets))
+	for _, a := range assets {
+		windowAssets = append(windowAssets, models.MaintenanceAsset{ElementType: a.ElementType, ElementID: a.ElementID})
+	}
+	return g.MaintenanceCustomers(windowAssets), true
+}
+
+func (h *MaintenanceHandler) respondChangeError(w http.ResponseWriter, err error, prefix string) {
+	switch {
+	case errors.Is(err, repository.ErrMaintenanceWindowNotFound):
+		respondError(w, http.StatusNotFound, "Maintenance window not found")
+	case errors.Is(err, repository.ErrMaintenanceWindowState):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, repository.ErrInvalidMaintenanceWindow):
+		respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, prefix+err.Error())
+	}
+}
@@ -85,6 +85,12 @@ func (h *NodeHandler) List(w http.ResponseWriter, r *http.Request) {
 		status := models.NodeStatus(statusParam)
 		filter.Status = &status
 	}
+	areaID, err := parseIDParam(r, "area_id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.AreaID = areaID
 	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
 		if limit, err := strconv.Atoi(limitParam); err == nil {
 			filter.Limit = limit
@@ -213,6 +219,12 @@ func (h *NodeHandler) GetGeoJSON(w http.ResponseWriter, r *http.Request) {
 		nodeType := models.NodeType(typeParam)
 		filter.Type = &nodeType
 	}
+	areaID, err := parseIDParam(r, "area_id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.AreaID = areaID
 
 	features, err := h.repo.GetAllAsGeoJSON(r.Context(), filter)
 	if err != nil {
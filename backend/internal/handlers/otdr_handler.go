@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"spectra-backend/internal/models"
+	"spectra-backend/internal/otdr"
+	"spectra-backend/internal/repository"
+	"spectra-backend/internal/topology"
+)
+
+// OTDRHandler handles HTTP requests for OTDR traces and fault location
+type OTDRHandler struct {
+	repo    *repository.OTDRRepository
+	service *topology.Service
+}
+
+// NewOTDRHandler creates a new OTDRHandler
+func NewOTDRHandler(repo *repository.OTDRRepository, service *topology.Service) *OTDRHandler {
+	return &OTDRHandler{repo: repo, service: service}
+}
+
+// Upload handles POST /api/otdr/traces
+// Accepts a .sor file as multipart field "file" or as the raw body. Fields (form or query):
+// core_id and from_node_id (where the OTDR was connected) are required; slack_pct and node_slack_meter are optional.
+// A file that is not a readable SOR trace is rejected with 422.
+func (h *OTDRHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	data, err := readUpload(w, r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	coreID, errCore := strconv.ParseInt(r.FormValue("core_id"), 10, 64)
+	fromNodeID, errNode := strconv.ParseInt(r.FormValue("from_node_id"), 10, 64)
+	if errCore != nil || errNode != nil {
+		respondError(w, http.StatusBadRequest, "core_id and from_node_id are required")
+		return
+	}
+	slack, err := formSlack(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	parsed, err := otdr.Parse(data)
+	if err != nil {
+		respondError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	trace := parsed.ToModel()
+	trace.CoreID = coreID
+	trace.FromNodeID = fromNodeID
+	trace.SlackPct = models.DefaultOTDRSlackPct
+	if slack.SlackPct != nil {
+		trace.SlackPct = *slack.SlackPct
+	}
+	if slack.NodeSlackMeter != nil {
+		trace.NodeSlackMeter = *slack.NodeSlackMeter
+	}
+	if r.MultipartForm != nil && len(r.MultipartForm.File["file"]) > 0 {
+		name := r.MultipartForm.File["file"][0].Filename
+		trace.FileName = &name
+	} else if name := r.FormValue("file_name"); name != "" {
+		trace.FileName = &name
+	}
+
+	// Check the trace can be placed before storing it
+	g, err := h.service.Graph(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to load topology: "+err.Error())
+		return
+	}
+	if _, err := g.AnalyzeOTDR(trace, models.FiberSlack{}); err != nil {
+		h.respondAnalysisError(w, err)
+		return
+	}
+
+	created, err := h.repo.Create(r.Context(), trace, data)
+	if err != nil {
+		if errors.Is(err, repository.ErrCoreNotFound) {
+			respondError(w, http.StatusNotFound, "Core not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to store OTDR trace: "+err.Error())
+		return
+	}
+
+	analysis, err := g.AnalyzeOTDR(created, models.FiberSlack{})
+	if err != nil {
+		h.respondAnalysisError(w, err)
+		return
+	}
+
+	message := "OTDR trace imported successfully"
+	if parsed.Summary.ChecksumAvailable && !parsed.Summary.ChecksumValid {
+		message += " (file checksum does not match)"
+	}
+	respondJSON(w, http.StatusCreated, models.SuccessResponse(analysis, message))
+}
+
+// List handles GET /api/otdr/traces
+// Query params: core_id, cable_id, area_id, limit, offset
+func (h *OTDRHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter := &models.OTDRTraceFilter{}
+	query := r.URL.Query()
+
+	if coreParam := query.Get("core_id"); coreParam != "" {
+		if id, err := strconv.ParseInt(coreParam, 10, 64); err == nil {
+			filter.CoreID = &id
+		}
+	}
+	if cableParam := query.Get("cable_id"); cableParam != "" {
+		if id, err := strconv.ParseInt(cableParam, 10, 64); err == nil {
+			filter.CableID = &id
+		}
+	}
+	areaID, err := parseIDParam(r, "area_id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.AreaID = areaID
+	filter.Limit = parseIntParam(r, "limit", 0)
+	filter.Offset = parseIntParam(r, "offset", 0)
+
+	traces, total, err := h.repo.List(r.Context(), filter)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list OTDR traces: "+err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.NewPaginatedResponse(traces, total, filter.Limit, filter.Offset))
+}
+
+// GetByID handles GET /api/otdr/traces/{id}
+// Returns the trace laid over the current plant. Query params slack_pct and node_slack_meter
+// override the slack stored with the trace.
+func (h *OTDRHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid trace ID")
+		return
+	}
+	slack, err := formSlack(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	trace, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get OTDR trace: "+err.Error())
+		return
+	}
+	if trace == nil {
+		respondError(w, http.StatusNotFound, "OTDR trace not found")
+		return
+	}
+
+	g, err := h.service.Graph(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to load topology: "+err.Error())
+		return
+	}
+	analysis, err := g.AnalyzeOTDR(trace, slack)
+	if err != nil {
+		h.respondAnalysisError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(analysis, ""))
+}
+
+// Download handles GET /api/otdr/traces/{id}/file
+// Returns the original .sor file
+func (h *OTDRHandler) Download(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPathAt(r, 3)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid trace ID")
+		return
+	}
+
+	filename, data, err := h.repo.GetFile(r.Context(), id)
+	if errors.Is(err, repository.ErrOTDRTraceNotFound) {
+		respondError(w, http.StatusNotFound, "OTDR trace not found")
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get OTDR file: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// Delete handles DELETE /api/otdr/traces/{id}
+func (h *OTDRHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid trace ID")
+		return
+	}
+
+	if err := h.repo.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, repository.ErrOTDRTraceNotFound) {
+			respondError(w, http.StatusNotFound, "OTDR trace not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to delete OTDR trace: "+err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(nil, "OTDR trace deleted successfully"))
+}
+
+// Locate handles GET /api/otdr/locate
+// Query params: core_id, from_node_id, distance_meter (optical distance read off the OTDR),
+// slack_pct, node_slack_meter
+func (h *OTDRHandler) Locate(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := &models.OTDRLocateRequest{}
+	var errCore, errNode, errDistance error
+	req.CoreID, errCore = strconv.ParseInt(query.Get("core_id"), 10, 64)
+	req.FromNodeID, errNode = strconv.ParseInt(query.Get("from_node_id"), 10, 64)
+	req.DistanceMeter, errDistance = strconv.ParseFloat(query.Get("distance_meter"), 64)
+	if errCore != nil || errNode != nil || errDistance != nil {
+		respondError(w, http.StatusBadRequest, "core_id, from_node_id and distance_meter are required")
+		return
+	}
+	if math.IsNaN(req.DistanceMeter) || math.IsInf(req.DistanceMeter, 0) || req.DistanceMeter < 0 {
+		respondError(w, http.StatusBadRequest, "distance_meter must be a non-negative number")
+		return
+	}
+	slack, err := formSlack(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	req.FiberSlack = slack
+
+	g, err := h.service.Graph(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to load topology: "+err.Error())
+		return
+	}
+	location, err := g.LocateOnFiber(req)
+	if err != nil {
+		h.respondAnalysisError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(location, ""))
+}
+
+func (h *OTDRHandler) respondAnalysisError(w http.ResponseWriter, err error) {
+	var unknown *topology.UnknownElementsError
+	switch {
+	case errors.As(err, &unknown):
+		respondError(w, http.StatusNotFound, "Unknown elements: "+strings.Join(unknown.Missing, ", "))
+	case errors.Is(err, topology.ErrNotFiberEnd):
+		respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, "Failed to analyse OTDR trace: "+err.Error())
+	}
+}
+
+// formSlack reads slack_pct and node_slack_meter from the form or query
+func formSlack(r *http.Request) (models.FiberSlack, error) {
+	var slack models.FiberSlack
+	for key, target := range map[string]**float64{"slack_pct": &slack.SlackPct, "node_slack_meter": &slack.NodeSlackMeter} {
+		param := r.FormValue(key)
+		if param == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(param, 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) || value < 0 {
+			return slack, fmt.Errorf("%s must be a non-negative number", key)
+		}
+		*target = &value
+	}
+	return slack, nil
+}
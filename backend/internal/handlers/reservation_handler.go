@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"spectra-backend/internal/models"
+	"spectra-backend/internal/repository"
+)
+
+// ReservationHandler handles HTTP requests for core and port reservations
+type ReservationHandler struct {
+	repo *repository.ReservationRepository
+}
+
+// NewReservationHandler creates a new ReservationHandler
+func NewReservationHandler(repo *repository.ReservationRepository) *ReservationHandler {
+	return &ReservationHandler{repo: repo}
+}
+
+// Create handles POST /api/reservations
+// Reserves every listed element or none of them
+func (h *ReservationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateReservationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	// Validate required fields
+	if strings.TrimSpace(req.Owner) == "" {
+		respondError(w, http.StatusBadRequest, "Owner is required")
+		return
+	}
+	if len(req.Elements) == 0 {
+		respondError(w, http.StatusBadRequest, "At least one element is required")
+		return
+	}
+	if req.DurationHours != nil && *req.DurationHours <= 0 {
+		respondError(w, http.StatusBadRequest, "duration_hours must be positive")
+		return
+	}
+
+	reservations, err := h.repo.Create(r.Context(), &req)
+	if err != nil {
+		var conflict *repository.ReservationConflictError
+		switch {
+		case errors.As(err, &conflict):
+			respondJSON(w, http.StatusConflict, models.Response{
+				Success: false,
+				Error:   err.Error(),
+				Data:    conflict.Elements,
+			})
+		case errors.Is(err, repository.ErrInvalidReservation):
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondError(w, http.StatusInternalServerError, "Failed to create reservation: "+err.Error())
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, models.SuccessResponse(reservations, "Reservation created successfully"))
+}
+
+// GetByID handles GET /api/reservations/{id}
+func (h *ReservationHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid reservation ID")
+		return
+	}
+
+	reservation, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get reservation: "+err.Error())
+		return
+	}
+
+	if reservation == nil {
+		respondError(w, http.StatusNotFound, "Reservation not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(reservation, ""))
+}
+
+// List handles GET /api/reservations
+// Query params: owner, work_order, status, element_type, cable_id, area_id, expires_within_hours, limit, offset
+func (h *ReservationHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter := &models.ReservationFilter{}
+	query := r.URL.Query()
+
+	if owner := query.Get("owner"); owner != "" {
+		filter.Owner = &owner
+	}
+	if workOrder := query.Get("work_order"); workOrder != "" {
+		filter.WorkOrder = &workOrder
+	}
+	if statusParam := query.Get("status"); statusParam != "" {
+		status := models.ReservationStatus(strings.ToUpper(statusParam))
+		filter.Status = &status
+	}
+	if typeParam := query.Get("element_type"); typeParam != "" {
+		elementType := models.ConnectionType(strings.ToUpper(typeParam))
+		filter.ElementType = &elementType
+	}
+	if cableParam := query.Get("cable_id"); cableParam != "" {
+		if id, err := strconv.ParseInt(cableParam, 10, 64); err == nil {
+			filter.CableID = &id
+		}
+	}
+	if hours := parseFloatParam(r, "expires_within_hours", 0); hours > 0 {
+		expiresBy := time.Now().Add(time.Duration(hours * float64(time.Hour)))
+		filter.ExpiresBy = &expiresBy
+	}
+	areaID, err := parseIDParam(r, "area_id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.AreaID = areaID
+	filter.Limit = parseIntParam(r, "limit", 0)
+	filter.Offset = parseIntParam(r, "offset", 0)
+
+	reservations, total, err := h.repo.List(r.Context(), filter)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list reservations: "+err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.NewPaginatedResponse(reservations, total, filter.Limit, filter.Offset))
+}
+
+// Extend handles POST /api/reservations/{id}/extend
+// Body: {"expires_at": "..."} or {"duration_hours": 48}
+func (h *ReservationHandler) Extend(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid reservation ID")
+		return
+	}
+
+	var req models.ExtendReservationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	reservation, err := h.repo.Extend(r.Context(), id, &req)
+	if err != nil {
+		h.respondChangeError(w, err, "Failed to extend reservation: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(reservation, "Reservation extended successfully"))
+}
+
+// Release handles POST /api/reservations/{id}/release
+func (h *ReservationHandler) Release(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid reservation ID")
+		return
+	}
+
+	reservation, err := h.repo.Release(r.Context(), id)
+	if err != nil {
+		h.respondChangeError(w, err, "Failed to release reservation: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(reservation, "Reservation released successfully"))
+}
+
+func (h *ReservationHandler) respondChangeError(w http.ResponseWriter, err error, prefix string) {
+	switch {
+	case errors.Is(err, repository.ErrReservationNotFound):
+		respondError(w, http.StatusNotFound, "Reservation not found")
+	case errors.Is(err, repository.ErrReservationNotActive):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, repository.ErrInvalidReservation):
+		respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, prefix+err.Error())
+	}
+}
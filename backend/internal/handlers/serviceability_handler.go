@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"spectra-backend/internal/models"
+	"spectra-backend/internal/repository"
+	"spectra-backend/internal/topology"
+)
+
+// ServiceabilityHandler handles HTTP requests for serviceability checks of prospective customers
+type ServiceabilityHandler struct {
+	nodeRepo *repository.NodeRepository
+	topology *topology.Service
+}
+
+// NewServiceabilityHandler creates a new ServiceabilityHandler
+func NewServiceabilityHandler(nodeRepo *repository.NodeRepository, topologyService *topology.Service) *ServiceabilityHandler {
+	return &ServiceabilityHandler{nodeRepo: nodeRepo, topology: topologyService}
+}
+
+// Check handles GET /api/serviceability
+// Query params: lat, lng (required), max_drop_m (default 150), max_span_m (default 50),
+// tx_power_dbm, attenuation_db_per_km, limit (default 5)
+func (h *ServiceabilityHandler) Check(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	lat, errLat := strconv.ParseFloat(query.Get("lat"), 64)
+	lng, errLng := strconv.ParseFloat(query.Get("lng"), 64)
+	if errLat != nil || errLng != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		respondError(w, http.StatusBadRequest, "lat and lng are required and must be valid coordinates")
+		return
+	}
+
+	req := &models.ServiceabilityRequest{
+		Latitude:      lat,
+		Longitude:     lng,
+		MaxDropMeters: parseFloatParam(r, "max_drop_m", models.DefaultMaxDropMeters),
+		MaxSpanMeters: parseFloatParam(r, "max_span_m", models.DefaultMaxSpanMeters),
+		Limit:         parseIntParam(r, "limit", models.DefaultServiceabilityOptions),
+	}
+	if req.MaxDropMeters <= 0 || req.MaxSpanMeters <= 0 {
+		respondError(w, http.StatusBadRequest, "max_drop_m and max_span_m must be positive")
+		return
+	}
+	for key, target := range map[string]**float64{"tx_power_dbm": &req.OLTTxPowerDBm, "attenuation_db_per_km": &req.AttenuationDBPerKm} {
+		param := query.Get(key)
+		if param == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, key+" must be a number")
+			return
+		}
+		*target = &value
+	}
+
+	odpType := models.NodeTypeODP
+	odps, err := h.nodeRepo.GetNearby(r.Context(), &models.NearbyQuery{
+		Latitude:  lat,
+		Longitude: lng,
+		RadiusKM:  req.MaxDropMeters / 1000,
+		Type:      &odpType,
+	})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get nearby ODPs: "+err.Error())
+		return
+	}
+
+	g, err := h.topology.Graph(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to load topology: "+err.Error())
+		return
+	}
+
+	result := g.Serviceability(req, odps)
+	message := "Address is serviceable"
+	if !result.Serviceable {
+		message = "Address is not serviceable: " + result.Reason
+	}
+	respondJSON(w, http.StatusOK, models.SuccessResponse(result, message))
+}
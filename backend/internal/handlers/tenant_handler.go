@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"spectra-backend/internal/database"
+	"spectra-backend/internal/models"
+	"spectra-backend/internal/repository"
+)
+
+// TenantHeader names the tenant a request acts for, by ID or code. The API trusts the header, so the
+// gateway in front of it must set it from the caller's credentials and strip any value the caller sent.
+const TenantHeader = "X-Tenant-ID"
+
+// healthPath is the only route served to callers that are neither a tenant nor the operator
+const healthPath = "/api/health"
+
+// TenantHandler handles HTTP requests for tenant administration and scopes requests to their tenant
+type TenantHandler struct {
+	repo        *repository.TenantRepository
+	operatorKey string
+}
+
+// NewTenantHandler creates a new TenantHandler. Requests authenticate as the operator with
+// "Authorization: Bearer <operatorKey>"; an empty key disables operator access.
+func NewTenantHandler(repo *repository.TenantRepository, operatorKey string) *TenantHandler {
+	return &TenantHandler{repo: repo, operatorKey: operatorKey}
+}
+
+// Scope is middleware that scopes each request to the active tenant named by its X-Tenant-ID
+// header, so every query it runs only sees that tenant's records and the shared infrastructure.
+// Requests without the header must carry the operator key to act for the operator; any other
+// request is rejected.
+func (h *TenantHandler) Scope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == healthPath {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		ref := r.Header.Get(TenantHeader)
+		if ref == "" {
+			if !h.isOperatorKey(r.Header.Get("Authorization")) {
+				respondError(w, http.StatusUnauthorized, "Request has no tenant; send "+TenantHeader+" or the operator key")
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(database.WithOperator(ctx)))
+			return
+		}
+
+		tenant, err := h.repo.Resolve(ctx, ref)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to resolve tenant: "+err.Error())
+			return
+		}
+		if tenant == nil {
+			respondError(w, http.StatusForbidden, "Unknown tenant "+ref)
+			return
+		}
+		if !tenant.Active {
+			respondError(w, http.StatusForbidden, "Tenant "+tenant.Code+" is inactive")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(database.WithTenant(ctx, tenant.ID)))
+	})
+}
+
+// isOperatorKey reports whether an Authorization header carries the operator key
+func (h *TenantHandler) isOperatorKey(auth string) bool {
+	token, ok := strings.CutPrefix(auth, "Bearer ")
+	if !ok || h.operatorKey == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.operatorKey)) == 1
+}
+
+// Create handles POST /api/tenants
+// Body: {"code", "name", "contact_email", "active", "description"}
+func (h *TenantHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if !h.operatorOnly(w, r) {
+		return
+	}
+
+	var req models.CreateTenantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	tenant, err := h.repo.Create(r.Context(), &req)
+	if err != nil {
+		h.respondTenantError(w, err, "Failed to create tenant: ")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, models.SuccessResponse(tenant, "Tenant created successfully"))
+}
+
+// GetByID handles GET /api/tenants/{id}
+// Includes the number of records of each type the tenant owns
+func (h *TenantHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	if !h.operatorOnly(w, r) {
+		return
+	}
+
+	id, err := getIDFromPath(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid tenant ID")
+		return
+	}
+
+	tenant, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get tenant: "+err.Error())
+		return
+	}
+
+	if tenant == nil {
+		respondError(w, http.StatusNotFound, "Tenant not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(tenant, ""))
+}
+
+// Current handles GET /api/tenants/current
+// Returns the tenant the request acts for with the records it owns, or null for the operator
+func (h *TenantHandler) Current(w http.ResponseWriter, r *http.Request) {
+	if database.IsOperator(r.Context()) {
+		respondJSON(w, http.StatusOK, models.SuccessResponse(nil, "Acting as the operator"))
+		return
+	}
+	id, ok := database.TenantID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Request has no tenant")
+		return
+	}
+
+	tenant, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get tenant: "+err.Error())
+		return
+	}
+	if tenant == nil {
+		respondError(w, http.StatusNotFound, "Tenant not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(tenant, ""))
+}
+
+// List handles GET /api/tenants
+// Query params: active, search, limit, offset
+func (h *TenantHandler) List(w http.ResponseWriter, r *http.Request) {
+	if !h.operatorOnly(w, r) {
+		return
+	}
+
+	filter := &models.TenantFilter{}
+	query := r.URL.Query()
+
+	if query.Get("active") != "" {
+		active := parseBoolParam(r, "active", true)
+		filter.Active = &active
+	}
+	if search := query.Get("search"); search != "" {
+		filter.Search = &search
+	}
+	filter.Limit = parseIntParam(r, "limit", 0)
+	filter.Offset = parseIntParam(r, "offset", 0)
+
+	tenants, total, err := h.repo.List(r.Context(), filter)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list tenants: "+err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.NewPaginatedResponse(tenants, total, filter.Limit, filter.Offset))
+}
+
+// Update handles PUT /api/tenants/{id}
+func (h *TenantHandler) Update(w http.ResponseWriter, r *http.Request) {
+	if !h.operatorOnly(w, r) {
+		return
+	}
+
+	id, err := getIDFromPath(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid tenant ID")
+		return
+	}
+
+	var req models.UpdateTenantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	tenant, err := h.repo.Update(r.Context(), id, &req)
+	if err != nil {
+		h.respondTenantError(w, err, "Failed to update tenant: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(tenant, "Tenant updated successfully"))
+}
+
+// Delete handles DELETE /api/tenants/{id}
+// Tenants that still own records cannot be deleted; hand the records back first
+func (h *TenantHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if !h.operatorOnly(w, r) {
+		return
+	}
+
+	id, err := getIDFromPath(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid tenant ID")
+		return
+	}
+
+	if err := h.repo.Delete(r.Context(), id); err != nil {
+		h.respondTenantError(w, err, "Failed to delete tenant: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(nil, "Tenant deleted successfully"))
+}
+
+// Assign handles POST /api/tenants/assign
+// Body: {"tenant_id", "element_type", "element_ids", "include_cores"}
+// Hands records to a tenant, or back to the operator when tenant_id is omitted
+func (h *TenantHandler) Assign(w http.ResponseWriter, r *http.Request) {
+	if !h.operatorOnly(w, r) {
+		return
+	}
+
+	var req models.TenantAssignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	req.ElementType = models.TenantElementType(strings.ToUpper(string(req.ElementType)))
+
+	assignment, err := h.repo.Assign(r.Context(), &req)
+	if err != nil {
+		h.respondTenantError(w, err, "Failed to assign records: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(assignment, "Records assigned successfully"))
+}
+
+// operatorOnly refuses requests that did not authenticate as the operator or that act for a
+// tenant, responding on failure
+func (h *TenantHandler) operatorOnly(w http.ResponseWriter, r *http.Request) bool {
+	if !database.IsOperator(r.Context()) {
+		respondError(w, http.StatusForbidden, "Tenant administration is only available to the operator")
+		return false
+	}
+	return true
+}
+
+func (h *TenantHandler) respondTenantError(w http.ResponseWriter, err error, prefix string) {
+	switch {
+	case errors.Is(err, repository.ErrTenantNotFound):
+		respondError(w, http.StatusNotFound, "Tenant not found")
+	case errors.Is(err, repository.ErrTenantExists),
+		errors.Is(err, repository.ErrTenantInUse):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, repository.ErrInvalidTenant):
+		respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, prefix+err.Error())
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"spectra-backend/internal/database"
+)
+
+func TestScopeRequiresTenantOrOperator(t *testing.T) {
+	h := NewTenantHandler(nil, "secret")
+	var operator bool
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		operator = database.IsOperator(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name         string
+		path         string
+		auth         string
+		wantStatus   int
+		wantOperator bool
+	}{
+		{name: "no credentials", path: "/api/nodes", wantStatus: http.StatusUnauthorized},
+		{name: "wrong key", path: "/api/nodes", auth: "Bearer guess", wantStatus: http.StatusUnauthorized},
+		{name: "key without bearer scheme", path: "/api/nodes", auth: "secret", wantStatus: http.StatusUnauthorized},
+		{name: "operator key", path: "/api/nodes", auth: "Bearer secret", wantStatus: http.StatusNoContent, wantOperator: true},
+		{name: "health check", path: "/api/health", wantStatus: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operator = false
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			h.Scope(next).ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if operator != tt.wantOperator {
+				t.Errorf("operator = %v, want %v", operator, tt.wantOperator)
+			}
+		})
+	}
+}
+
+func TestScopeWithoutOperatorKey(t *testing.T) {
+	h := NewTenantHandler(nil, "")
+	req := httptest.NewRequest(http.MethodGet, "/api/tenants", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	rec := httptest.NewRecorder()
+	h.Scope(http.NotFoundHandler()).ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestOperatorOnly(t *testing.T) {
+	h := NewTenantHandler(nil, "secret")
+	tests := []struct {
+		name string
+		ctx  func(*http.Request) *http.Request
+		want bool
+	}{
+		{name: "no credentials", ctx: func(r *http.Request) *http.Request { return r }, want: false},
+		{name: "operator", ctx: func(r *http.Request) *http.Request {
+			return r.WithContext(database.WithOperator(r.Context()))
+		}, want: true},
+		{name: "tenant", ctx: func(r *http.Request) *http.Request {
+			return r.WithContext(database.WithTenant(r.Context(), 7))
+		}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			if got := h.operatorOnly(rec, tt.ctx(httptest.NewRequest(http.MethodGet, "/api/tenants", nil))); got != tt.want {
+				t.Errorf("operatorOnly = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
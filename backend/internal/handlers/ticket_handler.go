@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"spectra-backend/internal/models"
+	"spectra-backend/internal/repository"
+)
+
+// TicketHandler handles HTTP requests for trouble tickets
+type TicketHandler struct {
+	repo *repository.TicketRepository
+}
+
+// NewTicketHandler creates a new TicketHandler
+func NewTicketHandler(repo *repository.TicketRepository) *TicketHandler {
+	return &TicketHandler{repo: repo}
+}
+
+// Create handles POST /api/tickets
+func (h *TicketHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateTicketRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	// Validate required fields
+	if strings.TrimSpace(req.Title) == "" {
+		respondError(w, http.StatusBadRequest, "Title is required")
+		return
+	}
+
+	ticket, err := h.repo.Create(r.Context(), &req)
+	if err != nil {
+		h.respondChangeError(w, err, "Failed to create ticket: ")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, models.SuccessResponse(ticket, "Ticket created successfully"))
+}
+
+// GetByID handles GET /api/tickets/{id}
+func (h *TicketHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid ticket ID")
+		return
+	}
+
+	ticket, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get ticket: "+err.Error())
+		return
+	}
+
+	if ticket == nil {
+		respondError(w, http.StatusNotFound, "Ticket not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(ticket, ""))
+}
+
+// List handles GET /api/tickets, the NOC queue ordered by priority and resolution deadline
+// Query params: status (comma-separated), active, priority, team, assignee, category,
+// element_type and element_id (tickets linked to an element), area_id, sla_breached, search, limit, offset
+func (h *TicketHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter := &models.TicketFilter{}
+	query := r.URL.Query()
+
+	for _, statusParam := range splitParam(strings.ToUpper(query.Get("status"))) {
+		status := models.TicketStatus(statusParam)
+		if !status.IsValid() {
+			respondError(w, http.StatusBadRequest, "Invalid status "+statusParam)
+			return
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+	filter.Active = parseBoolParam(r, "active", false)
+	if priorityParam := query.Get("priority"); priorityParam != "" {
+		priority := models.TicketPriority(strings.ToUpper(priorityParam))
+		filter.Priority = &priority
+	}
+	if team := query.Get("team"); team != "" {
+		filter.AssignedTeam = &team
+	}
+	if assignee := query.Get("assignee"); assignee != "" {
+		filter.Assignee = &assignee
+	}
+	if category := query.Get("category"); category != "" {
+		filter.Category = &category
+	}
+	if typeParam := query.Get("element_type"); typeParam != "" {
+		link := &models.TicketLinkRequest{ElementType: models.TicketLinkType(strings.ToUpper(typeParam))}
+		id, err := strconv.ParseInt(query.Get("element_id"), 10, 64)
+		if err != nil || !link.ElementType.IsValid() {
+			respondError(w, http.StatusBadRequest, "element_type must be node, cable, customer or alarm with an element_id")
+			return
+		}
+		link.ElementID = id
+		filter.Link = link
+	}
+	filter.SLABreached = parseBoolParam(r, "sla_breached", false)
+	if search := query.Get("search"); search != "" {
+		filter.Search = &search
+	}
+	areaID, err := parseIDParam(r, "area_id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.AreaID = areaID
+	filter.Limit = parseIntParam(r, "limit", 0)
+	filter.Offset = parseIntParam(r, "offset", 0)
+
+	tickets, total, err := h.repo.List(r.Context(), filter)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list tickets: "+err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.NewPaginatedResponse(tickets, total, filter.Limit, filter.Offset))
+}
+
+// Update handles PUT /api/tickets/{id}
+func (h *TicketHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid ticket ID")
+		return
+	}
+
+	var req models.UpdateTicketRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	ticket, err := h.repo.Update(r.Context(), id, &req)
+	if err != nil {
+		h.respondChangeError(w, err, "Failed to update ticket: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(ticket, "Ticket updated successfully"))
+}
+
+// Assign handles POST /api/tickets/{id}/assign
+// Body: {"assigned_team": "...", "assignee": "...", "changed_by": "..."}
+func (h *TicketHandler) Assign(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid ticket ID")
+		return
+	}
+
+	var req models.AssignTicketRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	ticket, err := h.repo.Assign(r.Context(), id, &req)
+	if err != nil {
+		h.respondChangeError(w, err, "Failed to assign ticket: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(ticket, "Ticket assigned successfully"))
+}
+
+// ChangeStatus handles POST /api/tickets/{id}/status
+// Body: {"status": "RESOLVED", "resolution": "...", "note": "...", "changed_by": "..."}
+func (h *TicketHandler) ChangeStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid ticket ID")
+		return
+	}
+
+	var req models.TicketStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if req.Status == "" {
+		respondError(w, http.StatusBadRequest, "Status is required")
+		return
+	}
+	req.Status = models.TicketStatus(strings.ToUpper(string(req.Status)))
+
+	ticket, err := h.repo.ChangeStatus(r.Context(), id, &req)
+	if err != nil {
+		h.respondChangeError(w, err, "Failed to change ticket status: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(ticket, "Ticket status updated successfully"))
+}
+
+// AddComment handles POST /api/tickets/{id}/comments
+func (h *TicketHandler) AddComment(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid ticket ID")
+		return
+	}
+
+	var req models.CreateTicketCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	// Validate required fields
+	if strings.TrimSpace(req.Author) == "" || strings.TrimSpace(req.Body) == "" {
+		respondError(w, http.StatusBadRequest, "Author and body are required")
+		return
+	}
+
+	comment, err := h.repo.AddComment(r.Context(), id, &req)
+	if err != nil {
+		h.respondChangeError(w, err, "Failed to add comment: ")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, models.SuccessResponse(comment, "Comment added successfully"))
+}
+
+// AddLinks handles POST /api/tickets/{id}/links
+// Body: {"links": [{"element_type": "CABLE", "element_id": 7}, ...]}
+func (h *TicketHandler) AddLinks(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid ticket ID")
+		return
+	}
+
+	var req struct {
+		Links []models.TicketLinkRequest `json:"links"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if len(req.Links) == 0 {
+		respondError(w, http.StatusBadRequest, "At least one link is required")
+		return
+	}
+
+	ticket, err := h.repo.AddLinks(r.Context(), id, req.Links)
+	if err != nil {
+		h.respondChangeError(w, err, "Failed to link ticket: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(ticket, "Ticket linked successfully"))
+}
+
+// DeleteLink handles DELETE /api/tickets/{id}/links/{linkId}
+func (h *TicketHandler) DeleteLink(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid ticket ID")
+		return
+	}
+	linkID, err := getIDFromPathAt(r, 4)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid link ID")
+		return
+	}
+
+	if err := h.repo.DeleteLink(r.Context(), id, linkID); err != nil {
+		if errors.Is(err, repository.ErrTicketLinkNotFound) {
+			respondError(w, http.StatusNotFound, "Ticket link not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to delete ticket link: "+err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(nil, "Ticket link deleted successfully"))
+}
+
+func (h *TicketHandler) respondChangeError(w http.ResponseWriter, err error, prefix string) {
+	switch {
+	case errors.Is(err, repository.ErrTicketNotFound):
+		respondError(w, http.StatusNotFound, "Ticket not found")
+	case errors.Is(err, repository.ErrTicketTransition):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, repository.ErrInvalidTicket):
+		respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, prefix+err.Error())
+	}
+}
@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"spectra-backend/internal/models"
+	"spectra-backend/internal/topology"
+)
+
+// maxTopologyDepth bounds neighbourhood walks
+const maxTopologyDepth = 20
+
+// TopologyHandler handles HTTP requests for the network topology graph
+type TopologyHandler struct {
+	service *topology.Service
+}
+
+// NewTopologyHandler creates a new TopologyHandler
+func NewTopologyHandler(service *topology.Service) *TopologyHandler {
+	return &TopologyHandler{service: service}
+}
+
+// Get handles GET /api/topology
+// Query params: root (vertex id such as node:12; the whole graph when omitted), depth (default 2),
+// kinds (comma-separated vertex kinds the walk may enter, e.g. node,cable)
+func (h *TopologyHandler) Get(w http.ResponseWriter, r *http.Request) {
+	graph, ok := h.graph(w, r)
+	if !ok {
+		return
+	}
+	respondJSON(w, http.StatusOK, models.SuccessResponse(graph, ""))
+}
+
+// ExportGraphML handles GET /api/topology/graphml
+// Accepts the same query params as Get
+func (h *TopologyHandler) ExportGraphML(w http.ResponseWriter, r *http.Request) {
+	h.export(w, r, "application/graphml+xml", "graphml", topology.WriteGraphML)
+}
+
+// ExportDOT handles GET /api/topology/dot
+// Accepts the same query params as Get
+func (h *TopologyHandler) ExportDOT(w http.ResponseWriter, r *http.Request) {
+	h.export(w, r, "text/vnd.graphviz", "dot", topology.WriteDOT)
+}
+
+// Impact handles POST /api/impact
+// Body: {"cable_ids": [...], "core_ids": [...], "node_ids": [...]}
+func (h *TopologyHandler) Impact(w http.ResponseWriter, r *http.Request) {
+	var req models.ImpactRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	if len(req.CableIDs)+len(req.CoreIDs)+len(req.NodeIDs) == 0 {
+		respondError(w, http.StatusBadRequest, "At least one of cable_ids, core_ids or node_ids is required")
+		return
+	}
+
+	g, err := h.service.Graph(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to load topology: "+err.Error())
+		return
+	}
+
+	report, err := g.Impact(&req)
+	var unknown *topology.UnknownElementsError
+	if errors.As(err, &unknown) {
+		respondError(w, http.StatusNotFound, "Unknown elements: "+strings.Join(unknown.Missing, ", "))
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to analyse impact: "+err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(report, ""))
+}
+
+// Coverage handles POST /api/coverage
+// Body (optional): {"premises": [{"ref", "latitude", "longitude"}, ...], "max_drop_meters",
+// "include_planned", "areas": [{"name", "polygon": [[lng, lat], ...]}]}; without premises every
+// CUSTOMER node is checked
+func (h *TopologyHandler) Coverage(w http.ResponseWriter, r *http.Request) {
+	var req models.CoverageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	if req.MaxDropMeters < 0 {
+		respondError(w, http.StatusBadRequest, "max_drop_meters cannot be negative")
+		return
+	}
+	for _, a := range req.Areas {
+		if strings.TrimSpace(a.Name) == "" || len(a.Polygon) < 3 {
+			respondError(w, http.StatusBadRequest, "Each area needs a name and a polygon of at least 3 points")
+			return
+		}
+		for _, point := range a.Polygon {
+			if len(point) < 2 {
+				respondError(w, http.StatusBadRequest, fmt.Sprintf("Area %q has a point without [lng, lat]", a.Name))
+				return
+			}
+		}
+	}
+
+	g, err := h.service.Graph(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to load topology: "+err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(g.Coverage(&req), ""))
+}
+
+// Diversity handles GET /api/redundancy/diversity
+// Query params: core_a and core_b (cores on the working and protection paths), or customer_id
+func (h *TopologyHandler) Diversity(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	customerID, customerErr := strconv.ParseInt(query.Get("customer_id"), 10, 64)
+	coreA, errA := strconv.ParseInt(query.Get("core_a"), 10, 64)
+	coreB, errB := strconv.ParseInt(query.Get("core_b"), 10, 64)
+	if customerErr != nil && (errA != nil || errB != nil) {
+		respondError(w, http.StatusBadRequest, "core_a and core_b, or customer_id, are required")
+		return
+	}
+
+	g, err := h.service.Graph(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to load topology: "+err.Error())
+		return
+	}
+
+	var report *models.DiversityReport
+	if customerErr == nil {
+		report, err = g.CustomerDiversity(customerID)
+	} else {
+		report, err = g.Diversity(coreA, coreB)
+	}
+	var unknown *topology.UnknownElementsError
+	switch {
+	case errors.As(err, &unknown):
+		respondError(w, http.StatusNotFound, "Unknown elements: "+strings.Join(unknown.Missing, ", "))
+		return
+	case errors.Is(err, topology.ErrCustomerNotFound):
+		respondError(w, http.StatusNotFound, "Customer not found or not attached to a node")
+		return
+	case err != nil:
+		respondError(w, http.StatusInternalServerError, "Failed to analyse diversity: "+err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(report, report.Summary))
+}
+
+// FeedDependencies handles GET /api/redundancy/feeds
+// Lists ODCs whose entire feed depends on one cable
+func (h *TopologyHandler) FeedDependencies(w http.ResponseWriter, r *http.Request) {
+	g, err := h.service.Graph(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to load topology: "+err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, models.SuccessResponse(g.FeedDependencies(), ""))
+}
+
+// LocateOnCable handles GET /api/cables/{id}/locate
+// Query params: distance_m (required), from (origin or destination, default origin),
+// optical (true when distance_m is fiber length rather than cable length), slack_pct, node_slack_meter
+func (h *TopologyHandler) LocateOnCable(w http.ResponseWriter, r *http.Request) {
+	cableID, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid cable ID")
+		return
+	}
+
+	query := r.URL.Query()
+	req := &models.CableLocateRequest{
+		CableID: cableID,
+		From:    models.CableEnd(strings.ToUpper(query.Get("from"))),
+		Optical: parseBoolParam(r, "optical", false),
+	}
+	if req.From == "" {
+		req.From = models.CableEndOrigin
+	}
+	if req.From != models.CableEndOrigin && req.From != models.CableEndDestination {
+		respondError(w, http.StatusBadRequest, "from must be origin or destination")
+		return
+	}
+	req.DistanceMeter, err = strconv.ParseFloat(query.Get("distance_m"), 64)
+	if err != nil || req.DistanceMeter < 0 {
+		respondError(w, http.StatusBadRequest, "distance_m must be a non-negative number")
+		return
+	}
+	if req.FiberSlack, err = formSlack(r); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	g, err := h.service.Graph(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to load topology: "+err.Error())
+		return
+	}
+	location, err := g.LocateOnCable(req)
+	if err != nil {
+		h.respondCableError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(location, ""))
+}
+
+// MeasureOnCable handles GET /api/cables/{id}/measure
+// Query params: lat, lng (required), slack_pct, node_slack_meter
+func (h *TopologyHandler) MeasureOnCable(w http.ResponseWriter, r *http.Request) {
+	cableID, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid cable ID")
+		return
+	}
+
+	query := r.URL.Query()
+	lat, errLat := strconv.ParseFloat(query.Get("lat"), 64)
+	lng, errLng := strconv.ParseFloat(query.Get("lng"), 64)
+	if errLat != nil || errLng != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		respondError(w, http.StatusBadRequest, "lat and lng are required and must be valid coordinates")
+		return
+	}
+	slack, err := formSlack(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	g, err := h.service.Graph(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to load topology: "+err.Error())
+		return
+	}
+	measure, err := g.MeasureOnCable(cableID, lat, lng, slack)
+	if err != nil {
+		h.respondCableError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(measure, ""))
+}
+
+func (h *TopologyHandler) respondCableError(w http.ResponseWriter, err error) {
+	var unknown *topology.UnknownElementsError
+	switch {
+	case errors.As(err, &unknown):
+		respondError(w, http.StatusNotFound, "Cable not found")
+	case errors.Is(err, topology.ErrNoCableRoute):
+		respondError(w, http.StatusUnprocessableEntity, "Cable has no route or drawn path to measure along")
+	default:
+		respondError(w, http.StatusInternalServerError, "Failed to measure along cable: "+err.Error())
+	}
+}
+
+func (h *TopologyHandler) export(w http.ResponseWriter, r *http.Request, contentType, extension string, write func(io.Writer, *models.TopologyGraph) error) {
+	graph, ok := h.graph(w, r)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := write(&buf, graph); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to export topology: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "topology."+extension))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// graph resolves the requested (sub)graph, responding on failure
+func (h *TopologyHandler) graph(w http.ResponseWriter, r *http.Request) (*models.TopologyGraph, bool) {
+	query := r.URL.Query()
+
+	depth := parseIntParam(r, "depth", 2)
+	if depth < 0 || depth > maxTopologyDepth {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("depth must be between 0 and %d", maxTopologyDepth))
+		return nil, false
+	}
+	kinds := map[models.TopologyVertexKind]bool{}
+	for _, kind := range splitParam(strings.ToUpper(query.Get("kinds"))) {
+		if !models.TopologyVertexKind(kind).IsValid() {
+			respondError(w, http.StatusBadRequest, "Invalid kind "+kind)
+			return nil, false
+		}
+		kinds[models.TopologyVertexKind(kind)] = true
+	}
+
+	root := query.Get("root")
+	if root != "" {
+		normalized, err := topology.NormalizeVertexID(root)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return nil, false
+		}
+		root = normalized
+	}
+
+	g, err := h.service.Graph(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to load topology: "+err.Error())
+		return nil, false
+	}
+
+	if root == "" {
+		return g.All(), true
+	}
+	result, err := g.Neighborhood(root, depth, kinds)
+	if errors.Is(err, topology.ErrVertexNotFound) {
+		respondError(w, http.StatusNotFound, "Vertex "+root+" not found")
+		return nil, false
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to walk topology: "+err.Error())
+		return nil, false
+	}
+	return result, true
+}
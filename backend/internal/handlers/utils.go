@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"path"
 	"strconv"
@@ -85,3 +86,17 @@ func parseBoolParam(r *http.Request, key string, defaultValue bool) bool {
 	}
 	return value
 }
+
+// parseIDParam parses an optional ID query parameter, returning nil when it is absent and an
+// error when it is not a valid ID
+func parseIDParam(r *http.Request, key string) (*int64, error) {
+	param := r.URL.Query().Get(key)
+	if param == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseInt(param, 10, 64)
+	if err != nil || id <= 0 {
+		return nil, fmt.Errorf("%s must be a positive ID", key)
+	}
+	return &id, nil
+}
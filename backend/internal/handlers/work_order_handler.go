@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"spectra-backend/internal/models"
+	"spectra-backend/internal/repository"
+)
+
+// WorkOrderHandler handles HTTP requests for installation and maintenance work orders
+type WorkOrderHandler struct {
+	repo *repository.WorkOrderRepository
+}
+
+// NewWorkOrderHandler creates a new WorkOrderHandler
+func NewWorkOrderHandler(repo *repository.WorkOrderRepository) *WorkOrderHandler {
+	return &WorkOrderHandler{repo: repo}
+}
+
+// Create handles POST /api/work-orders
+func (h *WorkOrderHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateWorkOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	// Validate required fields
+	if strings.TrimSpace(req.Code) == "" || strings.TrimSpace(req.Title) == "" {
+		respondError(w, http.StatusBadRequest, "Code and title are required")
+		return
+	}
+	if req.Type == "" {
+		respondError(w, http.StatusBadRequest, "Type is required")
+		return
+	}
+
+	workOrder, err := h.repo.Create(r.Context(), &req)
+	if err != nil {
+		h.respondChangeError(w, err, "Failed to create work order: ")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, models.SuccessResponse(workOrder, "Work order created successfully"))
+}
+
+// GetByID handles GET /api/work-orders/{id}
+func (h *WorkOrderHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid work order ID")
+		return
+	}
+
+	workOrder, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get work order: "+err.Error())
+		return
+	}
+
+	if workOrder == nil {
+		respondError(w, http.StatusNotFound, "Work order not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(workOrder, ""))
+}
+
+// List handles GET /api/work-orders
+// Query params: status, type, crew, from and to (RFC 3339, work scheduled within the window),
+// element_type and element_id (work targeting an asset), area_id, search, limit, offset
+func (h *WorkOrderHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter := &models.WorkOrderFilter{}
+	query := r.URL.Query()
+
+	if statusParam := query.Get("status"); statusParam != "" {
+		status := models.WorkOrderStatus(strings.ToUpper(statusParam))
+		filter.Status = &status
+	}
+	if typeParam := query.Get("type"); typeParam != "" {
+		workOrderType := models.WorkOrderType(strings.ToUpper(typeParam))
+		filter.Type = &workOrderType
+	}
+	if crew := query.Get("crew"); crew != "" {
+		filter.Crew = &crew
+	}
+	for param, target := range map[string]**time.Time{"from": &filter.ScheduledFrom, "to": &filter.ScheduledTo} {
+		if value := query.Get(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				respondError(w, http.StatusBadRequest, param+" must be an RFC 3339 time")
+				return
+			}
+			*target = &t
+		}
+	}
+	if typeParam := query.Get("element_type"); typeParam != "" {
+		asset := &models.WorkOrderAssetRequest{ElementType: models.WorkOrderAssetType(strings.ToUpper(typeParam))}
+		id, err := strconv.ParseInt(query.Get("element_id"), 10, 64)
+		if err != nil || !asset.ElementType.IsValid() {
+			respondError(w, http.StatusBadRequest, "element_type must be node, cable or customer with an element_id")
+			return
+		}
+		asset.ElementID = id
+		filter.Asset = asset
+	}
+	if search := query.Get("search"); search != "" {
+		filter.Search = &search
+	}
+	areaID, err := parseIDParam(r, "area_id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.AreaID = areaID
+	filter.Limit = parseIntParam(r, "limit", 0)
+	filter.Offset = parseIntParam(r, "offset", 0)
+
+	workOrders, total, err := h.repo.List(r.Context(), filter)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list work orders: "+err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.NewPaginatedResponse(workOrders, total, filter.Limit, filter.Offset))
+}
+
+// Update handles PUT /api/work-orders/{id}
+func (h *WorkOrderHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid work order ID")
+		return
+	}
+
+	var req models.UpdateWorkOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	workOrder, err := h.repo.Update(r.Context(), id, &req)
+	if err != nil {
+		h.respondChangeError(w, err, "Failed to update work order: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(workOrder, "Work order updated successfully"))
+}
+
+// UpdateTask handles PATCH /api/work-orders/{id}/tasks/{taskId}
+// Body: {"done": true, "done_by": "..."}
+func (h *WorkOrderHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid work order ID")
+		return
+	}
+	taskID, err := getIDFromPathAt(r, 4)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid task ID")
+		return
+	}
+
+	var req models.WorkOrderTaskUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	task, err := h.repo.UpdateTask(r.Context(), id, taskID, &req)
+	if err != nil {
+		if errors.Is(err, repository.ErrWorkOrderTaskNotFound) {
+			respondError(w, http.StatusNotFound, "Task not found")
+			return
+		}
+		h.respondChangeError(w, err, "Failed to update task: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(task, "Task updated successfully"))
+}
+
+// ChangeStatus handles POST /api/work-orders/{id}/status
+// Body: {"status": "IN_PROGRESS|PLANNED|CANCELLED"}
+func (h *WorkOrderHandler) ChangeStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid work order ID")
+		return
+	}
+
+	var req models.WorkOrderStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	status := models.WorkOrderStatus(strings.ToUpper(string(req.Status)))
+	if !status.IsValid() {
+		respondError(w, http.StatusBadRequest, "Status must be PLANNED, IN_PROGRESS or CANCELLED")
+		return
+	}
+	if status == models.WorkOrderStatusCompleted {
+		respondError(w, http.StatusBadRequest, "Use /complete to complete a work order")
+		return
+	}
+
+	workOrder, err := h.repo.ChangeStatus(r.Context(), id, status)
+	if err != nil {
+		h.respondChangeError(w, err, "Failed to change work order status: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(workOrder, "Work order status updated successfully"))
+}
+
+// Complete handles POST /api/work-orders/{id}/complete
+// Body: {"completed_by": "...", "apply_changes": true}. Applies the design changes to the live
+// inventory and releases the job's reservations in one transaction.
+func (h *WorkOrderHandler) Complete(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid work order ID")
+		return
+	}
+
+	var req models.CompleteWorkOrderRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+			return
+		}
+	}
+
+	workOrder, err := h.repo.Complete(r.Context(), id, &req)
+	if err != nil {
+		var changeErr *repository.DesignChangeError
+		if errors.As(err, &changeErr) {
+			respondError(w, http.StatusConflict, err.Error())
+			return
+		}
+		h.respondChangeError(w, err, "Failed to complete work order: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(workOrder, "Work order completed successfully"))
+}
+
+func (h *WorkOrderHandler) respondChangeError(w http.ResponseWriter, err error, prefix string) {
+	switch {
+	case errors.Is(err, repository.ErrWorkOrderNotFound):
+		respondError(w, http.StatusNotFound, "Work order not found")
+	case errors.Is(err, repository.ErrWorkOrderClosed),
+		errors.Is(err, repository.ErrWorkOrderTransition),
+		errors.Is(err, repository.ErrWorkOrderTasksOpen):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, repository.ErrInvalidWorkOrder):
+		respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, prefix+err.Error())
+	}
+}
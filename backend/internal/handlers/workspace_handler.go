@@ -0,0 +1,331 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"spectra-backend/internal/bom"
+	"spectra-backend/internal/models"
+	"spectra-backend/internal/repository"
+)
+
+// WorkspaceHandler handles HTTP requests for design workspaces
+type WorkspaceHandler struct {
+	repo        *repository.WorkspaceRepository
+	catalogRepo *repository.PriceCatalogRepository
+}
+
+// NewWorkspaceHandler creates a new WorkspaceHandler
+func NewWorkspaceHandler(repo *repository.WorkspaceRepository, catalogRepo *repository.PriceCatalogRepository) *WorkspaceHandler {
+	return &WorkspaceHandler{repo: repo, catalogRepo: catalogRepo}
+}
+
+// List handles GET /api/workspaces
+// Query params: status, created_by, search, area_id, limit, offset
+func (h *WorkspaceHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter := &models.WorkspaceFilter{}
+	query := r.URL.Query()
+
+	if statusParam := query.Get("status"); statusParam != "" {
+		status := models.WorkspaceStatus(strings.ToUpper(statusParam))
+		filter.Status = &status
+	}
+	if createdBy := query.Get("created_by"); createdBy != "" {
+		filter.CreatedBy = &createdBy
+	}
+	if search := query.Get("search"); search != "" {
+		filter.Search = &search
+	}
+	areaID, err := parseIDParam(r, "area_id")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.AreaID = areaID
+	filter.Limit = parseIntParam(r, "limit", 0)
+	filter.Offset = parseIntParam(r, "offset", 0)
+
+	workspaces, total, err := h.repo.List(r.Context(), filter)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list workspaces: "+err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.NewPaginatedResponse(workspaces, total, filter.Limit, filter.Offset))
+}
+
+// Create handles POST /api/workspaces
+func (h *WorkspaceHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateWorkspaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if strings.TrimSpace(req.Name) == "" {
+		respondError(w, http.StatusBadRequest, "Name is required")
+		return
+	}
+
+	workspace, err := h.repo.Create(r.Context(), &req)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to create workspace: "+err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, models.SuccessResponse(workspace, "Workspace created successfully"))
+}
+
+// GetByID handles GET /api/workspaces/{id}
+func (h *WorkspaceHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid workspace ID")
+		return
+	}
+
+	workspace, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get workspace: "+err.Error())
+		return
+	}
+
+	if workspace == nil {
+		respondError(w, http.StatusNotFound, "Workspace not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(workspace, ""))
+}
+
+// Update handles PUT /api/workspaces/{id}
+func (h *WorkspaceHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPath(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid workspace ID")
+		return
+	}
+
+	var req models.UpdateWorkspaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	if req.Name != nil && strings.TrimSpace(*req.Name) == "" {
+		respondError(w, http.StatusBadRequest, "Name cannot be empty")
+		return
+	}
+
+	workspace, err := h.repo.Update(r.Context(), id, &req)
+	if err != nil {
+		h.respondChangeError(w, err, "Failed to update workspace: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(workspace, "Workspace updated successfully"))
+}
+
+// Diff handles GET /api/workspaces/{id}/diff
+// Lists what the workspace adds, the live nodes and cores it touches, the drafted items priced
+// from the price catalog and the conflicts that block merging
+func (h *WorkspaceHandler) Diff(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid workspace ID")
+		return
+	}
+
+	diff, err := h.repo.Diff(r.Context(), id)
+	if err != nil {
+		h.respondChangeError(w, err, "Failed to diff workspace: ")
+		return
+	}
+
+	catalog, err := h.catalogRepo.List(r.Context(), nil)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get price catalog: "+err.Error())
+		return
+	}
+	var items []models.DesignItem
+	for _, list := range [][]models.DesignItem{diff.Nodes, diff.Cables, diff.Customers, diff.Splices} {
+		items = append(items, list...)
+	}
+	diff.Cost = bom.Price(bom.FromDesign(items).Lines(), catalog)
+	diff.Cost.WorkspaceID = &id
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(diff, ""))
+}
+
+// AddItem handles POST /api/workspaces/{id}/items
+// Body: {"item_type": "NODE|CABLE|CUSTOMER|SPLICE", "node|cable|customer|splice": {...}}.
+// Negative IDs in the payload reference other items of the workspace.
+func (h *WorkspaceHandler) AddItem(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid workspace ID")
+		return
+	}
+
+	req, ok := decodeDesignItem(w, r)
+	if !ok {
+		return
+	}
+
+	item, err := h.repo.AddItem(r.Context(), id, req)
+	if err != nil {
+		h.respondChangeError(w, err, "Failed to add design item: ")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, models.SuccessResponse(item, "Design item added successfully"))
+}
+
+// UpdateItem handles PUT /api/workspaces/{id}/items/{itemId}
+func (h *WorkspaceHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid workspace ID")
+		return
+	}
+	itemID, err := getIDFromPathAt(r, 4)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid design item ID")
+		return
+	}
+
+	req, ok := decodeDesignItem(w, r)
+	if !ok {
+		return
+	}
+
+	item, err := h.repo.UpdateItem(r.Context(), id, itemID, req)
+	if err != nil {
+		h.respondChangeError(w, err, "Failed to update design item: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(item, "Design item updated successfully"))
+}
+
+// DeleteItem handles DELETE /api/workspaces/{id}/items/{itemId}
+func (h *WorkspaceHandler) DeleteItem(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid workspace ID")
+		return
+	}
+	itemID, err := getIDFromPathAt(r, 4)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid design item ID")
+		return
+	}
+
+	if err := h.repo.DeleteItem(r.Context(), id, itemID); err != nil {
+		h.respondChangeError(w, err, "Failed to delete design item: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(nil, "Design item deleted successfully"))
+}
+
+// Submit handles POST /api/workspaces/{id}/submit
+// Body: {"by": "...", "note": "..."}. Refused while the diff shows conflicts.
+func (h *WorkspaceHandler) Submit(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.repo.Submit, false, "submit", "Workspace submitted for review")
+}
+
+// Approve handles POST /api/workspaces/{id}/approve
+func (h *WorkspaceHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.repo.Approve, false, "approve", "Workspace approved")
+}
+
+// Reject handles POST /api/workspaces/{id}/reject
+// Body: {"by": "...", "note": "..."}. The note is required and the workspace returns to DRAFT.
+func (h *WorkspaceHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.repo.Reject, true, "reject", "Workspace returned to draft")
+}
+
+// Merge handles POST /api/workspaces/{id}/merge
+// Creates the drafted items of an approved workspace in the live inventory in one transaction
+func (h *WorkspaceHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.repo.Merge, false, "merge", "Workspace merged into live inventory")
+}
+
+// Discard handles POST /api/workspaces/{id}/discard
+func (h *WorkspaceHandler) Discard(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.repo.Discard, false, "discard", "Workspace discarded")
+}
+
+// review runs a review step with the optional {"by", "note"} body
+func (h *WorkspaceHandler) review(w http.ResponseWriter, r *http.Request,
+	step func(context.Context, int64, *models.WorkspaceReviewRequest) (*models.DesignWorkspace, error),
+	noteRequired bool, action, message string) {
+	id, err := getIDFromPathAt(r, 2)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid workspace ID")
+		return
+	}
+
+	var req models.WorkspaceReviewRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+			return
+		}
+	}
+	if noteRequired && (req.Note == nil || strings.TrimSpace(*req.Note) == "") {
+		respondError(w, http.StatusBadRequest, "A note is required to "+action+" a workspace")
+		return
+	}
+
+	workspace, err := step(r.Context(), id, &req)
+	if err != nil {
+		h.respondChangeError(w, err, "Failed to "+action+" workspace: ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.SuccessResponse(workspace, message))
+}
+
+// decodeDesignItem reads a drafted item from the request body, responding on failure
+func decodeDesignItem(w http.ResponseWriter, r *http.Request) (*models.DesignItemRequest, bool) {
+	var req models.DesignItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return nil, false
+	}
+	req.ItemType = models.DesignItemType(strings.ToUpper(string(req.ItemType)))
+	if !req.ItemType.IsValid() {
+		respondError(w, http.StatusBadRequest, "item_type must be NODE, CABLE, CUSTOMER or SPLICE")
+		return nil, false
+	}
+	return &req, true
+}
+
+func (h *WorkspaceHandler) respondChangeError(w http.ResponseWriter, err error, prefix string) {
+	var conflictErr *repository.WorkspaceConflictError
+	switch {
+	case errors.As(err, &conflictErr):
+		respondJSON(w, http.StatusConflict, models.Response{
+			Success: false,
+			Error:   err.Error(),
+			Data:    conflictErr.Conflicts,
+		})
+	case errors.Is(err, repository.ErrWorkspaceNotFound):
+		respondError(w, http.StatusNotFound, "Workspace not found")
+	case errors.Is(err, repository.ErrDesignItemNotFound):
+		respondError(w, http.StatusNotFound, "Design item not found")
+	case errors.Is(err, repository.ErrWorkspaceNotDraft),
+		errors.Is(err, repository.ErrWorkspaceClosed),
+		errors.Is(err, repository.ErrWorkspaceTransition),
+		errors.Is(err, repository.ErrDesignItemInUse):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, repository.ErrInvalidDesignItem):
+		respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, prefix+err.Error())
+	}
+}
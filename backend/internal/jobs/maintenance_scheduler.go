@@ -0,0 +1,88 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"spectra-backend/internal/repository"
+	"spectra-backend/internal/topology"
+)
+
+// MaintenanceScheduler starts maintenance windows when they are due and ends them when they are over,
+// flipping their nodes and cables to MAINTENANCE and back
+type MaintenanceScheduler struct {
+	repo     *repository.MaintenanceRepository
+	topology *topology.Service
+	interval time.Duration
+}
+
+// NewMaintenanceScheduler creates a new MaintenanceScheduler
+func NewMaintenanceScheduler(repo *repository.MaintenanceRepository, topologyService *topology.Service, interval time.Duration) *MaintenanceScheduler {
+	if interval <= 0 {
+		interval = DefaultSweepInterval
+	}
+	return &MaintenanceScheduler{repo: repo, topology: topologyService, interval: interval}
+}
+
+// Run checks immediately and then every interval until the context is cancelled
+func (s *MaintenanceScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.sweep(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *MaintenanceScheduler) sweep(ctx context.Context) {
+	now := time.Now()
+	started := s.startDue(ctx, now)
+
+	ended, err := s.repo.EndExpired(ctx, now)
+	if err != nil && ctx.Err() == nil {
+		log.Printf("Maintenance end check failed: %v", err)
+	}
+	if ended > 0 {
+		log.Printf("Ended %d maintenance windows", ended)
+	}
+
+	if started+ended > 0 {
+		s.topology.Invalidate()
+	}
+}
+
+// startDue starts the windows whose start time has passed and returns how many started.
+// The customer set is refreshed from the topology as it stands when the window opens.
+func (s *MaintenanceScheduler) startDue(ctx context.Context, now time.Time) int {
+	due, err := s.repo.ListDue(ctx, now)
+	if err == nil && len(due) == 0 {
+		return 0
+	}
+	var g *topology.Graph
+	if err == nil {
+		g, err = s.topology.Graph(ctx)
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Maintenance start check failed: %v", err)
+		}
+		return 0
+	}
+
+	started := 0
+	for _, window := range due {
+		if _, err := s.repo.Start(ctx, window.ID, g.MaintenanceCustomers(window.Assets)); err != nil {
+			log.Printf("Failed to start maintenance window %d: %v", window.ID, err)
+			continue
+		}
+		log.Printf("Started maintenance window %d (%s)", window.ID, window.Title)
+		started++
+	}
+	return started
+}
@@ -0,0 +1,61 @@
+// Package jobs runs periodic background work next to the HTTP server.
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"spectra-backend/internal/repository"
+)
+
+// DefaultSweepInterval is how often expired reservations are released
+const DefaultSweepInterval = time.Minute
+
+// ReservationSweeper releases reservations once they pass their expiry
+type ReservationSweeper struct {
+	repo     *repository.ReservationRepository
+	interval time.Duration
+	onExpire func() // Called after a sweep released at least one reservation
+}
+
+// NewReservationSweeper creates a new ReservationSweeper
+func NewReservationSweeper(repo *repository.ReservationRepository, interval time.Duration, onExpire func()) *ReservationSweeper {
+	if interval <= 0 {
+		interval = DefaultSweepInterval
+	}
+	return &ReservationSweeper{repo: repo, interval: interval, onExpire: onExpire}
+}
+
+// Run sweeps immediately and then every interval until the context is cancelled
+func (s *ReservationSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.sweep(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *ReservationSweeper) sweep(ctx context.Context) {
+	count, err := s.repo.ReleaseExpired(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Reservation sweep failed: %v", err)
+		}
+		return
+	}
+	if count == 0 {
+		return
+	}
+
+	log.Printf("Released %d expired reservations", count)
+	if s.onExpire != nil {
+		s.onExpire()
+	}
+}
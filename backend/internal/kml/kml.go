@@ -0,0 +1,262 @@
+// Package kml reads and writes KML and KMZ (zipped KML) documents.
+// Only the subset used for outside plant exchange is supported:
+// Folders, Placemarks with Point or LineString geometry, Styles and ExtendedData.
+package kml
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// maxDocumentBytes limits the size of the KML document extracted from a KMZ archive,
+// so a small archive cannot decompress into an unbounded document
+const maxDocumentBytes = 256 << 20
+
+// Data is a single ExtendedData name/value pair
+type Data struct {
+	Name  string
+	Value string
+}
+
+// Placemark is a flattened KML Placemark
+type Placemark struct {
+	Name        string
+	Description string
+	StyleURL    string
+	// Folders holds the names of the enclosing Folders, outermost first
+	Folders []string
+	Data    []Data
+	// Point is [lng, lat] when the placemark is a point
+	Point []float64
+	// Line is [[lng, lat], ...] when the placemark is a line
+	Line [][]float64
+	// Geometry names the geometry element found, e.g. "Point", "LineString" or "Polygon"
+	Geometry string
+}
+
+// Value returns the ExtendedData value for a name, matched case-insensitively
+func (p *Placemark) Value(name string) (string, bool) {
+	for _, d := range p.Data {
+		if strings.EqualFold(d.Name, name) {
+			return strings.TrimSpace(d.Value), true
+		}
+	}
+	return "", false
+}
+
+// Decode parses a KML or KMZ document, detecting KMZ by its zip signature
+func Decode(data []byte) ([]Placemark, error) {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		doc, err := extractKMZ(data)
+		if err != nil {
+			return nil, err
+		}
+		data = doc
+	}
+	return Parse(bytes.NewReader(data))
+}
+
+// extractKMZ returns the main KML document of a KMZ archive: doc.kml, or else the first .kml at the root
+func extractKMZ(data []byte) ([]byte, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid KMZ archive: %w", err)
+	}
+
+	var main *zip.File
+	for _, f := range archive.File {
+		if !strings.EqualFold(path.Ext(f.Name), ".kml") {
+			continue
+		}
+		if strings.EqualFold(f.Name, "doc.kml") {
+			main = f
+			break
+		}
+		if main == nil || (strings.Contains(main.Name, "/") && !strings.Contains(f.Name, "/")) {
+			main = f
+		}
+	}
+	if main == nil {
+		return nil, fmt.Errorf("KMZ archive contains no .kml document")
+	}
+
+	if main.UncompressedSize64 > maxDocumentBytes {
+		return nil, fmt.Errorf("%s exceeds %d MB uncompressed", main.Name, maxDocumentBytes>>20)
+	}
+	rc, err := main.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", main.Name, err)
+	}
+	defer rc.Close()
+
+	// The declared size can lie, so the read itself is capped too
+	doc, err := io.ReadAll(io.LimitReader(rc, maxDocumentBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", main.Name, err)
+	}
+	if len(doc) > maxDocumentBytes {
+		return nil, fmt.Errorf("%s exceeds %d MB uncompressed", main.Name, maxDocumentBytes>>20)
+	}
+	return doc, nil
+}
+
+// Element names without a namespace match any namespace, so both the
+// OGC 2.2 and the older Google Earth namespaces are accepted
+
+type xmlContainer struct {
+	Name       string         `xml:"name"`
+	Documents  []xmlContainer `xml:"Document"`
+	Folders    []xmlContainer `xml:"Folder"`
+	Placemarks []xmlPlacemark `xml:"Placemark"`
+}
+
+type xmlPlacemark struct {
+	Name          string            `xml:"name"`
+	Description   string            `xml:"description"`
+	StyleURL      string            `xml:"styleUrl"`
+	ExtendedData  *xmlExtendedData  `xml:"ExtendedData"`
+	Point         *xmlCoordinates   `xml:"Point"`
+	LineString    *xmlCoordinates   `xml:"LineString"`
+	Polygon       *struct{}         `xml:"Polygon"`
+	MultiGeometry *xmlMultiGeometry `xml:"MultiGeometry"`
+}
+
+type xmlMultiGeometry struct {
+	Points      []xmlCoordinates `xml:"Point"`
+	LineStrings []xmlCoordinates `xml:"LineString"`
+}
+
+type xmlCoordinates struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type xmlExtendedData struct {
+	Data []struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:"value"`
+	} `xml:"Data"`
+	SchemaData []struct {
+		SimpleData []struct {
+			Name  string `xml:"name,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"SimpleData"`
+	} `xml:"SchemaData"`
+}
+
+// Parse reads a KML document and returns its placemarks in document order
+func Parse(r io.Reader) ([]Placemark, error) {
+	var root xmlContainer
+	if err := xml.NewDecoder(r).Decode(&root); err != nil {
+		return nil, fmt.Errorf("invalid KML document: %w", err)
+	}
+
+	var placemarks []Placemark
+	if err := collectPlacemarks(&root, nil, &placemarks); err != nil {
+		return nil, err
+	}
+	return placemarks, nil
+}
+
+// collectPlacemarks walks Documents and Folders depth-first, recording the folder path of each placemark
+func collectPlacemarks(c *xmlContainer, folders []string, out *[]Placemark) error {
+	for _, p := range c.Placemarks {
+		placemark, err := p.flatten(folders)
+		if err != nil {
+			return err
+		}
+		*out = append(*out, placemark)
+	}
+	for i := range c.Documents {
+		if err := collectPlacemarks(&c.Documents[i], folders, out); err != nil {
+			return err
+		}
+	}
+	for i := range c.Folders {
+		folderPath := append(append([]string{}, folders...), strings.TrimSpace(c.Folders[i].Name))
+		if err := collectPlacemarks(&c.Folders[i], folderPath, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *xmlPlacemark) flatten(folders []string) (Placemark, error) {
+	placemark := Placemark{
+		Name:        strings.TrimSpace(p.Name),
+		Description: strings.TrimSpace(p.Description),
+		StyleURL:    strings.TrimSpace(p.StyleURL),
+		Folders:     folders,
+	}
+
+	if p.ExtendedData != nil {
+		for _, d := range p.ExtendedData.Data {
+			placemark.Data = append(placemark.Data, Data{Name: d.Name, Value: d.Value})
+		}
+		for _, schema := range p.ExtendedData.SchemaData {
+			for _, d := range schema.SimpleData {
+				placemark.Data = append(placemark.Data, Data{Name: d.Name, Value: d.Value})
+			}
+		}
+	}
+
+	// A MultiGeometry contributes its first point or line
+	point, line := p.Point, p.LineString
+	if p.MultiGeometry != nil {
+		if point == nil && len(p.MultiGeometry.Points) > 0 {
+			point = &p.MultiGeometry.Points[0]
+		}
+		if line == nil && len(p.MultiGeometry.LineStrings) > 0 {
+			line = &p.MultiGeometry.LineStrings[0]
+		}
+	}
+
+	switch {
+	case point != nil:
+		placemark.Geometry = "Point"
+		coords, err := parseCoordinates(point.Coordinates)
+		if err != nil {
+			return placemark, fmt.Errorf("placemark %q: %w", placemark.Name, err)
+		}
+		if len(coords) > 0 {
+			placemark.Point = coords[0]
+		}
+	case line != nil:
+		placemark.Geometry = "LineString"
+		coords, err := parseCoordinates(line.Coordinates)
+		if err != nil {
+			return placemark, fmt.Errorf("placemark %q: %w", placemark.Name, err)
+		}
+		placemark.Line = coords
+	case p.Polygon != nil:
+		placemark.Geometry = "Polygon"
+	}
+
+	return placemark, nil
+}
+
+// parseCoordinates parses a KML coordinate list "lng,lat[,alt] lng,lat[,alt] ..." into [lng, lat] pairs
+func parseCoordinates(s string) ([][]float64, error) {
+	var coords [][]float64
+	for _, tuple := range strings.Fields(s) {
+		parts := strings.Split(tuple, ",")
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("invalid coordinate %q", tuple)
+		}
+		lng, err := strconv.ParseFloat(parts[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid longitude in %q", tuple)
+		}
+		lat, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid latitude in %q", tuple)
+		}
+		coords = append(coords, []float64{lng, lat})
+	}
+	return coords, nil
+}
@@ -0,0 +1,420 @@
+package kml
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"spectra-backend/internal/models"
+)
+
+// MappingOptions controls how placemarks are mapped to plant items
+type MappingOptions struct {
+	// DefaultNodeType is used for points whose type cannot be inferred; empty skips them
+	DefaultNodeType models.NodeType
+	// DefaultCableType is used for lines whose type cannot be inferred
+	DefaultCableType models.CableType
+	DefaultCoreCount int
+	// Status is assigned to imported items that carry no status of their own
+	NodeStatus  models.NodeStatus
+	CableStatus models.CableStatus
+	// NamePrefixes maps additional name prefixes (e.g. "FAT") to node types, checked before the built-in ones
+	NamePrefixes map[string]models.NodeType
+}
+
+// nodeKeywords maps name prefixes and style/folder words to node types.
+// Local naming conventions (FAT/FDT, JC, Tiang, PLG) are recognised alongside the PRD types.
+var nodeKeywords = []struct {
+	keyword  string
+	nodeType models.NodeType
+}{
+	{"OLT", models.NodeTypeOLT},
+	{"ODC", models.NodeTypeODC},
+	{"FDT", models.NodeTypeODC},
+	{"ODP", models.NodeTypeODP},
+	{"FAT", models.NodeTypeODP},
+	{"CLOSURE", models.NodeTypeClosure},
+	{"JC", models.NodeTypeClosure},
+	{"POLE", models.NodeTypePole},
+	{"TIANG", models.NodeTypePole},
+	{"CUSTOMER", models.NodeTypeCustomer},
+	{"CUST", models.NodeTypeCustomer},
+	{"PLG", models.NodeTypeCustomer},
+}
+
+var cableKeywords = []struct {
+	keyword   string
+	cableType models.CableType
+}{
+	{"ADSS", models.CableTypeADSS},
+	{"FEEDER", models.CableTypeADSS},
+	{"BACKBONE", models.CableTypeADSS},
+	{"DUCT", models.CableTypeDuct},
+	{"DISTRIBUTION", models.CableTypeDuct},
+	{"DROP", models.CableTypeDrop},
+	{"DC", models.CableTypeDrop},
+}
+
+// coreCountPattern matches core counts written in names such as "ADSS 24C" or "48 Core"
+var coreCountPattern = regexp.MustCompile(`(?i)\b(\d{1,3})\s*(?:C|CORE|CORES|F|FO)\b`)
+
+// ToImportPlan maps placemarks to nodes (points) and cables (lines).
+// Types come from ExtendedData "type", then a name prefix ("ODP-01"), then the styleUrl,
+// then the enclosing folder names.
+func ToImportPlan(placemarks []Placemark, opts MappingOptions) *models.ImportPlan {
+	plan := &models.ImportPlan{Format: "KML"}
+
+	for i := range placemarks {
+		p := &placemarks[i]
+		ref := fmt.Sprintf("#%d %s", i+1, p.Name)
+
+		switch {
+		case len(p.Point) >= 2:
+			node, err := toImportNode(p, opts)
+			if err != nil {
+				plan.Skipped = append(plan.Skipped, skipped(ref, models.ImportItemNode, p.Name, err.Error()))
+				continue
+			}
+			plan.Nodes = append(plan.Nodes, models.ImportNode{Ref: ref, Node: *node})
+		case len(p.Line) >= 2:
+			cable, err := toImportCable(p, opts)
+			if err != nil {
+				plan.Skipped = append(plan.Skipped, skipped(ref, models.ImportItemCable, p.Name, err.Error()))
+				continue
+			}
+			plan.Cables = append(plan.Cables, models.ImportCable{Ref: ref, Cable: *cable})
+		case p.Geometry == "":
+			plan.Skipped = append(plan.Skipped, skipped(ref, "", p.Name, "placemark has no geometry"))
+		case p.Geometry == "LineString":
+			plan.Skipped = append(plan.Skipped, skipped(ref, models.ImportItemCable, p.Name, "line has fewer than two coordinates"))
+		default:
+			plan.Skipped = append(plan.Skipped, skipped(ref, "", p.Name, p.Geometry+" geometry is not supported"))
+		}
+	}
+
+	return plan
+}
+
+func skipped(ref string, kind models.ImportItemKind, name, message string) models.ImportItemResult {
+	return models.ImportItemResult{
+		Ref:     ref,
+		Kind:    kind,
+		Action:  models.ImportActionSkip,
+		Name:    name,
+		Message: message,
+	}
+}
+
+func toImportNode(p *Placemark, opts MappingOptions) (*models.CreateNodeRequest, error) {
+	nodeType := inferNodeType(p, opts)
+	if nodeType == "" {
+		return nil, fmt.Errorf("cannot determine node type from name, style or folder")
+	}
+
+	lng, lat := p.Point[0], p.Point[1]
+	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return nil, fmt.Errorf("coordinates out of range")
+	}
+
+	name := p.Name
+	if name == "" {
+		name = fmt.Sprintf("%s %.6f,%.6f", nodeType, lat, lng)
+	}
+
+	req := &models.CreateNodeRequest{
+		Name:      name,
+		Type:      nodeType,
+		Latitude:  lat,
+		Longitude: lng,
+		Status:    opts.NodeStatus,
+	}
+	if status, ok := p.Value("status"); ok && models.NodeStatus(strings.ToUpper(status)).IsValid() {
+		req.Status = models.NodeStatus(strings.ToUpper(status))
+	}
+	if capacity, ok := firstInt(p, "capacity_ports", "capacity", "ports"); ok {
+		req.CapacityPorts = &capacity
+	}
+	if model, ok := p.Value("model"); ok && model != "" {
+		req.Model = &model
+	}
+	if address, ok := p.Value("address"); ok && address != "" {
+		req.Address = &address
+	}
+
+	return req, nil
+}
+
+func toImportCable(p *Placemark, opts MappingOptions) (*models.CreateCableRequest, error) {
+	for _, c := range p.Line {
+		if c[1] < -90 || c[1] > 90 || c[0] < -180 || c[0] > 180 {
+			return nil, fmt.Errorf("coordinates out of range")
+		}
+	}
+
+	cableType := inferCableType(p)
+	if cableType == "" {
+		cableType = opts.DefaultCableType
+	}
+	if cableType == "" {
+		cableType = models.CableTypeADSS
+	}
+
+	coreCount, ok := firstInt(p, "core_count", "cores")
+	if !ok {
+		if match := coreCountPattern.FindStringSubmatch(p.Name); match != nil {
+			coreCount, _ = strconv.Atoi(match[1])
+		} else {
+			coreCount = opts.DefaultCoreCount
+		}
+	}
+	if coreCount < 1 || coreCount > 288 {
+		return nil, fmt.Errorf("core count %d out of range", coreCount)
+	}
+
+	colorHex := models.CableTypeColors[cableType]
+	req := &models.CreateCableRequest{
+		Type:            cableType,
+		CoreCount:       coreCount,
+		PathCoordinates: p.Line,
+		ColorHex:        &colorHex,
+		Status:          opts.CableStatus,
+	}
+	if p.Name != "" {
+		name := p.Name
+		req.Name = &name
+	}
+	if status, ok := p.Value("status"); ok && models.CableStatus(strings.ToUpper(status)).IsValid() {
+		req.Status = models.CableStatus(strings.ToUpper(status))
+	}
+	if scheme, ok := p.Value("color_scheme"); ok && scheme != "" {
+		req.ColorScheme = &scheme
+	}
+
+	return req, nil
+}
+
+func inferNodeType(p *Placemark, opts MappingOptions) models.NodeType {
+	if value, ok := p.Value("type"); ok {
+		if t := models.NodeType(strings.ToUpper(value)); t.IsValid() {
+			return t
+		}
+	}
+
+	name := strings.ToUpper(p.Name)
+	for prefix, t := range opts.NamePrefixes {
+		if hasWordPrefix(name, strings.ToUpper(prefix)) {
+			return t
+		}
+	}
+	for _, k := range nodeKeywords {
+		if hasWordPrefix(name, k.keyword) {
+			return k.nodeType
+		}
+	}
+
+	if t := nodeTypeFromWords(p.StyleURL); t != "" {
+		return t
+	}
+	for i := len(p.Folders) - 1; i >= 0; i-- {
+		if t := nodeTypeFromWords(p.Folders[i]); t != "" {
+			return t
+		}
+	}
+
+	return opts.DefaultNodeType
+}
+
+func inferCableType(p *Placemark) models.CableType {
+	if value, ok := p.Value("type"); ok {
+		if t := models.CableType(strings.ToUpper(value)); t.IsValid() {
+			return t
+		}
+	}
+
+	name := strings.ToUpper(p.Name)
+	for _, k := range cableKeywords {
+		if hasWordPrefix(name, k.keyword) {
+			return k.cableType
+		}
+	}
+
+	sources := append([]string{p.StyleURL}, reversed(p.Folders)...)
+	for _, source := range sources {
+		for _, word := range words(source) {
+			for _, k := range cableKeywords {
+				if word == k.keyword {
+					return k.cableType
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// nodeTypeFromWords finds a node keyword among the words of a style id or folder name,
+// accepting plurals such as "ODPs" or "POLES"
+func nodeTypeFromWords(s string) models.NodeType {
+	for _, word := range words(s) {
+		for _, k := range nodeKeywords {
+			if word == k.keyword || word == k.keyword+"S" || word == k.keyword+"ES" {
+				return k.nodeType
+			}
+		}
+	}
+	return ""
+}
+
+// hasWordPrefix reports whether s starts with prefix followed by a non-letter, so "ODP-01" and
+// "ODP 01" match "ODP" but "ODPX" does not
+func hasWordPrefix(s, prefix string) bool {
+	if !strings.HasPrefix(s, prefix) {
+		return false
+	}
+	rest := s[len(prefix):]
+	return rest == "" || !unicode.IsLetter(rune(rest[0]))
+}
+
+// words splits a string into upper-case alphanumeric words
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToUpper(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func reversed(s []string) []string {
+	out := make([]string, len(s))
+	for i, v := range s {
+		out[len(s)-1-i] = v
+	}
+	return out
+}
+
+func firstInt(p *Placemark, names ...string) (int, bool) {
+	for _, name := range names {
+		if value, ok := p.Value(name); ok {
+			if n, err := strconv.Atoi(value); err == nil {
+				return n, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// PlantDocument builds the export document: one folder per node type and per cable type,
+// styled with the PRD symbology. usedCores maps cable IDs to their USED core count.
+func PlantDocument(name string, nodes []models.Node, cables []models.Cable, usedCores map[int64]int) *Document {
+	doc := &Document{Name: name}
+
+	nodeFolders := map[models.NodeType]*Folder{}
+	for _, t := range models.AllNodeTypes {
+		doc.Styles = append(doc.Styles, Style{ID: nodeStyleID(t), IconColor: models.NodeTypeColors[t], IconScale: 1.0})
+		nodeFolders[t] = &Folder{Name: string(t)}
+	}
+	cableFolders := map[models.CableType]*Folder{}
+	for _, t := range models.AllCableTypes {
+		doc.Styles = append(doc.Styles, Style{
+			ID:        cableStyleID(t),
+			LineColor: models.CableTypeColors[t],
+			LineWidth: float64(models.CableTypeWidths[t]),
+		})
+		cableFolders[t] = &Folder{Name: string(t) + " Cables"}
+	}
+
+	nodeByID := make(map[int64]*models.Node, len(nodes))
+	for i := range nodes {
+		n := &nodes[i]
+		nodeByID[n.ID] = n
+		folder, ok := nodeFolders[n.Type]
+		if !ok {
+			continue
+		}
+		data := []Data{
+			{"id", strconv.FormatInt(n.ID, 10)},
+			{"type", string(n.Type)},
+			{"status", string(n.Status)},
+			{"capacity_ports", strconv.Itoa(n.CapacityPorts)},
+			{"used_ports", strconv.Itoa(n.UsedPorts)},
+		}
+		if n.Model != nil {
+			data = append(data, Data{"model", *n.Model})
+		}
+		if n.Address != nil {
+			data = append(data, Data{"address", *n.Address})
+		}
+		folder.Placemarks = append(folder.Placemarks, Placemark{
+			Name:     n.Name,
+			StyleURL: "#" + nodeStyleID(n.Type),
+			Data:     data,
+			Point:    []float64{n.Longitude, n.Latitude},
+		})
+	}
+
+	for i := range cables {
+		c := &cables[i]
+		folder, ok := cableFolders[c.Type]
+		if !ok {
+			continue
+		}
+
+		// Cables drawn without a path are exported as a straight line between their nodes
+		path := c.PathCoordinates
+		if len(path) < 2 && c.OriginNodeID != nil && c.DestNodeID != nil {
+			origin, dest := nodeByID[*c.OriginNodeID], nodeByID[*c.DestNodeID]
+			if origin != nil && dest != nil {
+				path = [][]float64{{origin.Longitude, origin.Latitude}, {dest.Longitude, dest.Latitude}}
+			}
+		}
+		if len(path) < 2 {
+			continue
+		}
+
+		name := fmt.Sprintf("%s %dC #%d", c.Type, c.CoreCount, c.ID)
+		if c.Name != nil && *c.Name != "" {
+			name = *c.Name
+		}
+		data := []Data{
+			{"id", strconv.FormatInt(c.ID, 10)},
+			{"type", string(c.Type)},
+			{"status", string(c.Status)},
+			{"core_count", strconv.Itoa(c.CoreCount)},
+			{"used_cores", strconv.Itoa(usedCores[c.ID])},
+			{"color_scheme", c.ColorScheme},
+		}
+		if c.LengthMeter != nil {
+			data = append(data, Data{"length_meter", strconv.FormatFloat(*c.LengthMeter, 'f', 1, 64)})
+		}
+		if c.OriginNodeID != nil {
+			data = append(data, Data{"origin_node_id", strconv.FormatInt(*c.OriginNodeID, 10)})
+		}
+		if c.DestNodeID != nil {
+			data = append(data, Data{"dest_node_id", strconv.FormatInt(*c.DestNodeID, 10)})
+		}
+		folder.Placemarks = append(folder.Placemarks, Placemark{
+			Name:     name,
+			StyleURL: "#" + cableStyleID(c.Type),
+			Data:     data,
+			Line:     path,
+		})
+	}
+
+	for _, t := range models.AllNodeTypes {
+		doc.Folders = append(doc.Folders, *nodeFolders[t])
+	}
+	for _, t := range models.AllCableTypes {
+		doc.Folders = append(doc.Folders, *cableFolders[t])
+	}
+
+	return doc
+}
+
+// Style ids carry the type name so re-importing an export maps back to the same types
+func nodeStyleID(t models.NodeType) string {
+	return "node-" + strings.ToLower(string(t))
+}
+
+func cableStyleID(t models.CableType) string {
+	return "cable-" + strings.ToLower(string(t))
+}
@@ -0,0 +1,191 @@
+package kml
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Namespace is the OGC KML 2.2 namespace
+const Namespace = "http://www.opengis.net/kml/2.2"
+
+// PointIcon is the icon used for point styles; IconStyle color tints it
+const PointIcon = "http://maps.google.com/mapfiles/kml/shapes/placemark_circle.png"
+
+// Document is a KML document to be written
+type Document struct {
+	Name    string
+	Styles  []Style
+	Folders []Folder
+}
+
+// Style is a shared point or line style referenced by "#ID"
+type Style struct {
+	ID string
+	// IconColor and LineColor are "#RRGGBB" hex colors; empty omits the sub-style
+	IconColor string
+	IconScale float64
+	LineColor string
+	LineWidth float64
+}
+
+// Folder groups placemarks in the output document
+type Folder struct {
+	Name       string
+	Placemarks []Placemark
+}
+
+type outKML struct {
+	XMLName  xml.Name    `xml:"kml"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	Document outDocument `xml:"Document"`
+}
+
+type outDocument struct {
+	Name    string      `xml:"name"`
+	Styles  []outStyle  `xml:"Style"`
+	Folders []outFolder `xml:"Folder"`
+}
+
+type outStyle struct {
+	ID        string        `xml:"id,attr"`
+	IconStyle *outIconStyle `xml:"IconStyle,omitempty"`
+	LineStyle *outLineStyle `xml:"LineStyle,omitempty"`
+}
+
+type outIconStyle struct {
+	Color string  `xml:"color"`
+	Scale float64 `xml:"scale,omitempty"`
+	Icon  struct {
+		Href string `xml:"href"`
+	} `xml:"Icon"`
+}
+
+type outLineStyle struct {
+	Color string  `xml:"color"`
+	Width float64 `xml:"width"`
+}
+
+type outFolder struct {
+	Name       string         `xml:"name"`
+	Placemarks []outPlacemark `xml:"Placemark"`
+}
+
+type outPlacemark struct {
+	Name         string           `xml:"name"`
+	Description  string           `xml:"description,omitempty"`
+	StyleURL     string           `xml:"styleUrl,omitempty"`
+	ExtendedData *outExtendedData `xml:"ExtendedData,omitempty"`
+	Point        *outCoordinates  `xml:"Point,omitempty"`
+	LineString   *outLineString   `xml:"LineString,omitempty"`
+}
+
+type outExtendedData struct {
+	Data []outData `xml:"Data"`
+}
+
+type outData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type outCoordinates struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type outLineString struct {
+	Tessellate  int    `xml:"tessellate"`
+	Coordinates string `xml:"coordinates"`
+}
+
+// Write encodes the document as KML
+func Write(w io.Writer, doc *Document) error {
+	out := outKML{
+		Xmlns:    Namespace,
+		Document: outDocument{Name: doc.Name},
+	}
+
+	for _, s := range doc.Styles {
+		style := outStyle{ID: s.ID}
+		if s.IconColor != "" {
+			style.IconStyle = &outIconStyle{Color: Color(s.IconColor), Scale: s.IconScale}
+			style.IconStyle.Icon.Href = PointIcon
+		}
+		if s.LineColor != "" {
+			style.LineStyle = &outLineStyle{Color: Color(s.LineColor), Width: s.LineWidth}
+		}
+		out.Document.Styles = append(out.Document.Styles, style)
+	}
+
+	for _, f := range doc.Folders {
+		folder := outFolder{Name: f.Name}
+		for _, p := range f.Placemarks {
+			folder.Placemarks = append(folder.Placemarks, toOutPlacemark(&p))
+		}
+		out.Document.Folders = append(out.Document.Folders, folder)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return fmt.Errorf("failed to encode KML: %w", err)
+	}
+	return enc.Flush()
+}
+
+// WriteKMZ encodes the document as a KMZ archive holding a single doc.kml
+func WriteKMZ(w io.Writer, doc *Document) error {
+	archive := zip.NewWriter(w)
+	entry, err := archive.Create("doc.kml")
+	if err != nil {
+		return fmt.Errorf("failed to create KMZ entry: %w", err)
+	}
+	if err := Write(entry, doc); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+func toOutPlacemark(p *Placemark) outPlacemark {
+	out := outPlacemark{
+		Name:        p.Name,
+		Description: p.Description,
+		StyleURL:    p.StyleURL,
+	}
+	if len(p.Data) > 0 {
+		out.ExtendedData = &outExtendedData{}
+		for _, d := range p.Data {
+			out.ExtendedData.Data = append(out.ExtendedData.Data, outData{Name: d.Name, Value: d.Value})
+		}
+	}
+	if len(p.Point) >= 2 {
+		out.Point = &outCoordinates{Coordinates: formatCoordinates([][]float64{p.Point})}
+	} else if len(p.Line) >= 2 {
+		out.LineString = &outLineString{Tessellate: 1, Coordinates: formatCoordinates(p.Line)}
+	}
+	return out
+}
+
+// formatCoordinates formats [lng, lat] pairs as a KML coordinate list
+func formatCoordinates(coords [][]float64) string {
+	tuples := make([]string, 0, len(coords))
+	for _, c := range coords {
+		tuples = append(tuples, strconv.FormatFloat(c[0], 'f', -1, 64)+","+strconv.FormatFloat(c[1], 'f', -1, 64))
+	}
+	return strings.Join(tuples, " ")
+}
+
+// Color converts a "#RRGGBB" hex color to KML's opaque "aabbggrr" form
+func Color(hex string) string {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) != 6 {
+		return "ff000000"
+	}
+	return strings.ToLower("ff" + hex[4:6] + hex[2:4] + hex[0:2])
+}
@@ -0,0 +1,108 @@
+package models
+
+import "time"
+
+// AreaLevel is the tier of a service area in the region → district → cluster hierarchy
+type AreaLevel string
+
+const (
+	AreaLevelRegion   AreaLevel = "REGION"
+	AreaLevelDistrict AreaLevel = "DISTRICT"
+	AreaLevelCluster  AreaLevel = "CLUSTER"
+)
+
+// AreaLevels lists area levels from the top of the hierarchy down
+var AreaLevels = []AreaLevel{AreaLevelRegion, AreaLevelDistrict, AreaLevelCluster}
+
+// IsValid checks if the area level is valid
+func (l AreaLevel) IsValid() bool {
+	return l.Depth() >= 0
+}
+
+// Depth returns 0 for regions, 1 for districts, 2 for clusters and -1 for unknown levels
+func (l AreaLevel) Depth() int {
+	for i, known := range AreaLevels {
+		if l == known {
+			return i
+		}
+	}
+	return -1
+}
+
+// ParentLevel returns the level an area's parent must have, or "" for regions
+func (l AreaLevel) ParentLevel() AreaLevel {
+	if d := l.Depth(); d > 0 {
+		return AreaLevels[d-1]
+	}
+	return ""
+}
+
+// ServiceArea is a polygon of the network's footprint. Nodes are assigned to the deepest area
+// containing them; customers belong to the area of their node.
+type ServiceArea struct {
+	ID              int64       `json:"id" db:"id"`
+	Name            string      `json:"name" db:"name"`
+	Level           AreaLevel   `json:"level" db:"level"`
+	ParentID        *int64      `json:"parent_id,omitempty" db:"parent_id"`
+	Polygon         [][]float64 `json:"polygon" db:"polygon"` // [[lng, lat], ...]
+	MaintenanceTeam *string     `json:"maintenance_team,omitempty" db:"maintenance_team"`
+	Description     *string     `json:"description,omitempty" db:"description"`
+	CreatedAt       time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at" db:"updated_at"`
+
+	// Joined data
+	Children []ServiceArea `json:"children,omitempty" db:"-"`
+}
+
+// CreateServiceAreaRequest represents the request body for creating a service area. Regions have
+// no parent; districts sit in a region and clusters in a district.
+type CreateServiceAreaRequest struct {
+	Name            string      `json:"name" validate:"required,min=1,max=100"`
+	Level           AreaLevel   `json:"level" validate:"required,oneof=REGION DISTRICT CLUSTER"`
+	ParentID        *int64      `json:"parent_id,omitempty"`
+	Polygon         [][]float64 `json:"polygon" validate:"required,min=3"`
+	MaintenanceTeam *string     `json:"maintenance_team,omitempty"`
+	Description     *string     `json:"description,omitempty"`
+}
+
+// UpdateServiceAreaRequest represents the request body for updating a service area
+type UpdateServiceAreaRequest struct {
+	Name            *string     `json:"name,omitempty"`
+	ParentID        *int64      `json:"parent_id,omitempty"` // Moves the area under another of the parent level
+	Polygon         [][]float64 `json:"polygon,omitempty"`
+	MaintenanceTeam *string     `json:"maintenance_team,omitempty"`
+	Description     *string     `json:"description,omitempty"`
+}
+
+// ServiceAreaFilter represents query filters for listing service areas
+type ServiceAreaFilter struct {
+	Level    *AreaLevel `json:"level,omitempty"`
+	ParentID *int64     `json:"parent_id,omitempty"`
+	Search   *string    `json:"search,omitempty"` // Search by name
+	Limit    int        `json:"limit,omitempty"`
+	Offset   int        `json:"offset,omitempty"`
+}
+
+// AreaAssignment reports a run of point-in-polygon assignment of nodes to service areas
+type AreaAssignment struct {
+	Nodes      int `json:"nodes"`      // Nodes checked
+	Assigned   int `json:"assigned"`   // Nodes inside an area
+	Unassigned int `json:"unassigned"` // Nodes outside every area
+	Changed    int `json:"changed"`    // Nodes whose area changed
+}
+
+// AreaStats totals the plant and customers in a service area and the areas below it
+type AreaStats struct {
+	Area              ServiceArea            `json:"area"`
+	SubAreas          int                    `json:"sub_areas"`
+	Nodes             int                    `json:"nodes"`
+	NodesByType       map[NodeType]int       `json:"nodes_by_type"`
+	CapacityPorts     int                    `json:"capacity_ports"` // ODP ports
+	UsedPorts         int                    `json:"used_ports"`
+	PortUtilization   float64                `json:"port_utilization"`
+	Cables            int                    `json:"cables"` // Cables with an end in the area
+	CableLengthMeter  float64                `json:"cable_length_meter"`
+	Customers         int                    `json:"customers"`
+	CustomersByStatus map[CustomerStatus]int `json:"customers_by_status"`
+	OpenTickets       int                    `json:"open_tickets"` // Tickets linked to plant or customers in the area
+}
@@ -0,0 +1,94 @@
+package models
+
+import "time"
+
+// BOMCategory is the kind of material on a bill of materials line
+type BOMCategory string
+
+const (
+	BOMCategoryCable    BOMCategory = "CABLE"
+	BOMCategoryOLT      BOMCategory = "OLT"
+	BOMCategoryODC      BOMCategory = "ODC"
+	BOMCategoryODP      BOMCategory = "ODP"
+	BOMCategoryClosure  BOMCategory = "CLOSURE"
+	BOMCategorySplitter BOMCategory = "SPLITTER"
+	BOMCategorySplice   BOMCategory = "SPLICE"
+	BOMCategoryPole     BOMCategory = "POLE"
+)
+
+// BOMCategories lists the categories in the order they appear on a bill of materials
+var BOMCategories = []BOMCategory{
+	BOMCategoryCable, BOMCategoryOLT, BOMCategoryODC, BOMCategoryODP,
+	BOMCategoryClosure, BOMCategorySplitter, BOMCategorySplice, BOMCategoryPole,
+}
+
+// IsValid reports whether the category is one of the known categories
+func (c BOMCategory) IsValid() bool {
+	for _, known := range BOMCategories {
+		if c == known {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultCurrency is the currency of catalog prices that do not name one
+const DefaultCurrency = "IDR"
+
+// PriceCatalogItem is the unit price of a material. Spec narrows the category: "<TYPE>-<cores>"
+// for cables (e.g. "ADSS-24"), the device model for OLTs, ODCs, ODPs, closures and poles, and the
+// ratio for splitters (e.g. "1:8"). An item with an empty spec prices every line of its category
+// that has no item of its own.
+type PriceCatalogItem struct {
+	ID          int64       `json:"id" db:"id"`
+	Category    BOMCategory `json:"category" db:"category"`
+	Spec        string      `json:"spec" db:"spec"`
+	Description *string     `json:"description,omitempty" db:"description"`
+	Unit        string      `json:"unit" db:"unit"` // m or pcs
+	UnitPrice   float64     `json:"unit_price" db:"unit_price"`
+	Currency    string      `json:"currency" db:"currency"`
+	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// CreatePriceCatalogItemRequest represents the request body for adding a catalog price
+type CreatePriceCatalogItemRequest struct {
+	Category    BOMCategory `json:"category" validate:"required,oneof=CABLE OLT ODC ODP CLOSURE SPLITTER SPLICE POLE"`
+	Spec        string      `json:"spec,omitempty" validate:"max=100"`
+	Description *string     `json:"description,omitempty"`
+	Unit        string      `json:"unit,omitempty" validate:"max=20"` // Defaults to m for cables and pcs otherwise
+	UnitPrice   float64     `json:"unit_price" validate:"gte=0"`
+	Currency    string      `json:"currency,omitempty" validate:"omitempty,len=3"`
+}
+
+// UpdatePriceCatalogItemRequest represents the request body for editing a catalog price
+type UpdatePriceCatalogItemRequest struct {
+	Spec        *string  `json:"spec,omitempty" validate:"omitempty,max=100"`
+	Description *string  `json:"description,omitempty"`
+	Unit        *string  `json:"unit,omitempty" validate:"omitempty,max=20"`
+	UnitPrice   *float64 `json:"unit_price,omitempty" validate:"omitempty,gte=0"`
+	Currency    *string  `json:"currency,omitempty" validate:"omitempty,len=3"`
+}
+
+// BOMLine is a quantity of one material, priced when the catalog has a matching item
+type BOMLine struct {
+	Category      BOMCategory `json:"category"`
+	Spec          string      `json:"spec"`
+	Description   string      `json:"description"`
+	Unit          string      `json:"unit"`
+	Quantity      float64     `json:"quantity"`
+	CatalogItemID *int64      `json:"catalog_item_id,omitempty"`
+	UnitPrice     *float64    `json:"unit_price,omitempty"`
+	Currency      *string     `json:"currency,omitempty"`
+	Total         *float64    `json:"total,omitempty"`
+}
+
+// BillOfMaterials lists the materials of a design workspace or of the plant inside a bounding box
+type BillOfMaterials struct {
+	WorkspaceID *int64             `json:"workspace_id,omitempty"`
+	BBox        []float64          `json:"bbox,omitempty"` // minLng, minLat, maxLng, maxLat
+	Lines       []BOMLine          `json:"lines"`
+	Totals      map[string]float64 `json:"totals"`   // By currency
+	Unpriced    int                `json:"unpriced"` // Lines without a catalog price
+	GeneratedAt time.Time          `json:"generated_at"`
+}
@@ -23,6 +23,30 @@ const (
 	CableStatusInactive    CableStatus = "INACTIVE"
 )
 
+// AllCableTypes lists cable types in display order
+var AllCableTypes = []CableType{
+	CableTypeADSS, CableTypeDuct, CableTypeDrop,
+}
+
+// IsValid reports whether the cable type is one of the known types
+func (t CableType) IsValid() bool {
+	for _, known := range AllCableTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValid reports whether the cable status is one of the known statuses
+func (s CableStatus) IsValid() bool {
+	switch s {
+	case CableStatusActive, CableStatusMaintenance, CableStatusPlan, CableStatusInactive:
+		return true
+	}
+	return false
+}
+
 // Cable represents a fiber optic cable connecting two nodes
 type Cable struct {
 	ID           int64       `json:"id" db:"id"`
@@ -33,6 +57,7 @@ type Cable struct {
 	OriginNodeID *int64      `json:"origin_node_id,omitempty" db:"origin_node_id"`
 	DestNodeID   *int64      `json:"dest_node_id,omitempty" db:"dest_node_id"`
 	ColorHex     string      `json:"color_hex" db:"color_hex"`
+	ColorScheme  string      `json:"color_scheme" db:"color_scheme"`
 	Status       CableStatus `json:"status" db:"status"`
 	CreatedAt    time.Time   `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time   `json:"updated_at" db:"updated_at"`
@@ -43,6 +68,9 @@ type Cable struct {
 	// Joined data
 	OriginNode *Node `json:"origin_node,omitempty" db:"-"`
 	DestNode   *Node `json:"dest_node,omitempty" db:"-"`
+
+	// Populated when an update changed the core count
+	CoreResize *CoreResizeReport `json:"core_resize,omitempty" db:"-"`
 }
 
 // CreateCableRequest represents the request body for creating a cable
@@ -55,6 +83,7 @@ type CreateCableRequest struct {
 	DestNodeID      *int64      `json:"dest_node_id,omitempty"`
 	PathCoordinates [][]float64 `json:"path_coordinates,omitempty"` // [[lng, lat], [lng, lat], ...]
 	ColorHex        *string     `json:"color_hex,omitempty"`
+	ColorScheme     *string     `json:"color_scheme,omitempty"` // Defaults to TIA-598
 	Status          CableStatus `json:"status,omitempty"`
 }
 
@@ -69,6 +98,10 @@ type UpdateCableRequest struct {
 	PathCoordinates [][]float64  `json:"path_coordinates,omitempty"`
 	ColorHex        *string      `json:"color_hex,omitempty"`
 	Status          *CableStatus `json:"status,omitempty" validate:"omitempty,oneof=ACTIVE MAINTENANCE PLAN INACTIVE"`
+
+	// Force drops cores that are USED, RESERVED or spliced when core_count shrinks,
+	// deleting their connections
+	Force bool `json:"force,omitempty"`
 }
 
 // CableFilter represents query filters for listing cables
@@ -77,6 +110,7 @@ type CableFilter struct {
 	Status       *CableStatus `json:"status,omitempty"`
 	OriginNodeID *int64       `json:"origin_node_id,omitempty"`
 	DestNodeID   *int64       `json:"dest_node_id,omitempty"`
+	AreaID       *int64       `json:"area_id,omitempty"` // Cables with an end in the area
 	Limit        int          `json:"limit,omitempty"`
 	Offset       int          `json:"offset,omitempty"`
 }
@@ -97,11 +131,12 @@ type GeoJSONLineString struct {
 // ToGeoJSON converts a Cable to GeoJSON format
 func (c *Cable) ToGeoJSON() CableGeoJSON {
 	properties := map[string]interface{}{
-		"id":         c.ID,
-		"type":       c.Type,
-		"core_count": c.CoreCount,
-		"status":     c.Status,
-		"color_hex":  c.ColorHex,
+		"id":           c.ID,
+		"type":         c.Type,
+		"core_count":   c.CoreCount,
+		"status":       c.Status,
+		"color_hex":    c.ColorHex,
+		"color_scheme": c.ColorScheme,
 	}
 
 	if c.Name != nil {
@@ -131,3 +166,23 @@ func (c *Cable) ToGeoJSON() CableGeoJSON {
 		Properties: properties,
 	}
 }
+
+// SplitCableRequest represents the request body for cutting a cable and inserting a node mid-span
+// Exactly one of NodeID (existing node) or NewNode must be provided
+type SplitCableRequest struct {
+	NodeID                *int64             `json:"node_id,omitempty"`
+	NewNode               *CreateNodeRequest `json:"new_node,omitempty"`
+	CreateStraightSplices bool               `json:"create_straight_splices"`
+	SpliceLossDB          *float64           `json:"splice_loss_db,omitempty"`
+	SecondCableName       *string            `json:"second_cable_name,omitempty"`
+}
+
+// SplitCableResult represents the outcome of a cable split
+type SplitCableResult struct {
+	SplitNode          *Node        `json:"split_node"`
+	FirstCable         *Cable       `json:"first_cable"`
+	SecondCable        *Cable       `json:"second_cable"`
+	CoresCopied        int          `json:"cores_copied"`
+	ConnectionsRewired int          `json:"connections_rewired"`
+	StraightSplices    []Connection `json:"straight_splices"`
+}
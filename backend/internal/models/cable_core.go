@@ -14,6 +14,15 @@ const (
 	CoreStatusDamaged  CoreStatus = "DAMAGED"
 )
 
+// IsValid reports whether the core status is one of the known statuses
+func (s CoreStatus) IsValid() bool {
+	switch s {
+	case CoreStatusVacant, CoreStatusUsed, CoreStatusReserved, CoreStatusDamaged:
+		return true
+	}
+	return false
+}
+
 // CableCore represents an individual fiber core within a cable
 type CableCore struct {
 	ID        int64      `json:"id" db:"id"`
@@ -24,6 +33,9 @@ type CableCore struct {
 	Status    CoreStatus `json:"status" db:"status"`
 	CreatedAt time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+
+	// Populated from the cable's color scheme in the cores API
+	TubeNumber int `json:"tube_number,omitempty" db:"-"`
 }
 
 // CreateCableCoreRequest represents the request body for creating a cable core
@@ -55,26 +67,34 @@ var StandardCoreColors = []string{
 }
 
 // GenerateCoresForCable creates core entries for a cable based on core count
+// using the default TIA-598 color scheme
 func GenerateCoresForCable(cableID int64, coreCount int) []CableCore {
-	cores := make([]CableCore, coreCount)
-	tubeCount := len(StandardTubeColors)
-	coreColorCount := len(StandardCoreColors)
-
-	for i := 0; i < coreCount; i++ {
-		tubeIndex := i / coreColorCount
-		coreIndex := i % coreColorCount
+	scheme := BuiltinColorSchemes[DefaultColorScheme]
+	return scheme.GenerateCores(cableID, coreCount)
+}
 
-		tubeColor := StandardTubeColors[tubeIndex%tubeCount]
-		coreColor := StandardCoreColors[coreIndex]
+// CoreResizeReport describes the effect of changing a cable's core count
+type CoreResizeReport struct {
+	CableID              int64          `json:"cable_id"`
+	OldCoreCount         int            `json:"old_core_count"`
+	NewCoreCount         int            `json:"new_core_count"`
+	AddedCores           int            `json:"added_cores"`
+	RemovedCores         int            `json:"removed_cores"`
+	BlockingCores        []BlockingCore `json:"blocking_cores"`
+	RemovedConnectionIDs []int64        `json:"removed_connection_ids"`
+	Forced               bool           `json:"forced"`
+	Applied              bool           `json:"applied"`
+}
 
-		cores[i] = CableCore{
-			CableID:   cableID,
-			CoreIndex: i + 1,
-			TubeColor: &tubeColor,
-			CoreColor: &coreColor,
-			Status:    CoreStatusVacant,
-		}
-	}
+// BlockingCore is a core that would be dropped by a resize but is still in service
+type BlockingCore struct {
+	CoreID        int64      `json:"core_id"`
+	CoreIndex     int        `json:"core_index"`
+	Status        CoreStatus `json:"status"`
+	ConnectionIDs []int64    `json:"connection_ids"`
+}
 
-	return cores
+// IsInService reports whether a core status prevents it from being dropped silently
+func (s CoreStatus) IsInService() bool {
+	return s == CoreStatusUsed || s == CoreStatusReserved
 }
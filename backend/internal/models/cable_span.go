@@ -0,0 +1,131 @@
+package models
+
+import (
+	"time"
+)
+
+// CableSpan represents one physical span of a cable between two nodes (poles, closures, ...)
+type CableSpan struct {
+	ID              int64     `json:"id" db:"id"`
+	CableID         int64     `json:"cable_id" db:"cable_id"`
+	FromNodeID      int64     `json:"from_node_id" db:"from_node_id"`
+	ToNodeID        int64     `json:"to_node_id" db:"to_node_id"`
+	Sequence        int       `json:"sequence" db:"sequence"`
+	SpanLengthMeter *float64  `json:"span_length_meter,omitempty" db:"span_length_meter"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+
+	// Joined data
+	FromNode *Node `json:"from_node,omitempty" db:"-"`
+	ToNode   *Node `json:"to_node,omitempty" db:"-"`
+}
+
+// CableSpanInput represents a single span in a span replacement request
+type CableSpanInput struct {
+	FromNodeID      int64    `json:"from_node_id" validate:"required"`
+	ToNodeID        int64    `json:"to_node_id" validate:"required"`
+	SpanLengthMeter *float64 `json:"span_length_meter,omitempty"`
+}
+
+// ReplaceCableSpansRequest represents the request body for setting a cable's ordered spans
+// Spans must be contiguous: each span starts where the previous one ended
+type ReplaceCableSpansRequest struct {
+	Spans []CableSpanInput `json:"spans" validate:"required,min=1"`
+}
+
+// CoreBreakout marks a core as cut (broken out) at an intermediate node of its cable
+// Cores without a breakout pass through the node express, unspliced
+type CoreBreakout struct {
+	ID        int64     `json:"id" db:"id"`
+	CoreID    int64     `json:"core_id" db:"core_id"`
+	NodeID    int64     `json:"node_id" db:"node_id"`
+	Notes     *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// CreateCoreBreakoutRequest represents the request body for breaking out a core at a node
+type CreateCoreBreakoutRequest struct {
+	NodeID int64   `json:"node_id" validate:"required"`
+	Notes  *string `json:"notes,omitempty"`
+}
+
+// CableRoute represents the ordered physical route of a cable
+type CableRoute struct {
+	CableID          int64       `json:"cable_id"`
+	NodeIDs          []int64     `json:"node_ids"`
+	Spans            []CableSpan `json:"spans"`
+	TotalLengthMeter float64     `json:"total_length_meter"`
+}
+
+// CoreSegment represents a continuous piece of fiber between two cut points of a core
+type CoreSegment struct {
+	CoreID             int64   `json:"core_id"`
+	CableID            int64   `json:"cable_id"`
+	FromNodeID         int64   `json:"from_node_id"`
+	ToNodeID           int64   `json:"to_node_id"`
+	PassThroughNodeIDs []int64 `json:"pass_through_node_ids"` // Intermediate nodes passed express
+	LengthMeter        float64 `json:"length_meter"`
+}
+
+// HasEndpoint reports whether the segment starts or ends at the given node
+func (s *CoreSegment) HasEndpoint(nodeID int64) bool {
+	return s.FromNodeID == nodeID || s.ToNodeID == nodeID
+}
+
+// OtherEnd returns the endpoint opposite to the given node
+func (s *CoreSegment) OtherEnd(nodeID int64) int64 {
+	if s.FromNodeID == nodeID {
+		return s.ToNodeID
+	}
+	return s.FromNodeID
+}
+
+// CoreTrace represents the result of following a core through splices and express passes
+type CoreTrace struct {
+	StartCoreID int64         `json:"start_core_id"`
+	StartNodeID int64         `json:"start_node_id"` // End of the start core the trace began at
+	Segments    []CoreSegment `json:"segments"`
+	Hops        []TraceNode   `json:"hops"`
+	TotalLoss   float64       `json:"total_loss_db"`
+	TotalHops   int           `json:"total_hops"`
+}
+
+// SplitRouteAtBreakouts splits a cable route into core segments at the nodes where the core is broken out.
+// spanLengths[i] is the length of the span between route[i] and route[i+1].
+func SplitRouteAtBreakouts(coreID, cableID int64, route []int64, spanLengths []float64, breakouts map[int64]bool) []CoreSegment {
+	segments := []CoreSegment{}
+	if len(route) < 2 {
+		return segments
+	}
+
+	current := CoreSegment{
+		CoreID:             coreID,
+		CableID:            cableID,
+		FromNodeID:         route[0],
+		PassThroughNodeIDs: []int64{},
+	}
+
+	for i := 1; i < len(route); i++ {
+		if i-1 < len(spanLengths) {
+			current.LengthMeter += spanLengths[i-1]
+		}
+
+		nodeID := route[i]
+		isLast := i == len(route)-1
+		if isLast || breakouts[nodeID] {
+			current.ToNodeID = nodeID
+			segments = append(segments, current)
+			current = CoreSegment{
+				CoreID:             coreID,
+				CableID:            cableID,
+				FromNodeID:         nodeID,
+				PassThroughNodeIDs: []int64{},
+			}
+			continue
+		}
+
+		current.PassThroughNodeIDs = append(current.PassThroughNodeIDs, nodeID)
+	}
+
+	return segments
+}
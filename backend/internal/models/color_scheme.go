@@ -0,0 +1,177 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// ColorSchemeUnit represents how fibers are grouped inside a cable
+type ColorSchemeUnit string
+
+const (
+	ColorSchemeUnitTube   ColorSchemeUnit = "TUBE"
+	ColorSchemeUnitRibbon ColorSchemeUnit = "RIBBON"
+)
+
+// Built-in color scheme codes
+const (
+	ColorSchemeTIA598   = "TIA-598"
+	ColorSchemeIEC60304 = "IEC-60304"
+)
+
+// DefaultColorScheme is used for cables that do not select a scheme
+const DefaultColorScheme = ColorSchemeTIA598
+
+// ColorScheme describes a fiber color-coding scheme and the tube (or ribbon) size
+type ColorScheme struct {
+	ID            *int64          `json:"id,omitempty" db:"id"`
+	Code          string          `json:"code" db:"code"`
+	Name          string          `json:"name" db:"name"`
+	Unit          ColorSchemeUnit `json:"unit" db:"unit"`
+	FibersPerTube int             `json:"fibers_per_tube" db:"fibers_per_tube"`
+	TubeColors    []string        `json:"tube_colors" db:"tube_colors"`
+	FiberColors   []string        `json:"fiber_colors" db:"fiber_colors"`
+	// StripeMarkers mark repeated colors: the first repetition of a color gets StripeMarkers[0], and so on
+	StripeMarkers []string   `json:"stripe_markers" db:"stripe_markers"`
+	Builtin       bool       `json:"builtin" db:"-"`
+	CreatedAt     *time.Time `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt     *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+// CreateColorSchemeRequest represents the request body for creating a custom color scheme
+type CreateColorSchemeRequest struct {
+	Code          string          `json:"code" validate:"required,max=50"`
+	Name          string          `json:"name" validate:"required,max=100"`
+	Unit          ColorSchemeUnit `json:"unit,omitempty" validate:"omitempty,oneof=TUBE RIBBON"`
+	FibersPerTube int             `json:"fibers_per_tube" validate:"required,min=1"`
+	TubeColors    []string        `json:"tube_colors" validate:"required,min=1"`
+	FiberColors   []string        `json:"fiber_colors" validate:"required,min=1"`
+	StripeMarkers []string        `json:"stripe_markers,omitempty"`
+}
+
+// UpdateColorSchemeRequest represents the request body for updating a custom color scheme
+type UpdateColorSchemeRequest struct {
+	Name          *string          `json:"name,omitempty"`
+	Unit          *ColorSchemeUnit `json:"unit,omitempty" validate:"omitempty,oneof=TUBE RIBBON"`
+	FibersPerTube *int             `json:"fibers_per_tube,omitempty" validate:"omitempty,min=1"`
+	TubeColors    []string         `json:"tube_colors,omitempty"`
+	FiberColors   []string         `json:"fiber_colors,omitempty"`
+	StripeMarkers []string         `json:"stripe_markers,omitempty"`
+}
+
+// ApplyColorSchemeRequest represents the request body for switching a cable to another scheme
+type ApplyColorSchemeRequest struct {
+	ColorScheme string `json:"color_scheme" validate:"required"`
+}
+
+// DefaultStripeMarkers are the ring/stripe markings used when a color repeats
+var DefaultStripeMarkers = []string{
+	"Black Stripe", "Double Black Stripe", "Triple Black Stripe",
+	"Dashed Black", "Double Dashed Black", "Triple Dashed Black",
+}
+
+// BuiltinColorSchemes holds the standard schemes that are always available
+var BuiltinColorSchemes = map[string]ColorScheme{
+	ColorSchemeTIA598: {
+		Code:          ColorSchemeTIA598,
+		Name:          "TIA-598 (12 fibers per tube)",
+		Unit:          ColorSchemeUnitTube,
+		FibersPerTube: 12,
+		TubeColors:    StandardTubeColors,
+		FiberColors:   StandardCoreColors,
+		StripeMarkers: DefaultStripeMarkers,
+		Builtin:       true,
+	},
+	ColorSchemeIEC60304: {
+		Code:          ColorSchemeIEC60304,
+		Name:          "IEC 60304 (12 fibers per tube)",
+		Unit:          ColorSchemeUnitTube,
+		FibersPerTube: 12,
+		TubeColors: []string{
+			"Red", "Green", "Blue", "Yellow", "White", "Grey",
+			"Brown", "Violet", "Turquoise", "Black", "Orange", "Pink",
+		},
+		FiberColors: []string{
+			"Red", "Green", "Blue", "Yellow", "White", "Grey",
+			"Brown", "Violet", "Turquoise", "Black", "Orange", "Pink",
+		},
+		StripeMarkers: DefaultStripeMarkers,
+		Builtin:       true,
+	},
+}
+
+// Validate checks that a scheme can generate cores
+func (s *ColorScheme) Validate() error {
+	if s.Code == "" {
+		return fmt.Errorf("code is required")
+	}
+	if s.FibersPerTube < 1 {
+		return fmt.Errorf("fibers_per_tube must be at least 1")
+	}
+	if len(s.TubeColors) == 0 {
+		return fmt.Errorf("tube_colors must not be empty")
+	}
+	if len(s.FiberColors) == 0 {
+		return fmt.Errorf("fiber_colors must not be empty")
+	}
+	if s.Unit != ColorSchemeUnitTube && s.Unit != ColorSchemeUnitRibbon {
+		return fmt.Errorf("unit must be TUBE or RIBBON")
+	}
+	return nil
+}
+
+// ColorsForCore returns the tube and fiber color of a 1-based core index
+// Colors that repeat (more tubes than tube colors, or more fibers per tube than fiber colors)
+// are distinguished by stripe markers
+func (s *ColorScheme) ColorsForCore(coreIndex int) (string, string) {
+	position := coreIndex - 1
+	tubeIndex := position / s.FibersPerTube
+	fiberIndex := position % s.FibersPerTube
+
+	tubeColor := s.markedColor(s.TubeColors, tubeIndex)
+	fiberColor := s.markedColor(s.FiberColors, fiberIndex)
+
+	return tubeColor, fiberColor
+}
+
+// TubeNumber returns the 1-based tube (or ribbon) number of a 1-based core index
+func (s *ColorScheme) TubeNumber(coreIndex int) int {
+	return (coreIndex-1)/s.FibersPerTube + 1
+}
+
+// markedColor picks a color from a sequence, adding a stripe marker when the sequence wraps
+func (s *ColorScheme) markedColor(colors []string, index int) string {
+	color := colors[index%len(colors)]
+	repetition := index / len(colors)
+	if repetition == 0 {
+		return color
+	}
+
+	markers := s.StripeMarkers
+	if len(markers) == 0 {
+		markers = DefaultStripeMarkers
+	}
+	if repetition <= len(markers) {
+		return color + " / " + markers[repetition-1]
+	}
+	return fmt.Sprintf("%s / %d Rings", color, repetition)
+}
+
+// GenerateCores creates core entries for a cable using this scheme
+func (s *ColorScheme) GenerateCores(cableID int64, coreCount int) []CableCore {
+	cores := make([]CableCore, coreCount)
+
+	for i := 0; i < coreCount; i++ {
+		tubeColor, coreColor := s.ColorsForCore(i + 1)
+
+		cores[i] = CableCore{
+			CableID:   cableID,
+			CoreIndex: i + 1,
+			TubeColor: &tubeColor,
+			CoreColor: &coreColor,
+			Status:    CoreStatusVacant,
+		}
+	}
+
+	return cores
+}
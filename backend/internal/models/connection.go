@@ -12,6 +12,11 @@ const (
 	ConnectionTypePort ConnectionType = "PORT"
 )
 
+// IsValid reports whether the endpoint type is CORE or PORT
+func (t ConnectionType) IsValid() bool {
+	return t == ConnectionTypeCore || t == ConnectionTypePort
+}
+
 // Connection represents a splicing link between cable cores or ports
 type Connection struct {
 	ID             int64          `json:"id" db:"id"`
@@ -40,6 +45,11 @@ type CreateConnectionRequest struct {
 	Notes          *string        `json:"notes,omitempty"`
 }
 
+// HasUnlocatedPort reports whether a PORT endpoint lacks the location node it is numbered at
+func (r *CreateConnectionRequest) HasUnlocatedPort() bool {
+	return (r.InputType == ConnectionTypePort || r.OutputType == ConnectionTypePort) && r.LocationNodeID == nil
+}
+
 // UpdateConnectionRequest represents the request body for updating a connection
 type UpdateConnectionRequest struct {
 	LocationNodeID *int64          `json:"location_node_id,omitempty"`
@@ -58,8 +68,9 @@ type ConnectionFilter struct {
 	InputID        *int64
 	OutputType     *ConnectionType
 	OutputID       *int64
-	Limit          int `json:"limit,omitempty"`
-	Offset         int `json:"offset,omitempty"`
+	AreaID         *int64 `json:"area_id,omitempty"` // Connections at nodes in the area
+	Limit          int    `json:"limit,omitempty"`
+	Offset         int    `json:"offset,omitempty"`
 }
 
 // SpliceMatrix represents a visual representation of connections at a location
@@ -0,0 +1,91 @@
+package models
+
+// DefaultMaxDropMeters is the longest drop from an ODP to a premises assumed when none is given
+const DefaultMaxDropMeters = 150.0
+
+// PremisesStatus is how a premises stands against the ODPs around it
+type PremisesStatus string
+
+const (
+	PremisesConnected   PremisesStatus = "CONNECTED"    // A customer is on the network there
+	PremisesServiceable PremisesStatus = "SERVICEABLE"  // An ODP with free ports is within reach
+	PremisesNoCapacity  PremisesStatus = "NO_CAPACITY"  // Only full ODPs are within reach
+	PremisesOutOfReach  PremisesStatus = "OUT_OF_REACH" // No ODP is within reach
+)
+
+// Premises is a home or business to check for coverage
+type Premises struct {
+	Ref       *string `json:"ref,omitempty"`     // Caller's identifier, echoed in the results
+	NodeID    *int64  `json:"node_id,omitempty"` // CUSTOMER node at the premises, if any
+	Name      *string `json:"name,omitempty"`
+	Latitude  float64 `json:"latitude" validate:"required,latitude"`
+	Longitude float64 `json:"longitude" validate:"required,longitude"`
+}
+
+// CoverageArea is a named polygon to total coverage by
+type CoverageArea struct {
+	Name    string      `json:"name" validate:"required"`
+	Polygon [][]float64 `json:"polygon" validate:"required,min=3"` // [[lng, lat], ...]
+}
+
+// CoverageRequest represents the request body for a coverage analysis. Without premises, every
+// CUSTOMER node is checked.
+type CoverageRequest struct {
+	Premises       []Premises     `json:"premises,omitempty"`
+	MaxDropMeters  float64        `json:"max_drop_meters,omitempty"` // Defaults to DefaultMaxDropMeters
+	IncludePlanned bool           `json:"include_planned,omitempty"` // Count ODPs with status PLAN
+	Areas          []CoverageArea `json:"areas,omitempty"`
+}
+
+// PremisesCoverage is the result for one premises
+type PremisesCoverage struct {
+	Premises
+	Status     PremisesStatus `json:"status"`
+	ODPID      *int64         `json:"odp_id,omitempty"` // The feeding ODP, or the nearest usable one
+	ODPName    *string        `json:"odp_name,omitempty"`
+	DropMeters *float64       `json:"drop_meters,omitempty"` // Straight-line distance to the ODP
+	Area       *string        `json:"area,omitempty"`
+}
+
+// ODPCoverage totals the premises an ODP passes and connects
+type ODPCoverage struct {
+	NodeID         int64   `json:"node_id"`
+	Name           string  `json:"name"`
+	Latitude       float64 `json:"latitude"`
+	Longitude      float64 `json:"longitude"`
+	CapacityPorts  int     `json:"capacity_ports"`
+	UsedPorts      int     `json:"used_ports"`
+	FreePorts      int     `json:"free_ports"`
+	HomesPassed    int     `json:"homes_passed"`    // Premises within reach
+	HomesConnected int     `json:"homes_connected"` // Connected premises it feeds
+	Demand         int     `json:"demand"`          // Serviceable premises nearest to it
+	Shortfall      int     `json:"shortfall"`       // Demand beyond its free ports
+}
+
+// AreaCoverage totals the premises inside a coverage area
+type AreaCoverage struct {
+	Name           string `json:"name"`
+	Premises       int    `json:"premises"`
+	HomesPassed    int    `json:"homes_passed"`
+	HomesConnected int    `json:"homes_connected"`
+	Serviceable    int    `json:"serviceable"`
+	NoCapacity     int    `json:"no_capacity"`
+	OutOfReach     int    `json:"out_of_reach"`
+}
+
+// CoverageReport is the result of a coverage analysis. Homes passed counts every premises within
+// reach of an ODP, connected or not; the penetration rate is connected over passed.
+type CoverageReport struct {
+	MaxDropMeters  float64                  `json:"max_drop_meters"`
+	Premises       int                      `json:"premises"`
+	HomesPassed    int                      `json:"homes_passed"`
+	HomesConnected int                      `json:"homes_connected"`
+	Serviceable    int                      `json:"serviceable"`
+	NoCapacity     int                      `json:"no_capacity"`
+	OutOfReach     int                      `json:"out_of_reach"`
+	Penetration    float64                  `json:"penetration"`
+	ODPs           []ODPCoverage            `json:"odps"`
+	Areas          []AreaCoverage           `json:"areas,omitempty"`
+	Results        []PremisesCoverage       `json:"results"`
+	GeoJSON        GeoJSONFeatureCollection `json:"geojson"`
+}
@@ -29,7 +29,8 @@ type Customer struct {
 	UpdatedAt        time.Time      `json:"updated_at" db:"updated_at"`
 
 	// Joined data
-	Node *Node `json:"node,omitempty" db:"-"`
+	Node                *Node  `json:"node,omitempty" db:"-"`
+	MaintenanceWindowID *int64 `json:"maintenance_window_id,omitempty" db:"-"` // Active window the customer is inside, on status lists
 }
 
 // CreateCustomerRequest represents the request body for creating a customer
@@ -59,7 +60,8 @@ type UpdateCustomerRequest struct {
 type CustomerFilter struct {
 	NodeID        *int64          `json:"node_id,omitempty"`
 	CurrentStatus *CustomerStatus `json:"current_status,omitempty"`
-	Search        *string         `json:"search,omitempty"` // Search by name or ONT SN
+	AreaID        *int64          `json:"area_id,omitempty"` // Customers at nodes in the area
+	Search        *string         `json:"search,omitempty"`  // Search by name or ONT SN
 	Limit         int             `json:"limit,omitempty"`
 	Offset        int             `json:"offset,omitempty"`
 }
@@ -0,0 +1,45 @@
+package models
+
+// DefaultMaxSpanMeters is the longest aerial drop span between two supports assumed when none is given
+const DefaultMaxSpanMeters = 50.0
+
+// DropRoute is a proposed drop cable route from a node to a premises
+type DropRoute struct {
+	FromNodeID        int64       `json:"from_node_id"`
+	PoleIDs           []int64     `json:"pole_ids"`         // Poles followed in order, empty for a direct drop
+	PathCoordinates   [][]float64 `json:"path_coordinates"` // [[lng, lat], ...] from the node to the premises
+	LengthMeter       float64     `json:"length_meter"`
+	LongestSpanMeter  float64     `json:"longest_span_meter"`
+	FollowsPoles      bool        `json:"follows_poles"`
+	WithinSpanLimit   bool        `json:"within_span_limit"` // Every span is within the max span length
+	StraightLineMeter float64     `json:"straight_line_meter"`
+}
+
+// DefaultDropCoreCount is the core count of a designed drop cable when none is given
+const DefaultDropCoreCount = 1
+
+// DropDesignRequest represents the request body for designing a drop cable from an ODP to a
+// customer node. ODP ports are numbered from 1 to the ODP's capacity_ports.
+type DropDesignRequest struct {
+	ODPID          int64   `json:"odp_id" validate:"required"`
+	CustomerNodeID int64   `json:"customer_node_id" validate:"required"`
+	MaxSpanMeters  float64 `json:"max_span_meters,omitempty"` // Defaults to DefaultMaxSpanMeters
+	Port           *int    `json:"port,omitempty"`            // Defaults to the lowest free port
+	CoreCount      int     `json:"core_count,omitempty"`      // Defaults to DefaultDropCoreCount
+	Name           *string `json:"name,omitempty"`
+}
+
+// DropDesign is a drop cable proposed between an ODP and a customer node. Once committed it also
+// holds the cable created and the connection of its first core to the ODP port.
+type DropDesign struct {
+	ODP          Node               `json:"odp"`
+	CustomerNode Node               `json:"customer_node"`
+	Route        DropRoute          `json:"route"`
+	Port         int                `json:"port"`
+	FreePorts    []int              `json:"free_ports"`
+	Cable        CreateCableRequest `json:"cable"` // The cable to create
+	Warnings     []string           `json:"warnings"`
+	Committed    bool               `json:"committed"`
+	CreatedCable *Cable             `json:"created_cable,omitempty"`
+	Connection   *Connection        `json:"connection,omitempty"`
+}
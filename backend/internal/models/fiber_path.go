@@ -0,0 +1,70 @@
+package models
+
+// FiberPathMetric is what the path finder minimises
+type FiberPathMetric string
+
+const (
+	FiberPathMetricDistance FiberPathMetric = "DISTANCE"
+	FiberPathMetricLoss     FiberPathMetric = "LOSS"
+)
+
+// DefaultAttenuationDBPerKm is the fiber attenuation assumed when none is given (1310 nm, G.652)
+const DefaultAttenuationDBPerKm = 0.35
+
+// SpliceableNodeTypes are the node types where a new splice may join two cores
+var SpliceableNodeTypes = map[NodeType]bool{
+	NodeTypeODC:     true,
+	NodeTypeODP:     true,
+	NodeTypeClosure: true,
+}
+
+// FiberPathRequest represents the request body for finding a path of vacant cores between two nodes
+type FiberPathRequest struct {
+	FromNodeID         int64               `json:"from_node_id" validate:"required"`
+	ToNodeID           int64               `json:"to_node_id" validate:"required"`
+	Metric             FiberPathMetric     `json:"metric,omitempty" validate:"omitempty,oneof=DISTANCE LOSS"`
+	AttenuationDBPerKm *float64            `json:"attenuation_db_per_km,omitempty"`
+	SpliceLossDB       *float64            `json:"splice_loss_db,omitempty"` // Loss of each new splice
+	Reserve            *ReservationDetails `json:"reserve,omitempty"`        // Reserve the cores in one transaction
+}
+
+// FiberPathSplice joins a hop to the previous one
+type FiberPathSplice struct {
+	NodeID       int64   `json:"node_id"`
+	ConnectionID *int64  `json:"connection_id,omitempty"` // Set when the splice already exists
+	New          bool    `json:"new"`
+	LossDB       float64 `json:"loss_db"`
+}
+
+// FiberPathHop is one core segment of a fiber path
+type FiberPathHop struct {
+	Sequence           int              `json:"sequence"`
+	CoreID             int64            `json:"core_id"`
+	CoreIndex          int              `json:"core_index"`
+	CableID            int64            `json:"cable_id"`
+	CableName          *string          `json:"cable_name,omitempty"`
+	FromNodeID         int64            `json:"from_node_id"`
+	FromNodeName       string           `json:"from_node_name"`
+	ToNodeID           int64            `json:"to_node_id"`
+	ToNodeName         string           `json:"to_node_name"`
+	PassThroughNodeIDs []int64          `json:"pass_through_node_ids"`
+	LengthMeter        float64          `json:"length_meter"`
+	FiberLossDB        float64          `json:"fiber_loss_db"`
+	Splice             *FiberPathSplice `json:"splice,omitempty"` // Splice at FromNodeID, absent on the first hop
+}
+
+// FiberPath is a core-by-core plan between two nodes with its predicted loss
+type FiberPath struct {
+	FromNodeID       int64           `json:"from_node_id"`
+	ToNodeID         int64           `json:"to_node_id"`
+	Metric           FiberPathMetric `json:"metric"`
+	Hops             []FiberPathHop  `json:"hops"`
+	CoreIDs          []int64         `json:"core_ids"`
+	SpliceCount      int             `json:"splice_count"`
+	NewSpliceCount   int             `json:"new_splice_count"`
+	TotalLengthMeter float64         `json:"total_length_meter"`
+	FiberLossDB      float64         `json:"fiber_loss_db"`
+	SpliceLossDB     float64         `json:"splice_loss_db"`
+	TotalLossDB      float64         `json:"total_loss_db"`
+	Reservations     []Reservation   `json:"reservations,omitempty"`
+}
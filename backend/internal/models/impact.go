@@ -0,0 +1,63 @@
+package models
+
+// ImpactRequest lists the network elements assumed to have failed
+type ImpactRequest struct {
+	CableIDs []int64 `json:"cable_ids,omitempty"`
+	CoreIDs  []int64 `json:"core_ids,omitempty"`
+	NodeIDs  []int64 `json:"node_ids,omitempty"`
+}
+
+// ImpactCustomer is a customer that loses service, with the contact details needed to notify them
+type ImpactCustomer struct {
+	ID               int64          `json:"id"`
+	Name             string         `json:"name"`
+	Phone            *string        `json:"phone,omitempty"`
+	Email            *string        `json:"email,omitempty"`
+	ONTSN            *string        `json:"ont_sn,omitempty"`
+	SubscriptionType *string        `json:"subscription_type,omitempty"`
+	CurrentStatus    CustomerStatus `json:"current_status"`
+	NodeID           int64          `json:"node_id"`
+	NodeName         string         `json:"node_name"`
+
+	// Set when the customer is inside an active maintenance window, so the loss is expected
+	MaintenanceWindowID *int64 `json:"maintenance_window_id,omitempty"`
+}
+
+// ImpactCable is a cable with cores that fail or lose their feed
+type ImpactCable struct {
+	CableID       int64     `json:"cable_id"`
+	Name          *string   `json:"name,omitempty"`
+	Type          CableType `json:"type"`
+	Failed        bool      `json:"failed"` // The whole cable is down
+	AffectedCores int       `json:"affected_cores"`
+}
+
+// ImpactSplice is a connection on an affected fiber path
+type ImpactSplice struct {
+	ConnectionID     int64          `json:"connection_id"`
+	LocationNodeID   *int64         `json:"location_node_id,omitempty"`
+	LocationNodeName *string        `json:"location_node_name,omitempty"`
+	InputType        ConnectionType `json:"input_type"`
+	InputID          int64          `json:"input_id"`
+	OutputType       ConnectionType `json:"output_type"`
+	OutputID         int64          `json:"output_id"`
+	LossDB           *float64       `json:"loss_db,omitempty"`
+}
+
+// ImpactReport describes what breaks when the requested elements fail
+type ImpactReport struct {
+	Request                 ImpactRequest            `json:"request"`
+	FailedCableIDs          []int64                  `json:"failed_cable_ids"`
+	Cables                  []ImpactCable            `json:"cables"`
+	AffectedODCs            []Node                   `json:"affected_odcs"`
+	AffectedODPs            []Node                   `json:"affected_odps"`
+	Customers               []ImpactCustomer         `json:"customers"`
+	CustomerCount           int                      `json:"customer_count"`
+	MaintenanceCustomers    int                      `json:"maintenance_customers"` // Customers already down for planned maintenance
+	CustomersBySubscription map[string]int           `json:"customers_by_subscription"`
+	Splices                 []ImpactSplice           `json:"splices"`
+	GeoJSON                 GeoJSONFeatureCollection `json:"geojson"`
+}
+
+// UnspecifiedSubscription groups customers without a subscription type in impact counts
+const UnspecifiedSubscription = "UNSPECIFIED"
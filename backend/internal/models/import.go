@@ -0,0 +1,110 @@
+package models
+
+// ImportAction represents what an import did (or would do) with a source item
+type ImportAction string
+
+const (
+	ImportActionCreate ImportAction = "CREATE"
+	ImportActionUpdate ImportAction = "UPDATE"
+	ImportActionSkip   ImportAction = "SKIP"
+	ImportActionError  ImportAction = "ERROR"
+)
+
+// ImportItemKind represents the kind of plant item being imported
+type ImportItemKind string
+
+const (
+	ImportItemNode     ImportItemKind = "NODE"
+	ImportItemCable    ImportItemKind = "CABLE"
+	ImportItemCustomer ImportItemKind = "CUSTOMER"
+)
+
+// DefaultSnapToleranceMeters is how far a cable end may be from a node and still be attached to it
+const DefaultSnapToleranceMeters = 25.0
+
+// ImportNode is a node parsed from an import file
+type ImportNode struct {
+	// Ref identifies the source item in the report, e.g. "#12 ODP-01"
+	Ref string
+	// ExternalID, when set, makes the import update the node previously imported with it
+	ExternalID *string
+	Node       CreateNodeRequest
+}
+
+// ImportCable is a cable parsed from an import file.
+// Origin and destination are resolved from the node external IDs, or else by snapping
+// the path ends to nodes, when not set.
+type ImportCable struct {
+	Ref              string
+	ExternalID       *string
+	OriginExternalID *string
+	DestExternalID   *string
+	Cable            CreateCableRequest
+}
+
+// CustomerImportRow is a spreadsheet row mapped to a customer; the serving ODP is referenced by name
+type CustomerImportRow struct {
+	Ref      string
+	ODPName  string
+	Customer CreateCustomerRequest
+}
+
+// ImportPlan is the format-neutral result of parsing an import file
+type ImportPlan struct {
+	Format string
+	Nodes  []ImportNode
+	Cables []ImportCable
+	// Skipped holds source items the parser could not map to plant items
+	Skipped []ImportItemResult
+}
+
+// ImportOptions controls how an import plan is applied
+type ImportOptions struct {
+	// DryRun runs the import and rolls it back, reporting what would change
+	DryRun              bool
+	SnapToleranceMeters float64
+}
+
+// ImportItemResult reports the outcome for a single source item
+type ImportItemResult struct {
+	Ref        string         `json:"ref"`
+	Kind       ImportItemKind `json:"kind"`
+	Action     ImportAction   `json:"action"`
+	Name       string         `json:"name,omitempty"`
+	Type       string         `json:"type,omitempty"`
+	ExternalID *string        `json:"external_id,omitempty"`
+	ID         *int64         `json:"id,omitempty"` // Omitted for items created on dry runs
+	Message    string         `json:"message,omitempty"`
+
+	// Snapped cable ends
+	OriginNode *string `json:"origin_node,omitempty"`
+	DestNode   *string `json:"dest_node,omitempty"`
+}
+
+// ImportReport summarizes an import or dry run
+type ImportReport struct {
+	Format string `json:"format"`
+	DryRun bool   `json:"dry_run"`
+	// Committed is false for dry runs and for all-or-nothing imports rejected by invalid rows
+	Committed bool               `json:"committed"`
+	Created   int                `json:"created"`
+	Updated   int                `json:"updated"`
+	Skipped   int                `json:"skipped"`
+	Failed    int                `json:"failed"`
+	Items     []ImportItemResult `json:"items"`
+}
+
+// Add records an item result and updates the counters
+func (r *ImportReport) Add(item ImportItemResult) {
+	switch item.Action {
+	case ImportActionCreate:
+		r.Created++
+	case ImportActionUpdate:
+		r.Updated++
+	case ImportActionSkip:
+		r.Skipped++
+	case ImportActionError:
+		r.Failed++
+	}
+	r.Items = append(r.Items, item)
+}
@@ -0,0 +1,123 @@
+package models
+
+import "time"
+
+// MaintenanceStatus represents where a maintenance window is in its lifecycle
+type MaintenanceStatus string
+
+const (
+	MaintenanceStatusScheduled MaintenanceStatus = "SCHEDULED"
+	MaintenanceStatusActive    MaintenanceStatus = "ACTIVE"
+	MaintenanceStatusCompleted MaintenanceStatus = "COMPLETED"
+	MaintenanceStatusCancelled MaintenanceStatus = "CANCELLED"
+)
+
+// IsValid reports whether the maintenance status is one of the known statuses
+func (s MaintenanceStatus) IsValid() bool {
+	switch s {
+	case MaintenanceStatusScheduled, MaintenanceStatusActive, MaintenanceStatusCompleted, MaintenanceStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// MaintenanceAssetType is the kind of element taken down for maintenance
+type MaintenanceAssetType string
+
+const (
+	MaintenanceAssetNode  MaintenanceAssetType = "NODE"
+	MaintenanceAssetCable MaintenanceAssetType = "CABLE"
+)
+
+// IsValid reports whether the asset type is one of the known types
+func (t MaintenanceAssetType) IsValid() bool {
+	return t == MaintenanceAssetNode || t == MaintenanceAssetCable
+}
+
+// MaintenanceWindow is planned downtime on nodes and cables. While it is ACTIVE the assets are
+// flagged MAINTENANCE and their downstream customers are expected to lose service.
+type MaintenanceWindow struct {
+	ID          int64             `json:"id" db:"id"`
+	Title       string            `json:"title" db:"title"`
+	Description *string           `json:"description,omitempty" db:"description"`
+	WorkOrderID *int64            `json:"work_order_id,omitempty" db:"work_order_id"`
+	Status      MaintenanceStatus `json:"status" db:"status"`
+	StartsAt    time.Time         `json:"starts_at" db:"starts_at"`
+	EndsAt      time.Time         `json:"ends_at" db:"ends_at"`
+	StartedAt   *time.Time        `json:"started_at,omitempty" db:"started_at"`
+	EndedAt     *time.Time        `json:"ended_at,omitempty" db:"ended_at"`
+	CreatedBy   *string           `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt   time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at" db:"updated_at"`
+
+	// Joined data
+	CustomerCount int                `json:"customer_count" db:"-"`
+	Assets        []MaintenanceAsset `json:"assets,omitempty" db:"-"`
+	Customers     []ImpactCustomer   `json:"customers,omitempty" db:"-"` // For pre-notification
+}
+
+// ImpactRequest returns the assets of the window as failed elements for impact analysis
+func (w *MaintenanceWindow) ImpactRequest() *ImpactRequest {
+	req := &ImpactRequest{}
+	for _, a := range w.Assets {
+		switch a.ElementType {
+		case MaintenanceAssetNode:
+			req.NodeIDs = append(req.NodeIDs, a.ElementID)
+		case MaintenanceAssetCable:
+			req.CableIDs = append(req.CableIDs, a.ElementID)
+		}
+	}
+	return req
+}
+
+// MaintenanceAsset is a node or cable covered by a maintenance window
+type MaintenanceAsset struct {
+	ID             int64                `json:"id" db:"id"`
+	WindowID       int64                `json:"window_id" db:"window_id"`
+	ElementType    MaintenanceAssetType `json:"element_type" db:"element_type"`
+	ElementID      int64                `json:"element_id" db:"element_id"`
+	PreviousStatus *string              `json:"previous_status,omitempty" db:"previous_status"` // Restored when the window ends
+
+	// Joined data
+	Name *string `json:"name,omitempty" db:"-"`
+}
+
+// MaintenanceAssetRequest identifies a node or cable in a request
+type MaintenanceAssetRequest struct {
+	ElementType MaintenanceAssetType `json:"element_type" validate:"required,oneof=NODE CABLE"`
+	ElementID   int64                `json:"element_id" validate:"required"`
+}
+
+// CreateMaintenanceWindowRequest represents the request body for scheduling a maintenance window
+type CreateMaintenanceWindowRequest struct {
+	Title       string                    `json:"title" validate:"required,min=1,max=200"`
+	Description *string                   `json:"description,omitempty"`
+	WorkOrderID *int64                    `json:"work_order_id,omitempty"`
+	StartsAt    time.Time                 `json:"starts_at" validate:"required"`
+	EndsAt      time.Time                 `json:"ends_at" validate:"required,gtfield=StartsAt"`
+	CreatedBy   *string                   `json:"created_by,omitempty" validate:"omitempty,max=100"`
+	Assets      []MaintenanceAssetRequest `json:"assets" validate:"required,min=1"`
+}
+
+// UpdateMaintenanceWindowRequest represents the request body for editing a scheduled window.
+// Assets replace the existing ones when present.
+type UpdateMaintenanceWindowRequest struct {
+	Title       *string                   `json:"title,omitempty" validate:"omitempty,min=1,max=200"`
+	Description *string                   `json:"description,omitempty"`
+	StartsAt    *time.Time                `json:"starts_at,omitempty"`
+	EndsAt      *time.Time                `json:"ends_at,omitempty"`
+	Assets      []MaintenanceAssetRequest `json:"assets,omitempty"`
+}
+
+// MaintenanceWindowFilter represents filter options for listing maintenance windows
+type MaintenanceWindowFilter struct {
+	Status      *MaintenanceStatus       `json:"status,omitempty"`
+	From        *time.Time               `json:"from,omitempty"` // Windows ending at or after
+	To          *time.Time               `json:"to,omitempty"`   // Windows starting at or before
+	Asset       *MaintenanceAssetRequest `json:"asset,omitempty"`
+	CustomerID  *int64                   `json:"customer_id,omitempty"`
+	WorkOrderID *int64                   `json:"work_order_id,omitempty"`
+	AreaID      *int64                   `json:"area_id,omitempty"` // Windows affecting plant or customers in the area
+	Limit       int                      `json:"limit,omitempty"`
+	Offset      int                      `json:"offset,omitempty"`
+}
@@ -26,6 +26,30 @@ const (
 	NodeStatusInactive    NodeStatus = "INACTIVE"
 )
 
+// AllNodeTypes lists node types in display order
+var AllNodeTypes = []NodeType{
+	NodeTypeOLT, NodeTypeODC, NodeTypeODP, NodeTypeClosure, NodeTypePole, NodeTypeCustomer,
+}
+
+// IsValid reports whether the node type is one of the known types
+func (t NodeType) IsValid() bool {
+	for _, known := range AllNodeTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValid reports whether the node status is one of the known statuses
+func (s NodeStatus) IsValid() bool {
+	switch s {
+	case NodeStatusActive, NodeStatusMaintenance, NodeStatusPlan, NodeStatusInactive:
+		return true
+	}
+	return false
+}
+
 // Node represents a network infrastructure point (OLT, ODC, ODP, etc.)
 type Node struct {
 	ID            int64      `json:"id" db:"id"`
@@ -38,6 +62,7 @@ type Node struct {
 	UsedPorts     int        `json:"used_ports" db:"used_ports"`
 	Model         *string    `json:"model,omitempty" db:"model"`
 	Status        NodeStatus `json:"status" db:"status"`
+	AreaID        *int64     `json:"area_id,omitempty" db:"area_id"` // Deepest service area containing the node
 	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
 }
@@ -71,6 +96,7 @@ type UpdateNodeRequest struct {
 type NodeFilter struct {
 	Type   *NodeType   `json:"type,omitempty"`
 	Status *NodeStatus `json:"status,omitempty"`
+	AreaID *int64      `json:"area_id,omitempty"` // Nodes in the area or its sub-areas
 	Limit  int         `json:"limit,omitempty"`
 	Offset int         `json:"offset,omitempty"`
 }
@@ -113,6 +139,9 @@ func (n *Node) ToGeoJSON() NodeGeoJSON {
 	if n.Model != nil {
 		properties["model"] = *n.Model
 	}
+	if n.AreaID != nil {
+		properties["area_id"] = *n.AreaID
+	}
 
 	return NodeGeoJSON{
 		Type: "Feature",
@@ -0,0 +1,183 @@
+package models
+
+import "time"
+
+// OTDREventType is what an OTDR key event is taken to be
+type OTDREventType string
+
+const (
+	OTDREventSplice    OTDREventType = "SPLICE"    // Non-reflective: fusion splice, bend
+	OTDREventConnector OTDREventType = "CONNECTOR" // Reflective: connector, mechanical splice
+	OTDREventEnd       OTDREventType = "END"       // End of fiber or break
+)
+
+const (
+	// DefaultOTDRSlackPct is the extra fiber per meter of cable assumed when none is given:
+	// fiber helix in the tubes plus sag and snaking of the cable
+	DefaultOTDRSlackPct = 2.0
+	// OTDRMatchToleranceMeter is how far an event may be from a splice and still be taken as it
+	OTDRMatchToleranceMeter = 25.0
+	// OTDRLossToleranceDB is how much a splice may measure above its recorded loss before it is flagged
+	OTDRLossToleranceDB = 0.1
+)
+
+// OTDRTrace is an uploaded OTDR measurement of a core, shot from one of its end nodes
+type OTDRTrace struct {
+	ID               int64      `json:"id" db:"id"`
+	CoreID           int64      `json:"core_id" db:"core_id"`
+	FromNodeID       int64      `json:"from_node_id" db:"from_node_id"` // Where the OTDR was connected
+	FileName         *string    `json:"file_name,omitempty" db:"file_name"`
+	SORVersion       int        `json:"sor_version" db:"sor_version"`
+	WavelengthNM     float64    `json:"wavelength_nm" db:"wavelength_nm"`
+	PulseWidthNS     *int       `json:"pulse_width_ns,omitempty" db:"pulse_width_ns"`
+	GroupIndex       float64    `json:"group_index" db:"group_index"`
+	FiberLengthMeter float64    `json:"fiber_length_meter" db:"fiber_length_meter"` // Distance of the end event
+	TotalLossDB      float64    `json:"total_loss_db" db:"total_loss_db"`
+	ORLDB            *float64   `json:"orl_db,omitempty" db:"orl_db"`
+	AcquiredAt       *time.Time `json:"acquired_at,omitempty" db:"acquired_at"`
+	Supplier         *string    `json:"supplier,omitempty" db:"supplier"`
+	OTDRModel        *string    `json:"otdr_model,omitempty" db:"otdr_model"`
+	OTDRSerial       *string    `json:"otdr_serial,omitempty" db:"otdr_serial"`
+	CableLabel       *string    `json:"cable_label,omitempty" db:"cable_label"` // Cable ID typed on the instrument
+	FiberLabel       *string    `json:"fiber_label,omitempty" db:"fiber_label"`
+	LocationA        *string    `json:"location_a,omitempty" db:"location_a"`
+	LocationB        *string    `json:"location_b,omitempty" db:"location_b"`
+	Operator         *string    `json:"operator,omitempty" db:"operator"`
+	SlackPct         float64    `json:"slack_pct" db:"slack_pct"`
+	NodeSlackMeter   float64    `json:"node_slack_meter" db:"node_slack_meter"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+
+	// Joined data
+	CableID    int64       `json:"cable_id" db:"-"`
+	CoreIndex  int         `json:"core_index" db:"-"`
+	EventCount int         `json:"event_count" db:"-"`
+	Events     []OTDREvent `json:"events,omitempty" db:"-"`
+}
+
+// OTDREvent is a key event of a trace
+type OTDREvent struct {
+	ID                 int64         `json:"id" db:"id"`
+	TraceID            int64         `json:"trace_id" db:"trace_id"`
+	EventNumber        int           `json:"event_number" db:"event_number"`
+	Type               OTDREventType `json:"type" db:"type"`
+	Code               string        `json:"code" db:"code"` // Event code as stored by the instrument
+	DistanceMeter      float64       `json:"distance_meter" db:"distance_meter"`
+	LossDB             float64       `json:"loss_db" db:"loss_db"`
+	ReflectanceDB      *float64      `json:"reflectance_db,omitempty" db:"reflectance_db"`
+	AttenuationDBPerKm *float64      `json:"attenuation_db_per_km,omitempty" db:"attenuation_db_per_km"`
+	Comment            *string       `json:"comment,omitempty" db:"comment"`
+}
+
+// OTDRTraceFilter represents filter options for listing traces
+type OTDRTraceFilter struct {
+	CoreID  *int64 `json:"core_id,omitempty"`
+	CableID *int64 `json:"cable_id,omitempty"`
+	AreaID  *int64 `json:"area_id,omitempty"` // Traces on cables in the area
+	Limit   int    `json:"limit,omitempty"`
+	Offset  int    `json:"offset,omitempty"`
+}
+
+// FiberSlack describes how much more fiber there is than cable route.
+// Fiber length is cable length × (1 + slack_pct/100), plus a coil of node_slack_meter at every node
+// passed or spliced at, with the node taken to be in the middle of its coil.
+type FiberSlack struct {
+	SlackPct       *float64 `json:"slack_pct,omitempty"`
+	NodeSlackMeter *float64 `json:"node_slack_meter,omitempty"`
+}
+
+// OTDRLocateRequest asks where an optical distance measured from a node falls on the plant
+type OTDRLocateRequest struct {
+	FiberSlack
+	CoreID        int64   `json:"core_id" validate:"required"`
+	FromNodeID    int64   `json:"from_node_id" validate:"required"`
+	DistanceMeter float64 `json:"distance_meter" validate:"required"`
+}
+
+// FiberLocation is where an optical distance falls along a fiber route
+type FiberLocation struct {
+	OpticalDistanceMeter     float64 `json:"optical_distance_meter"`
+	CableDistanceMeter       float64 `json:"cable_distance_meter"` // Along the route, slack removed
+	CoreID                   int64   `json:"core_id,omitempty"`    // Absent when locating along a cable
+	CableID                  int64   `json:"cable_id"`
+	CableName                *string `json:"cable_name,omitempty"`
+	SpanFromNodeID           int64   `json:"span_from_node_id,omitempty"` // Span the point falls in, in shooting direction
+	SpanToNodeID             int64   `json:"span_to_node_id,omitempty"`   // Node IDs are absent on a cable without end nodes
+	NearestNodeID            int64   `json:"nearest_node_id,omitempty"`
+	NearestNodeName          string  `json:"nearest_node_name,omitempty"`
+	NearestNodeDistanceMeter float64 `json:"nearest_node_distance_meter"` // Cable meters to the nearest node
+	Latitude                 float64 `json:"latitude"`
+	Longitude                float64 `json:"longitude"`
+	BeyondEnd                bool    `json:"beyond_end"` // Past the end of the route; placed at its last node
+}
+
+// CableEnd names the end of a cable that distances are measured from
+type CableEnd string
+
+const (
+	CableEndOrigin      CableEnd = "ORIGIN"
+	CableEndDestination CableEnd = "DESTINATION"
+)
+
+// CableLocateRequest asks where a distance along a cable falls
+type CableLocateRequest struct {
+	FiberSlack
+	CableID       int64    `json:"cable_id" validate:"required"`
+	DistanceMeter float64  `json:"distance_meter" validate:"required"`
+	From          CableEnd `json:"from,omitempty" validate:"omitempty,oneof=ORIGIN DESTINATION"`
+	Optical       bool     `json:"optical,omitempty"` // The distance is fiber length, e.g. read off an OTDR
+}
+
+// CableMeasure is where a point projects onto a cable, measured from both ends
+type CableMeasure struct {
+	CableID                     int64   `json:"cable_id"`
+	Latitude                    float64 `json:"latitude"` // Point on the cable nearest to the query
+	Longitude                   float64 `json:"longitude"`
+	OffsetMeter                 float64 `json:"offset_meter"` // From the query point to the cable
+	FromOriginMeter             float64 `json:"from_origin_meter"`
+	FromDestinationMeter        float64 `json:"from_destination_meter"`
+	OpticalFromOriginMeter      float64 `json:"optical_from_origin_meter"`
+	OpticalFromDestinationMeter float64 `json:"optical_from_destination_meter"`
+	LengthMeter                 float64 `json:"length_meter"`
+	OpticalLengthMeter          float64 `json:"optical_length_meter"`
+	SpanFromNodeID              int64   `json:"span_from_node_id,omitempty"` // Span the point falls in, origin first
+	SpanToNodeID                int64   `json:"span_to_node_id,omitempty"`   // Node IDs are absent on a cable without end nodes
+	NearestNodeID               int64   `json:"nearest_node_id,omitempty"`
+	NearestNodeName             string  `json:"nearest_node_name,omitempty"`
+	NearestNodeDistanceMeter    float64 `json:"nearest_node_distance_meter"`
+}
+
+// OTDREventMatch is a trace event placed on the plant
+type OTDREventMatch struct {
+	OTDREvent
+	Location     *FiberLocation `json:"location,omitempty"`
+	ConnectionID *int64         `json:"connection_id,omitempty"` // Splice the event was matched to
+	Expected     bool           `json:"expected"`                // A known splice, the launch or the route end
+}
+
+// OTDRSpliceCheck compares the loss recorded on a splice with what the trace measured
+type OTDRSpliceCheck struct {
+	ConnectionID         int64    `json:"connection_id"`
+	NodeID               int64    `json:"node_id"`
+	NodeName             string   `json:"node_name"`
+	OpticalDistanceMeter float64  `json:"optical_distance_meter"` // Where the splice should show
+	ExpectedLossDB       *float64 `json:"expected_loss_db,omitempty"`
+	EventNumber          *int     `json:"event_number,omitempty"` // Absent when the splice is below the trace threshold
+	MeasuredLossDB       *float64 `json:"measured_loss_db,omitempty"`
+	LossDeltaDB          *float64 `json:"loss_delta_db,omitempty"`
+	ExceedsExpected      bool     `json:"exceeds_expected"`
+}
+
+// OTDRAnalysis lays a trace over the recorded route of its fiber
+type OTDRAnalysis struct {
+	Trace                    OTDRTrace                `json:"trace"`
+	SlackPct                 float64                  `json:"slack_pct"`
+	NodeSlackMeter           float64                  `json:"node_slack_meter"`
+	RouteCoreIDs             []int64                  `json:"route_core_ids"` // Cores followed across splices
+	RouteLengthMeter         float64                  `json:"route_length_meter"`
+	ExpectedFiberLengthMeter float64                  `json:"expected_fiber_length_meter"`
+	Events                   []OTDREventMatch         `json:"events"`
+	Splices                  []OTDRSpliceCheck        `json:"splices"`
+	Break                    *FiberLocation           `json:"break,omitempty"` // Set when the fiber ends short of the route
+	Summary                  string                   `json:"summary"`
+	GeoJSON                  GeoJSONFeatureCollection `json:"geojson"`
+}
@@ -0,0 +1,65 @@
+package models
+
+// CableRef identifies a cable in analysis reports
+type CableRef struct {
+	CableID int64     `json:"cable_id"`
+	Name    *string   `json:"name,omitempty"`
+	Type    CableType `json:"type"`
+}
+
+// FiberPathTrace is the physical footprint of a traced fiber path
+type FiberPathTrace struct {
+	CoreIDs         []int64 `json:"core_ids"`
+	CableIDs        []int64 `json:"cable_ids"`
+	NodeIDs         []int64 `json:"node_ids"`
+	TerminalNodeIDs []int64 `json:"terminal_node_ids"` // Where the path ends
+	LengthMeter     float64 `json:"length_meter"`
+}
+
+// SharedSpan is a stretch between two adjacent nodes that both paths run along,
+// whether in the same cable or in different cables on the same poles or duct
+type SharedSpan struct {
+	FromNodeID int64   `json:"from_node_id"`
+	ToNodeID   int64   `json:"to_node_id"`
+	CableIDsA  []int64 `json:"cable_ids_a"`
+	CableIDsB  []int64 `json:"cable_ids_b"`
+}
+
+// SharedNode is a node that both paths pass through or splice at
+type SharedNode struct {
+	NodeID   int64    `json:"node_id"`
+	Name     string   `json:"name"`
+	Type     NodeType `json:"type"`
+	Terminal bool     `json:"terminal"` // Both paths end here, so sharing it is expected
+}
+
+// DiversityReport compares a working and a protection path
+type DiversityReport struct {
+	CustomerID     *int64           `json:"customer_id,omitempty"`
+	Feeds          []FiberPathTrace `json:"feeds,omitempty"` // Customer mode: every traced path reaching the customer's node
+	PathA          *FiberPathTrace  `json:"path_a"`
+	PathB          *FiberPathTrace  `json:"path_b"`
+	SharedCables   []CableRef       `json:"shared_cables"`
+	SharedSpans    []SharedSpan     `json:"shared_spans"`
+	SharedPoles    []SharedNode     `json:"shared_poles"`
+	SharedClosures []SharedNode     `json:"shared_closures"` // Closures, cabinets and other splice points
+	Diverse        bool             `json:"diverse"`
+	Summary        string           `json:"summary"`
+}
+
+// FeedDependency is an ODC whose whole feed runs through a single cable
+type FeedDependency struct {
+	ODC           Node       `json:"odc"`
+	Cables        []CableRef `json:"cables"` // Each of these alone cuts the ODC off
+	CustomerCount int        `json:"customer_count"`
+}
+
+// FeedReport lists ODCs with a single point of failure in their feed
+type FeedReport struct {
+	ODCCount          int              `json:"odc_count"`
+	SinglyFedCount    int              `json:"singly_fed_count"`
+	UnfedCount        int              `json:"unfed_count"`
+	SinglyFed         []FeedDependency `json:"singly_fed"`
+	Unfed             []Node           `json:"unfed"` // No lit cable path to any OLT
+	RedundantODCCount int              `json:"redundant_odc_count"`
+}
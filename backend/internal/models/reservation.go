@@ -0,0 +1,89 @@
+package models
+
+import "time"
+
+// ReservationStatus represents the state of a reservation
+type ReservationStatus string
+
+const (
+	ReservationStatusActive   ReservationStatus = "ACTIVE"
+	ReservationStatusReleased ReservationStatus = "RELEASED"
+	ReservationStatusExpired  ReservationStatus = "EXPIRED"
+)
+
+// DefaultReservationDuration is how long a reservation lasts when no expiry is given
+const DefaultReservationDuration = 7 * 24 * time.Hour
+
+// Reservation holds a core or port for a planner's design
+type Reservation struct {
+	ID             int64             `json:"id" db:"id"`
+	ElementType    ConnectionType    `json:"element_type" db:"element_type"` // CORE or PORT
+	ElementID      int64             `json:"element_id" db:"element_id"`
+	LocationNodeID *int64            `json:"location_node_id,omitempty" db:"location_node_id"` // Node a reserved port sits on
+	Owner          string            `json:"owner" db:"owner"`
+	Reason         *string           `json:"reason,omitempty" db:"reason"`
+	WorkOrder      *string           `json:"work_order,omitempty" db:"work_order"`
+	ExpiresAt      time.Time         `json:"expires_at" db:"expires_at"`
+	Status         ReservationStatus `json:"status" db:"status"`
+	ReleasedAt     *time.Time        `json:"released_at,omitempty" db:"released_at"`
+	CreatedAt      time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at" db:"updated_at"`
+
+	// Joined data for core reservations
+	CableID   *int64 `json:"cable_id,omitempty" db:"-"`
+	CoreIndex *int   `json:"core_index,omitempty" db:"-"`
+}
+
+// ReservationElement identifies a core or port to reserve. A port is its number on the node
+// named by LocationNodeID.
+type ReservationElement struct {
+	Type           ConnectionType `json:"type" validate:"required,oneof=CORE PORT"`
+	ID             int64          `json:"id" validate:"required"`
+	LocationNodeID int64          `json:"location_node_id,omitempty"` // Required for ports
+}
+
+// ReservationDetails describes who holds a reservation, why and until when
+type ReservationDetails struct {
+	Owner         string     `json:"owner" validate:"required,min=1,max=100"`
+	Reason        *string    `json:"reason,omitempty"`
+	WorkOrder     *string    `json:"work_order,omitempty" validate:"omitempty,max=100"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	DurationHours *float64   `json:"duration_hours,omitempty"` // Used when expires_at is omitted
+}
+
+// CreateReservationRequest represents the request body for reserving cores and ports together
+type CreateReservationRequest struct {
+	ReservationDetails
+	Elements []ReservationElement `json:"elements" validate:"required,min=1"`
+}
+
+// ExtendReservationRequest represents the request body for extending a reservation
+type ExtendReservationRequest struct {
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	DurationHours *float64   `json:"duration_hours,omitempty"` // Added to the current expiry
+}
+
+// ReservationFilter represents filter options for listing reservations
+type ReservationFilter struct {
+	Owner       *string            `json:"owner,omitempty"`
+	WorkOrder   *string            `json:"work_order,omitempty"`
+	Status      *ReservationStatus `json:"status,omitempty"`
+	ElementType *ConnectionType    `json:"element_type,omitempty"`
+	CableID     *int64             `json:"cable_id,omitempty"`
+	AreaID      *int64             `json:"area_id,omitempty"` // Reservations on cores of cables in the area
+	ExpiresBy   *time.Time         `json:"expires_by,omitempty"`
+	Limit       int                `json:"limit,omitempty"`
+	Offset      int                `json:"offset,omitempty"`
+}
+
+// Expiry resolves when a new reservation ends: expires_at, else now plus duration_hours,
+// else now plus the default duration
+func (d *ReservationDetails) Expiry(now time.Time) time.Time {
+	switch {
+	case d.ExpiresAt != nil:
+		return *d.ExpiresAt
+	case d.DurationHours != nil:
+		return now.Add(time.Duration(*d.DurationHours * float64(time.Hour)))
+	}
+	return now.Add(DefaultReservationDuration)
+}
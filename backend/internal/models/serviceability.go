@@ -0,0 +1,85 @@
+package models
+
+import "math"
+
+// Loss budget assumptions used when the request does not override them
+const (
+	DefaultOLTTxPowerDBm   = 3.0 // GPON class B+ launch power
+	DefaultConnectorLossDB = 1.0 // Mated connectors between the OLT and the ONT
+	DefaultODCSplitPorts   = 4   // ODCs are assumed to hold 1:4 splitters
+	SplitterStageLossDB    = 3.5 // Each 1:2 stage of a splitter, excess loss included
+)
+
+// DefaultServiceabilityOptions is how many ODPs a serviceability check ranks when no limit is given
+const DefaultServiceabilityOptions = 5
+
+// SplitterLossDB returns the typical insertion loss of a 1:N splitter
+func SplitterLossDB(ports int) float64 {
+	if ports < 2 {
+		return 0
+	}
+	return SplitterStageLossDB * math.Ceil(math.Log2(float64(ports)))
+}
+
+// RxBasis is how a predicted Rx power was worked out
+type RxBasis string
+
+const (
+	RxBasisMeasured RxBasis = "MEASURED" // From the Rx power of online customers on the same ODP
+	RxBasisBudget   RxBasis = "BUDGET"   // From the OLT launch power less the losses along the feed
+)
+
+// ServiceabilityRequest represents the parameters of a serviceability check
+type ServiceabilityRequest struct {
+	Latitude           float64  `json:"latitude" validate:"required,latitude"`
+	Longitude          float64  `json:"longitude" validate:"required,longitude"`
+	MaxDropMeters      float64  `json:"max_drop_meters,omitempty"` // Defaults to DefaultMaxDropMeters
+	MaxSpanMeters      float64  `json:"max_span_meters,omitempty"` // Defaults to DefaultMaxSpanMeters
+	OLTTxPowerDBm      *float64 `json:"olt_tx_power_dbm,omitempty"`
+	AttenuationDBPerKm *float64 `json:"attenuation_db_per_km,omitempty"`
+	Limit              int      `json:"limit,omitempty"` // Defaults to DefaultServiceabilityOptions
+}
+
+// LossBudget predicts the Rx power at a new ONT behind an ODP. ODPLevelDBm is the power reaching
+// the ONT before the drop: averaged from the customers already on the ODP, or worked out from the
+// OLT launch power less the feed, splitter and connector losses.
+type LossBudget struct {
+	Basis             RxBasis  `json:"basis"`
+	MeasuredCustomers int      `json:"measured_customers,omitempty"`
+	OLTTxPowerDBm     *float64 `json:"olt_tx_power_dbm,omitempty"`
+	FeedLengthMeter   *float64 `json:"feed_length_meter,omitempty"` // OLT to ODP
+	FeedFiberLossDB   *float64 `json:"feed_fiber_loss_db,omitempty"`
+	SpliceLossDB      *float64 `json:"splice_loss_db,omitempty"`
+	SplitterLossDB    *float64 `json:"splitter_loss_db,omitempty"`
+	ConnectorLossDB   *float64 `json:"connector_loss_db,omitempty"`
+	ODPLevelDBm       float64  `json:"odp_level_dbm"`
+	DropLossDB        float64  `json:"drop_loss_db"`
+	PredictedRxDBm    float64  `json:"predicted_rx_dbm"`
+	RxStatus          string   `json:"rx_status"` // GOOD, WARNING or CRITICAL
+}
+
+// ServiceabilityOption is one ODP that might serve an address
+type ServiceabilityOption struct {
+	Rank          int         `json:"rank"`
+	ODPID         int64       `json:"odp_id"`
+	ODPName       string      `json:"odp_name"`
+	ODPStatus     NodeStatus  `json:"odp_status"`
+	CapacityPorts int         `json:"capacity_ports"`
+	FreePorts     int         `json:"free_ports"`
+	Drop          DropRoute   `json:"drop"`
+	Budget        *LossBudget `json:"budget,omitempty"` // Absent when the ODP has no feed to an OLT
+	Feasible      bool        `json:"feasible"`
+	Reason        string      `json:"reason,omitempty"` // Why the ODP cannot serve the address
+}
+
+// ServiceabilityResult answers whether an address can be connected. Feasible options come first,
+// shortest drop first.
+type ServiceabilityResult struct {
+	Latitude      float64                `json:"latitude"`
+	Longitude     float64                `json:"longitude"`
+	MaxDropMeters float64                `json:"max_drop_meters"`
+	Serviceable   bool                   `json:"serviceable"`
+	Reason        string                 `json:"reason,omitempty"` // Why not, when not serviceable
+	Best          *ServiceabilityOption  `json:"best,omitempty"`
+	Options       []ServiceabilityOption `json:"options"`
+}
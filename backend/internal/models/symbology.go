@@ -0,0 +1,27 @@
+package models
+
+// Map symbology from the PRD, shared by the map exports (KML, GIS layers)
+
+// NodeTypeColors defines the default display color of each node type
+var NodeTypeColors = map[NodeType]string{
+	NodeTypeOLT:      "#00008B", // Dark blue
+	NodeTypeODC:      "#006400", // Dark green
+	NodeTypeODP:      "#87CEEB", // Light blue
+	NodeTypeClosure:  "#FFA500", // Orange
+	NodeTypePole:     "#8B4513", // Brown
+	NodeTypeCustomer: "#000000", // Black
+}
+
+// CableTypeColors defines the default display color of each cable type
+var CableTypeColors = map[CableType]string{
+	CableTypeADSS: "#FF0000", // Red backbone / feeder
+	CableTypeDuct: "#0000FF", // Blue distribution
+	CableTypeDrop: "#000000", // Black drop
+}
+
+// CableTypeWidths defines the default line width in pixels of each cable type
+var CableTypeWidths = map[CableType]int{
+	CableTypeADSS: 4,
+	CableTypeDuct: 2,
+	CableTypeDrop: 1,
+}
@@ -0,0 +1,104 @@
+package models
+
+import "time"
+
+// Tenant is a partner ISP or business unit sharing the plant. A tenant sees the shared
+// infrastructure and only its own cores, customers, tickets and other records.
+type Tenant struct {
+	ID           int64     `json:"id" db:"id"`
+	Code         string    `json:"code" db:"code"` // Sent in the X-Tenant-ID header, as is the ID
+	Name         string    `json:"name" db:"name"`
+	ContactEmail *string   `json:"contact_email,omitempty" db:"contact_email"`
+	Active       bool      `json:"active" db:"active"`
+	Description  *string   `json:"description,omitempty" db:"description"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+
+	// Joined data
+	Owned map[TenantElementType]int `json:"owned,omitempty" db:"-"` // Rows the tenant owns, by element type
+}
+
+// CreateTenantRequest represents the request body for creating a tenant
+type CreateTenantRequest struct {
+	Code         string  `json:"code" validate:"required,min=1,max=50"`
+	Name         string  `json:"name" validate:"required,min=1,max=100"`
+	ContactEmail *string `json:"contact_email,omitempty"`
+	Active       *bool   `json:"active,omitempty"` // Defaults to true
+	Description  *string `json:"description,omitempty"`
+}
+
+// UpdateTenantRequest represents the request body for updating a tenant. Inactive tenants are
+// refused by the API.
+type UpdateTenantRequest struct {
+	Name         *string `json:"name,omitempty"`
+	ContactEmail *string `json:"contact_email,omitempty"`
+	Active       *bool   `json:"active,omitempty"`
+	Description  *string `json:"description,omitempty"`
+}
+
+// TenantFilter represents query filters for listing tenants
+type TenantFilter struct {
+	Active *bool   `json:"active,omitempty"`
+	Search *string `json:"search,omitempty"` // Search by code or name
+	Limit  int     `json:"limit,omitempty"`
+	Offset int     `json:"offset,omitempty"`
+}
+
+// TenantElementType is a kind of record that can belong to a tenant
+type TenantElementType string
+
+const (
+	TenantElementNode              TenantElementType = "NODE"
+	TenantElementCable             TenantElementType = "CABLE"
+	TenantElementCore              TenantElementType = "CORE"
+	TenantElementConnection        TenantElementType = "CONNECTION"
+	TenantElementCustomer          TenantElementType = "CUSTOMER"
+	TenantElementReservation       TenantElementType = "RESERVATION"
+	TenantElementOTDRTrace         TenantElementType = "OTDR_TRACE"
+	TenantElementTicket            TenantElementType = "TICKET"
+	TenantElementWorkOrder         TenantElementType = "WORK_ORDER"
+	TenantElementMaintenanceWindow TenantElementType = "MAINTENANCE_WINDOW"
+	TenantElementWorkspace         TenantElementType = "WORKSPACE"
+	TenantElementServiceArea       TenantElementType = "SERVICE_AREA"
+)
+
+// TenantElementTables maps each element type to the table holding it
+var TenantElementTables = map[TenantElementType]string{
+	TenantElementNode:              "nodes",
+	TenantElementCable:             "cables",
+	TenantElementCore:              "cable_cores",
+	TenantElementConnection:        "connections",
+	TenantElementCustomer:          "customers",
+	TenantElementReservation:       "reservations",
+	TenantElementOTDRTrace:         "otdr_traces",
+	TenantElementTicket:            "tickets",
+	TenantElementWorkOrder:         "work_orders",
+	TenantElementMaintenanceWindow: "maintenance_windows",
+	TenantElementWorkspace:         "design_workspaces",
+	TenantElementServiceArea:       "service_areas",
+}
+
+// IsValid checks if the tenant element type is valid
+func (t TenantElementType) IsValid() bool {
+	_, ok := TenantElementTables[t]
+	return ok
+}
+
+// TenantAssignRequest hands records to a tenant, or back to the operator when tenant_id is
+// omitted. Nodes, cables, connections, maintenance windows and service areas without a tenant are
+// shared with every tenant; the other records are visible only to their tenant.
+type TenantAssignRequest struct {
+	TenantID     *int64            `json:"tenant_id,omitempty"`
+	ElementType  TenantElementType `json:"element_type" validate:"required"`
+	ElementIDs   []int64           `json:"element_ids" validate:"required,min=1"`
+	IncludeCores bool              `json:"include_cores,omitempty"` // With CABLE, also assign every core of the cables
+}
+
+// TenantAssignment reports how many records an assignment changed
+type TenantAssignment struct {
+	TenantID    *int64            `json:"tenant_id,omitempty"`
+	ElementType TenantElementType `json:"element_type"`
+	Assigned    int               `json:"assigned"`
+	Cores       int               `json:"cores,omitempty"` // Cores assigned with their cables
+	Missing     []int64           `json:"missing"`         // Requested IDs that do not exist
+}
@@ -0,0 +1,246 @@
+package models
+
+import "time"
+
+// TicketStatus represents where a trouble ticket is in its lifecycle
+type TicketStatus string
+
+const (
+	TicketStatusOpen       TicketStatus = "OPEN"
+	TicketStatusAssigned   TicketStatus = "ASSIGNED"
+	TicketStatusInProgress TicketStatus = "IN_PROGRESS"
+	TicketStatusResolved   TicketStatus = "RESOLVED"
+	TicketStatusClosed     TicketStatus = "CLOSED"
+)
+
+// TicketTransitions lists the statuses each status may move to. Resolved tickets can be reopened
+// into progress; closed tickets are final.
+var TicketTransitions = map[TicketStatus][]TicketStatus{
+	TicketStatusOpen:       {TicketStatusAssigned, TicketStatusInProgress, TicketStatusResolved, TicketStatusClosed},
+	TicketStatusAssigned:   {TicketStatusOpen, TicketStatusInProgress, TicketStatusResolved, TicketStatusClosed},
+	TicketStatusInProgress: {TicketStatusAssigned, TicketStatusResolved},
+	TicketStatusResolved:   {TicketStatusInProgress, TicketStatusClosed},
+	TicketStatusClosed:     {},
+}
+
+// IsValid reports whether the ticket status is one of the known statuses
+func (s TicketStatus) IsValid() bool {
+	_, ok := TicketTransitions[s]
+	return ok
+}
+
+// CanMoveTo reports whether the state machine allows moving from s to next
+func (s TicketStatus) CanMoveTo(next TicketStatus) bool {
+	for _, allowed := range TicketTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActive reports whether work on a ticket is still outstanding
+func (s TicketStatus) IsActive() bool {
+	return s != TicketStatusResolved && s != TicketStatusClosed
+}
+
+// TicketPriority represents how urgent a ticket is
+type TicketPriority string
+
+const (
+	TicketPriorityCritical TicketPriority = "CRITICAL"
+	TicketPriorityHigh     TicketPriority = "HIGH"
+	TicketPriorityMedium   TicketPriority = "MEDIUM"
+	TicketPriorityLow      TicketPriority = "LOW"
+)
+
+// TicketSLATarget is how soon a ticket must be responded to (leave OPEN) and resolved
+type TicketSLATarget struct {
+	Response   time.Duration
+	Resolution time.Duration
+}
+
+// TicketSLATargets are the SLA targets by priority
+var TicketSLATargets = map[TicketPriority]TicketSLATarget{
+	TicketPriorityCritical: {Response: 15 * time.Minute, Resolution: 4 * time.Hour},
+	TicketPriorityHigh:     {Response: time.Hour, Resolution: 8 * time.Hour},
+	TicketPriorityMedium:   {Response: 4 * time.Hour, Resolution: 24 * time.Hour},
+	TicketPriorityLow:      {Response: 24 * time.Hour, Resolution: 72 * time.Hour},
+}
+
+// IsValid reports whether the ticket priority is one of the known priorities
+func (p TicketPriority) IsValid() bool {
+	_, ok := TicketSLATargets[p]
+	return ok
+}
+
+// TicketLinkType is the kind of element a ticket refers to
+type TicketLinkType string
+
+const (
+	TicketLinkNode     TicketLinkType = "NODE"
+	TicketLinkCable    TicketLinkType = "CABLE"
+	TicketLinkCustomer TicketLinkType = "CUSTOMER"
+	TicketLinkAlarm    TicketLinkType = "ALARM" // Alarms live in the NMS, so alarm IDs are not checked
+)
+
+// IsValid reports whether the link type is one of the known types
+func (t TicketLinkType) IsValid() bool {
+	switch t {
+	case TicketLinkNode, TicketLinkCable, TicketLinkCustomer, TicketLinkAlarm:
+		return true
+	}
+	return false
+}
+
+// Ticket represents a trouble ticket for the NOC and field teams
+type Ticket struct {
+	ID              int64          `json:"id" db:"id"`
+	Title           string         `json:"title" db:"title"`
+	Description     *string        `json:"description,omitempty" db:"description"`
+	Category        *string        `json:"category,omitempty" db:"category"`
+	Priority        TicketPriority `json:"priority" db:"priority"`
+	Status          TicketStatus   `json:"status" db:"status"`
+	AssignedTeam    *string        `json:"assigned_team,omitempty" db:"assigned_team"`
+	Assignee        *string        `json:"assignee,omitempty" db:"assignee"` // Technician
+	ReportedBy      *string        `json:"reported_by,omitempty" db:"reported_by"`
+	Resolution      *string        `json:"resolution,omitempty" db:"resolution"`
+	ResponseDueAt   time.Time      `json:"response_due_at" db:"response_due_at"`
+	ResolutionDueAt time.Time      `json:"resolution_due_at" db:"resolution_due_at"`
+	RespondedAt     *time.Time     `json:"responded_at,omitempty" db:"responded_at"`
+	ResolvedAt      *time.Time     `json:"resolved_at,omitempty" db:"resolved_at"`
+	ClosedAt        *time.Time     `json:"closed_at,omitempty" db:"closed_at"`
+	CreatedAt       time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at" db:"updated_at"`
+
+	// Computed on read
+	SLA TicketSLA `json:"sla" db:"-"`
+
+	// Joined data
+	Links    []TicketLink          `json:"links,omitempty" db:"-"`
+	Comments []TicketComment       `json:"comments,omitempty" db:"-"`
+	History  []TicketStatusHistory `json:"history,omitempty" db:"-"`
+}
+
+// TicketSLA is the state of a ticket's SLA timers
+type TicketSLA struct {
+	ResponseBreached           bool    `json:"response_breached"`
+	ResolutionBreached         bool    `json:"resolution_breached"`
+	ResolutionRemainingMinutes float64 `json:"resolution_remaining_minutes"` // Negative once overdue; frozen at resolution
+}
+
+// EvaluateSLA fills in the SLA timers as of now. Timers stop when the ticket is responded to or resolved.
+func (t *Ticket) EvaluateSLA(now time.Time) {
+	responded := now
+	if t.RespondedAt != nil {
+		responded = *t.RespondedAt
+	}
+	resolved := now
+	if t.ResolvedAt != nil {
+		resolved = *t.ResolvedAt
+	} else if t.ClosedAt != nil {
+		resolved = *t.ClosedAt
+	}
+	t.SLA = TicketSLA{
+		ResponseBreached:           responded.After(t.ResponseDueAt),
+		ResolutionBreached:         resolved.After(t.ResolutionDueAt),
+		ResolutionRemainingMinutes: t.ResolutionDueAt.Sub(resolved).Minutes(),
+	}
+}
+
+// TicketLink ties a ticket to a node, cable, customer or alarm
+type TicketLink struct {
+	ID          int64          `json:"id" db:"id"`
+	TicketID    int64          `json:"ticket_id" db:"ticket_id"`
+	ElementType TicketLinkType `json:"element_type" db:"element_type"`
+	ElementID   int64          `json:"element_id" db:"element_id"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+
+	// Joined data
+	Name *string `json:"name,omitempty" db:"-"` // Node, cable or customer name
+}
+
+// TicketComment is a note added to a ticket
+type TicketComment struct {
+	ID        int64     `json:"id" db:"id"`
+	TicketID  int64     `json:"ticket_id" db:"ticket_id"`
+	Author    string    `json:"author" db:"author"`
+	Body      string    `json:"body" db:"body"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// TicketStatusHistory records a status change
+type TicketStatusHistory struct {
+	ID         int64         `json:"id" db:"id"`
+	TicketID   int64         `json:"ticket_id" db:"ticket_id"`
+	FromStatus *TicketStatus `json:"from_status,omitempty" db:"from_status"` // Absent for the creation entry
+	ToStatus   TicketStatus  `json:"to_status" db:"to_status"`
+	ChangedBy  *string       `json:"changed_by,omitempty" db:"changed_by"`
+	Note       *string       `json:"note,omitempty" db:"note"`
+	CreatedAt  time.Time     `json:"created_at" db:"created_at"`
+}
+
+// TicketLinkRequest identifies an element to link to a ticket
+type TicketLinkRequest struct {
+	ElementType TicketLinkType `json:"element_type" validate:"required,oneof=NODE CABLE CUSTOMER ALARM"`
+	ElementID   int64          `json:"element_id" validate:"required"`
+}
+
+// CreateTicketRequest represents the request body for opening a ticket.
+// A ticket created with a team or assignee starts ASSIGNED.
+type CreateTicketRequest struct {
+	Title        string              `json:"title" validate:"required,min=1,max=200"`
+	Description  *string             `json:"description,omitempty"`
+	Category     *string             `json:"category,omitempty" validate:"omitempty,max=50"`
+	Priority     TicketPriority      `json:"priority,omitempty" validate:"omitempty,oneof=CRITICAL HIGH MEDIUM LOW"`
+	AssignedTeam *string             `json:"assigned_team,omitempty" validate:"omitempty,max=100"`
+	Assignee     *string             `json:"assignee,omitempty" validate:"omitempty,max=100"`
+	ReportedBy   *string             `json:"reported_by,omitempty" validate:"omitempty,max=100"`
+	Links        []TicketLinkRequest `json:"links,omitempty"`
+}
+
+// UpdateTicketRequest represents the request body for editing a ticket. Changing the priority
+// moves the SLA deadlines, still counted from when the ticket was opened.
+type UpdateTicketRequest struct {
+	Title       *string         `json:"title,omitempty" validate:"omitempty,min=1,max=200"`
+	Description *string         `json:"description,omitempty"`
+	Category    *string         `json:"category,omitempty" validate:"omitempty,max=50"`
+	Priority    *TicketPriority `json:"priority,omitempty" validate:"omitempty,oneof=CRITICAL HIGH MEDIUM LOW"`
+}
+
+// AssignTicketRequest represents the request body for assigning a ticket to a team or technician
+type AssignTicketRequest struct {
+	AssignedTeam *string `json:"assigned_team,omitempty" validate:"omitempty,max=100"`
+	Assignee     *string `json:"assignee,omitempty" validate:"omitempty,max=100"`
+	ChangedBy    *string `json:"changed_by,omitempty"`
+}
+
+// TicketStatusRequest represents the request body for moving a ticket through its lifecycle
+type TicketStatusRequest struct {
+	Status     TicketStatus `json:"status" validate:"required,oneof=OPEN ASSIGNED IN_PROGRESS RESOLVED CLOSED"`
+	ChangedBy  *string      `json:"changed_by,omitempty"`
+	Note       *string      `json:"note,omitempty"`
+	Resolution *string      `json:"resolution,omitempty"` // Required when resolving
+}
+
+// CreateTicketCommentRequest represents the request body for commenting on a ticket
+type CreateTicketCommentRequest struct {
+	Author string `json:"author" validate:"required,min=1,max=100"`
+	Body   string `json:"body" validate:"required,min=1"`
+}
+
+// TicketFilter represents filter options for the NOC queue
+type TicketFilter struct {
+	Statuses     []TicketStatus     `json:"statuses,omitempty"`
+	Active       bool               `json:"active,omitempty"` // Only tickets not yet resolved or closed
+	Priority     *TicketPriority    `json:"priority,omitempty"`
+	AssignedTeam *string            `json:"assigned_team,omitempty"`
+	Assignee     *string            `json:"assignee,omitempty"`
+	Category     *string            `json:"category,omitempty"`
+	Link         *TicketLinkRequest `json:"link,omitempty"`    // Tickets linked to this element
+	AreaID       *int64             `json:"area_id,omitempty"` // Tickets linked to plant or customers in the area
+	SLABreached  bool               `json:"sla_breached,omitempty"`
+	Search       *string            `json:"search,omitempty"` // Search by title
+	Limit        int                `json:"limit,omitempty"`
+	Offset       int                `json:"offset,omitempty"`
+}
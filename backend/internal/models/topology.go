@@ -0,0 +1,92 @@
+package models
+
+import (
+	"time"
+)
+
+// TopologyVertexKind is the kind of network element a topology vertex stands for
+type TopologyVertexKind string
+
+const (
+	TopologyVertexNode     TopologyVertexKind = "NODE"
+	TopologyVertexCable    TopologyVertexKind = "CABLE"
+	TopologyVertexCore     TopologyVertexKind = "CORE"
+	TopologyVertexPort     TopologyVertexKind = "PORT"
+	TopologyVertexCustomer TopologyVertexKind = "CUSTOMER"
+)
+
+// TopologyEdgeKind is the relationship a topology edge stands for
+type TopologyEdgeKind string
+
+const (
+	// TopologyEdgeCableEnd links a cable to its origin or destination node
+	TopologyEdgeCableEnd TopologyEdgeKind = "CABLE_END"
+	// TopologyEdgeCablePass links a cable to an intermediate node on its span route
+	TopologyEdgeCablePass TopologyEdgeKind = "CABLE_PASS"
+	// TopologyEdgeCoreOf links a core to its cable
+	TopologyEdgeCoreOf TopologyEdgeKind = "CORE_OF"
+	// TopologyEdgeBreakout links a core to a node where it is cut out of the cable
+	TopologyEdgeBreakout TopologyEdgeKind = "BREAKOUT"
+	// TopologyEdgeSplice links the two endpoints (cores or ports) of a connection
+	TopologyEdgeSplice TopologyEdgeKind = "SPLICE"
+	// TopologyEdgePortAt links a port to the node its connections are located at
+	TopologyEdgePortAt TopologyEdgeKind = "PORT_AT"
+	// TopologyEdgeServes links a node to a customer connected to it
+	TopologyEdgeServes TopologyEdgeKind = "SERVES"
+)
+
+// AllTopologyVertexKinds lists vertex kinds in display order
+var AllTopologyVertexKinds = []TopologyVertexKind{
+	TopologyVertexNode, TopologyVertexCable, TopologyVertexCore, TopologyVertexPort, TopologyVertexCustomer,
+}
+
+// IsValid reports whether the vertex kind is one of the known kinds
+func (k TopologyVertexKind) IsValid() bool {
+	for _, known := range AllTopologyVertexKinds {
+		if k == known {
+			return true
+		}
+	}
+	return false
+}
+
+// TopologyVertex is a network element in the topology graph.
+// IDs are "<kind>:<database id>" in lower case, e.g. "node:12" or "core:345".
+type TopologyVertex struct {
+	ID         string                 `json:"id"`
+	Kind       TopologyVertexKind     `json:"kind"`
+	Label      string                 `json:"label"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+// TopologyEdge is an undirected relationship between two vertices
+type TopologyEdge struct {
+	ID         string                 `json:"id"`
+	Kind       TopologyEdgeKind       `json:"kind"`
+	Source     string                 `json:"source"`
+	Target     string                 `json:"target"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// TopologyGraph is the whole topology graph or the neighbourhood of a root vertex
+type TopologyGraph struct {
+	Root        *string          `json:"root,omitempty"`
+	Depth       int              `json:"depth,omitempty"`
+	Vertices    []TopologyVertex `json:"vertices"`
+	Edges       []TopologyEdge   `json:"edges"`
+	VertexCount int              `json:"vertex_count"`
+	EdgeCount   int              `json:"edge_count"`
+	LoadedAt    time.Time        `json:"loaded_at"`
+}
+
+// NetworkSnapshot is a consistent copy of the plant tables the topology graph is built from
+type NetworkSnapshot struct {
+	Nodes       []Node
+	Cables      []Cable
+	Cores       []CableCore
+	Spans       []CableSpan
+	Breakouts   []CoreBreakout
+	Connections []Connection
+	Customers   []Customer
+	Maintenance map[int64]int64 // Customer ID -> active maintenance window ID
+}
@@ -0,0 +1,268 @@
+package models
+
+import "time"
+
+// WorkOrderType is the kind of planned job
+type WorkOrderType string
+
+const (
+	WorkOrderTypeInstallation WorkOrderType = "INSTALLATION"
+	WorkOrderTypeExpansion    WorkOrderType = "EXPANSION" // e.g. adding an ODP or splitter
+	WorkOrderTypeResplice     WorkOrderType = "RESPLICE"
+	WorkOrderTypeMaintenance  WorkOrderType = "MAINTENANCE"
+)
+
+// IsValid reports whether the work order type is one of the known types
+func (t WorkOrderType) IsValid() bool {
+	switch t {
+	case WorkOrderTypeInstallation, WorkOrderTypeExpansion, WorkOrderTypeResplice, WorkOrderTypeMaintenance:
+		return true
+	}
+	return false
+}
+
+// WorkOrderStatus represents where a work order is in its lifecycle
+type WorkOrderStatus string
+
+const (
+	WorkOrderStatusPlanned    WorkOrderStatus = "PLANNED"
+	WorkOrderStatusInProgress WorkOrderStatus = "IN_PROGRESS"
+	WorkOrderStatusCompleted  WorkOrderStatus = "COMPLETED"
+	WorkOrderStatusCancelled  WorkOrderStatus = "CANCELLED"
+)
+
+// WorkOrderTransitions lists the statuses each status may move to through the status endpoint.
+// COMPLETED is reached only by completing the work order, which applies its design changes.
+var WorkOrderTransitions = map[WorkOrderStatus][]WorkOrderStatus{
+	WorkOrderStatusPlanned:    {WorkOrderStatusInProgress, WorkOrderStatusCancelled},
+	WorkOrderStatusInProgress: {WorkOrderStatusPlanned, WorkOrderStatusCancelled},
+	WorkOrderStatusCompleted:  {},
+	WorkOrderStatusCancelled:  {},
+}
+
+// IsValid reports whether the work order status is one of the known statuses
+func (s WorkOrderStatus) IsValid() bool {
+	_, ok := WorkOrderTransitions[s]
+	return ok
+}
+
+// CanMoveTo reports whether the status endpoint allows moving from s to next
+func (s WorkOrderStatus) CanMoveTo(next WorkOrderStatus) bool {
+	for _, allowed := range WorkOrderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOpen reports whether a work order can still be edited and completed
+func (s WorkOrderStatus) IsOpen() bool {
+	return s == WorkOrderStatusPlanned || s == WorkOrderStatusInProgress
+}
+
+// WorkOrderAssetType is the kind of element a work order targets
+type WorkOrderAssetType string
+
+const (
+	WorkOrderAssetNode     WorkOrderAssetType = "NODE"
+	WorkOrderAssetCable    WorkOrderAssetType = "CABLE"
+	WorkOrderAssetCustomer WorkOrderAssetType = "CUSTOMER"
+)
+
+// IsValid reports whether the asset type is one of the known types
+func (t WorkOrderAssetType) IsValid() bool {
+	switch t {
+	case WorkOrderAssetNode, WorkOrderAssetCable, WorkOrderAssetCustomer:
+		return true
+	}
+	return false
+}
+
+// DesignChangeType is the kind of inventory change a work order applies on completion
+type DesignChangeType string
+
+const (
+	DesignChangeAddSplice     DesignChangeType = "ADD_SPLICE"
+	DesignChangeRemoveSplice  DesignChangeType = "REMOVE_SPLICE"
+	DesignChangeSetCoreStatus DesignChangeType = "SET_CORE_STATUS"
+)
+
+// WorkOrder is a planned installation or maintenance job
+type WorkOrder struct {
+	ID             int64           `json:"id" db:"id"`
+	Code           string          `json:"code" db:"code"` // Matches the work_order of reservations made for the job
+	Title          string          `json:"title" db:"title"`
+	Description    *string         `json:"description,omitempty" db:"description"`
+	Type           WorkOrderType   `json:"type" db:"type"`
+	Status         WorkOrderStatus `json:"status" db:"status"`
+	Crew           *string         `json:"crew,omitempty" db:"crew"`
+	ScheduledStart *time.Time      `json:"scheduled_start,omitempty" db:"scheduled_start"`
+	ScheduledEnd   *time.Time      `json:"scheduled_end,omitempty" db:"scheduled_end"`
+	StartedAt      *time.Time      `json:"started_at,omitempty" db:"started_at"`
+	CompletedAt    *time.Time      `json:"completed_at,omitempty" db:"completed_at"`
+	CompletedBy    *string         `json:"completed_by,omitempty" db:"completed_by"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
+
+	// Joined data
+	Tasks     []WorkOrderTask     `json:"tasks,omitempty" db:"-"`
+	Materials []WorkOrderMaterial `json:"materials,omitempty" db:"-"`
+	Assets    []WorkOrderAsset    `json:"assets,omitempty" db:"-"`
+	Changes   []DesignChange      `json:"changes,omitempty" db:"-"`
+}
+
+// WorkOrderTask is one item of a work order's checklist
+type WorkOrderTask struct {
+	ID          int64      `json:"id" db:"id"`
+	WorkOrderID int64      `json:"work_order_id" db:"work_order_id"`
+	Position    int        `json:"position" db:"position"`
+	Description string     `json:"description" db:"description"`
+	Done        bool       `json:"done" db:"done"`
+	DoneBy      *string    `json:"done_by,omitempty" db:"done_by"`
+	DoneAt      *time.Time `json:"done_at,omitempty" db:"done_at"`
+}
+
+// WorkOrderMaterial is a material the crew needs for a work order
+type WorkOrderMaterial struct {
+	ID          int64   `json:"id" db:"id"`
+	WorkOrderID int64   `json:"work_order_id" db:"work_order_id"`
+	Item        string  `json:"item" db:"item"`
+	Quantity    float64 `json:"quantity" db:"quantity"`
+	Unit        string  `json:"unit" db:"unit"` // e.g. pcs, m
+	Notes       *string `json:"notes,omitempty" db:"notes"`
+}
+
+// WorkOrderAsset is a node, cable or customer a work order targets
+type WorkOrderAsset struct {
+	ID          int64              `json:"id" db:"id"`
+	WorkOrderID int64              `json:"work_order_id" db:"work_order_id"`
+	ElementType WorkOrderAssetType `json:"element_type" db:"element_type"`
+	ElementID   int64              `json:"element_id" db:"element_id"`
+
+	// Joined data
+	Name *string `json:"name,omitempty" db:"-"`
+}
+
+// DesignChange is an inventory change attached to a work order. Changes are applied in position
+// order when the work order is completed.
+type DesignChange struct {
+	ID          int64            `json:"id" db:"id"`
+	WorkOrderID int64            `json:"work_order_id" db:"work_order_id"`
+	Position    int              `json:"position" db:"position"`
+	ChangeType  DesignChangeType `json:"change_type" db:"change_type"`
+
+	// ADD_SPLICE
+	LocationNodeID *int64          `json:"location_node_id,omitempty" db:"location_node_id"`
+	InputType      *ConnectionType `json:"input_type,omitempty" db:"input_type"`
+	InputID        *int64          `json:"input_id,omitempty" db:"input_id"`
+	OutputType     *ConnectionType `json:"output_type,omitempty" db:"output_type"`
+	OutputID       *int64          `json:"output_id,omitempty" db:"output_id"`
+	LossDB         *float64        `json:"loss_db,omitempty" db:"loss_db"`
+
+	// REMOVE_SPLICE, and the connection created by ADD_SPLICE once applied
+	ConnectionID *int64 `json:"connection_id,omitempty" db:"connection_id"`
+
+	// SET_CORE_STATUS
+	CoreID     *int64      `json:"core_id,omitempty" db:"core_id"`
+	CoreStatus *CoreStatus `json:"core_status,omitempty" db:"core_status"`
+
+	Notes     *string    `json:"notes,omitempty" db:"notes"`
+	AppliedAt *time.Time `json:"applied_at,omitempty" db:"applied_at"`
+}
+
+// WorkOrderTaskRequest is a checklist item in a request
+type WorkOrderTaskRequest struct {
+	Description string `json:"description" validate:"required,min=1"`
+}
+
+// WorkOrderMaterialRequest is a required material in a request
+type WorkOrderMaterialRequest struct {
+	Item     string  `json:"item" validate:"required,min=1,max=100"`
+	Quantity float64 `json:"quantity" validate:"required,gt=0"`
+	Unit     string  `json:"unit" validate:"required,max=20"`
+	Notes    *string `json:"notes,omitempty"`
+}
+
+// WorkOrderAssetRequest identifies a target asset in a request
+type WorkOrderAssetRequest struct {
+	ElementType WorkOrderAssetType `json:"element_type" validate:"required,oneof=NODE CABLE CUSTOMER"`
+	ElementID   int64              `json:"element_id" validate:"required"`
+}
+
+// DesignChangeRequest is a design change in a request
+type DesignChangeRequest struct {
+	ChangeType     DesignChangeType `json:"change_type" validate:"required,oneof=ADD_SPLICE REMOVE_SPLICE SET_CORE_STATUS"`
+	LocationNodeID *int64           `json:"location_node_id,omitempty"`
+	InputType      *ConnectionType  `json:"input_type,omitempty" validate:"omitempty,oneof=CORE PORT"`
+	InputID        *int64           `json:"input_id,omitempty"`
+	OutputType     *ConnectionType  `json:"output_type,omitempty" validate:"omitempty,oneof=CORE PORT"`
+	OutputID       *int64           `json:"output_id,omitempty"`
+	LossDB         *float64         `json:"loss_db,omitempty"`
+	ConnectionID   *int64           `json:"connection_id,omitempty"`
+	CoreID         *int64           `json:"core_id,omitempty"`
+	CoreStatus     *CoreStatus      `json:"core_status,omitempty" validate:"omitempty,oneof=VACANT USED RESERVED DAMAGED"`
+	Notes          *string          `json:"notes,omitempty"`
+}
+
+// CreateWorkOrderRequest represents the request body for planning a work order
+type CreateWorkOrderRequest struct {
+	Code           string                     `json:"code" validate:"required,min=1,max=100"`
+	Title          string                     `json:"title" validate:"required,min=1,max=200"`
+	Description    *string                    `json:"description,omitempty"`
+	Type           WorkOrderType              `json:"type" validate:"required,oneof=INSTALLATION EXPANSION RESPLICE MAINTENANCE"`
+	Crew           *string                    `json:"crew,omitempty" validate:"omitempty,max=100"`
+	ScheduledStart *time.Time                 `json:"scheduled_start,omitempty"`
+	ScheduledEnd   *time.Time                 `json:"scheduled_end,omitempty"`
+	Tasks          []WorkOrderTaskRequest     `json:"tasks,omitempty"`
+	Materials      []WorkOrderMaterialRequest `json:"materials,omitempty"`
+	Assets         []WorkOrderAssetRequest    `json:"assets,omitempty"`
+	Changes        []DesignChangeRequest      `json:"changes,omitempty"`
+}
+
+// UpdateWorkOrderRequest represents the request body for editing an open work order.
+// Tasks, materials, assets and changes replace the existing lists when present.
+type UpdateWorkOrderRequest struct {
+	Title          *string                    `json:"title,omitempty" validate:"omitempty,min=1,max=200"`
+	Description    *string                    `json:"description,omitempty"`
+	Type           *WorkOrderType             `json:"type,omitempty" validate:"omitempty,oneof=INSTALLATION EXPANSION RESPLICE MAINTENANCE"`
+	Crew           *string                    `json:"crew,omitempty" validate:"omitempty,max=100"`
+	ScheduledStart *time.Time                 `json:"scheduled_start,omitempty"`
+	ScheduledEnd   *time.Time                 `json:"scheduled_end,omitempty"`
+	Tasks          []WorkOrderTaskRequest     `json:"tasks,omitempty"`
+	Materials      []WorkOrderMaterialRequest `json:"materials,omitempty"`
+	Assets         []WorkOrderAssetRequest    `json:"assets,omitempty"`
+	Changes        []DesignChangeRequest      `json:"changes,omitempty"`
+}
+
+// WorkOrderTaskUpdate represents the request body for ticking off a checklist item
+type WorkOrderTaskUpdate struct {
+	Done   bool    `json:"done"`
+	DoneBy *string `json:"done_by,omitempty"`
+}
+
+// WorkOrderStatusRequest represents the request body for starting, pausing or cancelling a work order
+type WorkOrderStatusRequest struct {
+	Status WorkOrderStatus `json:"status" validate:"required,oneof=PLANNED IN_PROGRESS CANCELLED"`
+}
+
+// CompleteWorkOrderRequest represents the request body for completing a work order.
+// Design changes are applied unless apply_changes is false.
+type CompleteWorkOrderRequest struct {
+	CompletedBy  *string `json:"completed_by,omitempty"`
+	ApplyChanges *bool   `json:"apply_changes,omitempty"`
+}
+
+// WorkOrderFilter represents filter options for listing work orders
+type WorkOrderFilter struct {
+	Status        *WorkOrderStatus       `json:"status,omitempty"`
+	Type          *WorkOrderType         `json:"type,omitempty"`
+	Crew          *string                `json:"crew,omitempty"`
+	ScheduledFrom *time.Time             `json:"scheduled_from,omitempty"` // Windows ending at or after
+	ScheduledTo   *time.Time             `json:"scheduled_to,omitempty"`   // Windows starting at or before
+	Asset         *WorkOrderAssetRequest `json:"asset,omitempty"`
+	AreaID        *int64                 `json:"area_id,omitempty"` // Work orders on plant or customers in the area
+	Search        *string                `json:"search,omitempty"`  // Search by code or title
+	Limit         int                    `json:"limit,omitempty"`
+	Offset        int                    `json:"offset,omitempty"`
+}
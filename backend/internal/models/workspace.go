@@ -0,0 +1,201 @@
+package models
+
+import "time"
+
+// WorkspaceStatus represents where a design workspace is in its review lifecycle
+type WorkspaceStatus string
+
+const (
+	WorkspaceStatusDraft     WorkspaceStatus = "DRAFT"
+	WorkspaceStatusSubmitted WorkspaceStatus = "SUBMITTED"
+	WorkspaceStatusApproved  WorkspaceStatus = "APPROVED"
+	WorkspaceStatusMerged    WorkspaceStatus = "MERGED"
+	WorkspaceStatusDiscarded WorkspaceStatus = "DISCARDED"
+)
+
+// WorkspaceTransitions lists the statuses each status may move to. Rejecting a submitted or
+// approved workspace sends it back to DRAFT.
+var WorkspaceTransitions = map[WorkspaceStatus][]WorkspaceStatus{
+	WorkspaceStatusDraft:     {WorkspaceStatusSubmitted, WorkspaceStatusDiscarded},
+	WorkspaceStatusSubmitted: {WorkspaceStatusApproved, WorkspaceStatusDraft, WorkspaceStatusDiscarded},
+	WorkspaceStatusApproved:  {WorkspaceStatusMerged, WorkspaceStatusDraft, WorkspaceStatusDiscarded},
+	WorkspaceStatusMerged:    {},
+	WorkspaceStatusDiscarded: {},
+}
+
+// IsValid reports whether the workspace status is one of the known statuses
+func (s WorkspaceStatus) IsValid() bool {
+	_, ok := WorkspaceTransitions[s]
+	return ok
+}
+
+// CanMoveTo reports whether a workspace may move from s to next
+func (s WorkspaceStatus) CanMoveTo(next WorkspaceStatus) bool {
+	for _, allowed := range WorkspaceTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOpen reports whether a workspace can still be renamed and moved through review
+func (s WorkspaceStatus) IsOpen() bool {
+	return len(WorkspaceTransitions[s]) > 0
+}
+
+// DesignItemType is the kind of element drafted in a workspace
+type DesignItemType string
+
+const (
+	DesignItemNode     DesignItemType = "NODE"
+	DesignItemCable    DesignItemType = "CABLE"
+	DesignItemCustomer DesignItemType = "CUSTOMER"
+	DesignItemSplice   DesignItemType = "SPLICE"
+)
+
+// DesignItemMergeOrder lists the item types in the order they are merged, so that every item
+// is merged after the drafted items it references
+var DesignItemMergeOrder = []DesignItemType{DesignItemNode, DesignItemCable, DesignItemCustomer, DesignItemSplice}
+
+// IsValid reports whether the item type is one of the known types
+func (t DesignItemType) IsValid() bool {
+	switch t {
+	case DesignItemNode, DesignItemCable, DesignItemCustomer, DesignItemSplice:
+		return true
+	}
+	return false
+}
+
+// DraftRef turns a drafted item ID into the negative ID other drafted items use to reference it
+func DraftRef(itemID int64) int64 {
+	return -itemID
+}
+
+// DesignWorkspace is a branch of planned network changes. Its drafted items stay out of the live
+// inventory until the workspace is approved and merged; discarding it leaves nothing behind.
+type DesignWorkspace struct {
+	ID          int64           `json:"id" db:"id"`
+	Name        string          `json:"name" db:"name"`
+	Description *string         `json:"description,omitempty" db:"description"`
+	Status      WorkspaceStatus `json:"status" db:"status"`
+	CreatedBy   *string         `json:"created_by,omitempty" db:"created_by"`
+	SubmittedBy *string         `json:"submitted_by,omitempty" db:"submitted_by"`
+	SubmittedAt *time.Time      `json:"submitted_at,omitempty" db:"submitted_at"`
+	ReviewedBy  *string         `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt  *time.Time      `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	ReviewNote  *string         `json:"review_note,omitempty" db:"review_note"`
+	MergedBy    *string         `json:"merged_by,omitempty" db:"merged_by"`
+	MergedAt    *time.Time      `json:"merged_at,omitempty" db:"merged_at"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at" db:"updated_at"`
+
+	// Joined data
+	ItemCount int          `json:"item_count" db:"-"`
+	Items     []DesignItem `json:"items,omitempty" db:"-"`
+}
+
+// DesignItem is a node, cable, customer or splice drafted in a workspace.
+//
+// Drafted items reference each other by negative ID: a node_id, origin_node_id, dest_node_id or
+// location_node_id of -12 means the node drafted as item 12, and a splice input_id of -15 with an
+// input_core_index means that core of the cable drafted as item 15. Positive IDs are live elements.
+type DesignItem struct {
+	ID          int64          `json:"id" db:"id"`
+	WorkspaceID int64          `json:"workspace_id" db:"workspace_id"`
+	ItemType    DesignItemType `json:"item_type" db:"item_type"`
+	LiveID      *int64         `json:"live_id,omitempty" db:"live_id"` // Set when the workspace is merged
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at" db:"updated_at"`
+
+	// Payload, one of which is set according to the item type
+	Node     *CreateNodeRequest     `json:"node,omitempty" db:"-"`
+	Cable    *CreateCableRequest    `json:"cable,omitempty" db:"-"`
+	Customer *CreateCustomerRequest `json:"customer,omitempty" db:"-"`
+	Splice   *DesignSplice          `json:"splice,omitempty" db:"-"`
+}
+
+// DesignSplice is a drafted connection. A negative input_id or output_id of type CORE refers to
+// a drafted cable, and the matching core index picks the core.
+type DesignSplice struct {
+	CreateConnectionRequest
+	InputCoreIndex  *int `json:"input_core_index,omitempty"`
+	OutputCoreIndex *int `json:"output_core_index,omitempty"`
+}
+
+// DesignItemRequest represents the request body for drafting or redrafting an item.
+// Exactly the payload matching the item type must be set.
+type DesignItemRequest struct {
+	ItemType DesignItemType         `json:"item_type" validate:"required,oneof=NODE CABLE CUSTOMER SPLICE"`
+	Node     *CreateNodeRequest     `json:"node,omitempty"`
+	Cable    *CreateCableRequest    `json:"cable,omitempty"`
+	Customer *CreateCustomerRequest `json:"customer,omitempty"`
+	Splice   *DesignSplice          `json:"splice,omitempty"`
+}
+
+// CreateWorkspaceRequest represents the request body for opening a design workspace
+type CreateWorkspaceRequest struct {
+	Name        string  `json:"name" validate:"required,min=1,max=200"`
+	Description *string `json:"description,omitempty"`
+	CreatedBy   *string `json:"created_by,omitempty" validate:"omitempty,max=100"`
+}
+
+// UpdateWorkspaceRequest represents the request body for renaming a workspace
+type UpdateWorkspaceRequest struct {
+	Name        *string `json:"name,omitempty" validate:"omitempty,min=1,max=200"`
+	Description *string `json:"description,omitempty"`
+}
+
+// WorkspaceReviewRequest represents the request body for submitting, approving, rejecting,
+// merging or discarding a workspace
+type WorkspaceReviewRequest struct {
+	By   *string `json:"by,omitempty" validate:"omitempty,max=100"`
+	Note *string `json:"note,omitempty"`
+}
+
+// DesignConflict is a reason a drafted item cannot be merged into the live inventory as it stands
+type DesignConflict struct {
+	ItemID   int64          `json:"item_id"`
+	ItemType DesignItemType `json:"item_type"`
+	Reason   string         `json:"reason"`
+}
+
+// DesignSummary counts what a workspace adds to the network
+type DesignSummary struct {
+	Nodes             int                `json:"nodes"`
+	NodesByType       map[string]int     `json:"nodes_by_type"`
+	Cables            int                `json:"cables"`
+	CableMeters       float64            `json:"cable_meters"`
+	CableMetersByType map[string]float64 `json:"cable_meters_by_type"`
+	FiberMeters       float64            `json:"fiber_meters"` // Cable meters times core count
+	Customers         int                `json:"customers"`
+	Splices           int                `json:"splices"`
+	LiveCoresUsed     int                `json:"live_cores_used"` // Vacant live cores the splices take
+}
+
+// WorkspaceDiff compares a workspace with the live inventory: what it adds, which live elements
+// it touches and what blocks the merge
+type WorkspaceDiff struct {
+	WorkspaceID int64            `json:"workspace_id"`
+	Status      WorkspaceStatus  `json:"status"`
+	Nodes       []DesignItem     `json:"nodes"`
+	Cables      []DesignItem     `json:"cables"`
+	Customers   []DesignItem     `json:"customers"`
+	Splices     []DesignItem     `json:"splices"`
+	LiveNodes   []Node           `json:"live_nodes"` // Live nodes the drafted items attach to
+	LiveCores   []CableCore      `json:"live_cores"` // Live cores the drafted splices use
+	Summary     DesignSummary    `json:"summary"`
+	Cost        *BillOfMaterials `json:"cost,omitempty"` // Drafted items priced from the price catalog
+	Conflicts   []DesignConflict `json:"conflicts"`
+	Mergeable   bool             `json:"mergeable"`
+}
+
+// WorkspaceFilter represents filter options for listing workspaces
+type WorkspaceFilter struct {
+	Status    *WorkspaceStatus `json:"status,omitempty"`
+	CreatedBy *string          `json:"created_by,omitempty"`
+	Search    *string          `json:"search,omitempty"`  // Search by name
+	AreaID    *int64           `json:"area_id,omitempty"` // Workspaces with items attached to plant in the area
+	Limit     int              `json:"limit,omitempty"`
+	Offset    int              `json:"offset,omitempty"`
+}
@@ -0,0 +1,400 @@
+// Package otdr reads OTDR traces in the Telcordia SR-4731 (Bellcore SOR) format, versions 1 and 2.
+// Only the blocks needed to place events on the plant are decoded; the trace samples are skipped.
+package otdr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSOR is returned when a file is not a readable SOR trace
+var ErrInvalidSOR = errors.New("invalid SOR file")
+
+// SpeedOfLight in vacuum, in meters per second
+const SpeedOfLight = 299792458.0
+
+// Block is an entry of the SOR map
+type Block struct {
+	Name    string
+	Version uint16
+	Size    uint32
+	Offset  int
+}
+
+// GeneralParams is the GenParams block: what was measured and by whom
+type GeneralParams struct {
+	Language       string
+	CableID        string
+	FiberID        string
+	FiberType      uint16 // ITU-T recommendation number, e.g. 652; version 2 only
+	WavelengthNM   uint16
+	LocationA      string
+	LocationB      string
+	CableCode      string
+	BuildCondition string // BC as-built, CC as-current, RC as-repaired, OT other
+	Operator       string
+	Comment        string
+}
+
+// SupplierParams is the SupParams block: the instrument
+type SupplierParams struct {
+	Supplier        string
+	OTDRModel       string
+	OTDRSerial      string
+	ModuleModel     string
+	ModuleSerial    string
+	SoftwareVersion string
+	Other           string
+}
+
+// FixedParams is the part of the FxdParams block needed to interpret events
+type FixedParams struct {
+	Timestamp        time.Time
+	DistanceUnits    string
+	WavelengthNM     float64
+	PulseWidthsNS    []uint16
+	GroupIndex       float64
+	BackscatterDB    float64
+	Averages         uint32
+	AcquisitionRange uint32
+}
+
+// EventKind classifies a key event by its code
+type EventKind string
+
+const (
+	EventNonReflective EventKind = "NON_REFLECTIVE" // Fusion splice, bend
+	EventReflective    EventKind = "REFLECTIVE"     // Connector, mechanical splice
+	EventEnd           EventKind = "END"            // End of fiber or break
+)
+
+// Event is a key event found on the trace
+type Event struct {
+	Number             int
+	DistanceMeter      float64
+	AttenuationDBPerKm float64 // Of the fiber leading into the event
+	LossDB             float64
+	ReflectanceDB      float64
+	Code               string
+	Technique          string
+	Comment            string
+	Kind               EventKind
+}
+
+// Summary is the end-to-end result stored after the key events
+type Summary struct {
+	TotalLossDB       float64
+	LossStartMeter    float64
+	LossFinishMeter   float64
+	ORLDB             float64
+	FiberLengthMeter  float64
+	ChecksumAvailable bool
+	ChecksumValid     bool
+}
+
+// Trace is a decoded SOR file
+type Trace struct {
+	Version  int // 1 or 2
+	Blocks   []Block
+	General  GeneralParams
+	Supplier SupplierParams
+	Fixed    FixedParams
+	Events   []Event
+	Summary  Summary
+}
+
+// Parse decodes a SOR file
+func Parse(data []byte) (*Trace, error) {
+	t := &Trace{}
+	if err := t.readMap(data); err != nil {
+		return nil, err
+	}
+
+	if b, ok := t.block("GenParams"); ok {
+		t.readGeneral(t.blockReader(data, b))
+	}
+	if b, ok := t.block("SupParams"); ok {
+		t.readSupplier(t.blockReader(data, b))
+	}
+	b, ok := t.block("FxdParams")
+	if !ok {
+		return nil, fmt.Errorf("%w: no FxdParams block", ErrInvalidSOR)
+	}
+	if err := t.readFixed(t.blockReader(data, b)); err != nil {
+		return nil, err
+	}
+	if b, ok := t.block("KeyEvents"); ok {
+		if err := t.readEvents(t.blockReader(data, b)); err != nil {
+			return nil, err
+		}
+	}
+	if b, ok := t.block("Cksum"); ok {
+		t.checkSum(data, b)
+	}
+
+	return t, nil
+}
+
+// Distance converts a one-way propagation time in units of 100 ps to meters of fiber
+func (t *Trace) Distance(time100ps float64) float64 {
+	gi := t.Fixed.GroupIndex
+	if gi <= 0 {
+		gi = 1.468
+	}
+	return time100ps * 1e-10 * SpeedOfLight / gi
+}
+
+func (t *Trace) readMap(data []byte) error {
+	r := &reader{data: data}
+	if bytes.HasPrefix(data, []byte("Map\x00")) {
+		t.Version = 2
+		r.cstring()
+	} else {
+		t.Version = 1
+	}
+
+	version := r.u16()
+	size := r.u32()
+	count := int(r.u16())
+	if r.err != nil || size == 0 || int(size) > len(data) || count < 1 {
+		return fmt.Errorf("%w: unreadable map block", ErrInvalidSOR)
+	}
+	t.Blocks = append(t.Blocks, Block{Name: "Map", Version: version, Size: size})
+
+	offset := int(size)
+	for i := 1; i < count; i++ {
+		b := Block{Name: r.cstring(), Version: r.u16(), Size: r.u32(), Offset: offset}
+		if r.err != nil {
+			return fmt.Errorf("%w: truncated map block", ErrInvalidSOR)
+		}
+		offset += int(b.Size)
+		t.Blocks = append(t.Blocks, b)
+	}
+	return nil
+}
+
+func (t *Trace) block(name string) (Block, bool) {
+	for _, b := range t.Blocks {
+		if b.Name == name {
+			return b, true
+		}
+	}
+	return Block{}, false
+}
+
+// blockReader returns a reader over a block, past the block name that version 2 repeats
+func (t *Trace) blockReader(data []byte, b Block) *reader {
+	end := b.Offset + int(b.Size)
+	if b.Offset > len(data) {
+		return &reader{err: ErrInvalidSOR}
+	}
+	if end > len(data) {
+		end = len(data)
+	}
+	r := &reader{data: data[b.Offset:end]}
+	if t.Version == 2 {
+		r.cstring()
+	}
+	return r
+}
+
+func (t *Trace) readGeneral(r *reader) {
+	g := &t.General
+	g.Language = r.fixed(2)
+	g.CableID = r.cstring()
+	g.FiberID = r.cstring()
+	if t.Version == 2 {
+		g.FiberType = r.u16()
+	}
+	g.WavelengthNM = r.u16()
+	g.LocationA = r.cstring()
+	g.LocationB = r.cstring()
+	g.CableCode = r.cstring()
+	g.BuildCondition = r.fixed(2)
+	r.i32() // User offset
+	if t.Version == 2 {
+		r.i32() // User offset distance
+	}
+	g.Operator = r.cstring()
+	g.Comment = r.cstring()
+}
+
+func (t *Trace) readSupplier(r *reader) {
+	s := &t.Supplier
+	s.Supplier = r.cstring()
+	s.OTDRModel = r.cstring()
+	s.OTDRSerial = r.cstring()
+	s.ModuleModel = r.cstring()
+	s.ModuleSerial = r.cstring()
+	s.SoftwareVersion = r.cstring()
+	s.Other = r.cstring()
+}
+
+func (t *Trace) readFixed(r *reader) error {
+	f := &t.Fixed
+	if stamp := r.u32(); stamp > 0 {
+		f.Timestamp = time.Unix(int64(stamp), 0).UTC()
+	}
+	f.DistanceUnits = r.fixed(2)
+	f.WavelengthNM = float64(r.u16()) / 10
+	r.i32() // Acquisition offset
+	if t.Version == 2 {
+		r.i32() // Acquisition offset distance
+	}
+	n := int(r.u16())
+	for i := 0; i < n; i++ {
+		f.PulseWidthsNS = append(f.PulseWidthsNS, r.u16())
+	}
+	for i := 0; i < n; i++ {
+		r.u32() // Data spacing
+	}
+	for i := 0; i < n; i++ {
+		r.u32() // Number of data points
+	}
+	f.GroupIndex = float64(r.u32()) / 100000
+	f.BackscatterDB = -float64(r.u16()) / 10
+	f.Averages = r.u32()
+	if t.Version == 2 {
+		r.u16() // Averaging time
+	}
+	f.AcquisitionRange = r.u32()
+
+	if r.err != nil {
+		return fmt.Errorf("%w: truncated FxdParams block", ErrInvalidSOR)
+	}
+	if f.GroupIndex < 1 || f.GroupIndex > 2 {
+		return fmt.Errorf("%w: implausible group index %.5f", ErrInvalidSOR, f.GroupIndex)
+	}
+	return nil
+}
+
+func (t *Trace) readEvents(r *reader) error {
+	count := int(r.u16())
+	for i := 0; i < count; i++ {
+		e := Event{
+			Number:             int(r.u16()),
+			DistanceMeter:      t.Distance(float64(r.u32())),
+			AttenuationDBPerKm: float64(r.i16()) / 1000,
+			LossDB:             float64(r.i16()) / 1000,
+			ReflectanceDB:      float64(r.i32()) / 1000,
+			Code:               r.fixed(6),
+			Technique:          r.fixed(2),
+		}
+		if t.Version == 2 {
+			for m := 0; m < 5; m++ {
+				r.u32() // Marker locations
+			}
+		}
+		e.Comment = strings.TrimSpace(r.cstring())
+		e.Kind = eventKind(e.Code)
+		if r.err != nil {
+			return fmt.Errorf("%w: truncated KeyEvents block at event %d", ErrInvalidSOR, i+1)
+		}
+		t.Events = append(t.Events, e)
+	}
+
+	s := &t.Summary
+	s.TotalLossDB = float64(r.i32()) / 1000
+	s.LossStartMeter = t.Distance(float64(r.i32()))
+	s.LossFinishMeter = t.Distance(float64(r.u32()))
+	s.ORLDB = float64(r.u16()) / 1000
+	if r.err != nil {
+		// Some instruments stop after the events; the summary is then derived from them
+		s.TotalLossDB, s.LossStartMeter, s.LossFinishMeter, s.ORLDB = 0, 0, 0, 0
+		for _, e := range t.Events {
+			s.TotalLossDB += e.LossDB
+		}
+	}
+	for _, e := range t.Events {
+		if e.Kind == EventEnd && e.DistanceMeter > s.FiberLengthMeter {
+			s.FiberLengthMeter = e.DistanceMeter
+		}
+	}
+	if s.FiberLengthMeter == 0 {
+		s.FiberLengthMeter = s.LossFinishMeter
+	}
+	return nil
+}
+
+// checkSum verifies the CRC-16/CCITT stored in the Cksum block over every preceding byte
+func (t *Trace) checkSum(data []byte, b Block) {
+	r := t.blockReader(data, b)
+	at := b.Offset + r.pos
+	stored := r.u16()
+	if r.err != nil {
+		return
+	}
+	t.Summary.ChecksumAvailable = true
+	t.Summary.ChecksumValid = crc16CCITT(data[:at]) == stored
+}
+
+// eventKind reads the event code: the first character tells whether the event is reflective,
+// the second is E for the end of the fiber
+func eventKind(code string) EventKind {
+	if len(code) >= 2 && code[1] == 'E' {
+		return EventEnd
+	}
+	if len(code) >= 1 && (code[0] == '1' || code[0] == '2') {
+		return EventReflective
+	}
+	return EventNonReflective
+}
+
+func crc16CCITT(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// reader decodes little-endian SOR fields, remembering the first overrun
+type reader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *reader) take(n int) []byte {
+	if r.err != nil || r.pos+n > len(r.data) {
+		r.err = ErrInvalidSOR
+		return make([]byte, n)
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *reader) u16() uint16 { return binary.LittleEndian.Uint16(r.take(2)) }
+func (r *reader) i16() int16  { return int16(r.u16()) }
+func (r *reader) u32() uint32 { return binary.LittleEndian.Uint32(r.take(4)) }
+func (r *reader) i32() int32  { return int32(r.u32()) }
+
+func (r *reader) fixed(n int) string {
+	return strings.TrimRight(string(r.take(n)), "\x00 ")
+}
+
+func (r *reader) cstring() string {
+	if r.err != nil {
+		return ""
+	}
+	end := bytes.IndexByte(r.data[r.pos:], 0)
+	if end < 0 {
+		r.err = ErrInvalidSOR
+		return ""
+	}
+	s := string(r.data[r.pos : r.pos+end])
+	r.pos += end + 1
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,270 @@
+package otdr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+// sorWriter writes little-endian SOR fields
+type sorWriter struct {
+	bytes.Buffer
+}
+
+func (w *sorWriter) u16(v uint16) { binary.Write(w, binary.LittleEndian, v) }
+func (w *sorWriter) i16(v int16)  { binary.Write(w, binary.LittleEndian, v) }
+func (w *sorWriter) u32(v uint32) { binary.Write(w, binary.LittleEndian, v) }
+func (w *sorWriter) i32(v int32)  { binary.Write(w, binary.LittleEndian, v) }
+func (w *sorWriter) cstring(s string) {
+	w.WriteString(s)
+	w.WriteByte(0)
+}
+
+// sorEvent is a key event of a test fixture, at a one-way time in units of 100 ps
+type sorEvent struct {
+	number  uint16
+	time    uint32
+	lossMDB int16
+	code    string
+	comment string
+}
+
+// buildSOR writes a version 1 or 2 SOR file with the given key events, measured at 1550 nm with a
+// group index of 1.468. It returns the file and the offset of each block.
+func buildSOR(version int, groupIndex uint32, events []sorEvent, withSummary bool) ([]byte, map[string]int) {
+	v2 := version == 2
+	named := func(name string) *sorWriter {
+		w := &sorWriter{}
+		if v2 {
+			w.cstring(name)
+		}
+		return w
+	}
+
+	gen := named("GenParams")
+	gen.WriteString("EN")
+	gen.cstring("FEEDER-01")
+	gen.cstring("F007")
+	if v2 {
+		gen.u16(652)
+	}
+	gen.u16(1550)
+	gen.cstring("OLT Central")
+	gen.cstring("ODC North")
+	gen.cstring("")
+	gen.WriteString("BC")
+	gen.i32(0)
+	if v2 {
+		gen.i32(0)
+	}
+	gen.cstring("Rina")
+	gen.cstring("as built")
+
+	sup := named("SupParams")
+	for _, s := range []string{"Acme", "OTDR-9", "SN123", "", "", "1.0", ""} {
+		sup.cstring(s)
+	}
+
+	fxd := named("FxdParams")
+	fxd.u32(1700000000)
+	fxd.WriteString("mt")
+	fxd.u16(15500)
+	fxd.i32(0)
+	if v2 {
+		fxd.i32(0)
+	}
+	fxd.u16(1)
+	fxd.u16(100)
+	fxd.u32(1000)
+	fxd.u32(30000)
+	fxd.u32(groupIndex)
+	fxd.u16(800)
+	fxd.u32(1000)
+	if v2 {
+		fxd.u16(30)
+	}
+	fxd.u32(40000)
+
+	key := named("KeyEvents")
+	key.u16(uint16(len(events)))
+	var totalLoss int32
+	for _, e := range events {
+		key.u16(e.number)
+		key.u32(e.time)
+		key.i16(350)
+		key.i16(e.lossMDB)
+		key.i32(-45000)
+		key.WriteString(e.code)
+		key.WriteString("2P")
+		if v2 {
+			for m := 0; m < 5; m++ {
+				key.u32(0)
+			}
+		}
+		key.cstring(e.comment)
+		totalLoss += int32(e.lossMDB)
+	}
+	if withSummary {
+		key.i32(totalLoss)
+		key.i32(0)
+		key.u32(events[len(events)-1].time)
+		key.u16(32000)
+	}
+
+	blocks := []struct {
+		name string
+		data []byte
+	}{
+		{"GenParams", gen.Bytes()},
+		{"SupParams", sup.Bytes()},
+		{"FxdParams", fxd.Bytes()},
+		{"KeyEvents", key.Bytes()},
+	}
+
+	entries := &sorWriter{}
+	for _, b := range blocks {
+		entries.cstring(b.name)
+		entries.u16(200)
+		entries.u32(uint32(len(b.data)))
+	}
+	entries.cstring("Cksum")
+	entries.u16(200)
+	cksumSize := 2
+	if v2 {
+		cksumSize += len("Cksum") + 1
+	}
+	entries.u32(uint32(cksumSize))
+
+	mapSize := 2 + 4 + 2 + entries.Len()
+	if v2 {
+		mapSize += len("Map") + 1
+	}
+	out := &sorWriter{}
+	if v2 {
+		out.cstring("Map")
+	}
+	out.u16(200)
+	out.u32(uint32(mapSize))
+	out.u16(uint16(len(blocks) + 2))
+	out.Write(entries.Bytes())
+
+	offsets := map[string]int{}
+	for _, b := range blocks {
+		offsets[b.name] = out.Len()
+		out.Write(b.data)
+	}
+	offsets["Cksum"] = out.Len()
+	if v2 {
+		out.cstring("Cksum")
+	}
+	out.u16(crc16CCITT(out.Bytes()))
+	return out.Bytes(), offsets
+}
+
+// eventsAt returns a splice at 1 km and the fiber end at 2.5 km, as one-way times for a group index of 1.468
+func eventsAt() []sorEvent {
+	time := func(meters float64) uint32 { return uint32(math.Round(meters * 1.468 / (SpeedOfLight * 1e-10))) }
+	return []sorEvent{
+		{number: 1, time: time(1000), lossMDB: 80, code: "0F9999", comment: "closure A"},
+		{number: 2, time: time(2500), lossMDB: 0, code: "2E9999"},
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     int
+		withSummary bool
+		totalLoss   float64
+	}{
+		{name: "version 1", version: 1, withSummary: true, totalLoss: 0.08},
+		{name: "version 2", version: 2, withSummary: true, totalLoss: 0.08},
+		{name: "version 2 without summary", version: 2, withSummary: false, totalLoss: 0.08},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := buildSOR(tt.version, 146800, eventsAt(), tt.withSummary)
+			trace, err := Parse(data)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if trace.Version != tt.version {
+				t.Errorf("Version = %d, want %d", trace.Version, tt.version)
+			}
+			if trace.General.CableID != "FEEDER-01" || trace.General.FiberID != "F007" || trace.General.Operator != "Rina" {
+				t.Errorf("General = %+v", trace.General)
+			}
+			if trace.Supplier.Supplier != "Acme" || trace.Supplier.OTDRModel != "OTDR-9" || trace.Supplier.OTDRSerial != "SN123" {
+				t.Errorf("Supplier = %+v", trace.Supplier)
+			}
+			if trace.Fixed.WavelengthNM != 1550 || trace.Fixed.GroupIndex != 1.468 {
+				t.Errorf("Fixed wavelength %v, group index %v", trace.Fixed.WavelengthNM, trace.Fixed.GroupIndex)
+			}
+			if len(trace.Events) != 2 {
+				t.Fatalf("got %d events, want 2", len(trace.Events))
+			}
+			if e := trace.Events[0]; e.Kind != EventNonReflective || math.Abs(e.DistanceMeter-1000) > 0.1 ||
+				e.LossDB != 0.08 || e.Comment != "closure A" {
+				t.Errorf("first event = %+v", e)
+			}
+			if e := trace.Events[1]; e.Kind != EventEnd || math.Abs(e.DistanceMeter-2500) > 0.1 {
+				t.Errorf("end event = %+v", e)
+			}
+			if math.Abs(trace.Summary.FiberLengthMeter-2500) > 0.1 {
+				t.Errorf("FiberLengthMeter = %v, want 2500", trace.Summary.FiberLengthMeter)
+			}
+			if math.Abs(trace.Summary.TotalLossDB-tt.totalLoss) > 1e-9 {
+				t.Errorf("TotalLossDB = %v, want %v", trace.Summary.TotalLossDB, tt.totalLoss)
+			}
+			if !trace.Summary.ChecksumAvailable || !trace.Summary.ChecksumValid {
+				t.Errorf("checksum available %v, valid %v", trace.Summary.ChecksumAvailable, trace.Summary.ChecksumValid)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	v1, v1Offsets := buildSOR(1, 146800, eventsAt(), true)
+	v2, v2Offsets := buildSOR(2, 146800, eventsAt(), true)
+	badIndex, _ := buildSOR(1, 300000, eventsAt(), true)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: nil},
+		{name: "not a SOR file", data: []byte("hello, world")},
+		{name: "version 1 truncated in the map", data: v1[:12]},
+		{name: "version 2 truncated in the map", data: v2[:20]},
+		{name: "version 1 truncated in FxdParams", data: v1[:v1Offsets["FxdParams"]+10]},
+		{name: "version 2 truncated in FxdParams", data: v2[:v2Offsets["FxdParams"]+20]},
+		{name: "version 1 truncated in an event", data: v1[:v1Offsets["KeyEvents"]+10]},
+		{name: "version 2 truncated in an event", data: v2[:v2Offsets["KeyEvents"]+20]},
+		{name: "implausible group index", data: badIndex},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.data); !errors.Is(err, ErrInvalidSOR) {
+				t.Errorf("Parse() error = %v, want ErrInvalidSOR", err)
+			}
+		})
+	}
+}
+
+func TestParseChecksumMismatch(t *testing.T) {
+	data, offsets := buildSOR(2, 146800, eventsAt(), true)
+	data[offsets["SupParams"]+len("SupParams")+1] = 'a'
+
+	trace, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !trace.Summary.ChecksumAvailable || trace.Summary.ChecksumValid {
+		t.Errorf("checksum available %v, valid %v, want a mismatch", trace.Summary.ChecksumAvailable, trace.Summary.ChecksumValid)
+	}
+}
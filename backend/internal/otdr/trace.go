@@ -0,0 +1,81 @@
+package otdr
+
+import (
+	"spectra-backend/internal/models"
+)
+
+// ToModel maps a decoded SOR file to a trace with its events. The core, start node and slack are
+// left for the caller.
+func (t *Trace) ToModel() *models.OTDRTrace {
+	trace := &models.OTDRTrace{
+		SORVersion:       t.Version,
+		WavelengthNM:     t.Fixed.WavelengthNM,
+		GroupIndex:       t.Fixed.GroupIndex,
+		FiberLengthMeter: t.Summary.FiberLengthMeter,
+		TotalLossDB:      t.Summary.TotalLossDB,
+		Supplier:         optional(t.Supplier.Supplier),
+		OTDRModel:        optional(t.Supplier.OTDRModel),
+		OTDRSerial:       optional(t.Supplier.OTDRSerial),
+		CableLabel:       optional(t.General.CableID),
+		FiberLabel:       optional(t.General.FiberID),
+		LocationA:        optional(t.General.LocationA),
+		LocationB:        optional(t.General.LocationB),
+		Operator:         optional(t.General.Operator),
+		Events:           make([]models.OTDREvent, 0, len(t.Events)),
+	}
+	if trace.WavelengthNM == 0 {
+		trace.WavelengthNM = float64(t.General.WavelengthNM)
+	}
+	if len(t.Fixed.PulseWidthsNS) > 0 {
+		width := int(t.Fixed.PulseWidthsNS[0])
+		trace.PulseWidthNS = &width
+	}
+	if t.Summary.ORLDB > 0 {
+		orl := t.Summary.ORLDB
+		trace.ORLDB = &orl
+	}
+	if !t.Fixed.Timestamp.IsZero() {
+		acquired := t.Fixed.Timestamp
+		trace.AcquiredAt = &acquired
+	}
+
+	for _, e := range t.Events {
+		event := models.OTDREvent{
+			EventNumber:   e.Number,
+			Type:          eventType(e.Kind),
+			Code:          e.Code,
+			DistanceMeter: e.DistanceMeter,
+			LossDB:        e.LossDB,
+			Comment:       optional(e.Comment),
+		}
+		if e.Kind != EventNonReflective && e.ReflectanceDB != 0 {
+			reflectance := e.ReflectanceDB
+			event.ReflectanceDB = &reflectance
+		}
+		if e.AttenuationDBPerKm != 0 {
+			attenuation := e.AttenuationDBPerKm
+			event.AttenuationDBPerKm = &attenuation
+		}
+		trace.Events = append(trace.Events, event)
+	}
+	trace.EventCount = len(trace.Events)
+	return trace
+}
+
+func eventType(kind EventKind) models.OTDREventType {
+	switch kind {
+	case EventEnd:
+		return models.OTDREventEnd
+	case EventReflective:
+		return models.OTDREventConnector
+	default:
+		return models.OTDREventSplice
+	}
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,652 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"spectra-backend/internal/geo"
+	"spectra-backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrServiceAreaNotFound is returned when a service area ID is unknown
+	ErrServiceAreaNotFound = errors.New("service area not found")
+	// ErrInvalidServiceArea is returned when an area's polygon, level or parent is unusable
+	ErrInvalidServiceArea = errors.New("invalid service area")
+	// ErrServiceAreaInUse is returned when deleting an area that still has sub-areas
+	ErrServiceAreaInUse = errors.New("service area has sub-areas")
+)
+
+// serviceAreaSelect selects service areas
+const serviceAreaSelect = `
+	SELECT id, name, level, parent_id, polygon, maintenance_team, description, created_at, updated_at
+	FROM service_areas
+`
+
+// areaSubtreeSQL selects the IDs of a service area and every area below it. The area ID is
+// parameter %[1]d.
+const areaSubtreeSQL = `(
+	WITH RECURSIVE subtree AS (
+		SELECT id FROM service_areas WHERE id = $%[1]d
+		UNION ALL
+		SELECT a.id FROM service_areas a JOIN subtree s ON a.parent_id = s.id
+	)
+	SELECT id FROM subtree
+)`
+
+// areaNodesSQL selects the IDs of the nodes in a service area or any area below it, the area ID
+// being parameter n
+func areaNodesSQL(n int) string {
+	return fmt.Sprintf("(SELECT id FROM nodes WHERE area_id IN "+areaSubtreeSQL+")", n)
+}
+
+// areaCablesSQL selects the IDs of the cables with an end in a service area
+func areaCablesSQL(n int) string {
+	nodes := areaNodesSQL(n)
+	return fmt.Sprintf("(SELECT id FROM cables WHERE origin_node_id IN %s OR dest_node_id IN %s)", nodes, nodes)
+}
+
+// areaCustomersSQL selects the IDs of the customers whose node is in a service area
+func areaCustomersSQL(n int) string {
+	return fmt.Sprintf("(SELECT id FROM customers WHERE node_id IN %s)", areaNodesSQL(n))
+}
+
+// areaWorkspacesSQL selects the IDs of the design workspaces with items drafted at live nodes of a
+// service area, or merged into live nodes, cables or customers of it
+func areaWorkspacesSQL(n int) string {
+	nodes := areaNodesSQL(n)
+	return fmt.Sprintf(`(SELECT workspace_id FROM design_items WHERE
+		(item_type = 'NODE' AND live_id IN %[1]s)
+		OR (item_type = 'CABLE' AND (live_id IN %[2]s
+			OR (payload->>'origin_node_id')::bigint IN %[1]s OR (payload->>'dest_node_id')::bigint IN %[1]s))
+		OR (item_type = 'CUSTOMER' AND (live_id IN %[3]s OR (payload->>'node_id')::bigint IN %[1]s))
+		OR (item_type = 'SPLICE' AND (payload->>'location_node_id')::bigint IN %[1]s))`,
+		nodes, areaCablesSQL(n), areaCustomersSQL(n))
+}
+
+// areaElementSQL matches an element_type and element_id pair, such as a ticket link or a work
+// order asset, that refers to a node, cable or customer in a service area
+func areaElementSQL(alias string, n int) string {
+	return fmt.Sprintf(`((%[1]s.element_type = 'NODE' AND %[1]s.element_id IN %[2]s)
+		OR (%[1]s.element_type = 'CABLE' AND %[1]s.element_id IN %[3]s)
+		OR (%[1]s.element_type = 'CUSTOMER' AND %[1]s.element_id IN %[4]s))`,
+		alias, areaNodesSQL(n), areaCablesSQL(n), areaCustomersSQL(n))
+}
+
+// AreaRepository handles database operations for service areas
+type AreaRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewAreaRepository creates a new AreaRepository
+func NewAreaRepository(pool *pgxpool.Pool) *AreaRepository {
+	return &AreaRepository{pool: pool}
+}
+
+// Create adds a service area and reassigns every node, since the new area may now be the deepest
+// one containing them
+func (r *AreaRepository) Create(ctx context.Context, req *models.CreateServiceAreaRequest) (*models.ServiceArea, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidServiceArea)
+	}
+	if !req.Level.IsValid() {
+		return nil, fmt.Errorf("%w: unknown level %q", ErrInvalidServiceArea, req.Level)
+	}
+	polygon, err := encodeAreaPolygon(req.Polygon)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := checkAreaParent(ctx, tx, 0, req.Level, req.ParentID); err != nil {
+		return nil, err
+	}
+
+	area, err := scanServiceArea(tx.QueryRow(ctx, `
+		INSERT INTO service_areas (name, level, parent_id, polygon, maintenance_team, description)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, name, level, parent_id, polygon, maintenance_team, description, created_at, updated_at
+	`, req.Name, req.Level, req.ParentID, polygon, req.MaintenanceTeam, req.Description))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create service area: %w", err)
+	}
+
+	if _, err := assignAreas(ctx, tx, nil); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit service area: %w", err)
+	}
+
+	return area, nil
+}
+
+// GetByID retrieves a service area with its direct sub-areas
+func (r *AreaRepository) GetByID(ctx context.Context, id int64) (*models.ServiceArea, error) {
+	area, err := getServiceArea(ctx, r.pool, id)
+	if err != nil || area == nil {
+		return area, err
+	}
+
+	area.Children, err = listServiceAreas(ctx, r.pool, " WHERE parent_id = $1 ORDER BY name", id)
+	if err != nil {
+		return nil, err
+	}
+
+	return area, nil
+}
+
+// List retrieves service areas with optional filters
+func (r *AreaRepository) List(ctx context.Context, filter *models.ServiceAreaFilter) ([]models.ServiceArea, int64, error) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.Level != nil {
+		where += fmt.Sprintf(" AND level = $%d", argIndex)
+		args = append(args, *filter.Level)
+		argIndex++
+	}
+	if filter.ParentID != nil {
+		where += fmt.Sprintf(" AND parent_id = $%d", argIndex)
+		args = append(args, *filter.ParentID)
+		argIndex++
+	}
+	if filter.Search != nil {
+		where += fmt.Sprintf(" AND name ILIKE $%d", argIndex)
+		args = append(args, "%"+*filter.Search+"%")
+		argIndex++
+	}
+
+	var total int64
+	if err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM service_areas"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count service areas: %w", err)
+	}
+
+	limit := 100
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+	offset := 0
+	if filter.Offset > 0 {
+		offset = filter.Offset
+	}
+	args = append(args, limit, offset)
+
+	areas, err := listServiceAreas(ctx, r.pool,
+		fmt.Sprintf("%s ORDER BY CASE level WHEN 'REGION' THEN 0 WHEN 'DISTRICT' THEN 1 ELSE 2 END, name LIMIT $%d OFFSET $%d",
+			where, argIndex, argIndex+1), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return areas, total, nil
+}
+
+// ListAll retrieves every service area ordered by ID, for exports
+func (r *AreaRepository) ListAll(ctx context.Context) ([]models.ServiceArea, error) {
+	return listServiceAreas(ctx, r.pool, " ORDER BY id")
+}
+
+// Update edits a service area. Nodes are reassigned when the polygon or parent changes.
+func (r *AreaRepository) Update(ctx context.Context, id int64, req *models.UpdateServiceAreaRequest) (*models.ServiceArea, error) {
+	if req.Name != nil && strings.TrimSpace(*req.Name) == "" {
+		return nil, fmt.Errorf("%w: name cannot be empty", ErrInvalidServiceArea)
+	}
+	var polygon []byte
+	if req.Polygon != nil {
+		var err error
+		if polygon, err = encodeAreaPolygon(req.Polygon); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	areas, err := listServiceAreas(ctx, tx, " WHERE id = $1 FOR UPDATE", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(areas) == 0 {
+		return nil, ErrServiceAreaNotFound
+	}
+	if req.ParentID != nil {
+		if err := checkAreaParent(ctx, tx, id, areas[0].Level, req.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	area, err := scanServiceArea(tx.QueryRow(ctx, `
+		UPDATE service_areas SET name = COALESCE($1, name), parent_id = COALESCE($2, parent_id),
+			polygon = COALESCE($3, polygon), maintenance_team = COALESCE($4, maintenance_team),
+			description = COALESCE($5, description)
+		WHERE id = $6
+		RETURNING id, name, level, parent_id, polygon, maintenance_team, description, created_at, updated_at
+	`, req.Name, req.ParentID, polygon, req.MaintenanceTeam, req.Description, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update service area: %w", err)
+	}
+
+	if req.Polygon != nil || req.ParentID != nil {
+		if _, err := assignAreas(ctx, tx, nil); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit service area: %w", err)
+	}
+
+	return area, nil
+}
+
+// Delete removes a service area without sub-areas. Its nodes fall back to the next area containing them.
+func (r *AreaRepository) Delete(ctx context.Context, id int64) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var children int
+	if err := tx.QueryRow(ctx, "SELECT COUNT(*) FROM service_areas WHERE parent_id = $1", id).Scan(&children); err != nil {
+		return fmt.Errorf("failed to check sub-areas: %w", err)
+	}
+	if children > 0 {
+		return fmt.Errorf("%w: %d sub-areas", ErrServiceAreaInUse, children)
+	}
+
+	tag, err := tx.Exec(ctx, "DELETE FROM service_areas WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete service area: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrServiceAreaNotFound
+	}
+
+	if _, err := assignAreas(ctx, tx, nil); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit service area deletion: %w", err)
+	}
+	return nil
+}
+
+// Assign reassigns every node the caller owns, or every node for the operator, to the deepest
+// service area of its owner containing it
+func (r *AreaRepository) Assign(ctx context.Context) (*models.AreaAssignment, error) {
+	return assignAreas(ctx, r.pool, nil)
+}
+
+// Stats totals the nodes, ports, cables, customers and open tickets in a service area and the
+// areas below it
+func (r *AreaRepository) Stats(ctx context.Context, id int64) (*models.AreaStats, error) {
+	area, err := getServiceArea(ctx, r.pool, id)
+	if err != nil {
+		return nil, err
+	}
+	if area == nil {
+		return nil, ErrServiceAreaNotFound
+	}
+
+	stats := &models.AreaStats{
+		Area:              *area,
+		NodesByType:       map[models.NodeType]int{},
+		CustomersByStatus: map[models.CustomerStatus]int{},
+	}
+
+	if err := r.pool.QueryRow(ctx, fmt.Sprintf("SELECT COUNT(*) - 1 FROM "+areaSubtreeSQL+" s", 1), id).Scan(&stats.SubAreas); err != nil {
+		return nil, fmt.Errorf("failed to count sub-areas: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT type, COUNT(*),
+			COALESCE(SUM(capacity_ports) FILTER (WHERE type = 'ODP'), 0),
+			COALESCE(SUM(used_ports) FILTER (WHERE type = 'ODP'), 0)
+		FROM nodes WHERE id IN `+areaNodesSQL(1)+`
+		GROUP BY type
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count area nodes: %w", err)
+	}
+	for rows.Next() {
+		var nodeType models.NodeType
+		var count, capacity, used int
+		if err := rows.Scan(&nodeType, &count, &capacity, &used); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan area nodes: %w", err)
+		}
+		stats.NodesByType[nodeType] = count
+		stats.Nodes += count
+		stats.CapacityPorts += capacity
+		stats.UsedPorts += used
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count area nodes: %w", err)
+	}
+	if stats.CapacityPorts > 0 {
+		stats.PortUtilization = math.Round(float64(stats.UsedPorts)/float64(stats.CapacityPorts)*1000) / 1000
+	}
+
+	err = r.pool.QueryRow(ctx, "SELECT COUNT(*), COALESCE(SUM(length_meter), 0) FROM cables WHERE id IN "+areaCablesSQL(1), id).
+		Scan(&stats.Cables, &stats.CableLengthMeter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count area cables: %w", err)
+	}
+
+	rows, err = r.pool.Query(ctx, "SELECT current_status, COUNT(*) FROM customers WHERE node_id IN "+areaNodesSQL(1)+" GROUP BY current_status", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count area customers: %w", err)
+	}
+	for rows.Next() {
+		var status models.CustomerStatus
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan area customers: %w", err)
+		}
+		stats.CustomersByStatus[status] = count
+		stats.Customers += count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count area customers: %w", err)
+	}
+
+	err = r.pool.QueryRow(ctx, `
+		SELECT COUNT(DISTINCT t.id) FROM tickets t JOIN ticket_links l ON l.ticket_id = t.id
+		WHERE t.status NOT IN ('RESOLVED', 'CLOSED') AND `+areaElementSQL("l", 1), id).Scan(&stats.OpenTickets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count area tickets: %w", err)
+	}
+
+	return stats, nil
+}
+
+// checkAreaParent checks that an area of the level may sit under the parent: regions have no
+// parent, districts sit in a region and clusters in a district. An area cannot be its own parent.
+func checkAreaParent(ctx context.Context, q querier, id int64, level models.AreaLevel, parentID *int64) error {
+	want := level.ParentLevel()
+	if want == "" {
+		if parentID != nil {
+			return fmt.Errorf("%w: a region has no parent", ErrInvalidServiceArea)
+		}
+		return nil
+	}
+	if parentID == nil {
+		return fmt.Errorf("%w: a %s needs a parent %s", ErrInvalidServiceArea, strings.ToLower(string(level)), strings.ToLower(string(want)))
+	}
+	if *parentID == id {
+		return fmt.Errorf("%w: an area cannot be its own parent", ErrInvalidServiceArea)
+	}
+
+	parent, err := getServiceArea(ctx, q, *parentID)
+	if err != nil {
+		return err
+	}
+	if parent == nil {
+		return fmt.Errorf("%w: parent %d not found", ErrInvalidServiceArea, *parentID)
+	}
+	if parent.Level != want {
+		return fmt.Errorf("%w: the parent of a %s must be a %s, not a %s", ErrInvalidServiceArea,
+			strings.ToLower(string(level)), strings.ToLower(string(want)), strings.ToLower(string(parent.Level)))
+	}
+	return nil
+}
+
+// encodeAreaPolygon checks a polygon of at least three [lng, lat] points and encodes it for the
+// polygon column
+func encodeAreaPolygon(polygon [][]float64) ([]byte, error) {
+	if len(polygon) < 3 {
+		return nil, fmt.Errorf("%w: polygon needs at least 3 points", ErrInvalidServiceArea)
+	}
+	for i, p := range polygon {
+		if len(p) < 2 || p[0] < -180 || p[0] > 180 || p[1] < -90 || p[1] > 90 {
+			return nil, fmt.Errorf("%w: point %d is not a valid [lng, lat]", ErrInvalidServiceArea, i+1)
+		}
+	}
+	data, err := json.Marshal(polygon)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode polygon: %w", err)
+	}
+	return data, nil
+}
+
+// areaIndex finds the deepest service area containing a point
+type areaIndex struct {
+	areas []indexedArea
+}
+
+type indexedArea struct {
+	id                             int64
+	depth                          int
+	ring                           [][]float64
+	minLng, minLat, maxLng, maxLat float64
+}
+
+func newAreaIndex(areas []models.ServiceArea) *areaIndex {
+	index := &areaIndex{}
+	for _, a := range areas {
+		ia := indexedArea{id: a.ID, depth: a.Level.Depth(), ring: a.Polygon,
+			minLng: math.Inf(1), minLat: math.Inf(1), maxLng: math.Inf(-1), maxLat: math.Inf(-1)}
+		for _, p := range a.Polygon {
+			if len(p) < 2 {
+				continue
+			}
+			ia.minLng, ia.maxLng = math.Min(ia.minLng, p[0]), math.Max(ia.maxLng, p[0])
+			ia.minLat, ia.maxLat = math.Min(ia.minLat, p[1]), math.Max(ia.maxLat, p[1])
+		}
+		index.areas = append(index.areas, ia)
+	}
+	return index
+}
+
+// locate returns the deepest area containing the point, the lowest ID winning among overlapping
+// areas of one level, or nil when no area contains it
+func (x *areaIndex) locate(lng, lat float64) *int64 {
+	var best *indexedArea
+	for i := range x.areas {
+		a := &x.areas[i]
+		if lng < a.minLng || lng > a.maxLng || lat < a.minLat || lat > a.maxLat {
+			continue
+		}
+		if (best == nil || a.depth > best.depth) && geo.PointInPolygon(lng, lat, a.ring) {
+			best = a
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	id := best.id
+	return &id
+}
+
+// loadAreaIndex indexes the service areas of nodes the caller creates: the shared areas and the caller's
+// own. Callers creating many nodes load it once and pass it on.
+func loadAreaIndex(ctx context.Context, q querier) (*areaIndex, error) {
+	areas, err := listServiceAreas(ctx, q, " WHERE tenant_id IS NULL OR tenant_id = current_tenant_id() ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	return newAreaIndex(areas), nil
+}
+
+// ownerAreaIndex indexes the service areas of nodes owned by a tenant, or shared when tenantID is 0
+func ownerAreaIndex(ctx context.Context, q querier, tenantID int64) (*areaIndex, error) {
+	areas, err := listServiceAreas(ctx, q, " WHERE tenant_id IS NULL OR tenant_id = $1 ORDER BY id", tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return newAreaIndex(areas), nil
+}
+
+// assignAreas sets the area of the given nodes, or of every node when nodeIDs is nil, by
+// point-in-polygon against the service areas of each node's owner. A tenant only assigns the nodes
+// it owns; the operator assigns every node, so that one tenant's areas never show on another's nodes.
+func assignAreas(ctx context.Context, q querier, nodeIDs []int64) (*models.AreaAssignment, error) {
+	query := `
+		SELECT id, latitude, longitude, area_id, COALESCE(tenant_id, 0) FROM nodes
+		WHERE (current_tenant_id() IS NULL OR tenant_id = current_tenant_id())`
+	args := []interface{}{}
+	if nodeIDs != nil {
+		query, args = query+" AND id = ANY($1)", append(args, nodeIDs)
+	}
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load nodes for area assignment: %w", err)
+	}
+
+	type located struct {
+		nodeID, ownerID int64
+		lat, lng        float64
+		current         *int64
+	}
+	var nodes []located
+	for rows.Next() {
+		var n located
+		if err := rows.Scan(&n.nodeID, &n.lat, &n.lng, &n.current, &n.ownerID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan node for area assignment: %w", err)
+		}
+		nodes = append(nodes, n)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load nodes for area assignment: %w", err)
+	}
+
+	type change struct {
+		nodeID int64
+		areaID *int64
+	}
+	var changes []change
+	result := &models.AreaAssignment{}
+	indexes := map[int64]*areaIndex{}
+	for _, n := range nodes {
+		index := indexes[n.ownerID]
+		if index == nil {
+			index, err = ownerAreaIndex(ctx, q, n.ownerID)
+			if err != nil {
+				return nil, err
+			}
+			indexes[n.ownerID] = index
+		}
+		result.Nodes++
+		areaID := index.locate(n.lng, n.lat)
+		if areaID != nil {
+			result.Assigned++
+		} else {
+			result.Unassigned++
+		}
+		if (areaID == nil) != (n.current == nil) || (areaID != nil && *areaID != *n.current) {
+			changes = append(changes, change{n.nodeID, areaID})
+		}
+	}
+
+	if len(changes) > 0 {
+		batch := &pgx.Batch{}
+		for _, c := range changes {
+			batch.Queue("UPDATE nodes SET area_id = $1 WHERE id = $2", c.areaID, c.nodeID)
+		}
+		results := q.SendBatch(ctx, batch)
+		for range changes {
+			if _, err := results.Exec(); err != nil {
+				results.Close()
+				return nil, fmt.Errorf("failed to assign node area: %w", err)
+			}
+		}
+		if err := results.Close(); err != nil {
+			return nil, fmt.Errorf("failed to assign node areas: %w", err)
+		}
+	}
+	result.Changed = len(changes)
+
+	return result, nil
+}
+
+// assignNodeArea assigns one node to its service area and records it on the node
+func assignNodeArea(ctx context.Context, q querier, node *models.Node) error {
+	if _, err := assignAreas(ctx, q, []int64{node.ID}); err != nil {
+		return err
+	}
+	if err := q.QueryRow(ctx, "SELECT area_id FROM nodes WHERE id = $1", node.ID).Scan(&node.AreaID); err != nil {
+		return fmt.Errorf("failed to get node area: %w", err)
+	}
+	return nil
+}
+
+// scanServiceArea scans a service_areas row
+func scanServiceArea(row pgx.Row) (*models.ServiceArea, error) {
+	area := &models.ServiceArea{}
+	var polygon []byte
+	err := row.Scan(
+		&area.ID,
+		&area.Name,
+		&area.Level,
+		&area.ParentID,
+		&polygon,
+		&area.MaintenanceTeam,
+		&area.Description,
+		&area.CreatedAt,
+		&area.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(polygon, &area.Polygon); err != nil {
+		return nil, fmt.Errorf("failed to decode polygon of service area %d: %w", area.ID, err)
+	}
+	return area, nil
+}
+
+// getServiceArea retrieves a service area, or nil when it does not exist
+func getServiceArea(ctx context.Context, q querier, id int64) (*models.ServiceArea, error) {
+	area, err := scanServiceArea(q.QueryRow(ctx, serviceAreaSelect+" WHERE id = $1", id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service area: %w", err)
+	}
+	return area, nil
+}
+
+// listServiceAreas runs serviceAreaSelect with the given clause
+func listServiceAreas(ctx context.Context, q querier, clause string, args ...interface{}) ([]models.ServiceArea, error) {
+	rows, err := q.Query(ctx, serviceAreaSelect+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service areas: %w", err)
+	}
+	defer rows.Close()
+
+	areas := []models.ServiceArea{}
+	for rows.Next() {
+		area, err := scanServiceArea(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan service area: %w", err)
+		}
+		areas = append(areas, *area)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list service areas: %w", err)
+	}
+
+	return areas, nil
+}
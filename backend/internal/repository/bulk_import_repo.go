@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"spectra-backend/internal/models"
+)
+
+// ImportNodeRows validates spreadsheet node rows and, when every row is valid and this is not a
+// dry run, creates them all in one transaction. rejected holds rows that already failed parsing.
+func (r *ImportRepository) ImportNodeRows(ctx context.Context, format string, rows []models.ImportNode, rejected []models.ImportItemResult, dryRun bool) (*models.ImportReport, error) {
+	names := make([]string, 0, len(rows))
+	for _, row := range rows {
+		names = append(names, strings.ToLower(row.Node.Name))
+	}
+
+	existing := map[string]int64{}
+	dbRows, err := r.pool.Query(ctx, "SELECT id, lower(name), type FROM nodes WHERE lower(name) = ANY($1)", names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing nodes: %w", err)
+	}
+	for dbRows.Next() {
+		var id int64
+		var name, nodeType string
+		if err := dbRows.Scan(&id, &name, &nodeType); err != nil {
+			dbRows.Close()
+			return nil, fmt.Errorf("failed to scan node: %w", err)
+		}
+		existing[nodeType+"|"+name] = id
+	}
+	dbRows.Close()
+	if err := dbRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to check existing nodes: %w", err)
+	}
+
+	items := append([]models.ImportItemResult{}, rejected...)
+	var valid []int
+	seen := map[string]string{}
+	for i, row := range rows {
+		item := models.ImportItemResult{
+			Ref:    row.Ref,
+			Kind:   models.ImportItemNode,
+			Action: models.ImportActionCreate,
+			Name:   row.Node.Name,
+			Type:   string(row.Node.Type),
+		}
+		key := string(row.Node.Type) + "|" + strings.ToLower(row.Node.Name)
+		if id, ok := existing[key]; ok {
+			item.Action = models.ImportActionError
+			item.Message = fmt.Sprintf("%s %q already exists (#%d)", row.Node.Type, row.Node.Name, id)
+		} else if first, ok := seen[key]; ok {
+			item.Action = models.ImportActionError
+			item.Message = fmt.Sprintf("duplicate of %s", first)
+		} else {
+			seen[key] = row.Ref
+			valid = append(valid, i)
+		}
+		items = append(items, item)
+	}
+	sortByRow(items)
+
+	report := newRowImportReport(format, dryRun, items)
+	if report.Failed > 0 || dryRun {
+		return report, nil
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	areas, err := loadAreaIndex(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	ids := map[string]int64{}
+	for _, i := range valid {
+		node, err := insertNode(ctx, tx, &rows[i].Node, areas)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rows[i].Ref, err)
+		}
+		ids[rows[i].Ref] = node.ID
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+	report.Committed = true
+	setRowIDs(report, ids)
+
+	return report, nil
+}
+
+// ImportCustomerRows validates spreadsheet customer rows (duplicate ONT SN, unknown or ambiguous ODP)
+// and, when every row is valid and this is not a dry run, creates them all in one transaction
+func (r *ImportRepository) ImportCustomerRows(ctx context.Context, format string, rows []models.CustomerImportRow, rejected []models.ImportItemResult, dryRun bool) (*models.ImportReport, error) {
+	var serials, odpNames []string
+	for _, row := range rows {
+		if row.Customer.ONTSN != nil {
+			serials = append(serials, strings.ToUpper(*row.Customer.ONTSN))
+		}
+		if row.ODPName != "" {
+			odpNames = append(odpNames, strings.ToLower(row.ODPName))
+		}
+	}
+
+	// Serials are unique per tenant; the operator sees every tenant's customers but imports its own
+	existingSerials := map[string]int64{}
+	dbRows, err := r.pool.Query(ctx, `
+		SELECT id, upper(ont_sn) FROM customers
+		WHERE upper(ont_sn) = ANY($1) AND tenant_id IS NOT DISTINCT FROM current_tenant_id()
+	`, serials)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing ONT serial numbers: %w", err)
+	}
+	for dbRows.Next() {
+		var id int64
+		var sn string
+		if err := dbRows.Scan(&id, &sn); err != nil {
+			dbRows.Close()
+			return nil, fmt.Errorf("failed to scan customer: %w", err)
+		}
+		existingSerials[sn] = id
+	}
+	dbRows.Close()
+	if err := dbRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to check existing ONT serial numbers: %w", err)
+	}
+
+	odps := map[string][]int64{}
+	dbRows, err = r.pool.Query(ctx, "SELECT id, lower(name) FROM nodes WHERE type = 'ODP' AND lower(name) = ANY($1)", odpNames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up ODPs: %w", err)
+	}
+	for dbRows.Next() {
+		var id int64
+		var name string
+		if err := dbRows.Scan(&id, &name); err != nil {
+			dbRows.Close()
+			return nil, fmt.Errorf("failed to scan node: %w", err)
+		}
+		odps[name] = append(odps[name], id)
+	}
+	dbRows.Close()
+	if err := dbRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to look up ODPs: %w", err)
+	}
+
+	items := append([]models.ImportItemResult{}, rejected...)
+	var valid []int
+	seenSerials := map[string]string{}
+	for i := range rows {
+		row := &rows[i]
+		item := models.ImportItemResult{
+			Ref:    row.Ref,
+			Kind:   models.ImportItemCustomer,
+			Action: models.ImportActionCreate,
+			Name:   row.Customer.Name,
+		}
+
+		var problems []string
+		if row.Customer.ONTSN != nil {
+			sn := strings.ToUpper(*row.Customer.ONTSN)
+			if id, ok := existingSerials[sn]; ok {
+				problems = append(problems, fmt.Sprintf("ont_sn %s already belongs to customer #%d", sn, id))
+			} else if first, ok := seenSerials[sn]; ok {
+				problems = append(problems, fmt.Sprintf("ont_sn %s duplicates %s", sn, first))
+			} else {
+				seenSerials[sn] = row.Ref
+			}
+		}
+		if row.ODPName != "" {
+			switch matches := odps[strings.ToLower(row.ODPName)]; len(matches) {
+			case 0:
+				problems = append(problems, fmt.Sprintf("unknown ODP %q", row.ODPName))
+			case 1:
+				row.Customer.NodeID = &matches[0]
+			default:
+				problems = append(problems, fmt.Sprintf("ODP name %q is ambiguous (%d matches)", row.ODPName, len(matches)))
+			}
+		}
+
+		if len(problems) > 0 {
+			item.Action = models.ImportActionError
+			item.Message = strings.Join(problems, "; ")
+		} else {
+			valid = append(valid, i)
+		}
+		items = append(items, item)
+	}
+	sortByRow(items)
+
+	report := newRowImportReport(format, dryRun, items)
+	if report.Failed > 0 || dryRun {
+		return report, nil
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	ids := map[string]int64{}
+	for _, i := range valid {
+		customer, err := insertCustomer(ctx, tx, &rows[i].Customer)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rows[i].Ref, err)
+		}
+		ids[rows[i].Ref] = customer.ID
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+	report.Committed = true
+	setRowIDs(report, ids)
+
+	return report, nil
+}
+
+func newRowImportReport(format string, dryRun bool, items []models.ImportItemResult) *models.ImportReport {
+	report := &models.ImportReport{
+		Format: format,
+		DryRun: dryRun,
+		Items:  []models.ImportItemResult{},
+	}
+	for _, item := range items {
+		report.Add(item)
+	}
+	return report
+}
+
+func setRowIDs(report *models.ImportReport, ids map[string]int64) {
+	for i := range report.Items {
+		if id, ok := ids[report.Items[i].Ref]; ok {
+			report.Items[i].ID = &id
+		}
+	}
+}
+
+// sortByRow orders items by their "row N" reference
+func sortByRow(items []models.ImportItemResult) {
+	rowNumber := func(ref string) int {
+		var n int
+		fmt.Sscanf(ref, "row %d", &n)
+		return n
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return rowNumber(items[i].Ref) < rowNumber(items[j].Ref)
+	})
+}
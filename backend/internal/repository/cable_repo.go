@@ -2,8 +2,8 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"strings"
 
 	"spectra-backend/internal/models"
 
@@ -23,19 +23,41 @@ func NewCableRepository(pool *pgxpool.Pool) *CableRepository {
 
 // Create inserts a new cable into the database
 func (r *CableRepository) Create(ctx context.Context, req *models.CreateCableRequest) (*models.Cable, error) {
-	// Build path geometry from coordinates if provided
-	var pathGeometrySQL string
-	if len(req.PathCoordinates) >= 2 {
-		points := make([]string, len(req.PathCoordinates))
-		for i, coord := range req.PathCoordinates {
-			if len(coord) >= 2 {
-				points[i] = fmt.Sprintf("%f %f", coord[0], coord[1])
-			}
-		}
-		pathGeometrySQL = fmt.Sprintf("ST_SetSRID(ST_MakeLine(ARRAY[%s]::geometry[]), 4326)",
-			"ST_MakePoint("+strings.Join(points, "), ST_MakePoint(")+")")
+	schemeCode := models.DefaultColorScheme
+	if req.ColorScheme != nil && *req.ColorScheme != "" {
+		schemeCode = *req.ColorScheme
+	}
+	scheme, err := resolveColorScheme(ctx, r.pool, schemeCode)
+	if err != nil {
+		return nil, err
+	}
+	req.ColorScheme = &scheme.Code
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	cable, err := insertCable(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// A cable without its cores is unusable, so it is only created together with them
+	if err := generateCores(ctx, tx, cable.ID, req.CoreCount, scheme); err != nil {
+		return nil, fmt.Errorf("failed to generate cores: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit cable: %w", err)
 	}
 
+	return cable, nil
+}
+
+// insertCable inserts a cable row without cores using the given querier
+func insertCable(ctx context.Context, q querier, req *models.CreateCableRequest) (*models.Cable, error) {
 	colorHex := "#000000"
 	if req.ColorHex != nil {
 		colorHex = *req.ColorHex
@@ -46,45 +68,36 @@ func (r *CableRepository) Create(ctx context.Context, req *models.CreateCableReq
 		status = req.Status
 	}
 
-	var query string
-	var args []interface{}
-
-	if pathGeometrySQL != "" {
-		query = fmt.Sprintf(`
-			INSERT INTO cables (name, type, core_count, length_meter, origin_node_id, dest_node_id, path_geometry, color_hex, status)
-			VALUES ($1, $2, $3, $4, $5, $6, %s, $7, $8)
-			RETURNING id, name, type, core_count, length_meter, origin_node_id, dest_node_id, color_hex, status, created_at, updated_at
-		`, pathGeometrySQL)
-		args = []interface{}{
-			req.Name,
-			req.Type,
-			req.CoreCount,
-			req.LengthMeter,
-			req.OriginNodeID,
-			req.DestNodeID,
-			colorHex,
-			status,
-		}
-	} else {
-		query = `
-			INSERT INTO cables (name, type, core_count, length_meter, origin_node_id, dest_node_id, color_hex, status)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-			RETURNING id, name, type, core_count, length_meter, origin_node_id, dest_node_id, color_hex, status, created_at, updated_at
-		`
-		args = []interface{}{
-			req.Name,
-			req.Type,
-			req.CoreCount,
-			req.LengthMeter,
-			req.OriginNodeID,
-			req.DestNodeID,
-			colorHex,
-			status,
-		}
+	colorScheme := models.DefaultColorScheme
+	if req.ColorScheme != nil && *req.ColorScheme != "" {
+		colorScheme = *req.ColorScheme
+	}
+
+	pathJSON, err := encodePathCoordinates(req.PathCoordinates)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO cables (name, type, core_count, length_meter, origin_node_id, dest_node_id, path_coordinates, color_hex, color_scheme, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, name, type, core_count, length_meter, origin_node_id, dest_node_id, color_hex, color_scheme, status, created_at, updated_at
+	`
+	args := []interface{}{
+		req.Name,
+		req.Type,
+		req.CoreCount,
+		req.LengthMeter,
+		req.OriginNodeID,
+		req.DestNodeID,
+		pathJSON,
+		colorHex,
+		colorScheme,
+		status,
 	}
 
 	cable := &models.Cable{}
-	err := r.pool.QueryRow(ctx, query, args...).Scan(
+	err = q.QueryRow(ctx, query, args...).Scan(
 		&cable.ID,
 		&cable.Name,
 		&cable.Type,
@@ -93,6 +106,7 @@ func (r *CableRepository) Create(ctx context.Context, req *models.CreateCableReq
 		&cable.OriginNodeID,
 		&cable.DestNodeID,
 		&cable.ColorHex,
+		&cable.ColorScheme,
 		&cable.Status,
 		&cable.CreatedAt,
 		&cable.UpdatedAt,
@@ -101,19 +115,14 @@ func (r *CableRepository) Create(ctx context.Context, req *models.CreateCableReq
 	if err != nil {
 		return nil, fmt.Errorf("failed to create cable: %w", err)
 	}
-
-	// Auto-generate cable cores
-	if err := r.generateCores(ctx, cable.ID, req.CoreCount); err != nil {
-		// Log warning but don't fail
-		fmt.Printf("Warning: failed to generate cores for cable %d: %v\n", cable.ID, err)
-	}
+	cable.PathCoordinates = req.PathCoordinates
 
 	return cable, nil
 }
 
 // generateCores creates cable core entries for a new cable
-func (r *CableRepository) generateCores(ctx context.Context, cableID int64, coreCount int) error {
-	cores := models.GenerateCoresForCable(cableID, coreCount)
+func generateCores(ctx context.Context, q querier, cableID int64, coreCount int, scheme *models.ColorScheme) error {
+	cores := scheme.GenerateCores(cableID, coreCount)
 
 	batch := &pgx.Batch{}
 	for _, core := range cores {
@@ -123,7 +132,7 @@ func (r *CableRepository) generateCores(ctx context.Context, cableID int64, core
 		`, core.CableID, core.CoreIndex, core.TubeColor, core.CoreColor, core.Status)
 	}
 
-	results := r.pool.SendBatch(ctx, batch)
+	results := q.SendBatch(ctx, batch)
 	defer results.Close()
 
 	for i := 0; i < batch.Len(); i++ {
@@ -140,8 +149,7 @@ func (r *CableRepository) GetByID(ctx context.Context, id int64) (*models.Cable,
 	query := `
 		SELECT 
 			id, name, type, core_count, length_meter, origin_node_id, dest_node_id, 
-			color_hex, status, created_at, updated_at,
-			ST_AsGeoJSON(path_geometry)::jsonb->'coordinates' as path_coords
+			color_hex, color_scheme, status, created_at, updated_at, path_coordinates
 		FROM cables
 		WHERE id = $1
 	`
@@ -158,6 +166,7 @@ func (r *CableRepository) GetByID(ctx context.Context, id int64) (*models.Cable,
 		&cable.OriginNodeID,
 		&cable.DestNodeID,
 		&cable.ColorHex,
+		&cable.ColorScheme,
 		&cable.Status,
 		&cable.CreatedAt,
 		&cable.UpdatedAt,
@@ -171,6 +180,10 @@ func (r *CableRepository) GetByID(ctx context.Context, id int64) (*models.Cable,
 		return nil, fmt.Errorf("failed to get cable: %w", err)
 	}
 
+	if cable.PathCoordinates, err = decodePathCoordinates(pathCoordsJSON); err != nil {
+		return nil, err
+	}
+
 	return cable, nil
 }
 
@@ -204,6 +217,12 @@ func (r *CableRepository) List(ctx context.Context, filter *models.CableFilter)
 		argIndex++
 	}
 
+	if filter.AreaID != nil {
+		baseQuery += " AND id IN " + areaCablesSQL(argIndex)
+		args = append(args, *filter.AreaID)
+		argIndex++
+	}
+
 	// Count total
 	var total int64
 	countQuery := "SELECT COUNT(*) " + baseQuery
@@ -223,7 +242,7 @@ func (r *CableRepository) List(ctx context.Context, filter *models.CableFilter)
 	}
 
 	dataQuery := fmt.Sprintf(`
-		SELECT id, name, type, core_count, length_meter, origin_node_id, dest_node_id, color_hex, status, created_at, updated_at
+		SELECT id, name, type, core_count, length_meter, origin_node_id, dest_node_id, color_hex, color_scheme, status, created_at, updated_at
 		%s
 		ORDER BY created_at DESC
 		LIMIT $%d OFFSET $%d
@@ -249,6 +268,7 @@ func (r *CableRepository) List(ctx context.Context, filter *models.CableFilter)
 			&cable.OriginNodeID,
 			&cable.DestNodeID,
 			&cable.ColorHex,
+			&cable.ColorScheme,
 			&cable.Status,
 			&cable.CreatedAt,
 			&cable.UpdatedAt,
@@ -298,6 +318,15 @@ func (r *CableRepository) Update(ctx context.Context, id int64, req *models.Upda
 		args = append(args, *req.DestNodeID)
 		argIndex++
 	}
+	if req.PathCoordinates != nil {
+		pathJSON, err := encodePathCoordinates(req.PathCoordinates)
+		if err != nil {
+			return nil, err
+		}
+		setParts = append(setParts, fmt.Sprintf("path_coordinates = $%d", argIndex))
+		args = append(args, pathJSON)
+		argIndex++
+	}
 	if req.ColorHex != nil {
 		setParts = append(setParts, fmt.Sprintf("color_hex = $%d", argIndex))
 		args = append(args, *req.ColorHex)
@@ -315,15 +344,30 @@ func (r *CableRepository) Update(ctx context.Context, id int64, req *models.Upda
 
 	args = append(args, id)
 
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Keep cable_cores in step with the new core count
+	var resize *models.CoreResizeReport
+	if req.CoreCount != nil {
+		resize, err = resizeCores(ctx, tx, id, *req.CoreCount, req.Force)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	query := fmt.Sprintf(`
 		UPDATE cables
 		SET %s
 		WHERE id = $%d
-		RETURNING id, name, type, core_count, length_meter, origin_node_id, dest_node_id, color_hex, status, created_at, updated_at
+		RETURNING id, name, type, core_count, length_meter, origin_node_id, dest_node_id, color_hex, color_scheme, status, created_at, updated_at
 	`, joinStrings(setParts, ", "), argIndex)
 
 	cable := &models.Cable{}
-	err := r.pool.QueryRow(ctx, query, args...).Scan(
+	err = tx.QueryRow(ctx, query, args...).Scan(
 		&cable.ID,
 		&cable.Name,
 		&cable.Type,
@@ -332,6 +376,7 @@ func (r *CableRepository) Update(ctx context.Context, id int64, req *models.Upda
 		&cable.OriginNodeID,
 		&cable.DestNodeID,
 		&cable.ColorHex,
+		&cable.ColorScheme,
 		&cable.Status,
 		&cable.CreatedAt,
 		&cable.UpdatedAt,
@@ -344,6 +389,11 @@ func (r *CableRepository) Update(ctx context.Context, id int64, req *models.Upda
 		return nil, fmt.Errorf("failed to update cable: %w", err)
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit cable update: %w", err)
+	}
+	cable.CoreResize = resize
+
 	return cable, nil
 }
 
@@ -395,6 +445,24 @@ func (r *CableRepository) GetCores(ctx context.Context, cableID int64) ([]models
 		}
 		cores = append(cores, core)
 	}
+	rows.Close()
+
+	// Tube numbers depend on the cable's scheme (6-fiber tubes, 24-fiber ribbons, ...)
+	var schemeCode string
+	err = r.pool.QueryRow(ctx, "SELECT color_scheme FROM cables WHERE id = $1", cableID).Scan(&schemeCode)
+	if err == pgx.ErrNoRows {
+		return cores, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cable color scheme: %w", err)
+	}
+	scheme, err := resolveColorScheme(ctx, r.pool, schemeCode)
+	if err != nil {
+		return nil, err
+	}
+	for i := range cores {
+		cores[i].TubeNumber = scheme.TubeNumber(cores[i].CoreIndex)
+	}
 
 	return cores, nil
 }
@@ -457,27 +525,53 @@ func (r *CableRepository) UpdateCore(ctx context.Context, cableID, coreID int64,
 }
 
 // GetAllAsGeoJSON retrieves all cables as GeoJSON features
-func (r *CableRepository) GetAllAsGeoJSON(ctx context.Context) ([]models.CableGeoJSON, error) {
+func (r *CableRepository) GetAllAsGeoJSON(ctx context.Context, areaID *int64) ([]models.CableGeoJSON, error) {
+	cables, err := listCablesWithPaths(ctx, r.pool, areaID)
+	if err != nil {
+		return nil, err
+	}
+
+	var features []models.CableGeoJSON
+	for i := range cables {
+		if cables[i].PathCoordinates == nil {
+			continue
+		}
+		features = append(features, cables[i].ToGeoJSON())
+	}
+
+	return features, nil
+}
+
+// ListWithPaths retrieves every cable including its path coordinates (nil when not drawn)
+func (r *CableRepository) ListWithPaths(ctx context.Context) ([]models.Cable, error) {
+	return listCablesWithPaths(ctx, r.pool, nil)
+}
+
+// listCablesWithPaths lists cables with their paths, only those with an end in the service area
+// when areaID is set
+func listCablesWithPaths(ctx context.Context, q querier, areaID *int64) ([]models.Cable, error) {
+	where := ""
+	args := []interface{}{}
+	if areaID != nil {
+		where = " WHERE id IN " + areaCablesSQL(1)
+		args = append(args, *areaID)
+	}
+
 	query := `
 		SELECT 
 			id, name, type, core_count, length_meter, origin_node_id, dest_node_id, 
-			color_hex, status, created_at, updated_at,
-			COALESCE(
-				(SELECT json_agg(json_build_array(ST_X(geom), ST_Y(geom)))
-				 FROM ST_DumpPoints(path_geometry) AS dp(path, geom)),
-				'[]'::json
-			) as path_coords
-		FROM cables
-		WHERE path_geometry IS NOT NULL
+			color_hex, color_scheme, status, created_at, updated_at, path_coordinates
+		FROM cables` + where + `
+		ORDER BY id ASC
 	`
 
-	rows, err := r.pool.Query(ctx, query)
+	rows, err := q.Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get cables as geojson: %w", err)
+		return nil, fmt.Errorf("failed to list cables: %w", err)
 	}
 	defer rows.Close()
 
-	var features []models.CableGeoJSON
+	var cables []models.Cable
 	for rows.Next() {
 		var cable models.Cable
 		var pathCoordsJSON []byte
@@ -491,6 +585,7 @@ func (r *CableRepository) GetAllAsGeoJSON(ctx context.Context) ([]models.CableGe
 			&cable.OriginNodeID,
 			&cable.DestNodeID,
 			&cable.ColorHex,
+			&cable.ColorScheme,
 			&cable.Status,
 			&cable.CreatedAt,
 			&cable.UpdatedAt,
@@ -500,8 +595,70 @@ func (r *CableRepository) GetAllAsGeoJSON(ctx context.Context) ([]models.CableGe
 			return nil, fmt.Errorf("failed to scan cable: %w", err)
 		}
 
-		features = append(features, cable.ToGeoJSON())
+		if cable.PathCoordinates, err = decodePathCoordinates(pathCoordsJSON); err != nil {
+			return nil, err
+		}
+
+		cables = append(cables, cable)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list cables: %w", err)
 	}
 
-	return features, nil
+	return cables, nil
+}
+
+// GetUsedCoreCounts returns the number of USED cores per cable ID
+func (r *CableRepository) GetUsedCoreCounts(ctx context.Context) (map[int64]int, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT cable_id, COUNT(*)
+		FROM cable_cores
+		WHERE status = 'USED'
+		GROUP BY cable_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count used cores: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[int64]int{}
+	for rows.Next() {
+		var cableID int64
+		var count int
+		if err := rows.Scan(&cableID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan core count: %w", err)
+		}
+		counts[cableID] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// encodePathCoordinates serializes [[lng, lat], ...] for the path_coordinates JSONB column
+func encodePathCoordinates(coords [][]float64) ([]byte, error) {
+	if len(coords) < 2 {
+		return nil, nil
+	}
+	for i, coord := range coords {
+		if len(coord) < 2 {
+			return nil, fmt.Errorf("invalid path coordinate at index %d", i)
+		}
+	}
+	data, err := json.Marshal(coords)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode path coordinates: %w", err)
+	}
+	return data, nil
+}
+
+// decodePathCoordinates parses the path_coordinates JSONB column
+func decodePathCoordinates(data []byte) ([][]float64, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var coords [][]float64
+	if err := json.Unmarshal(data, &coords); err != nil {
+		return nil, fmt.Errorf("failed to decode path coordinates: %w", err)
+	}
+	return coords, nil
 }
@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"spectra-backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrInvalidCoreCount is returned when a requested core count is out of range
+	ErrInvalidCoreCount = errors.New("invalid core count")
+	// ErrForeignCores is returned when a tenant resizes a cable carrying cores it does not own
+	ErrForeignCores = errors.New("cable has cores of another tenant")
+)
+
+// MaxCoreCount is the largest core count supported for a single cable
+const MaxCoreCount = 288
+
+// CoreResizeError is returned when shrinking a cable would drop cores that are still in service
+type CoreResizeError struct {
+	Report *models.CoreResizeReport
+}
+
+// Error implements the error interface
+func (e *CoreResizeError) Error() string {
+	return fmt.Sprintf("%d cores to be removed are in use; retry with force to drop them", len(e.Report.BlockingCores))
+}
+
+// PreviewResize reports what changing a cable's core count would do without applying it
+func (r *CableRepository) PreviewResize(ctx context.Context, id int64, coreCount int) (*models.CoreResizeReport, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	// Always rolled back: the preview only plans the change
+	defer tx.Rollback(ctx)
+
+	report, err := planCoreResize(ctx, tx, id, coreCount)
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		return nil, ErrCableNotFound
+	}
+	return report, nil
+}
+
+// resizeCores adds or removes cable_cores rows so they match a new core count.
+// Cores that are USED, RESERVED or referenced by connections are only removed when forced,
+// in which case their connections are deleted as well. Returns nil when the cable does not exist.
+func resizeCores(ctx context.Context, tx pgx.Tx, cableID int64, coreCount int, force bool) (*models.CoreResizeReport, error) {
+	report, err := planCoreResize(ctx, tx, cableID, coreCount)
+	if err != nil || report == nil {
+		return report, err
+	}
+
+	if len(report.BlockingCores) > 0 && !force {
+		return nil, &CoreResizeError{Report: report}
+	}
+	report.Forced = force && len(report.BlockingCores) > 0
+
+	if report.RemovedCores > 0 {
+		rows, err := tx.Query(ctx, `
+			DELETE FROM connections c
+			USING cable_cores cc
+			WHERE cc.cable_id = $1 AND cc.core_index > $2
+			  AND ((c.input_type = 'CORE' AND c.input_id = cc.id) OR (c.output_type = 'CORE' AND c.output_id = cc.id))
+			RETURNING c.id, c.input_type, c.input_id, c.output_type, c.output_id
+		`, cableID, coreCount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete connections of removed cores: %w", err)
+		}
+		var peerCoreIDs []int64
+		for rows.Next() {
+			var connID, inputID, outputID int64
+			var inputType, outputType models.ConnectionType
+			if err := rows.Scan(&connID, &inputType, &inputID, &outputType, &outputID); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan removed connection: %w", err)
+			}
+			report.RemovedConnectionIDs = append(report.RemovedConnectionIDs, connID)
+			if inputType == models.ConnectionTypeCore {
+				peerCoreIDs = append(peerCoreIDs, inputID)
+			}
+			if outputType == models.ConnectionTypeCore {
+				peerCoreIDs = append(peerCoreIDs, outputID)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to delete connections of removed cores: %w", err)
+		}
+
+		// Free the surviving cores on the other side of removed splices, as deleting a connection does
+		if len(peerCoreIDs) > 0 {
+			_, err := tx.Exec(ctx, `
+				UPDATE cable_cores cc SET status = 'VACANT'
+				WHERE cc.id = ANY($1) AND cc.status = 'USED'
+				  AND NOT EXISTS (
+					SELECT 1 FROM connections c
+					WHERE (c.input_type = 'CORE' AND c.input_id = cc.id) OR (c.output_type = 'CORE' AND c.output_id = cc.id)
+				  )
+			`, peerCoreIDs)
+			if err != nil {
+				return nil, fmt.Errorf("failed to free spliced cores: %w", err)
+			}
+		}
+
+		if _, err := tx.Exec(ctx, "DELETE FROM cable_cores WHERE cable_id = $1 AND core_index > $2", cableID, coreCount); err != nil {
+			return nil, fmt.Errorf("failed to remove cores: %w", err)
+		}
+	}
+
+	if report.AddedCores > 0 {
+		if err := generateMissingCores(ctx, tx, cableID, coreCount); err != nil {
+			return nil, err
+		}
+	}
+
+	report.Applied = true
+	return report, nil
+}
+
+// planCoreResize computes the cores to add and remove for a new core count
+func planCoreResize(ctx context.Context, q querier, cableID int64, coreCount int) (*models.CoreResizeReport, error) {
+	if coreCount < 1 || coreCount > MaxCoreCount {
+		return nil, fmt.Errorf("%w: core count must be between 1 and %d", ErrInvalidCoreCount, MaxCoreCount)
+	}
+
+	// Locking a row needs the update policy, so tenants only find the cables they own
+	var oldCount int
+	err := q.QueryRow(ctx, "SELECT core_count FROM cables WHERE id = $1 FOR UPDATE", cableID).Scan(&oldCount)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cable: %w", err)
+	}
+
+	// Cores the operator or another tenant added to the cable are invisible to the tenant, which could
+	// neither remove them nor number new cores around them
+	var foreign int
+	if err := q.QueryRow(ctx, "SELECT foreign_core_count($1, current_tenant_id())", cableID).Scan(&foreign); err != nil {
+		return nil, fmt.Errorf("failed to check core owners: %w", err)
+	}
+	if foreign > 0 {
+		return nil, fmt.Errorf("%w: %d cores of cable %d belong to someone else", ErrForeignCores, foreign, cableID)
+	}
+
+	report := &models.CoreResizeReport{
+		CableID:              cableID,
+		OldCoreCount:         oldCount,
+		NewCoreCount:         coreCount,
+		BlockingCores:        []models.BlockingCore{},
+		RemovedConnectionIDs: []int64{},
+	}
+
+	// Count against the actual rows so earlier drift between core_count and cable_cores is repaired
+	var existingInRange int
+	err = q.QueryRow(ctx, "SELECT COUNT(*) FROM cable_cores WHERE cable_id = $1 AND core_index <= $2", cableID, coreCount).
+		Scan(&existingInRange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count cores: %w", err)
+	}
+	report.AddedCores = coreCount - existingInRange
+
+	rows, err := q.Query(ctx, `
+		SELECT cc.id, cc.core_index, cc.status,
+			   COALESCE(array_agg(c.id ORDER BY c.id) FILTER (WHERE c.id IS NOT NULL), '{}') AS connection_ids
+		FROM cable_cores cc
+		LEFT JOIN connections c
+			ON (c.input_type = 'CORE' AND c.input_id = cc.id) OR (c.output_type = 'CORE' AND c.output_id = cc.id)
+		WHERE cc.cable_id = $1 AND cc.core_index > $2
+		GROUP BY cc.id, cc.core_index, cc.status
+		ORDER BY cc.core_index ASC
+	`, cableID, coreCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check cores to remove: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var core models.BlockingCore
+		if err := rows.Scan(&core.CoreID, &core.CoreIndex, &core.Status, &core.ConnectionIDs); err != nil {
+			return nil, fmt.Errorf("failed to scan core: %w", err)
+		}
+		report.RemovedCores++
+		if core.Status.IsInService() || len(core.ConnectionIDs) > 0 {
+			report.BlockingCores = append(report.BlockingCores, core)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to check cores to remove: %w", err)
+	}
+
+	return report, nil
+}
+
+// generateMissingCores inserts the cores of a cable that are missing up to coreCount
+func generateMissingCores(ctx context.Context, q querier, cableID int64, coreCount int) error {
+	var schemeCode string
+	if err := q.QueryRow(ctx, "SELECT color_scheme FROM cables WHERE id = $1", cableID).Scan(&schemeCode); err != nil {
+		return fmt.Errorf("failed to get cable color scheme: %w", err)
+	}
+	scheme, err := resolveColorScheme(ctx, q, schemeCode)
+	if err != nil {
+		return err
+	}
+
+	rows, err := q.Query(ctx, "SELECT core_index FROM cable_cores WHERE cable_id = $1", cableID)
+	if err != nil {
+		return fmt.Errorf("failed to get existing cores: %w", err)
+	}
+	existing := map[int]bool{}
+	for rows.Next() {
+		var index int
+		if err := rows.Scan(&index); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan core index: %w", err)
+		}
+		existing[index] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to get existing cores: %w", err)
+	}
+
+	batch := &pgx.Batch{}
+	for _, core := range scheme.GenerateCores(cableID, coreCount) {
+		if existing[core.CoreIndex] {
+			continue
+		}
+		batch.Queue(`
+			INSERT INTO cable_cores (cable_id, core_index, tube_color, core_color, status)
+			VALUES ($1, $2, $3, $4, $5)
+		`, core.CableID, core.CoreIndex, core.TubeColor, core.CoreColor, core.Status)
+	}
+
+	results := q.SendBatch(ctx, batch)
+	defer results.Close()
+
+	for i := 0; i < batch.Len(); i++ {
+		if _, err := results.Exec(); err != nil {
+			return fmt.Errorf("failed to insert core: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,362 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"spectra-backend/internal/geo"
+	"spectra-backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrCableNotFound is returned when the referenced cable does not exist
+	ErrCableNotFound = errors.New("cable not found")
+	// ErrCoreNotFound is returned when the referenced core does not exist on the cable
+	ErrCoreNotFound = errors.New("core not found")
+	// ErrInvalidSpans is returned when a span list is not a contiguous route
+	ErrInvalidSpans = errors.New("invalid spans")
+	// ErrInvalidBreakout is returned when a core cannot be broken out at the requested node
+	ErrInvalidBreakout = errors.New("invalid breakout")
+	// ErrBreakoutNotFound is returned when the breakout does not exist on the core
+	ErrBreakoutNotFound = errors.New("breakout not found")
+)
+
+// CableSpanRepository handles database operations for cable spans and core breakouts
+type CableSpanRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewCableSpanRepository creates a new CableSpanRepository
+func NewCableSpanRepository(pool *pgxpool.Pool) *CableSpanRepository {
+	return &CableSpanRepository{pool: pool}
+}
+
+// ReplaceSpans replaces the ordered span list of a cable in one transaction
+func (r *CableSpanRepository) ReplaceSpans(ctx context.Context, cableID int64, req *models.ReplaceCableSpansRequest) (*models.CableRoute, error) {
+	if len(req.Spans) == 0 {
+		return nil, fmt.Errorf("%w: at least one span is required", ErrInvalidSpans)
+	}
+	for i, span := range req.Spans {
+		if span.FromNodeID == span.ToNodeID {
+			return nil, fmt.Errorf("%w: span %d starts and ends at node %d", ErrInvalidSpans, i+1, span.FromNodeID)
+		}
+		if i > 0 && req.Spans[i-1].ToNodeID != span.FromNodeID {
+			return nil, fmt.Errorf("%w: span %d does not start where span %d ends", ErrInvalidSpans, i+1, i)
+		}
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var originNodeID, destNodeID *int64
+	err = tx.QueryRow(ctx, "SELECT origin_node_id, dest_node_id FROM cables WHERE id = $1 FOR UPDATE", cableID).
+		Scan(&originNodeID, &destNodeID)
+	if err == pgx.ErrNoRows {
+		return nil, ErrCableNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cable: %w", err)
+	}
+
+	first := req.Spans[0].FromNodeID
+	last := req.Spans[len(req.Spans)-1].ToNodeID
+	if originNodeID != nil && *originNodeID != first {
+		return nil, fmt.Errorf("%w: route must start at origin node %d", ErrInvalidSpans, *originNodeID)
+	}
+	if destNodeID != nil && *destNodeID != last {
+		return nil, fmt.Errorf("%w: route must end at destination node %d", ErrInvalidSpans, *destNodeID)
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM cable_spans WHERE cable_id = $1", cableID); err != nil {
+		return nil, fmt.Errorf("failed to clear cable spans: %w", err)
+	}
+
+	if err := insertSpans(ctx, tx, cableID, req.Spans); err != nil {
+		return nil, err
+	}
+
+	routeNodeIDs := []int64{first}
+	for _, span := range req.Spans {
+		routeNodeIDs = append(routeNodeIDs, span.ToNodeID)
+	}
+
+	// Breakouts at nodes no longer on the route are meaningless
+	_, err = tx.Exec(ctx, `
+		DELETE FROM cable_core_breakouts b
+		USING cable_cores c
+		WHERE b.core_id = c.id AND c.cable_id = $1 AND NOT (b.node_id = ANY($2))
+	`, cableID, routeNodeIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prune core breakouts: %w", err)
+	}
+
+	// Fill in missing endpoints so the cable matches its route
+	_, err = tx.Exec(ctx, `
+		UPDATE cables
+		SET origin_node_id = COALESCE(origin_node_id, $1), dest_node_id = COALESCE(dest_node_id, $2)
+		WHERE id = $3
+	`, first, last, cableID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update cable endpoints: %w", err)
+	}
+
+	route, err := loadCableRoute(ctx, tx, cableID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE cables SET length_meter = COALESCE(length_meter, $1) WHERE id = $2", route.TotalLengthMeter, cableID); err != nil {
+		return nil, fmt.Errorf("failed to update cable length: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit spans: %w", err)
+	}
+
+	return route, nil
+}
+
+// GetRoute retrieves the ordered physical route of a cable
+func (r *CableSpanRepository) GetRoute(ctx context.Context, cableID int64) (*models.CableRoute, error) {
+	return loadCableRoute(ctx, r.pool, cableID)
+}
+
+// CreateBreakout marks a core as cut at an intermediate node of its cable
+func (r *CableSpanRepository) CreateBreakout(ctx context.Context, cableID, coreID int64, req *models.CreateCoreBreakoutRequest) (*models.CoreBreakout, error) {
+	if err := ensureCoreOnCable(ctx, r.pool, cableID, coreID); err != nil {
+		return nil, err
+	}
+
+	route, err := loadCableRoute(ctx, r.pool, cableID)
+	if err != nil {
+		return nil, err
+	}
+
+	intermediate := false
+	for i := 1; i < len(route.NodeIDs)-1; i++ {
+		if route.NodeIDs[i] == req.NodeID {
+			intermediate = true
+			break
+		}
+	}
+	if !intermediate {
+		return nil, fmt.Errorf("%w: node %d is not an intermediate node on cable %d", ErrInvalidBreakout, req.NodeID, cableID)
+	}
+
+	query := `
+		INSERT INTO cable_core_breakouts (core_id, node_id, notes)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (core_id, node_id) DO UPDATE SET notes = EXCLUDED.notes
+		RETURNING id, core_id, node_id, notes, created_at
+	`
+
+	breakout := &models.CoreBreakout{}
+	err = r.pool.QueryRow(ctx, query, coreID, req.NodeID, req.Notes).Scan(
+		&breakout.ID,
+		&breakout.CoreID,
+		&breakout.NodeID,
+		&breakout.Notes,
+		&breakout.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create core breakout: %w", err)
+	}
+
+	return breakout, nil
+}
+
+// ListBreakouts retrieves all breakouts of a core
+func (r *CableSpanRepository) ListBreakouts(ctx context.Context, cableID, coreID int64) ([]models.CoreBreakout, error) {
+	if err := ensureCoreOnCable(ctx, r.pool, cableID, coreID); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, core_id, node_id, notes, created_at
+		FROM cable_core_breakouts
+		WHERE core_id = $1
+		ORDER BY id ASC
+	`, coreID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list core breakouts: %w", err)
+	}
+	defer rows.Close()
+
+	breakouts := []models.CoreBreakout{}
+	for rows.Next() {
+		var b models.CoreBreakout
+		if err := rows.Scan(&b.ID, &b.CoreID, &b.NodeID, &b.Notes, &b.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan core breakout: %w", err)
+		}
+		breakouts = append(breakouts, b)
+	}
+
+	return breakouts, nil
+}
+
+// DeleteBreakout removes a core breakout, making the core pass through that node express again
+func (r *CableSpanRepository) DeleteBreakout(ctx context.Context, cableID, coreID, breakoutID int64) error {
+	query := `
+		DELETE FROM cable_core_breakouts b
+		USING cable_cores c
+		WHERE b.id = $1 AND b.core_id = $2 AND c.id = b.core_id AND c.cable_id = $3
+	`
+	result, err := r.pool.Exec(ctx, query, breakoutID, coreID, cableID)
+	if err != nil {
+		return fmt.Errorf("failed to delete core breakout: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrBreakoutNotFound
+	}
+
+	return nil
+}
+
+// GetCoreSegments retrieves the continuous segments of a core between its cut points
+func (r *CableSpanRepository) GetCoreSegments(ctx context.Context, cableID, coreID int64) ([]models.CoreSegment, error) {
+	if err := ensureCoreOnCable(ctx, r.pool, cableID, coreID); err != nil {
+		return nil, err
+	}
+	return loadCoreSegments(ctx, r.pool, coreID)
+}
+
+// ensureCoreOnCable verifies that a core belongs to the given cable
+func ensureCoreOnCable(ctx context.Context, q querier, cableID, coreID int64) error {
+	var exists bool
+	err := q.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM cable_cores WHERE id = $1 AND cable_id = $2)", coreID, cableID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check core: %w", err)
+	}
+	if !exists {
+		return ErrCoreNotFound
+	}
+	return nil
+}
+
+// loadCableRoute builds the ordered route of a cable from its spans.
+// Cables without spans fall back to a single origin-to-destination span.
+func loadCableRoute(ctx context.Context, q querier, cableID int64) (*models.CableRoute, error) {
+	var originNodeID, destNodeID *int64
+	var lengthMeter *float64
+	err := q.QueryRow(ctx, "SELECT origin_node_id, dest_node_id, length_meter FROM cables WHERE id = $1", cableID).
+		Scan(&originNodeID, &destNodeID, &lengthMeter)
+	if err == pgx.ErrNoRows {
+		return nil, ErrCableNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cable: %w", err)
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT s.id, s.cable_id, s.from_node_id, s.to_node_id, s.sequence, s.span_length_meter, s.created_at, s.updated_at,
+			   fn.latitude, fn.longitude, tn.latitude, tn.longitude
+		FROM cable_spans s
+		JOIN nodes fn ON fn.id = s.from_node_id
+		JOIN nodes tn ON tn.id = s.to_node_id
+		WHERE s.cable_id = $1
+		ORDER BY s.sequence ASC
+	`, cableID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cable spans: %w", err)
+	}
+	defer rows.Close()
+
+	route := &models.CableRoute{
+		CableID: cableID,
+		NodeIDs: []int64{},
+		Spans:   []models.CableSpan{},
+	}
+
+	for rows.Next() {
+		var span models.CableSpan
+		var fromLat, fromLng, toLat, toLng float64
+		err := rows.Scan(
+			&span.ID,
+			&span.CableID,
+			&span.FromNodeID,
+			&span.ToNodeID,
+			&span.Sequence,
+			&span.SpanLengthMeter,
+			&span.CreatedAt,
+			&span.UpdatedAt,
+			&fromLat, &fromLng, &toLat, &toLng,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan cable span: %w", err)
+		}
+
+		if span.SpanLengthMeter == nil {
+			length := geo.HaversineMeters(fromLat, fromLng, toLat, toLng)
+			span.SpanLengthMeter = &length
+		}
+
+		if len(route.NodeIDs) == 0 {
+			route.NodeIDs = append(route.NodeIDs, span.FromNodeID)
+		}
+		route.NodeIDs = append(route.NodeIDs, span.ToNodeID)
+		route.TotalLengthMeter += *span.SpanLengthMeter
+		route.Spans = append(route.Spans, span)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cable spans: %w", err)
+	}
+
+	if len(route.Spans) == 0 && originNodeID != nil && destNodeID != nil {
+		route.NodeIDs = []int64{*originNodeID, *destNodeID}
+		if lengthMeter != nil {
+			route.TotalLengthMeter = *lengthMeter
+		}
+	}
+
+	return route, nil
+}
+
+// loadCoreSegments splits a core's cable route at the nodes where the core is broken out
+func loadCoreSegments(ctx context.Context, q querier, coreID int64) ([]models.CoreSegment, error) {
+	var cableID int64
+	err := q.QueryRow(ctx, "SELECT cable_id FROM cable_cores WHERE id = $1", coreID).Scan(&cableID)
+	if err == pgx.ErrNoRows {
+		return nil, ErrCoreNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get core: %w", err)
+	}
+
+	route, err := loadCableRoute(ctx, q, cableID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.Query(ctx, "SELECT node_id FROM cable_core_breakouts WHERE core_id = $1", coreID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get core breakouts: %w", err)
+	}
+	defer rows.Close()
+
+	breakouts := map[int64]bool{}
+	for rows.Next() {
+		var nodeID int64
+		if err := rows.Scan(&nodeID); err != nil {
+			return nil, fmt.Errorf("failed to scan core breakout: %w", err)
+		}
+		breakouts[nodeID] = true
+	}
+
+	spanLengths := make([]float64, len(route.Spans))
+	for i, span := range route.Spans {
+		spanLengths[i] = *span.SpanLengthMeter
+	}
+	if len(route.Spans) == 0 {
+		spanLengths = []float64{route.TotalLengthMeter}
+	}
+
+	return models.SplitRouteAtBreakouts(coreID, cableID, route.NodeIDs, spanLengths, breakouts), nil
+}
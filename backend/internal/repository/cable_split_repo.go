@@ -0,0 +1,419 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"spectra-backend/internal/geo"
+	"spectra-backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrInvalidSplit is returned when a cable cannot be split at the requested node
+var ErrInvalidSplit = errors.New("invalid split")
+
+// DefaultSpliceLossDB is the loss assumed for a fusion splice when none is given
+const DefaultSpliceLossDB = 0.05
+
+// Split cuts a cable at a node (existing or new) and turns it into two cables in one transaction.
+// The original cable keeps its ID, cores and the first half of the route; a new cable with a copy
+// of the core set (statuses preserved) takes the second half. Connections and breakouts located on
+// the second half are moved to the new cores. Connections without a location stay on the first half.
+func (r *CableRepository) Split(ctx context.Context, id int64, req *models.SplitCableRequest) (*models.SplitCableResult, error) {
+	if (req.NodeID == nil) == (req.NewNode == nil) {
+		return nil, fmt.Errorf("%w: exactly one of node_id or new_node is required", ErrInvalidSplit)
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	cable, err := getCableForUpdate(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if cable.OriginNodeID == nil || cable.DestNodeID == nil {
+		return nil, fmt.Errorf("%w: cable must have both origin and destination nodes", ErrInvalidSplit)
+	}
+
+	// Resolve the split node
+	var node *models.Node
+	if req.NodeID != nil {
+		node, err = getNode(ctx, tx, *req.NodeID)
+		if err != nil {
+			return nil, err
+		}
+		if node == nil {
+			return nil, fmt.Errorf("%w: node %d not found", ErrInvalidSplit, *req.NodeID)
+		}
+	} else {
+		if req.NewNode.Name == "" {
+			return nil, fmt.Errorf("%w: new node name is required", ErrInvalidSplit)
+		}
+		if req.NewNode.Type == "" {
+			req.NewNode.Type = models.NodeTypeClosure
+		}
+		node, err = insertNode(ctx, tx, req.NewNode, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if node.ID == *cable.OriginNodeID || node.ID == *cable.DestNodeID {
+		return nil, fmt.Errorf("%w: cannot split a cable at its own endpoint", ErrInvalidSplit)
+	}
+
+	route, err := loadCableRoute(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	firstSpans, secondSpans, err := splitSpans(ctx, tx, route, node)
+	if err != nil {
+		return nil, err
+	}
+
+	// Split the drawn geometry at the point closest to the node
+	var firstPath, secondPath [][]float64
+	if len(cable.PathCoordinates) >= 2 {
+		if pos, ok := geo.NearestPointOnPath(cable.PathCoordinates, node.Latitude, node.Longitude); ok {
+			firstPath, secondPath = geo.SplitPath(cable.PathCoordinates, pos)
+			nodePoint := []float64{node.Longitude, node.Latitude}
+			firstPath[len(firstPath)-1] = nodePoint
+			secondPath[0] = nodePoint
+		}
+	}
+
+	firstLength, secondLength := splitLengths(cable, route, firstSpans, secondSpans, firstPath, secondPath)
+
+	// Shorten the original cable to the first half
+	firstPathJSON, err := encodePathCoordinates(firstPath)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE cables SET dest_node_id = $1, path_coordinates = $2, length_meter = $3 WHERE id = $4
+	`, node.ID, firstPathJSON, firstLength, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update first cable: %w", err)
+	}
+
+	secondName := req.SecondCableName
+	if secondName == nil && cable.Name != nil {
+		name := *cable.Name + " (B)"
+		secondName = &name
+	}
+	colorHex := cable.ColorHex
+	second, err := insertCable(ctx, tx, &models.CreateCableRequest{
+		Name:            secondName,
+		Type:            cable.Type,
+		CoreCount:       cable.CoreCount,
+		LengthMeter:     secondLength,
+		OriginNodeID:    &node.ID,
+		DestNodeID:      cable.DestNodeID,
+		PathCoordinates: secondPath,
+		ColorHex:        &colorHex,
+		ColorScheme:     &cable.ColorScheme,
+		Status:          cable.Status,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Duplicate the core set with preserved colors and statuses
+	_, err = tx.Exec(ctx, `
+		INSERT INTO cable_cores (cable_id, core_index, tube_color, core_color, status)
+		SELECT $2, core_index, tube_color, core_color, status
+		FROM cable_cores WHERE cable_id = $1
+	`, id, second.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy cores: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT o.id, n.id, o.status
+		FROM cable_cores o
+		JOIN cable_cores n ON n.cable_id = $2 AND n.core_index = o.core_index
+		WHERE o.cable_id = $1
+		ORDER BY o.core_index ASC
+	`, id, second.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map cores: %w", err)
+	}
+	var oldIDs, newIDs []int64
+	var statuses []models.CoreStatus
+	for rows.Next() {
+		var oldID, newID int64
+		var status models.CoreStatus
+		if err := rows.Scan(&oldID, &newID, &status); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan core mapping: %w", err)
+		}
+		oldIDs = append(oldIDs, oldID)
+		newIDs = append(newIDs, newID)
+		statuses = append(statuses, status)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read core mapping: %w", err)
+	}
+
+	// Re-sequence spans onto the two cables
+	if _, err := tx.Exec(ctx, "DELETE FROM cable_spans WHERE cable_id = $1", id); err != nil {
+		return nil, fmt.Errorf("failed to clear cable spans: %w", err)
+	}
+	if err := insertSpans(ctx, tx, id, firstSpans); err != nil {
+		return nil, err
+	}
+	if err := insertSpans(ctx, tx, second.ID, secondSpans); err != nil {
+		return nil, err
+	}
+
+	// Everything beyond the split node now belongs to the second cable
+	secondNodeIDs := []int64{*cable.DestNodeID}
+	for _, span := range secondSpans {
+		if span.ToNodeID != *cable.DestNodeID {
+			secondNodeIDs = append(secondNodeIDs, span.ToNodeID)
+		}
+	}
+
+	rewired := int64(0)
+	for _, column := range []string{"input", "output"} {
+		tag, err := tx.Exec(ctx, fmt.Sprintf(`
+			UPDATE connections c
+			SET %[1]s_id = m.new_id
+			FROM unnest($1::bigint[], $2::bigint[]) AS m(old_id, new_id)
+			WHERE c.%[1]s_type = 'CORE' AND c.%[1]s_id = m.old_id AND c.location_node_id = ANY($3)
+		`, column), oldIDs, newIDs, secondNodeIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rewire connections: %w", err)
+		}
+		rewired += tag.RowsAffected()
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE cable_core_breakouts b
+		SET core_id = m.new_id
+		FROM unnest($1::bigint[], $2::bigint[]) AS m(old_id, new_id)
+		WHERE b.core_id = m.old_id AND b.node_id = ANY($3)
+	`, oldIDs, newIDs, secondNodeIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move core breakouts: %w", err)
+	}
+	// The split node is now a cable end, so breakouts there are implied
+	if _, err := tx.Exec(ctx, "DELETE FROM cable_core_breakouts WHERE core_id = ANY($1) AND node_id = $2", oldIDs, node.ID); err != nil {
+		return nil, fmt.Errorf("failed to prune core breakouts: %w", err)
+	}
+
+	result := &models.SplitCableResult{
+		SplitNode:          node,
+		CoresCopied:        len(newIDs),
+		ConnectionsRewired: int(rewired),
+		StraightSplices:    []models.Connection{},
+	}
+
+	if req.CreateStraightSplices {
+		lossDB := DefaultSpliceLossDB
+		if req.SpliceLossDB != nil {
+			lossDB = *req.SpliceLossDB
+		}
+		notes := "Straight-through splice created by cable split"
+
+		for i := range oldIDs {
+			if statuses[i] != models.CoreStatusUsed {
+				continue
+			}
+			conn := models.Connection{}
+			err := tx.QueryRow(ctx, `
+				INSERT INTO connections (location_node_id, input_type, input_id, output_type, output_id, loss_db, notes)
+				VALUES ($1, 'CORE', $2, 'CORE', $3, $4, $5)
+				RETURNING id, location_node_id, input_type, input_id, output_type, output_id, loss_db, notes, created_at, updated_at
+			`, node.ID, oldIDs[i], newIDs[i], lossDB, notes).Scan(
+				&conn.ID,
+				&conn.LocationNodeID,
+				&conn.InputType,
+				&conn.InputID,
+				&conn.OutputType,
+				&conn.OutputID,
+				&conn.LossDB,
+				&conn.Notes,
+				&conn.CreatedAt,
+				&conn.UpdatedAt,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create straight-through splice: %w", err)
+			}
+			result.StraightSplices = append(result.StraightSplices, conn)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit cable split: %w", err)
+	}
+
+	if result.FirstCable, err = r.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	if result.SecondCable, err = r.GetByID(ctx, second.ID); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// getCableForUpdate retrieves and locks a cable row inside a transaction
+func getCableForUpdate(ctx context.Context, tx pgx.Tx, id int64) (*models.Cable, error) {
+	cable := &models.Cable{}
+	var pathCoordsJSON []byte
+
+	err := tx.QueryRow(ctx, `
+		SELECT id, name, type, core_count, length_meter, origin_node_id, dest_node_id,
+			   color_hex, color_scheme, status, created_at, updated_at, path_coordinates
+		FROM cables
+		WHERE id = $1
+		FOR UPDATE
+	`, id).Scan(
+		&cable.ID,
+		&cable.Name,
+		&cable.Type,
+		&cable.CoreCount,
+		&cable.LengthMeter,
+		&cable.OriginNodeID,
+		&cable.DestNodeID,
+		&cable.ColorHex,
+		&cable.ColorScheme,
+		&cable.Status,
+		&cable.CreatedAt,
+		&cable.UpdatedAt,
+		&pathCoordsJSON,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, ErrCableNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cable: %w", err)
+	}
+
+	if cable.PathCoordinates, err = decodePathCoordinates(pathCoordsJSON); err != nil {
+		return nil, err
+	}
+
+	return cable, nil
+}
+
+// splitSpans divides a cable route into the spans before and after the split node.
+// When the node is not yet on the route it is inserted into the nearest span.
+func splitSpans(ctx context.Context, q querier, route *models.CableRoute, node *models.Node) ([]models.CableSpanInput, []models.CableSpanInput, error) {
+	if len(route.Spans) == 0 {
+		return nil, nil, nil
+	}
+
+	spans := make([]models.CableSpanInput, len(route.Spans))
+	for i, span := range route.Spans {
+		spans[i] = models.CableSpanInput{
+			FromNodeID:      span.FromNodeID,
+			ToNodeID:        span.ToNodeID,
+			SpanLengthMeter: span.SpanLengthMeter,
+		}
+	}
+
+	for i := 1; i < len(route.NodeIDs)-1; i++ {
+		if route.NodeIDs[i] == node.ID {
+			return spans[:i], spans[i:], nil
+		}
+	}
+
+	// Node is off the recorded route: cut the span whose straight line passes closest to it
+	path := make([][]float64, len(route.NodeIDs))
+	for i, nodeID := range route.NodeIDs {
+		n, err := getNode(ctx, q, nodeID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if n == nil {
+			return nil, nil, fmt.Errorf("%w: route node %d not found", ErrInvalidSplit, nodeID)
+		}
+		path[i] = []float64{n.Longitude, n.Latitude}
+	}
+
+	pos, ok := geo.NearestPointOnPath(path, node.Latitude, node.Longitude)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: cable route has no usable geometry", ErrInvalidSplit)
+	}
+
+	k := pos.SegmentIndex
+	cut := spans[k]
+	before := geo.HaversineMeters(path[k][1], path[k][0], node.Latitude, node.Longitude)
+	after := geo.HaversineMeters(node.Latitude, node.Longitude, path[k+1][1], path[k+1][0])
+
+	var beforeLength, afterLength *float64
+	if cut.SpanLengthMeter != nil && before+after > 0 {
+		b := *cut.SpanLengthMeter * before / (before + after)
+		a := *cut.SpanLengthMeter - b
+		beforeLength, afterLength = &b, &a
+	}
+
+	first := append(append([]models.CableSpanInput{}, spans[:k]...), models.CableSpanInput{
+		FromNodeID:      cut.FromNodeID,
+		ToNodeID:        node.ID,
+		SpanLengthMeter: beforeLength,
+	})
+	second := append([]models.CableSpanInput{{
+		FromNodeID:      node.ID,
+		ToNodeID:        cut.ToNodeID,
+		SpanLengthMeter: afterLength,
+	}}, spans[k+1:]...)
+
+	return first, second, nil
+}
+
+// splitLengths apportions the cable length between the two halves of a split
+func splitLengths(cable *models.Cable, route *models.CableRoute, firstSpans, secondSpans []models.CableSpanInput, firstPath, secondPath [][]float64) (*float64, *float64) {
+	firstGeo := geo.PathLengthMeters(firstPath)
+	secondGeo := geo.PathLengthMeters(secondPath)
+
+	// Prefer the recorded span lengths, scaled to the recorded cable length when both exist
+	if len(route.Spans) > 0 {
+		firstGeo, secondGeo = 0, 0
+		for _, span := range firstSpans {
+			if span.SpanLengthMeter != nil {
+				firstGeo += *span.SpanLengthMeter
+			}
+		}
+		for _, span := range secondSpans {
+			if span.SpanLengthMeter != nil {
+				secondGeo += *span.SpanLengthMeter
+			}
+		}
+	}
+
+	total := firstGeo + secondGeo
+	if total == 0 {
+		return nil, nil
+	}
+
+	if cable.LengthMeter != nil {
+		first := *cable.LengthMeter * firstGeo / total
+		second := *cable.LengthMeter - first
+		return &first, &second
+	}
+
+	return &firstGeo, &secondGeo
+}
+
+// insertSpans inserts an ordered span list for a cable starting at sequence 1
+func insertSpans(ctx context.Context, q querier, cableID int64, spans []models.CableSpanInput) error {
+	for i, span := range spans {
+		_, err := q.Exec(ctx, `
+			INSERT INTO cable_spans (cable_id, from_node_id, to_node_id, sequence, span_length_meter)
+			VALUES ($1, $2, $3, $4, $5)
+		`, cableID, span.FromNodeID, span.ToNodeID, i+1, span.SpanLengthMeter)
+		if err != nil {
+			return fmt.Errorf("failed to insert span %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
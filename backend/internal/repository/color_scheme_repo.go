@@ -0,0 +1,350 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"spectra-backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrColorSchemeNotFound is returned when a color scheme code is unknown
+	ErrColorSchemeNotFound = errors.New("color scheme not found")
+	// ErrInvalidColorScheme is returned when a color scheme definition is unusable
+	ErrInvalidColorScheme = errors.New("invalid color scheme")
+	// ErrColorSchemeInUse is returned when deleting a scheme that cables still reference
+	ErrColorSchemeInUse = errors.New("color scheme in use")
+)
+
+// maxColorLabelLength is the length of the cable_cores tube_color and core_color columns
+const maxColorLabelLength = 60
+
+// ColorSchemeRepository handles database operations for fiber color schemes
+type ColorSchemeRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewColorSchemeRepository creates a new ColorSchemeRepository
+func NewColorSchemeRepository(pool *pgxpool.Pool) *ColorSchemeRepository {
+	return &ColorSchemeRepository{pool: pool}
+}
+
+// List retrieves built-in schemes followed by custom schemes
+func (r *ColorSchemeRepository) List(ctx context.Context) ([]models.ColorScheme, error) {
+	schemes := make([]models.ColorScheme, 0, len(models.BuiltinColorSchemes))
+	for _, scheme := range models.BuiltinColorSchemes {
+		schemes = append(schemes, scheme)
+	}
+	sort.Slice(schemes, func(i, j int) bool { return schemes[i].Code < schemes[j].Code })
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, code, name, unit, fibers_per_tube, tube_colors, fiber_colors, stripe_markers, created_at, updated_at
+		FROM color_schemes
+		ORDER BY code ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list color schemes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		scheme, err := scanColorScheme(rows)
+		if err != nil {
+			return nil, err
+		}
+		schemes = append(schemes, *scheme)
+	}
+
+	return schemes, nil
+}
+
+// Get retrieves a scheme by code, built-in or custom
+func (r *ColorSchemeRepository) Get(ctx context.Context, code string) (*models.ColorScheme, error) {
+	return resolveColorScheme(ctx, r.pool, code)
+}
+
+// Create inserts a new custom color scheme
+func (r *ColorSchemeRepository) Create(ctx context.Context, req *models.CreateColorSchemeRequest) (*models.ColorScheme, error) {
+	if _, ok := models.BuiltinColorSchemes[req.Code]; ok {
+		return nil, fmt.Errorf("%w: %s is a built-in scheme", ErrInvalidColorScheme, req.Code)
+	}
+
+	scheme := &models.ColorScheme{
+		Code:          req.Code,
+		Name:          req.Name,
+		Unit:          req.Unit,
+		FibersPerTube: req.FibersPerTube,
+		TubeColors:    req.TubeColors,
+		FiberColors:   req.FiberColors,
+		StripeMarkers: req.StripeMarkers,
+	}
+	if scheme.Unit == "" {
+		scheme.Unit = models.ColorSchemeUnitTube
+	}
+	if scheme.StripeMarkers == nil {
+		scheme.StripeMarkers = []string{}
+	}
+	if err := scheme.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidColorScheme, err)
+	}
+	if err := checkColorLabels(scheme); err != nil {
+		return nil, err
+	}
+
+	tubeColors, fiberColors, stripeMarkers, err := encodeSchemeColors(scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	row := r.pool.QueryRow(ctx, `
+		INSERT INTO color_schemes (code, name, unit, fibers_per_tube, tube_colors, fiber_colors, stripe_markers)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, code, name, unit, fibers_per_tube, tube_colors, fiber_colors, stripe_markers, created_at, updated_at
+	`, scheme.Code, scheme.Name, scheme.Unit, scheme.FibersPerTube, tubeColors, fiberColors, stripeMarkers)
+
+	created, err := scanColorScheme(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create color scheme: %w", err)
+	}
+
+	return created, nil
+}
+
+// Update modifies a custom color scheme. Existing cores keep their colors until the scheme is re-applied.
+func (r *ColorSchemeRepository) Update(ctx context.Context, code string, req *models.UpdateColorSchemeRequest) (*models.ColorScheme, error) {
+	if _, ok := models.BuiltinColorSchemes[code]; ok {
+		return nil, fmt.Errorf("%w: built-in schemes cannot be modified", ErrInvalidColorScheme)
+	}
+
+	scheme, err := resolveColorScheme(ctx, r.pool, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		scheme.Name = *req.Name
+	}
+	if req.Unit != nil {
+		scheme.Unit = *req.Unit
+	}
+	if req.FibersPerTube != nil {
+		scheme.FibersPerTube = *req.FibersPerTube
+	}
+	if req.TubeColors != nil {
+		scheme.TubeColors = req.TubeColors
+	}
+	if req.FiberColors != nil {
+		scheme.FiberColors = req.FiberColors
+	}
+	if req.StripeMarkers != nil {
+		scheme.StripeMarkers = req.StripeMarkers
+	}
+	if err := scheme.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidColorScheme, err)
+	}
+	if err := checkColorLabels(scheme); err != nil {
+		return nil, err
+	}
+
+	tubeColors, fiberColors, stripeMarkers, err := encodeSchemeColors(scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	row := r.pool.QueryRow(ctx, `
+		UPDATE color_schemes
+		SET name = $1, unit = $2, fibers_per_tube = $3, tube_colors = $4, fiber_colors = $5, stripe_markers = $6
+		WHERE code = $7
+		RETURNING id, code, name, unit, fibers_per_tube, tube_colors, fiber_colors, stripe_markers, created_at, updated_at
+	`, scheme.Name, scheme.Unit, scheme.FibersPerTube, tubeColors, fiberColors, stripeMarkers, code)
+
+	updated, err := scanColorScheme(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update color scheme: %w", err)
+	}
+
+	return updated, nil
+}
+
+// Delete removes a custom color scheme that no cable uses
+func (r *ColorSchemeRepository) Delete(ctx context.Context, code string) error {
+	if _, ok := models.BuiltinColorSchemes[code]; ok {
+		return fmt.Errorf("%w: built-in schemes cannot be deleted", ErrInvalidColorScheme)
+	}
+
+	var inUse int64
+	if err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM cables WHERE color_scheme = $1", code).Scan(&inUse); err != nil {
+		return fmt.Errorf("failed to check color scheme usage: %w", err)
+	}
+	if inUse > 0 {
+		return fmt.Errorf("%w: %d cables use %s", ErrColorSchemeInUse, inUse, code)
+	}
+
+	result, err := r.pool.Exec(ctx, "DELETE FROM color_schemes WHERE code = $1", code)
+	if err != nil {
+		return fmt.Errorf("failed to delete color scheme: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrColorSchemeNotFound
+	}
+
+	return nil
+}
+
+// ApplyToCable switches a cable to another scheme and recolors all of its cores
+// Core statuses and IDs are preserved; only tube and core colors change
+func (r *ColorSchemeRepository) ApplyToCable(ctx context.Context, cableID int64, code string) ([]models.CableCore, error) {
+	scheme, err := resolveColorScheme(ctx, r.pool, code)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, "UPDATE cables SET color_scheme = $1 WHERE id = $2", scheme.Code, cableID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update cable color scheme: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, ErrCableNotFound
+	}
+
+	rows, err := tx.Query(ctx, "SELECT id, core_index FROM cable_cores WHERE cable_id = $1", cableID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cable cores: %w", err)
+	}
+	batch := &pgx.Batch{}
+	for rows.Next() {
+		var coreID int64
+		var coreIndex int
+		if err := rows.Scan(&coreID, &coreIndex); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan core: %w", err)
+		}
+		tubeColor, coreColor := scheme.ColorsForCore(coreIndex)
+		batch.Queue("UPDATE cable_cores SET tube_color = $1, core_color = $2 WHERE id = $3", tubeColor, coreColor, coreID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get cable cores: %w", err)
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	for i := 0; i < batch.Len(); i++ {
+		if _, err := results.Exec(); err != nil {
+			results.Close()
+			return nil, fmt.Errorf("failed to recolor core: %w", err)
+		}
+	}
+	if err := results.Close(); err != nil {
+		return nil, fmt.Errorf("failed to recolor cores: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit color scheme change: %w", err)
+	}
+
+	return NewCableRepository(r.pool).GetCores(ctx, cableID)
+}
+
+// checkColorLabels rejects schemes whose tube or fiber labels, stripe markers included, do not fit the
+// core color columns for some core of the largest cable
+func checkColorLabels(scheme *models.ColorScheme) error {
+	for coreIndex := 1; coreIndex <= MaxCoreCount; coreIndex++ {
+		tubeColor, coreColor := scheme.ColorsForCore(coreIndex)
+		for _, label := range []string{tubeColor, coreColor} {
+			if utf8.RuneCountInString(label) > maxColorLabelLength {
+				return fmt.Errorf("%w: color %q of core %d is longer than %d characters",
+					ErrInvalidColorScheme, label, coreIndex, maxColorLabelLength)
+			}
+		}
+	}
+	return nil
+}
+
+// resolveColorScheme looks up a scheme by code among built-ins first, then custom schemes
+func resolveColorScheme(ctx context.Context, q querier, code string) (*models.ColorScheme, error) {
+	if scheme, ok := models.BuiltinColorSchemes[code]; ok {
+		return &scheme, nil
+	}
+
+	row := q.QueryRow(ctx, `
+		SELECT id, code, name, unit, fibers_per_tube, tube_colors, fiber_colors, stripe_markers, created_at, updated_at
+		FROM color_schemes
+		WHERE code = $1
+	`, code)
+
+	scheme, err := scanColorScheme(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrColorSchemeNotFound, code)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get color scheme: %w", err)
+	}
+
+	return scheme, nil
+}
+
+// scanColorScheme scans a color_schemes row
+func scanColorScheme(row pgx.Row) (*models.ColorScheme, error) {
+	scheme := &models.ColorScheme{}
+	var id int64
+	var tubeColors, fiberColors, stripeMarkers []byte
+
+	err := row.Scan(
+		&id,
+		&scheme.Code,
+		&scheme.Name,
+		&scheme.Unit,
+		&scheme.FibersPerTube,
+		&tubeColors,
+		&fiberColors,
+		&stripeMarkers,
+		&scheme.CreatedAt,
+		&scheme.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	scheme.ID = &id
+
+	if err := json.Unmarshal(tubeColors, &scheme.TubeColors); err != nil {
+		return nil, fmt.Errorf("failed to decode tube colors: %w", err)
+	}
+	if err := json.Unmarshal(fiberColors, &scheme.FiberColors); err != nil {
+		return nil, fmt.Errorf("failed to decode fiber colors: %w", err)
+	}
+	if err := json.Unmarshal(stripeMarkers, &scheme.StripeMarkers); err != nil {
+		return nil, fmt.Errorf("failed to decode stripe markers: %w", err)
+	}
+
+	return scheme, nil
+}
+
+// encodeSchemeColors serializes the color lists of a scheme for JSONB columns
+func encodeSchemeColors(scheme *models.ColorScheme) ([]byte, []byte, []byte, error) {
+	tubeColors, err := json.Marshal(scheme.TubeColors)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to encode tube colors: %w", err)
+	}
+	fiberColors, err := json.Marshal(scheme.FiberColors)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to encode fiber colors: %w", err)
+	}
+	stripeMarkers, err := json.Marshal(scheme.StripeMarkers)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to encode stripe markers: %w", err)
+	}
+	return tubeColors, fiberColors, stripeMarkers, nil
+}
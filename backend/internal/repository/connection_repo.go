@@ -22,6 +22,24 @@ func NewConnectionRepository(pool *pgxpool.Pool) *ConnectionRepository {
 
 // Create inserts a new connection into the database
 func (r *ConnectionRepository) Create(ctx context.Context, req *models.CreateConnectionRequest) (*models.Connection, error) {
+	conn, err := insertConnection(ctx, r.pool, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update cable core status if input/output are cores
+	if req.InputType == models.ConnectionTypeCore {
+		r.updateCoreStatus(ctx, req.InputID, models.CoreStatusUsed)
+	}
+	if req.OutputType == models.ConnectionTypeCore {
+		r.updateCoreStatus(ctx, req.OutputID, models.CoreStatusUsed)
+	}
+
+	return conn, nil
+}
+
+// insertConnection inserts a connection row using the given querier, leaving core statuses as they are
+func insertConnection(ctx context.Context, q querier, req *models.CreateConnectionRequest) (*models.Connection, error) {
 	query := `
 		INSERT INTO connections (location_node_id, input_type, input_id, output_type, output_id, loss_db, notes)
 		VALUES ($1, $2, $3, $4, $5, $6, $7)
@@ -29,7 +47,7 @@ func (r *ConnectionRepository) Create(ctx context.Context, req *models.CreateCon
 	`
 
 	conn := &models.Connection{}
-	err := r.pool.QueryRow(ctx, query,
+	err := q.QueryRow(ctx, query,
 		req.LocationNodeID,
 		req.InputType,
 		req.InputID,
@@ -54,14 +72,6 @@ func (r *ConnectionRepository) Create(ctx context.Context, req *models.CreateCon
 		return nil, fmt.Errorf("failed to create connection: %w", err)
 	}
 
-	// Update cable core status if input/output are cores
-	if req.InputType == models.ConnectionTypeCore {
-		r.updateCoreStatus(ctx, req.InputID, models.CoreStatusUsed)
-	}
-	if req.OutputType == models.ConnectionTypeCore {
-		r.updateCoreStatus(ctx, req.OutputID, models.CoreStatusUsed)
-	}
-
 	return conn, nil
 }
 
@@ -140,6 +150,12 @@ func (r *ConnectionRepository) List(ctx context.Context, filter *models.Connecti
 		argIndex++
 	}
 
+	if filter.AreaID != nil {
+		baseQuery += " AND location_node_id IN " + areaNodesSQL(argIndex)
+		args = append(args, *filter.AreaID)
+		argIndex++
+	}
+
 	// Count total
 	var total int64
 	countQuery := "SELECT COUNT(*) " + baseQuery
@@ -325,3 +341,192 @@ func (r *ConnectionRepository) CalculateTotalLoss(ctx context.Context, customerN
 
 	return totalLoss, nil
 }
+
+// TraceCore follows a core through its express passes and splices in both directions, starting
+// from the segment that ends at fromNodeID, or from the core's first segment when it is nil.
+// Cores are only joined at nodes where both sides end a segment (cable end or breakout), and
+// each splice's loss is counted once.
+func (r *ConnectionRepository) TraceCore(ctx context.Context, coreID int64, fromNodeID *int64) (*models.CoreTrace, error) {
+	trace := &models.CoreTrace{
+		StartCoreID: coreID,
+		Segments:    []models.CoreSegment{},
+		Hops:        []models.TraceNode{},
+	}
+
+	type hop struct {
+		nodeID int64
+		coreID int64
+		lossDB float64
+	}
+
+	segmentCache := map[int64][]models.CoreSegment{}
+	getSegments := func(id int64) ([]models.CoreSegment, error) {
+		if segs, ok := segmentCache[id]; ok {
+			return segs, nil
+		}
+		segs, err := loadCoreSegments(ctx, r.pool, id)
+		if err != nil {
+			return nil, err
+		}
+		segmentCache[id] = segs
+		return segs, nil
+	}
+
+	startSegments, err := getSegments(coreID)
+	if err != nil {
+		return nil, err
+	}
+	if len(startSegments) == 0 {
+		return trace, nil
+	}
+
+	start := startSegments[0]
+	trace.StartNodeID = start.FromNodeID
+	if fromNodeID != nil {
+		found := false
+		for _, seg := range startSegments {
+			if seg.HasEndpoint(*fromNodeID) {
+				start, found = seg, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: core %d has no segment ending at node %d", ErrInvalidBreakout, coreID, *fromNodeID)
+		}
+		trace.StartNodeID = *fromNodeID
+	}
+
+	queued := map[string]bool{}
+	segmentKey := func(s models.CoreSegment) string {
+		return fmt.Sprintf("%d:%d:%d", s.CoreID, s.FromNodeID, s.ToNodeID)
+	}
+	seenSplice := map[int64]bool{}
+
+	hops := []hop{{nodeID: trace.StartNodeID, coreID: coreID}}
+	queue := []models.CoreSegment{start}
+	queued[segmentKey(start)] = true
+
+	for len(queue) > 0 {
+		seg := queue[0]
+		queue = queue[1:]
+		trace.Segments = append(trace.Segments, seg)
+
+		for _, nodeID := range []int64{seg.FromNodeID, seg.ToNodeID} {
+			splices, err := r.coreSplicesAt(ctx, seg.CoreID, nodeID)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, splice := range splices {
+				if seenSplice[splice.connectionID] {
+					continue
+				}
+				seenSplice[splice.connectionID] = true
+
+				nextSegments, err := getSegments(splice.otherCoreID)
+				if err != nil {
+					return nil, err
+				}
+
+				joined := false
+				for _, next := range nextSegments {
+					if !next.HasEndpoint(nodeID) || queued[segmentKey(next)] {
+						continue
+					}
+					queued[segmentKey(next)] = true
+					queue = append(queue, next)
+					joined = true
+				}
+				if joined {
+					hops = append(hops, hop{nodeID: nodeID, coreID: splice.otherCoreID, lossDB: splice.lossDB})
+					trace.TotalLoss += splice.lossDB
+				}
+			}
+		}
+	}
+
+	nodeRepo := NewNodeRepository(r.pool)
+	cableRepo := NewCableRepository(r.pool)
+	for i, h := range hops {
+		node, err := nodeRepo.GetByID(ctx, h.nodeID)
+		if err != nil {
+			return nil, err
+		}
+		if node == nil {
+			continue
+		}
+
+		traceNode := models.TraceNode{
+			Node:     *node,
+			LossDB:   h.lossDB,
+			Sequence: i + 1,
+		}
+
+		core := &models.CableCore{}
+		err = r.pool.QueryRow(ctx, `
+			SELECT id, cable_id, core_index, tube_color, core_color, status, created_at, updated_at
+			FROM cable_cores WHERE id = $1
+		`, h.coreID).Scan(
+			&core.ID,
+			&core.CableID,
+			&core.CoreIndex,
+			&core.TubeColor,
+			&core.CoreColor,
+			&core.Status,
+			&core.CreatedAt,
+			&core.UpdatedAt,
+		)
+		if err != nil && err != pgx.ErrNoRows {
+			return nil, fmt.Errorf("failed to get core: %w", err)
+		}
+		if err == nil {
+			traceNode.Core = core
+			cable, err := cableRepo.GetByID(ctx, core.CableID)
+			if err != nil {
+				return nil, err
+			}
+			traceNode.Cable = cable
+		}
+
+		trace.Hops = append(trace.Hops, traceNode)
+	}
+	trace.TotalHops = len(trace.Hops)
+
+	return trace, nil
+}
+
+// coreSplice is a core-to-core connection seen from one of its cores
+type coreSplice struct {
+	connectionID int64
+	otherCoreID  int64
+	lossDB       float64
+}
+
+// coreSplicesAt retrieves core-to-core connections of a core at a node
+// Connections without a recorded location are assumed to be at the node
+func (r *ConnectionRepository) coreSplicesAt(ctx context.Context, coreID, nodeID int64) ([]coreSplice, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id,
+			   CASE WHEN input_type = 'CORE' AND input_id = $1 THEN output_id ELSE input_id END AS other_id,
+			   COALESCE(loss_db, 0)
+		FROM connections
+		WHERE input_type = 'CORE' AND output_type = 'CORE'
+		  AND (input_id = $1 OR output_id = $1)
+		  AND (location_node_id = $2 OR location_node_id IS NULL)
+	`, coreID, nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get core splices: %w", err)
+	}
+	defer rows.Close()
+
+	var splices []coreSplice
+	for rows.Next() {
+		var s coreSplice
+		if err := rows.Scan(&s.connectionID, &s.otherCoreID, &s.lossDB); err != nil {
+			return nil, fmt.Errorf("failed to scan core splice: %w", err)
+		}
+		splices = append(splices, s)
+	}
+
+	return splices, nil
+}
@@ -22,6 +22,11 @@ func NewCustomerRepository(pool *pgxpool.Pool) *CustomerRepository {
 
 // Create inserts a new customer into the database
 func (r *CustomerRepository) Create(ctx context.Context, req *models.CreateCustomerRequest) (*models.Customer, error) {
+	return insertCustomer(ctx, r.pool, req)
+}
+
+// insertCustomer inserts a customer using the given querier so it can take part in a transaction
+func insertCustomer(ctx context.Context, q querier, req *models.CreateCustomerRequest) (*models.Customer, error) {
 	status := models.CustomerStatusOffline
 	if req.CurrentStatus != nil {
 		status = *req.CurrentStatus
@@ -34,7 +39,7 @@ func (r *CustomerRepository) Create(ctx context.Context, req *models.CreateCusto
 	`
 
 	customer := &models.Customer{}
-	err := r.pool.QueryRow(ctx, query,
+	err := q.QueryRow(ctx, query,
 		req.NodeID,
 		req.Name,
 		req.ONTSN,
@@ -147,6 +152,12 @@ func (r *CustomerRepository) List(ctx context.Context, filter *models.CustomerFi
 		argIndex++
 	}
 
+	if filter.AreaID != nil {
+		baseQuery += " AND node_id IN " + areaNodesSQL(argIndex)
+		args = append(args, *filter.AreaID)
+		argIndex++
+	}
+
 	if filter.Search != nil && *filter.Search != "" {
 		baseQuery += fmt.Sprintf(" AND (name ILIKE $%d OR ont_sn ILIKE $%d)", argIndex, argIndex)
 		args = append(args, "%"+*filter.Search+"%")
@@ -311,18 +322,74 @@ func (r *CustomerRepository) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
-// GetByStatus retrieves customers by their connection status
-func (r *CustomerRepository) GetByStatus(ctx context.Context, status models.CustomerStatus) ([]models.Customer, error) {
+// GetByStatus retrieves customers by their connection status, tagged with the active maintenance
+// window they are inside, if any. A non-nil areaID keeps the customers of that service area.
+func (r *CustomerRepository) GetByStatus(ctx context.Context, status models.CustomerStatus, areaID *int64) ([]models.Customer, error) {
+	args := []interface{}{status}
+	areaFilter := ""
+	if areaID != nil {
+		areaFilter = " AND c.id IN " + areaCustomersSQL(2)
+		args = append(args, *areaID)
+	}
+
+	query := `
+		SELECT c.id, c.node_id, c.name, c.ont_sn, c.phone, c.email, c.current_status, c.last_rx_power, c.subscription_type,
+			c.created_at, c.updated_at,
+			(SELECT MIN(mc.window_id) FROM maintenance_window_customers mc
+			JOIN maintenance_windows m ON m.id = mc.window_id
+			WHERE mc.customer_id = c.id AND m.status = 'ACTIVE')
+		FROM customers c
+		WHERE c.current_status = $1` + areaFilter + `
+		ORDER BY c.updated_at DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customers by status: %w", err)
+	}
+	defer rows.Close()
+
+	var customers []models.Customer
+	for rows.Next() {
+		var customer models.Customer
+		err := rows.Scan(
+			&customer.ID,
+			&customer.NodeID,
+			&customer.Name,
+			&customer.ONTSN,
+			&customer.Phone,
+			&customer.Email,
+			&customer.CurrentStatus,
+			&customer.LastRxPower,
+			&customer.SubscriptionType,
+			&customer.CreatedAt,
+			&customer.UpdatedAt,
+			&customer.MaintenanceWindowID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan customer: %w", err)
+		}
+		customers = append(customers, customer)
+	}
+
+	return customers, nil
+}
+
+// ListAll retrieves every customer ordered by ID, for exports
+func (r *CustomerRepository) ListAll(ctx context.Context) ([]models.Customer, error) {
+	return listCustomers(ctx, r.pool)
+}
+
+func listCustomers(ctx context.Context, q querier) ([]models.Customer, error) {
 	query := `
 		SELECT id, node_id, name, ont_sn, phone, email, current_status, last_rx_power, subscription_type, created_at, updated_at
 		FROM customers
-		WHERE current_status = $1
-		ORDER BY updated_at DESC
+		ORDER BY id ASC
 	`
 
-	rows, err := r.pool.Query(ctx, query, status)
+	rows, err := q.Query(ctx, query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get customers by status: %w", err)
+		return nil, fmt.Errorf("failed to list customers: %w", err)
 	}
 	defer rows.Close()
 
@@ -347,13 +414,28 @@ func (r *CustomerRepository) GetByStatus(ctx context.Context, status models.Cust
 		}
 		customers = append(customers, customer)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list customers: %w", err)
+	}
 
 	return customers, nil
 }
 
-// GetLOSCustomers retrieves all customers with LOS (Loss of Signal) status
-func (r *CustomerRepository) GetLOSCustomers(ctx context.Context) ([]models.Customer, error) {
-	return r.GetByStatus(ctx, models.CustomerStatusLOS)
+// GetLOSCustomers retrieves all customers with LOS (Loss of Signal) status. Customers inside an
+// active maintenance window are expected to be down and are left out when suppressMaintenance is set.
+func (r *CustomerRepository) GetLOSCustomers(ctx context.Context, suppressMaintenance bool, areaID *int64) ([]models.Customer, error) {
+	customers, err := r.GetByStatus(ctx, models.CustomerStatusLOS, areaID)
+	if err != nil || !suppressMaintenance {
+		return customers, err
+	}
+
+	unplanned := []models.Customer{}
+	for _, c := range customers {
+		if c.MaintenanceWindowID == nil {
+			unplanned = append(unplanned, c)
+		}
+	}
+	return unplanned, nil
 }
 
 // UpdateStatus updates the status and Rx power of a customer
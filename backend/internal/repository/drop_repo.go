@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"spectra-backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrInvalidDropDesign is returned when a drop cannot be designed between the given nodes
+	ErrInvalidDropDesign = errors.New("invalid drop design")
+	// ErrNoFreePort is returned when every port of the ODP is taken
+	ErrNoFreePort = errors.New("ODP has no free port")
+	// ErrPortInUse is returned when the chosen ODP port already has a connection or an active reservation
+	ErrPortInUse = errors.New("ODP port already in use")
+	// ErrCustomerHasDrop is returned when the customer node already has a drop cable
+	ErrCustomerHasDrop = errors.New("customer node already has a drop cable")
+)
+
+// DropRepository handles database operations for designed drop cables
+type DropRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewDropRepository creates a new DropRepository
+func NewDropRepository(pool *pgxpool.Pool) *DropRepository {
+	return &DropRepository{pool: pool}
+}
+
+// PortsInUse returns the port numbers of a node that have a connection or an active reservation,
+// including those of other tenants
+func (r *DropRepository) PortsInUse(ctx context.Context, nodeID int64) (map[int64]bool, error) {
+	ports, err := collectIDs(ctx, r.pool, "SELECT ports_in_use($1)", nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ports in use: %w", err)
+	}
+	return ports, nil
+}
+
+// Commit creates the drop cable of a design and patches its first core to the ODP port, all in
+// one transaction. The port, which must be free of connections and reservations, and the customer
+// node are checked again under a lock on the ODP.
+func (r *DropRepository) Commit(ctx context.Context, design *models.DropDesign) (*models.DropDesign, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	odpID, customerNodeID := design.ODP.ID, design.CustomerNode.ID
+
+	// The ODP may be shared, which tenants can read but not update, so it is locked and its port count
+	// bumped by functions that run as the operator
+	if _, err := tx.Exec(ctx, "SELECT lock_node($1)", odpID); err != nil {
+		return nil, fmt.Errorf("failed to lock ODP: %w", err)
+	}
+	var capacityPorts, usedPorts int
+	err = tx.QueryRow(ctx, "SELECT capacity_ports, used_ports FROM nodes WHERE id = $1 AND type = $2",
+		odpID, models.NodeTypeODP).Scan(&capacityPorts, &usedPorts)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: ODP %d not found", ErrInvalidDropDesign, odpID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ODP: %w", err)
+	}
+	if usedPorts >= capacityPorts {
+		return nil, fmt.Errorf("%w: %d of %d ports used", ErrNoFreePort, usedPorts, capacityPorts)
+	}
+	if design.Port < 1 || design.Port > capacityPorts {
+		return nil, fmt.Errorf("%w: port must be between 1 and %d", ErrInvalidDropDesign, capacityPorts)
+	}
+
+	var portTaken, hasDrop bool
+	err = tx.QueryRow(ctx, `
+		SELECT
+			$2 IN (SELECT ports_in_use($1)),
+			EXISTS (
+				SELECT 1 FROM cables WHERE type = $4 AND status <> $5
+					AND (origin_node_id = $3 OR dest_node_id = $3)
+			)
+	`, odpID, design.Port, customerNodeID, models.CableTypeDrop, models.CableStatusInactive).Scan(&portTaken, &hasDrop)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check drop: %w", err)
+	}
+	if portTaken {
+		return nil, fmt.Errorf("%w: port %d", ErrPortInUse, design.Port)
+	}
+	if hasDrop {
+		return nil, fmt.Errorf("%w: node %d", ErrCustomerHasDrop, customerNodeID)
+	}
+
+	req := design.Cable
+	schemeCode := models.DefaultColorScheme
+	if req.ColorScheme != nil && *req.ColorScheme != "" {
+		schemeCode = *req.ColorScheme
+	}
+	scheme, err := resolveColorScheme(ctx, tx, schemeCode)
+	if err != nil {
+		return nil, err
+	}
+	req.ColorScheme = &scheme.Code
+
+	cable, err := insertCable(ctx, tx, &req)
+	if err != nil {
+		return nil, err
+	}
+	if err := generateCores(ctx, tx, cable.ID, req.CoreCount, scheme); err != nil {
+		return nil, err
+	}
+
+	var coreID int64
+	err = tx.QueryRow(ctx, "SELECT id FROM cable_cores WHERE cable_id = $1 AND core_index = 1", cable.ID).Scan(&coreID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get drop core: %w", err)
+	}
+
+	// The connection may sit on a shared ODP, so its notes leave the customer out
+	notes := fmt.Sprintf("Drop cable #%d", cable.ID)
+	conn, err := insertConnection(ctx, tx, &models.CreateConnectionRequest{
+		LocationNodeID: &odpID,
+		InputType:      models.ConnectionTypePort,
+		InputID:        int64(design.Port),
+		OutputType:     models.ConnectionTypeCore,
+		OutputID:       coreID,
+		Notes:          &notes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, "UPDATE cable_cores SET status = $1 WHERE id = $2", models.CoreStatusUsed, coreID); err != nil {
+		return nil, fmt.Errorf("failed to update core status: %w", err)
+	}
+	if _, err := tx.Exec(ctx, "SELECT add_used_port($1)", odpID); err != nil {
+		return nil, fmt.Errorf("failed to update ODP ports: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit drop: %w", err)
+	}
+
+	committed := *design
+	committed.Committed = true
+	committed.CreatedCable = cable
+	committed.Connection = conn
+	committed.ODP.UsedPorts = usedPorts + 1
+	return &committed, nil
+}
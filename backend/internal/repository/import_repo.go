@@ -0,0 +1,492 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"spectra-backend/internal/geo"
+	"spectra-backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ImportRepository applies parsed import plans (KML, GeoJSON, ...) to the plant
+type ImportRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewImportRepository creates a new ImportRepository
+func NewImportRepository(pool *pgxpool.Pool) *ImportRepository {
+	return &ImportRepository{pool: pool}
+}
+
+// ImportSession is an import in progress inside one transaction.
+// Items are applied as they are added so large files can be streamed; each item runs in its
+// own savepoint so a bad item is reported without aborting the rest.
+type ImportSession struct {
+	tx     pgx.Tx
+	opts   models.ImportOptions
+	areas  *areaIndex
+	report *models.ImportReport
+}
+
+// Begin starts an import session; the caller must call Finish or Close
+func (r *ImportRepository) Begin(ctx context.Context, format string, opts models.ImportOptions) (*ImportSession, error) {
+	if opts.SnapToleranceMeters <= 0 {
+		opts.SnapToleranceMeters = models.DefaultSnapToleranceMeters
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// Service areas are indexed once for the whole import rather than per node
+	areas, err := loadAreaIndex(ctx, tx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return nil, err
+	}
+
+	return &ImportSession{
+		tx:    tx,
+		opts:  opts,
+		areas: areas,
+		report: &models.ImportReport{
+			Format: format,
+			DryRun: opts.DryRun,
+			Items:  []models.ImportItemResult{},
+		},
+	}, nil
+}
+
+// Apply creates the nodes and then the cables of a plan in one transaction.
+// Cable ends without an explicit node are snapped to the nearest node (imported or existing)
+// within the snap tolerance. A dry run performs the same work and rolls it back.
+func (r *ImportRepository) Apply(ctx context.Context, plan *models.ImportPlan, opts models.ImportOptions) (*models.ImportReport, error) {
+	session, err := r.Begin(ctx, plan.Format, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close(ctx)
+
+	for _, item := range plan.Skipped {
+		session.Report(item)
+	}
+	for i := range plan.Nodes {
+		if err := session.AddNode(ctx, &plan.Nodes[i]); err != nil {
+			return nil, err
+		}
+	}
+	for i := range plan.Cables {
+		if err := session.AddCable(ctx, &plan.Cables[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return session.Finish(ctx)
+}
+
+// Report records a result for an item the caller did not hand to the session, such as an unparseable feature
+func (s *ImportSession) Report(item models.ImportItemResult) {
+	s.report.Add(item)
+}
+
+// AddNode creates or updates one node. The returned error is only set for failures
+// that break the whole transaction; item failures are recorded in the report.
+func (s *ImportSession) AddNode(ctx context.Context, in *models.ImportNode) error {
+	item, err := importNode(ctx, s.tx, in, s.opts, s.areas)
+	if err != nil {
+		return err
+	}
+	s.report.Add(item)
+	return nil
+}
+
+// AddCable creates or updates one cable, linking its ends to nodes
+func (s *ImportSession) AddCable(ctx context.Context, in *models.ImportCable) error {
+	item, err := importCable(ctx, s.tx, in, s.opts)
+	if err != nil {
+		return err
+	}
+	s.report.Add(item)
+	return nil
+}
+
+// Finish commits the import, or rolls it back on a dry run, and returns the report
+func (s *ImportSession) Finish(ctx context.Context) (*models.ImportReport, error) {
+	if s.opts.DryRun {
+		// IDs of rows created in the rolled-back transaction would be misleading
+		for i := range s.report.Items {
+			if s.report.Items[i].Action == models.ImportActionCreate {
+				s.report.Items[i].ID = nil
+			}
+		}
+		if err := s.tx.Rollback(ctx); err != nil {
+			return nil, fmt.Errorf("failed to roll back dry run: %w", err)
+		}
+		return s.report, nil
+	}
+
+	if err := s.tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+	s.report.Committed = true
+	return s.report, nil
+}
+
+// Close rolls back a session that was not finished; it is a no-op after Finish
+func (s *ImportSession) Close(ctx context.Context) {
+	s.tx.Rollback(ctx)
+}
+
+// importNode creates one node. A node with a known external ID is updated; without one, a node of
+// the same name and type already within the snap tolerance is skipped.
+func importNode(ctx context.Context, tx pgx.Tx, in *models.ImportNode, opts models.ImportOptions, areas *areaIndex) (models.ImportItemResult, error) {
+	req := in.Node
+	item := models.ImportItemResult{
+		Ref:        in.Ref,
+		Kind:       models.ImportItemNode,
+		Name:       req.Name,
+		Type:       string(req.Type),
+		ExternalID: in.ExternalID,
+	}
+
+	var existingID *int64
+	if in.ExternalID != nil {
+		id, err := findByExternalID(ctx, tx, "nodes", *in.ExternalID, false)
+		if err != nil {
+			return item, err
+		}
+		existingID = id
+	} else {
+		existing, err := findDuplicateNode(ctx, tx, &req, opts.SnapToleranceMeters)
+		if err != nil {
+			return item, err
+		}
+		if existing != nil {
+			item.Action = models.ImportActionSkip
+			item.ID = &existing.ID
+			item.Message = fmt.Sprintf("node already exists (#%d)", existing.ID)
+			return item, nil
+		}
+	}
+
+	err := inSavepoint(ctx, tx, func(sp pgx.Tx) error {
+		if existingID != nil {
+			item.Action = models.ImportActionUpdate
+			item.ID = existingID
+			return updateImportedNode(ctx, sp, *existingID, &req, areas)
+		}
+
+		node, err := insertNode(ctx, sp, &req, areas)
+		if err != nil {
+			return err
+		}
+		item.Action = models.ImportActionCreate
+		item.ID = &node.ID
+		return setExternalID(ctx, sp, "nodes", node.ID, in.ExternalID)
+	})
+	if err != nil {
+		return itemFailed(item, err)
+	}
+
+	return item, nil
+}
+
+// importCable links the cable ends to nodes and creates or updates the cable with its cores
+func importCable(ctx context.Context, tx pgx.Tx, in *models.ImportCable, opts models.ImportOptions) (models.ImportItemResult, error) {
+	req := in.Cable
+	item := models.ImportItemResult{
+		Ref:        in.Ref,
+		Kind:       models.ImportItemCable,
+		Type:       string(req.Type),
+		ExternalID: in.ExternalID,
+	}
+	if req.Name != nil {
+		item.Name = *req.Name
+	}
+
+	// Ends referenced by external ID must exist; a dangling reference is an item error
+	for _, end := range []struct {
+		externalID *string
+		nodeID     **int64
+		label      string
+	}{
+		{in.OriginExternalID, &req.OriginNodeID, "origin"},
+		{in.DestExternalID, &req.DestNodeID, "destination"},
+	} {
+		if end.externalID == nil || *end.nodeID != nil {
+			continue
+		}
+		id, err := findByExternalID(ctx, tx, "nodes", *end.externalID, true)
+		if err != nil {
+			return item, err
+		}
+		if id == nil {
+			item.Action = models.ImportActionError
+			item.Message = fmt.Sprintf("%s node with external_id %q not found", end.label, *end.externalID)
+			return item, nil
+		}
+		*end.nodeID = id
+	}
+
+	path := req.PathCoordinates
+	var notes []string
+	if len(path) >= 2 {
+		if req.OriginNodeID == nil {
+			first := path[0]
+			node, distance, err := nearestNode(ctx, tx, first[1], first[0], opts.SnapToleranceMeters)
+			if err != nil {
+				return item, err
+			}
+			if node != nil {
+				req.OriginNodeID = &node.ID
+				item.OriginNode = &node.Name
+				notes = append(notes, fmt.Sprintf("origin snapped to %s (%.1f m)", node.Name, distance))
+			}
+		}
+		if req.DestNodeID == nil {
+			last := path[len(path)-1]
+			node, distance, err := nearestNode(ctx, tx, last[1], last[0], opts.SnapToleranceMeters)
+			if err != nil {
+				return item, err
+			}
+			if node != nil {
+				req.DestNodeID = &node.ID
+				item.DestNode = &node.Name
+				notes = append(notes, fmt.Sprintf("destination snapped to %s (%.1f m)", node.Name, distance))
+			}
+		}
+		if req.LengthMeter == nil {
+			length := geo.PathLengthMeters(path)
+			req.LengthMeter = &length
+		}
+	}
+	if req.OriginNodeID == nil || req.DestNodeID == nil {
+		notes = append(notes, "an end is not attached to any node")
+	}
+
+	var existingID *int64
+	if in.ExternalID != nil {
+		id, err := findByExternalID(ctx, tx, "cables", *in.ExternalID, false)
+		if err != nil {
+			return item, err
+		}
+		existingID = id
+	} else if req.Name != nil && req.OriginNodeID != nil && req.DestNodeID != nil {
+		var id int64
+		err := tx.QueryRow(ctx, `
+			SELECT id FROM cables
+			WHERE lower(name) = lower($1) AND origin_node_id = $2 AND dest_node_id = $3
+			LIMIT 1
+		`, *req.Name, *req.OriginNodeID, *req.DestNodeID).Scan(&id)
+		if err != nil && err != pgx.ErrNoRows {
+			return item, fmt.Errorf("failed to check existing cable: %w", err)
+		}
+		if err == nil {
+			item.Action = models.ImportActionSkip
+			item.ID = &id
+			item.Message = fmt.Sprintf("cable already exists (#%d)", id)
+			return item, nil
+		}
+	}
+
+	err := inSavepoint(ctx, tx, func(sp pgx.Tx) error {
+		if existingID != nil {
+			item.Action = models.ImportActionUpdate
+			item.ID = existingID
+			return updateImportedCable(ctx, sp, *existingID, &req)
+		}
+
+		cable, err := createImportedCable(ctx, sp, &req)
+		if err != nil {
+			return err
+		}
+		item.Action = models.ImportActionCreate
+		item.ID = &cable.ID
+		return setExternalID(ctx, sp, "cables", cable.ID, in.ExternalID)
+	})
+	if err != nil {
+		return itemFailed(item, err)
+	}
+
+	item.Message = strings.Join(notes, "; ")
+	return item, nil
+}
+
+// inSavepoint runs fn in a savepoint, rolling only the savepoint back when fn fails
+func inSavepoint(ctx context.Context, tx pgx.Tx, fn func(sp pgx.Tx) error) error {
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+	if err := fn(sp); err != nil {
+		sp.Rollback(ctx)
+		return &itemError{err: err}
+	}
+	if err := sp.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to release savepoint: %w", err)
+	}
+	return nil
+}
+
+// itemError marks a failure confined to a single import item
+type itemError struct {
+	err error
+}
+
+func (e *itemError) Error() string { return e.err.Error() }
+func (e *itemError) Unwrap() error { return e.err }
+
+// itemFailed records an item failure in the result, passing through errors that affect the whole import
+func itemFailed(item models.ImportItemResult, err error) (models.ImportItemResult, error) {
+	var itemErr *itemError
+	if !errors.As(err, &itemErr) {
+		return item, err
+	}
+	item.Action = models.ImportActionError
+	item.ID = nil
+	item.Message = itemErr.Error()
+	var resizeErr *CoreResizeError
+	if errors.As(err, &resizeErr) {
+		item.Message = "core_count change blocked: " + resizeErr.Error()
+	}
+	return item, nil
+}
+
+func createImportedCable(ctx context.Context, q querier, req *models.CreateCableRequest) (*models.Cable, error) {
+	schemeCode := models.DefaultColorScheme
+	if req.ColorScheme != nil && *req.ColorScheme != "" {
+		schemeCode = *req.ColorScheme
+	}
+	scheme, err := resolveColorScheme(ctx, q, schemeCode)
+	if err != nil {
+		return nil, err
+	}
+	req.ColorScheme = &scheme.Code
+
+	cable, err := insertCable(ctx, q, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := generateCores(ctx, q, cable.ID, req.CoreCount, scheme); err != nil {
+		return nil, err
+	}
+	return cable, nil
+}
+
+// updateImportedNode overwrites a node with imported values, moving it to the area of its new position;
+// optional fields left empty keep their value
+func updateImportedNode(ctx context.Context, q querier, id int64, req *models.CreateNodeRequest, areas *areaIndex) error {
+	_, err := q.Exec(ctx, `
+		UPDATE nodes
+		SET name = $1, type = $2, latitude = $3, longitude = $4,
+			address = COALESCE($5, address),
+			capacity_ports = COALESCE($6, capacity_ports),
+			model = COALESCE($7, model),
+			status = COALESCE(NULLIF($8::text, ''), status),
+			area_id = $9
+		WHERE id = $10
+	`, req.Name, req.Type, req.Latitude, req.Longitude, req.Address, req.CapacityPorts, req.Model, string(req.Status),
+		areas.locate(req.Longitude, req.Latitude), id)
+	if err != nil {
+		return fmt.Errorf("failed to update node: %w", err)
+	}
+	return nil
+}
+
+// updateImportedCable overwrites a cable with imported values and resizes its cores when
+// core_count changed. Shrinking onto cores in service is refused rather than forced.
+func updateImportedCable(ctx context.Context, tx pgx.Tx, id int64, req *models.CreateCableRequest) error {
+	pathJSON, err := encodePathCoordinates(req.PathCoordinates)
+	if err != nil {
+		return err
+	}
+
+	var currentCount int
+	if err := tx.QueryRow(ctx, "SELECT core_count FROM cables WHERE id = $1", id).Scan(&currentCount); err != nil {
+		return fmt.Errorf("failed to get cable: %w", err)
+	}
+	if req.CoreCount != currentCount {
+		if _, err := resizeCores(ctx, tx, id, req.CoreCount, false); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE cables
+		SET name = COALESCE($1, name), type = $2, core_count = $3,
+			length_meter = COALESCE($4, length_meter),
+			origin_node_id = COALESCE($5, origin_node_id),
+			dest_node_id = COALESCE($6, dest_node_id),
+			path_coordinates = COALESCE($7, path_coordinates),
+			color_hex = COALESCE($8, color_hex),
+			status = COALESCE(NULLIF($9::text, ''), status)
+		WHERE id = $10
+	`, req.Name, req.Type, req.CoreCount, req.LengthMeter, req.OriginNodeID, req.DestNodeID,
+		pathJSON, req.ColorHex, string(req.Status), id)
+	if err != nil {
+		return fmt.Errorf("failed to update cable: %w", err)
+	}
+	return nil
+}
+
+// findByExternalID returns the ID of the caller's row in table ("nodes" or "cables") carrying an external ID.
+// External IDs are unique per tenant and the operator's rows are shared, so with shared set a tenant's own
+// row is preferred over a shared one; without it only rows the caller owns, and can update, are found.
+func findByExternalID(ctx context.Context, q querier, table, externalID string, shared bool) (*int64, error) {
+	var id int64
+	err := q.QueryRow(ctx, `
+		SELECT id FROM `+table+`
+		WHERE external_id = $1
+			AND (tenant_id IS NOT DISTINCT FROM current_tenant_id() OR ($2 AND tenant_id IS NULL))
+		ORDER BY tenant_id IS NULL
+		LIMIT 1
+	`, externalID, shared).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up external_id: %w", err)
+	}
+	return &id, nil
+}
+
+func setExternalID(ctx context.Context, q querier, table string, id int64, externalID *string) error {
+	if externalID == nil {
+		return nil
+	}
+	if _, err := q.Exec(ctx, "UPDATE "+table+" SET external_id = $1 WHERE id = $2", *externalID, id); err != nil {
+		return fmt.Errorf("failed to set external_id: %w", err)
+	}
+	return nil
+}
+
+// findDuplicateNode returns an existing node with the same name and type within maxMeters
+func findDuplicateNode(ctx context.Context, q querier, req *models.CreateNodeRequest, maxMeters float64) (*models.Node, error) {
+	rows, err := q.Query(ctx, `
+		SELECT id, latitude, longitude
+		FROM nodes
+		WHERE lower(name) = lower($1) AND type = $2
+	`, req.Name, req.Type)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing node: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var node models.Node
+		if err := rows.Scan(&node.ID, &node.Latitude, &node.Longitude); err != nil {
+			return nil, fmt.Errorf("failed to scan node: %w", err)
+		}
+		if geo.HaversineMeters(req.Latitude, req.Longitude, node.Latitude, node.Longitude) <= maxMeters {
+			return &node, nil
+		}
+	}
+
+	return nil, rows.Err()
+}
@@ -0,0 +1,619 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"spectra-backend/internal/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrMaintenanceWindowNotFound is returned when a maintenance window ID is unknown
+	ErrMaintenanceWindowNotFound = errors.New("maintenance window not found")
+	// ErrInvalidMaintenanceWindow is returned when a maintenance window request is unusable
+	ErrInvalidMaintenanceWindow = errors.New("invalid maintenance window")
+	// ErrMaintenanceWindowState is returned when a window's status does not allow the change
+	ErrMaintenanceWindowState = errors.New("maintenance window status does not allow this")
+)
+
+// maintenanceWindowSelect selects maintenance windows with the size of their customer set
+const maintenanceWindowSelect = `
+	SELECT m.id, m.title, m.description, m.work_order_id, m.status, m.starts_at, m.ends_at, m.started_at,
+		m.ended_at, m.created_by, m.created_at, m.updated_at,
+		(SELECT COUNT(*) FROM maintenance_window_customers mc WHERE mc.window_id = m.id)
+	FROM maintenance_windows m
+`
+
+// MaintenanceRepository handles database operations for maintenance windows
+type MaintenanceRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewMaintenanceRepository creates a new MaintenanceRepository
+func NewMaintenanceRepository(pool *pgxpool.Pool) *MaintenanceRepository {
+	return &MaintenanceRepository{pool: pool}
+}
+
+// Create schedules a maintenance window with its assets and the customers downstream of them
+func (r *MaintenanceRepository) Create(ctx context.Context, req *models.CreateMaintenanceWindowRequest, customerIDs []int64) (*models.MaintenanceWindow, error) {
+	if !req.EndsAt.After(req.StartsAt) {
+		return nil, fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidMaintenanceWindow)
+	}
+	if len(req.Assets) == 0 {
+		return nil, fmt.Errorf("%w: at least one asset is required", ErrInvalidMaintenanceWindow)
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var id int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO maintenance_windows (title, description, work_order_id, starts_at, ends_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, req.Title, req.Description, req.WorkOrderID, req.StartsAt, req.EndsAt, req.CreatedBy).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create maintenance window: %w", err)
+	}
+
+	if err := replaceMaintenanceAssets(ctx, tx, id, req.Assets); err != nil {
+		return nil, err
+	}
+	if err := replaceMaintenanceCustomers(ctx, tx, id, customerIDs); err != nil {
+		return nil, err
+	}
+
+	window, err := getMaintenanceWindow(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return window, nil
+}
+
+// GetByID retrieves a maintenance window with its assets and affected customers
+func (r *MaintenanceRepository) GetByID(ctx context.Context, id int64) (*models.MaintenanceWindow, error) {
+	return getMaintenanceWindow(ctx, r.pool, id)
+}
+
+// List retrieves maintenance windows ordered by start time
+func (r *MaintenanceRepository) List(ctx context.Context, filter *models.MaintenanceWindowFilter) ([]models.MaintenanceWindow, int64, error) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.Status != nil {
+		where += fmt.Sprintf(" AND m.status = $%d", argIndex)
+		args = append(args, *filter.Status)
+		argIndex++
+	}
+	if filter.From != nil {
+		where += fmt.Sprintf(" AND m.ends_at >= $%d", argIndex)
+		args = append(args, *filter.From)
+		argIndex++
+	}
+	if filter.To != nil {
+		where += fmt.Sprintf(" AND m.starts_at <= $%d", argIndex)
+		args = append(args, *filter.To)
+		argIndex++
+	}
+	if filter.Asset != nil {
+		where += fmt.Sprintf(` AND EXISTS (
+			SELECT 1 FROM maintenance_window_assets a WHERE a.window_id = m.id AND a.element_type = $%d AND a.element_id = $%d
+		)`, argIndex, argIndex+1)
+		args = append(args, filter.Asset.ElementType, filter.Asset.ElementID)
+		argIndex += 2
+	}
+	if filter.CustomerID != nil {
+		where += fmt.Sprintf(` AND EXISTS (
+			SELECT 1 FROM maintenance_window_customers mc WHERE mc.window_id = m.id AND mc.customer_id = $%d
+		)`, argIndex)
+		args = append(args, *filter.CustomerID)
+		argIndex++
+	}
+	if filter.WorkOrderID != nil {
+		where += fmt.Sprintf(" AND m.work_order_id = $%d", argIndex)
+		args = append(args, *filter.WorkOrderID)
+		argIndex++
+	}
+	if filter.AreaID != nil {
+		where += fmt.Sprintf(` AND (EXISTS (
+			SELECT 1 FROM maintenance_window_assets a WHERE a.window_id = m.id AND %s
+		) OR EXISTS (
+			SELECT 1 FROM maintenance_window_customers mc WHERE mc.window_id = m.id AND mc.customer_id IN %s
+		))`, areaElementSQL("a", argIndex), areaCustomersSQL(argIndex))
+		args = append(args, *filter.AreaID)
+		argIndex++
+	}
+
+	var total int64
+	if err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM maintenance_windows m"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count maintenance windows: %w", err)
+	}
+
+	limit := 100
+	offset := 0
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+	if filter.Offset > 0 {
+		offset = filter.Offset
+	}
+	args = append(args, limit, offset)
+
+	windows, err := listMaintenanceWindows(ctx, r.pool,
+		fmt.Sprintf("%s ORDER BY m.starts_at ASC, m.id ASC LIMIT $%d OFFSET $%d", where, argIndex, argIndex+1), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return windows, total, nil
+}
+
+// Update edits a scheduled window. customerIDs replaces the affected customer set unless nil.
+func (r *MaintenanceRepository) Update(ctx context.Context, id int64, req *models.UpdateMaintenanceWindowRequest, customerIDs []int64) (*models.MaintenanceWindow, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := lockMaintenanceWindow(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if current.Status != models.MaintenanceStatusScheduled {
+		return nil, fmt.Errorf("%w: only scheduled windows can be edited", ErrMaintenanceWindowState)
+	}
+
+	startsAt, endsAt := current.StartsAt, current.EndsAt
+	if req.StartsAt != nil {
+		startsAt = *req.StartsAt
+	}
+	if req.EndsAt != nil {
+		endsAt = *req.EndsAt
+	}
+	if !endsAt.After(startsAt) {
+		return nil, fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidMaintenanceWindow)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE maintenance_windows SET title = COALESCE($1, title), description = COALESCE($2, description),
+			starts_at = $3, ends_at = $4
+		WHERE id = $5
+	`, req.Title, req.Description, startsAt, endsAt, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update maintenance window: %w", err)
+	}
+
+	if req.Assets != nil {
+		if len(req.Assets) == 0 {
+			return nil, fmt.Errorf("%w: at least one asset is required", ErrInvalidMaintenanceWindow)
+		}
+		if err := replaceMaintenanceAssets(ctx, tx, id, req.Assets); err != nil {
+			return nil, err
+		}
+	}
+	if customerIDs != nil {
+		if err := replaceMaintenanceCustomers(ctx, tx, id, customerIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	window, err := getMaintenanceWindow(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return window, nil
+}
+
+// Start activates a scheduled window: its nodes and cables are flagged MAINTENANCE and the
+// customer set is refreshed from the current topology
+func (r *MaintenanceRepository) Start(ctx context.Context, id int64, customerIDs []int64) (*models.MaintenanceWindow, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := lockMaintenanceWindow(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if current.Status != models.MaintenanceStatusScheduled {
+		return nil, fmt.Errorf("%w: only scheduled windows can be started", ErrMaintenanceWindowState)
+	}
+
+	// Keep the status from before any overlapping active window, so the last window to end restores it
+	_, err = tx.Exec(ctx, `
+		UPDATE maintenance_window_assets a SET previous_status = COALESCE(
+			(SELECT o.previous_status FROM maintenance_window_assets o
+			JOIN maintenance_windows w ON w.id = o.window_id
+			WHERE w.status = 'ACTIVE' AND o.element_type = a.element_type AND o.element_id = a.element_id
+				AND o.previous_status IS NOT NULL
+			LIMIT 1),
+			CASE a.element_type
+				WHEN 'NODE' THEN (SELECT status FROM nodes WHERE id = a.element_id)
+				WHEN 'CABLE' THEN (SELECT status FROM cables WHERE id = a.element_id)
+			END
+		)
+		WHERE a.window_id = $1
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record asset statuses: %w", err)
+	}
+	for _, table := range []struct{ name, elementType string }{{"nodes", "NODE"}, {"cables", "CABLE"}} {
+		_, err := tx.Exec(ctx, `
+			UPDATE `+table.name+` SET status = 'MAINTENANCE'
+			WHERE id IN (SELECT element_id FROM maintenance_window_assets WHERE window_id = $1 AND element_type = $2)
+		`, id, table.elementType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to flag %s for maintenance: %w", table.name, err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE maintenance_windows SET status = $1, started_at = NOW() WHERE id = $2",
+		models.MaintenanceStatusActive, id); err != nil {
+		return nil, fmt.Errorf("failed to start maintenance window: %w", err)
+	}
+	if customerIDs != nil {
+		if err := replaceMaintenanceCustomers(ctx, tx, id, customerIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	window, err := getMaintenanceWindow(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return window, nil
+}
+
+// End completes an active window and restores the statuses its assets had before it started,
+// unless another active window still covers them
+func (r *MaintenanceRepository) End(ctx context.Context, id int64) (*models.MaintenanceWindow, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := lockMaintenanceWindow(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if current.Status != models.MaintenanceStatusActive {
+		return nil, fmt.Errorf("%w: only active windows can be ended", ErrMaintenanceWindowState)
+	}
+	if err := endMaintenanceWindow(ctx, tx, id); err != nil {
+		return nil, err
+	}
+
+	window, err := getMaintenanceWindow(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return window, nil
+}
+
+// Cancel cancels a window that has not started
+func (r *MaintenanceRepository) Cancel(ctx context.Context, id int64) (*models.MaintenanceWindow, error) {
+	tag, err := r.pool.Exec(ctx, "UPDATE maintenance_windows SET status = $1 WHERE id = $2 AND status = $3",
+		models.MaintenanceStatusCancelled, id, models.MaintenanceStatusScheduled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel maintenance window: %w", err)
+	}
+
+	window, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if window == nil {
+		return nil, ErrMaintenanceWindowNotFound
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, fmt.Errorf("%w: only scheduled windows can be cancelled", ErrMaintenanceWindowState)
+	}
+	return window, nil
+}
+
+// ListDue retrieves scheduled windows whose start time has passed, with their assets
+func (r *MaintenanceRepository) ListDue(ctx context.Context, now time.Time) ([]models.MaintenanceWindow, error) {
+	windows, err := listMaintenanceWindows(ctx, r.pool, " WHERE m.status = 'SCHEDULED' AND m.starts_at <= $1 ORDER BY m.starts_at", now)
+	if err != nil {
+		return nil, err
+	}
+	for i := range windows {
+		if windows[i].Assets, err = listMaintenanceAssets(ctx, r.pool, windows[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return windows, nil
+}
+
+// EndExpired ends every active window whose end time has passed and returns how many ended
+func (r *MaintenanceRepository) EndExpired(ctx context.Context, now time.Time) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	ids, err := collectIDs(ctx, tx, "SELECT id FROM maintenance_windows WHERE status = 'ACTIVE' AND ends_at <= $1 FOR UPDATE", now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find expired maintenance windows: %w", err)
+	}
+	for id := range ids {
+		if err := endMaintenanceWindow(ctx, tx, id); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(ids), nil
+}
+
+// endMaintenanceWindow completes a window, then restores asset statuses no other active window holds
+func endMaintenanceWindow(ctx context.Context, q querier, id int64) error {
+	if _, err := q.Exec(ctx, "UPDATE maintenance_windows SET status = $1, ended_at = NOW() WHERE id = $2",
+		models.MaintenanceStatusCompleted, id); err != nil {
+		return fmt.Errorf("failed to end maintenance window: %w", err)
+	}
+	for _, table := range []struct{ name, elementType string }{{"nodes", "NODE"}, {"cables", "CABLE"}} {
+		_, err := q.Exec(ctx, `
+			UPDATE `+table.name+` t SET status = a.previous_status
+			FROM maintenance_window_assets a
+			WHERE a.window_id = $1 AND a.element_type = $2 AND t.id = a.element_id
+				AND t.status = 'MAINTENANCE' AND a.previous_status IS NOT NULL
+				AND NOT EXISTS (
+					SELECT 1 FROM maintenance_window_assets o
+					JOIN maintenance_windows w ON w.id = o.window_id
+					WHERE w.status = 'ACTIVE' AND o.element_type = $2 AND o.element_id = t.id
+				)
+		`, id, table.elementType)
+		if err != nil {
+			return fmt.Errorf("failed to restore %s after maintenance: %w", table.name, err)
+		}
+	}
+	return nil
+}
+
+// replaceMaintenanceAssets checks that the nodes and cables exist and replaces the window's assets
+func replaceMaintenanceAssets(ctx context.Context, q querier, id int64, assets []models.MaintenanceAssetRequest) error {
+	idsByType := map[models.MaintenanceAssetType][]int64{}
+	for _, a := range assets {
+		if !a.ElementType.IsValid() {
+			return fmt.Errorf("%w: asset type must be NODE or CABLE", ErrInvalidMaintenanceWindow)
+		}
+		idsByType[a.ElementType] = append(idsByType[a.ElementType], a.ElementID)
+	}
+	for assetType, ids := range idsByType {
+		names, err := collectNames(ctx, q, elementNameQueries[string(assetType)], ids)
+		if err != nil {
+			return fmt.Errorf("failed to check maintenance assets: %w", err)
+		}
+		for _, elementID := range ids {
+			if _, ok := names[elementID]; !ok {
+				return fmt.Errorf("%w: %s %d does not exist", ErrInvalidMaintenanceWindow, assetType, elementID)
+			}
+		}
+	}
+
+	if _, err := q.Exec(ctx, "DELETE FROM maintenance_window_assets WHERE window_id = $1", id); err != nil {
+		return fmt.Errorf("failed to replace maintenance assets: %w", err)
+	}
+	for _, a := range assets {
+		_, err := q.Exec(ctx, `
+			INSERT INTO maintenance_window_assets (window_id, element_type, element_id) VALUES ($1, $2, $3)
+			ON CONFLICT (window_id, element_type, element_id) DO NOTHING
+		`, id, a.ElementType, a.ElementID)
+		if err != nil {
+			return fmt.Errorf("failed to add maintenance asset: %w", err)
+		}
+	}
+	return nil
+}
+
+// replaceMaintenanceCustomers replaces the window's affected customer set
+func replaceMaintenanceCustomers(ctx context.Context, q querier, id int64, customerIDs []int64) error {
+	if _, err := q.Exec(ctx, "DELETE FROM maintenance_window_customers WHERE window_id = $1", id); err != nil {
+		return fmt.Errorf("failed to replace maintenance customers: %w", err)
+	}
+	if len(customerIDs) == 0 {
+		return nil
+	}
+	_, err := q.Exec(ctx, `
+		INSERT INTO maintenance_window_customers (window_id, customer_id)
+		SELECT $1, c.id FROM customers c WHERE c.id = ANY($2)
+		ON CONFLICT DO NOTHING
+	`, id, customerIDs)
+	if err != nil {
+		return fmt.Errorf("failed to add maintenance customers: %w", err)
+	}
+	return nil
+}
+
+// listActiveMaintenanceCustomers maps each customer inside an active window to that window
+func listActiveMaintenanceCustomers(ctx context.Context, q querier) (map[int64]int64, error) {
+	rows, err := q.Query(ctx, `
+		SELECT mc.customer_id, MIN(mc.window_id)
+		FROM maintenance_window_customers mc
+		JOIN maintenance_windows m ON m.id = mc.window_id
+		WHERE m.status = 'ACTIVE'
+		GROUP BY mc.customer_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maintenance customers: %w", err)
+	}
+	defer rows.Close()
+
+	windows := map[int64]int64{}
+	for rows.Next() {
+		var customerID, windowID int64
+		if err := rows.Scan(&customerID, &windowID); err != nil {
+			return nil, fmt.Errorf("failed to scan maintenance customer: %w", err)
+		}
+		windows[customerID] = windowID
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list maintenance customers: %w", err)
+	}
+	return windows, nil
+}
+
+// lockMaintenanceWindow locks a window row for a change and returns it without its lists
+func lockMaintenanceWindow(ctx context.Context, q querier, id int64) (*models.MaintenanceWindow, error) {
+	windows, err := listMaintenanceWindows(ctx, q, " WHERE m.id = $1 FOR UPDATE OF m", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(windows) == 0 {
+		return nil, ErrMaintenanceWindowNotFound
+	}
+	return &windows[0], nil
+}
+
+// getMaintenanceWindow retrieves a window with its assets and customers, or nil
+func getMaintenanceWindow(ctx context.Context, q querier, id int64) (*models.MaintenanceWindow, error) {
+	windows, err := listMaintenanceWindows(ctx, q, " WHERE m.id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(windows) == 0 {
+		return nil, nil
+	}
+	window := &windows[0]
+
+	if window.Assets, err = listMaintenanceAssets(ctx, q, id); err != nil {
+		return nil, err
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT c.id, c.name, c.phone, c.email, c.ont_sn, c.subscription_type, c.current_status,
+			COALESCE(c.node_id, 0), COALESCE(n.name, '')
+		FROM maintenance_window_customers mc
+		JOIN customers c ON c.id = mc.customer_id
+		LEFT JOIN nodes n ON n.id = c.node_id
+		WHERE mc.window_id = $1
+		ORDER BY n.name, c.name, c.id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get maintenance customers: %w", err)
+	}
+	defer rows.Close()
+
+	window.Customers = []models.ImpactCustomer{}
+	for rows.Next() {
+		var c models.ImpactCustomer
+		err := rows.Scan(&c.ID, &c.Name, &c.Phone, &c.Email, &c.ONTSN, &c.SubscriptionType, &c.CurrentStatus, &c.NodeID, &c.NodeName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan maintenance customer: %w", err)
+		}
+		if window.Status == models.MaintenanceStatusActive {
+			c.MaintenanceWindowID = &window.ID
+		}
+		window.Customers = append(window.Customers, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get maintenance customers: %w", err)
+	}
+
+	return window, nil
+}
+
+// listMaintenanceAssets retrieves a window's assets with node and cable names
+func listMaintenanceAssets(ctx context.Context, q querier, id int64) ([]models.MaintenanceAsset, error) {
+	rows, err := q.Query(ctx, `
+		SELECT a.id, a.window_id, a.element_type, a.element_id, a.previous_status,
+			CASE a.element_type
+				WHEN 'NODE' THEN (SELECT name FROM nodes WHERE id = a.element_id)
+				WHEN 'CABLE' THEN (SELECT name FROM cables WHERE id = a.element_id)
+			END
+		FROM maintenance_window_assets a
+		WHERE a.window_id = $1
+		ORDER BY a.id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get maintenance assets: %w", err)
+	}
+	defer rows.Close()
+
+	assets := []models.MaintenanceAsset{}
+	for rows.Next() {
+		var a models.MaintenanceAsset
+		if err := rows.Scan(&a.ID, &a.WindowID, &a.ElementType, &a.ElementID, &a.PreviousStatus, &a.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan maintenance asset: %w", err)
+		}
+		assets = append(assets, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get maintenance assets: %w", err)
+	}
+	return assets, nil
+}
+
+// listMaintenanceWindows runs maintenanceWindowSelect with the given WHERE/ORDER clause
+func listMaintenanceWindows(ctx context.Context, q querier, clause string, args ...interface{}) ([]models.MaintenanceWindow, error) {
+	rows, err := q.Query(ctx, maintenanceWindowSelect+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maintenance windows: %w", err)
+	}
+	defer rows.Close()
+
+	windows := []models.MaintenanceWindow{}
+	for rows.Next() {
+		var m models.MaintenanceWindow
+		err := rows.Scan(
+			&m.ID,
+			&m.Title,
+			&m.Description,
+			&m.WorkOrderID,
+			&m.Status,
+			&m.StartsAt,
+			&m.EndsAt,
+			&m.StartedAt,
+			&m.EndedAt,
+			&m.CreatedBy,
+			&m.CreatedAt,
+			&m.UpdatedAt,
+			&m.CustomerCount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan maintenance window: %w", err)
+		}
+		windows = append(windows, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list maintenance windows: %w", err)
+	}
+
+	return windows, nil
+}
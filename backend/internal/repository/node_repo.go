@@ -3,7 +3,9 @@ package repository
 import (
 	"context"
 	"fmt"
+	"math"
 
+	"spectra-backend/internal/geo"
 	"spectra-backend/internal/models"
 
 	"github.com/jackc/pgx/v5"
@@ -22,10 +24,23 @@ func NewNodeRepository(pool *pgxpool.Pool) *NodeRepository {
 
 // Create inserts a new node into the database
 func (r *NodeRepository) Create(ctx context.Context, req *models.CreateNodeRequest) (*models.Node, error) {
+	return insertNode(ctx, r.pool, req, nil)
+}
+
+// insertNode inserts a node using the given querier so it can take part in a transaction.
+// The node is placed in its service area by the area index, which is loaded when areas is nil.
+func insertNode(ctx context.Context, q querier, req *models.CreateNodeRequest, areas *areaIndex) (*models.Node, error) {
+	if areas == nil {
+		var err error
+		if areas, err = loadAreaIndex(ctx, q); err != nil {
+			return nil, err
+		}
+	}
+
 	query := `
-		INSERT INTO nodes (name, type, latitude, longitude, address, capacity_ports, model, status)
-		VALUES ($1, $2, $3, $4, $5, COALESCE($6, 8), $7, COALESCE($8, 'ACTIVE'))
-		RETURNING id, name, type, latitude, longitude, address, capacity_ports, used_ports, model, status, created_at, updated_at
+		INSERT INTO nodes (name, type, latitude, longitude, address, capacity_ports, model, status, area_id)
+		VALUES ($1, $2, $3, $4, $5, COALESCE($6, 8), $7, COALESCE($8, 'ACTIVE'), $9)
+		RETURNING id, name, type, latitude, longitude, address, capacity_ports, used_ports, model, status, area_id, created_at, updated_at
 	`
 
 	capacityPorts := 8
@@ -39,7 +54,7 @@ func (r *NodeRepository) Create(ctx context.Context, req *models.CreateNodeReque
 	}
 
 	node := &models.Node{}
-	err := r.pool.QueryRow(ctx, query,
+	err := q.QueryRow(ctx, query,
 		req.Name,
 		req.Type,
 		req.Latitude,
@@ -48,6 +63,7 @@ func (r *NodeRepository) Create(ctx context.Context, req *models.CreateNodeReque
 		capacityPorts,
 		req.Model,
 		status,
+		areas.locate(req.Longitude, req.Latitude),
 	).Scan(
 		&node.ID,
 		&node.Name,
@@ -59,6 +75,7 @@ func (r *NodeRepository) Create(ctx context.Context, req *models.CreateNodeReque
 		&node.UsedPorts,
 		&node.Model,
 		&node.Status,
+		&node.AreaID,
 		&node.CreatedAt,
 		&node.UpdatedAt,
 	)
@@ -72,14 +89,19 @@ func (r *NodeRepository) Create(ctx context.Context, req *models.CreateNodeReque
 
 // GetByID retrieves a node by its ID
 func (r *NodeRepository) GetByID(ctx context.Context, id int64) (*models.Node, error) {
+	return getNode(ctx, r.pool, id)
+}
+
+// getNode retrieves a node by its ID using the given querier
+func getNode(ctx context.Context, q querier, id int64) (*models.Node, error) {
 	query := `
-		SELECT id, name, type, latitude, longitude, address, capacity_ports, used_ports, model, status, created_at, updated_at
+		SELECT id, name, type, latitude, longitude, address, capacity_ports, used_ports, model, status, area_id, created_at, updated_at
 		FROM nodes
 		WHERE id = $1
 	`
 
 	node := &models.Node{}
-	err := r.pool.QueryRow(ctx, query, id).Scan(
+	err := q.QueryRow(ctx, query, id).Scan(
 		&node.ID,
 		&node.Name,
 		&node.Type,
@@ -90,6 +112,7 @@ func (r *NodeRepository) GetByID(ctx context.Context, id int64) (*models.Node, e
 		&node.UsedPorts,
 		&node.Model,
 		&node.Status,
+		&node.AreaID,
 		&node.CreatedAt,
 		&node.UpdatedAt,
 	)
@@ -122,6 +145,12 @@ func (r *NodeRepository) List(ctx context.Context, filter *models.NodeFilter) ([
 		argIndex++
 	}
 
+	if filter.AreaID != nil {
+		baseQuery += " AND id IN " + areaNodesSQL(argIndex)
+		args = append(args, *filter.AreaID)
+		argIndex++
+	}
+
 	// Count total
 	var total int64
 	countQuery := "SELECT COUNT(*) " + baseQuery
@@ -141,7 +170,7 @@ func (r *NodeRepository) List(ctx context.Context, filter *models.NodeFilter) ([
 	}
 
 	dataQuery := fmt.Sprintf(`
-		SELECT id, name, type, latitude, longitude, address, capacity_ports, used_ports, model, status, created_at, updated_at
+		SELECT id, name, type, latitude, longitude, address, capacity_ports, used_ports, model, status, area_id, created_at, updated_at
 		%s
 		ORDER BY created_at DESC
 		LIMIT $%d OFFSET $%d
@@ -169,6 +198,7 @@ func (r *NodeRepository) List(ctx context.Context, filter *models.NodeFilter) ([
 			&node.UsedPorts,
 			&node.Model,
 			&node.Status,
+			&node.AreaID,
 			&node.CreatedAt,
 			&node.UpdatedAt,
 		)
@@ -244,7 +274,7 @@ func (r *NodeRepository) Update(ctx context.Context, id int64, req *models.Updat
 		UPDATE nodes
 		SET %s
 		WHERE id = $%d
-		RETURNING id, name, type, latitude, longitude, address, capacity_ports, used_ports, model, status, created_at, updated_at
+		RETURNING id, name, type, latitude, longitude, address, capacity_ports, used_ports, model, status, area_id, created_at, updated_at
 	`, joinStrings(setParts, ", "), argIndex)
 
 	node := &models.Node{}
@@ -259,6 +289,7 @@ func (r *NodeRepository) Update(ctx context.Context, id int64, req *models.Updat
 		&node.UsedPorts,
 		&node.Model,
 		&node.Status,
+		&node.AreaID,
 		&node.CreatedAt,
 		&node.UpdatedAt,
 	)
@@ -270,6 +301,12 @@ func (r *NodeRepository) Update(ctx context.Context, id int64, req *models.Updat
 		return nil, fmt.Errorf("failed to update node: %w", err)
 	}
 
+	if req.Latitude != nil || req.Longitude != nil {
+		if err := assignNodeArea(ctx, r.pool, node); err != nil {
+			return nil, err
+		}
+	}
+
 	return node, nil
 }
 
@@ -294,7 +331,7 @@ func (r *NodeRepository) GetNearby(ctx context.Context, query *models.NearbyQuer
 	// Haversine formula in SQL for distance calculation (returns distance in km)
 	// 6371 is Earth's radius in kilometers
 	sqlQuery := `
-		SELECT id, name, type, latitude, longitude, address, capacity_ports, used_ports, model, status, created_at, updated_at,
+		SELECT id, name, type, latitude, longitude, address, capacity_ports, used_ports, model, status, area_id, created_at, updated_at,
 			   (6371 * acos(cos(radians($1)) * cos(radians(latitude)) * cos(radians(longitude) - radians($2)) + sin(radians($1)) * sin(radians(latitude)))) AS distance_km
 		FROM nodes
 		WHERE (6371 * acos(cos(radians($1)) * cos(radians(latitude)) * cos(radians(longitude) - radians($2)) + sin(radians($1)) * sin(radians(latitude)))) <= $3
@@ -331,6 +368,7 @@ func (r *NodeRepository) GetNearby(ctx context.Context, query *models.NearbyQuer
 			&node.UsedPorts,
 			&node.Model,
 			&node.Status,
+			&node.AreaID,
 			&node.CreatedAt,
 			&node.UpdatedAt,
 			&distanceKM,
@@ -359,6 +397,103 @@ func (r *NodeRepository) GetAllAsGeoJSON(ctx context.Context, filter *models.Nod
 	return features, nil
 }
 
+// ListAll retrieves every node, ordered by ID
+func (r *NodeRepository) ListAll(ctx context.Context) ([]models.Node, error) {
+	return listNodes(ctx, r.pool)
+}
+
+func listNodes(ctx context.Context, q querier) ([]models.Node, error) {
+	query := `
+		SELECT id, name, type, latitude, longitude, address, capacity_ports, used_ports, model, status, area_id, created_at, updated_at
+		FROM nodes
+		ORDER BY id ASC
+	`
+
+	rows, err := q.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	defer rows.Close()
+
+	var nodes []models.Node
+	for rows.Next() {
+		var node models.Node
+		err := rows.Scan(
+			&node.ID,
+			&node.Name,
+			&node.Type,
+			&node.Latitude,
+			&node.Longitude,
+			&node.Address,
+			&node.CapacityPorts,
+			&node.UsedPorts,
+			&node.Model,
+			&node.Status,
+			&node.AreaID,
+			&node.CreatedAt,
+			&node.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan node: %w", err)
+		}
+		nodes = append(nodes, node)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	return nodes, nil
+}
+
+// nearestNode returns the node closest to a point within maxMeters, or nil when there is none.
+// A bounding box narrows the candidates before the exact haversine distance is computed.
+func nearestNode(ctx context.Context, q querier, lat, lng, maxMeters float64) (*models.Node, float64, error) {
+	latDelta := maxMeters / 111320.0
+	lngDelta := latDelta / math.Max(math.Cos(lat*math.Pi/180), 0.01)
+
+	rows, err := q.Query(ctx, `
+		SELECT id, name, type, latitude, longitude, address, capacity_ports, used_ports, model, status, area_id, created_at, updated_at
+		FROM nodes
+		WHERE latitude BETWEEN $1 AND $2 AND longitude BETWEEN $3 AND $4
+	`, lat-latDelta, lat+latDelta, lng-lngDelta, lng+lngDelta)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find nearest node: %w", err)
+	}
+	defer rows.Close()
+
+	var nearest *models.Node
+	nearestDistance := maxMeters
+	for rows.Next() {
+		var node models.Node
+		err := rows.Scan(
+			&node.ID,
+			&node.Name,
+			&node.Type,
+			&node.Latitude,
+			&node.Longitude,
+			&node.Address,
+			&node.CapacityPorts,
+			&node.UsedPorts,
+			&node.Model,
+			&node.Status,
+			&node.AreaID,
+			&node.CreatedAt,
+			&node.UpdatedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan node: %w", err)
+		}
+		if d := geo.HaversineMeters(lat, lng, node.Latitude, node.Longitude); d <= nearestDistance {
+			nearest, nearestDistance = &node, d
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to find nearest node: %w", err)
+	}
+
+	return nearest, nearestDistance, nil
+}
+
 // Helper function to join strings
 func joinStrings(parts []string, sep string) string {
 	result := ""
@@ -0,0 +1,295 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"spectra-backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrOTDRTraceNotFound is returned when a trace ID is unknown
+var ErrOTDRTraceNotFound = errors.New("OTDR trace not found")
+
+// otdrTraceSelect selects traces with the cable and index of their core and their event count
+const otdrTraceSelect = `
+	SELECT t.id, t.core_id, t.from_node_id, t.file_name, t.sor_version, t.wavelength_nm, t.pulse_width_ns,
+		t.group_index, t.fiber_length_meter, t.total_loss_db, t.orl_db, t.acquired_at, t.supplier,
+		t.otdr_model, t.otdr_serial, t.cable_label, t.fiber_label, t.location_a, t.location_b, t.operator,
+		t.slack_pct, t.node_slack_meter, t.created_at, cc.cable_id, cc.core_index,
+		(SELECT COUNT(*) FROM otdr_events e WHERE e.trace_id = t.id)
+	FROM otdr_traces t
+	JOIN cable_cores cc ON cc.id = t.core_id
+`
+
+// OTDRRepository handles database operations for OTDR traces
+type OTDRRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewOTDRRepository creates a new OTDRRepository
+func NewOTDRRepository(pool *pgxpool.Pool) *OTDRRepository {
+	return &OTDRRepository{pool: pool}
+}
+
+// Create stores a trace, its events and the original file in one transaction
+func (r *OTDRRepository) Create(ctx context.Context, trace *models.OTDRTrace, raw []byte) (*models.OTDRTrace, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	if err := tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM cable_cores WHERE id = $1)", trace.CoreID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check core: %w", err)
+	}
+	if !exists {
+		return nil, ErrCoreNotFound
+	}
+
+	var id int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO otdr_traces (core_id, from_node_id, file_name, sor_version, wavelength_nm, pulse_width_ns,
+			group_index, fiber_length_meter, total_loss_db, orl_db, acquired_at, supplier, otdr_model,
+			otdr_serial, cable_label, fiber_label, location_a, location_b, operator, slack_pct,
+			node_slack_meter, raw_file)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
+		RETURNING id
+	`,
+		trace.CoreID, trace.FromNodeID, sorText(trace.FileName, 255), trace.SORVersion, trace.WavelengthNM,
+		trace.PulseWidthNS, trace.GroupIndex, trace.FiberLengthMeter, trace.TotalLossDB, trace.ORLDB, trace.AcquiredAt,
+		sorText(trace.Supplier, 100), sorText(trace.OTDRModel, 100), sorText(trace.OTDRSerial, 100),
+		sorText(trace.CableLabel, 100), sorText(trace.FiberLabel, 100), sorText(trace.LocationA, 255),
+		sorText(trace.LocationB, 255), sorText(trace.Operator, 100), trace.SlackPct, trace.NodeSlackMeter, raw,
+	).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTDR trace: %w", err)
+	}
+
+	// Instruments repeat or skip event numbers, so events are numbered 1..n in file order
+	for i, e := range trace.Events {
+		number := i + 1
+		code := *sorText(&e.Code, 10)
+		_, err := tx.Exec(ctx, `
+			INSERT INTO otdr_events (trace_id, event_number, type, code, distance_meter, loss_db, reflectance_db,
+				attenuation_db_per_km, comment)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`, id, number, e.Type, code, e.DistanceMeter, e.LossDB, e.ReflectanceDB, e.AttenuationDBPerKm, sorText(e.Comment, 0))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTDR event %d: %w", number, err)
+		}
+	}
+
+	created, err := getOTDRTrace(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return created, nil
+}
+
+// sorText fits text read from a SOR file into a column of at most max characters (0 for TEXT).
+// Instruments write whatever bytes they like, so invalid UTF-8 and NUL bytes are dropped first.
+func sorText(s *string, max int) *string {
+	if s == nil {
+		return nil
+	}
+	text := strings.ReplaceAll(strings.ToValidUTF8(*s, ""), "\x00", "")
+	if runes := []rune(text); max > 0 && len(runes) > max {
+		text = string(runes[:max])
+	}
+	return &text
+}
+
+// GetByID retrieves a trace with its events
+func (r *OTDRRepository) GetByID(ctx context.Context, id int64) (*models.OTDRTrace, error) {
+	return getOTDRTrace(ctx, r.pool, id)
+}
+
+// GetFile retrieves the original file of a trace
+func (r *OTDRRepository) GetFile(ctx context.Context, id int64) (string, []byte, error) {
+	var name *string
+	var raw []byte
+	err := r.pool.QueryRow(ctx, "SELECT file_name, raw_file FROM otdr_traces WHERE id = $1", id).Scan(&name, &raw)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil, ErrOTDRTraceNotFound
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get OTDR file: %w", err)
+	}
+	if name == nil || *name == "" {
+		return fmt.Sprintf("trace-%d.sor", id), raw, nil
+	}
+	return *name, raw, nil
+}
+
+// List retrieves traces without their events, newest first
+func (r *OTDRRepository) List(ctx context.Context, filter *models.OTDRTraceFilter) ([]models.OTDRTrace, int64, error) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.CoreID != nil {
+		where += fmt.Sprintf(" AND t.core_id = $%d", argIndex)
+		args = append(args, *filter.CoreID)
+		argIndex++
+	}
+	if filter.CableID != nil {
+		where += fmt.Sprintf(" AND cc.cable_id = $%d", argIndex)
+		args = append(args, *filter.CableID)
+		argIndex++
+	}
+	if filter.AreaID != nil {
+		where += " AND cc.cable_id IN " + areaCablesSQL(argIndex)
+		args = append(args, *filter.AreaID)
+		argIndex++
+	}
+
+	var total int64
+	countQuery := "SELECT COUNT(*) FROM otdr_traces t JOIN cable_cores cc ON cc.id = t.core_id" + where
+	if err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count OTDR traces: %w", err)
+	}
+
+	limit := 100
+	offset := 0
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+	if filter.Offset > 0 {
+		offset = filter.Offset
+	}
+	args = append(args, limit, offset)
+
+	traces, err := listOTDRTraces(ctx, r.pool,
+		fmt.Sprintf("%s ORDER BY t.created_at DESC, t.id DESC LIMIT $%d OFFSET $%d", where, argIndex, argIndex+1), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return traces, total, nil
+}
+
+// Delete removes a trace and its events
+func (r *OTDRRepository) Delete(ctx context.Context, id int64) error {
+	result, err := r.pool.Exec(ctx, "DELETE FROM otdr_traces WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete OTDR trace: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrOTDRTraceNotFound
+	}
+
+	return nil
+}
+
+// getOTDRTrace retrieves a trace with its events, or nil
+func getOTDRTrace(ctx context.Context, q querier, id int64) (*models.OTDRTrace, error) {
+	traces, err := listOTDRTraces(ctx, q, " WHERE t.id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(traces) == 0 {
+		return nil, nil
+	}
+	trace := &traces[0]
+
+	rows, err := q.Query(ctx, `
+		SELECT id, trace_id, event_number, type, code, distance_meter, loss_db, reflectance_db,
+			attenuation_db_per_km, comment
+		FROM otdr_events
+		WHERE trace_id = $1
+		ORDER BY distance_meter, event_number
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get OTDR events: %w", err)
+	}
+	defer rows.Close()
+
+	trace.Events = []models.OTDREvent{}
+	for rows.Next() {
+		var e models.OTDREvent
+		err := rows.Scan(
+			&e.ID,
+			&e.TraceID,
+			&e.EventNumber,
+			&e.Type,
+			&e.Code,
+			&e.DistanceMeter,
+			&e.LossDB,
+			&e.ReflectanceDB,
+			&e.AttenuationDBPerKm,
+			&e.Comment,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan OTDR event: %w", err)
+		}
+		trace.Events = append(trace.Events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get OTDR events: %w", err)
+	}
+
+	return trace, nil
+}
+
+// listOTDRTraces runs otdrTraceSelect with the given WHERE/ORDER clause
+func listOTDRTraces(ctx context.Context, q querier, clause string, args ...interface{}) ([]models.OTDRTrace, error) {
+	rows, err := q.Query(ctx, otdrTraceSelect+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list OTDR traces: %w", err)
+	}
+	defer rows.Close()
+
+	traces := []models.OTDRTrace{}
+	for rows.Next() {
+		var t models.OTDRTrace
+		err := rows.Scan(
+			&t.ID,
+			&t.CoreID,
+			&t.FromNodeID,
+			&t.FileName,
+			&t.SORVersion,
+			&t.WavelengthNM,
+			&t.PulseWidthNS,
+			&t.GroupIndex,
+			&t.FiberLengthMeter,
+			&t.TotalLossDB,
+			&t.ORLDB,
+			&t.AcquiredAt,
+			&t.Supplier,
+			&t.OTDRModel,
+			&t.OTDRSerial,
+			&t.CableLabel,
+			&t.FiberLabel,
+			&t.LocationA,
+			&t.LocationB,
+			&t.Operator,
+			&t.SlackPct,
+			&t.NodeSlackMeter,
+			&t.CreatedAt,
+			&t.CableID,
+			&t.CoreIndex,
+			&t.EventCount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan OTDR trace: %w", err)
+		}
+		traces = append(traces, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list OTDR traces: %w", err)
+	}
+
+	return traces, nil
+}
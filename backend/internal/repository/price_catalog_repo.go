@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"spectra-backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrPriceCatalogItemNotFound is returned when a catalog item ID is unknown
+	ErrPriceCatalogItemNotFound = errors.New("price catalog item not found")
+	// ErrInvalidPriceCatalogItem is returned when a catalog price is unusable
+	ErrInvalidPriceCatalogItem = errors.New("invalid price catalog item")
+	// ErrPriceCatalogItemExists is returned when the category and spec already have a price
+	ErrPriceCatalogItemExists = errors.New("price catalog item already exists")
+)
+
+// priceCatalogSelect selects catalog items
+const priceCatalogSelect = `
+	SELECT id, category, spec, description, unit, unit_price, currency, created_at, updated_at
+	FROM price_catalog
+`
+
+// PriceCatalogRepository handles database operations for the material price catalog
+type PriceCatalogRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPriceCatalogRepository creates a new PriceCatalogRepository
+func NewPriceCatalogRepository(pool *pgxpool.Pool) *PriceCatalogRepository {
+	return &PriceCatalogRepository{pool: pool}
+}
+
+// List retrieves the catalog, optionally for one category
+func (r *PriceCatalogRepository) List(ctx context.Context, category *models.BOMCategory) ([]models.PriceCatalogItem, error) {
+	if category != nil {
+		return listPriceCatalogItems(ctx, r.pool, " WHERE category = $1 ORDER BY spec", *category)
+	}
+	return listPriceCatalogItems(ctx, r.pool, " ORDER BY category, spec")
+}
+
+// Create adds a price for a category and spec
+func (r *PriceCatalogRepository) Create(ctx context.Context, req *models.CreatePriceCatalogItemRequest) (*models.PriceCatalogItem, error) {
+	if !req.Category.IsValid() {
+		return nil, fmt.Errorf("%w: unknown category %q", ErrInvalidPriceCatalogItem, req.Category)
+	}
+	if req.UnitPrice < 0 {
+		return nil, fmt.Errorf("%w: unit_price cannot be negative", ErrInvalidPriceCatalogItem)
+	}
+
+	spec := strings.TrimSpace(req.Spec)
+	unit := req.Unit
+	if unit == "" {
+		unit = "pcs"
+		if req.Category == models.BOMCategoryCable {
+			unit = "m"
+		}
+	}
+	currency := strings.ToUpper(req.Currency)
+	if currency == "" {
+		currency = models.DefaultCurrency
+	}
+
+	var exists bool
+	err := r.pool.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM price_catalog WHERE category = $1 AND spec = $2)",
+		req.Category, spec).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check price catalog: %w", err)
+	}
+	if exists {
+		return nil, fmt.Errorf("%w: %s %q", ErrPriceCatalogItemExists, req.Category, spec)
+	}
+
+	item, err := scanPriceCatalogItem(r.pool.QueryRow(ctx, `
+		INSERT INTO price_catalog (category, spec, description, unit, unit_price, currency)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, category, spec, description, unit, unit_price, currency, created_at, updated_at
+	`, req.Category, spec, req.Description, unit, req.UnitPrice, currency))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create price catalog item: %w", err)
+	}
+
+	return item, nil
+}
+
+// Update edits a catalog price
+func (r *PriceCatalogRepository) Update(ctx context.Context, id int64, req *models.UpdatePriceCatalogItemRequest) (*models.PriceCatalogItem, error) {
+	if req.UnitPrice != nil && *req.UnitPrice < 0 {
+		return nil, fmt.Errorf("%w: unit_price cannot be negative", ErrInvalidPriceCatalogItem)
+	}
+	var spec, currency *string
+	if req.Spec != nil {
+		trimmed := strings.TrimSpace(*req.Spec)
+		spec = &trimmed
+	}
+	if req.Currency != nil {
+		upper := strings.ToUpper(*req.Currency)
+		currency = &upper
+	}
+
+	if spec != nil {
+		var exists bool
+		err := r.pool.QueryRow(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM price_catalog o JOIN price_catalog p ON p.category = o.category
+				WHERE p.id = $1 AND o.id <> $1 AND o.spec = $2
+			)
+		`, id, *spec).Scan(&exists)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check price catalog: %w", err)
+		}
+		if exists {
+			return nil, fmt.Errorf("%w: spec %q", ErrPriceCatalogItemExists, *spec)
+		}
+	}
+
+	item, err := scanPriceCatalogItem(r.pool.QueryRow(ctx, `
+		UPDATE price_catalog SET spec = COALESCE($1, spec), description = COALESCE($2, description),
+			unit = COALESCE($3, unit), unit_price = COALESCE($4, unit_price), currency = COALESCE($5, currency)
+		WHERE id = $6
+		RETURNING id, category, spec, description, unit, unit_price, currency, created_at, updated_at
+	`, spec, req.Description, req.Unit, req.UnitPrice, currency, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrPriceCatalogItemNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update price catalog item: %w", err)
+	}
+
+	return item, nil
+}
+
+// Delete removes a catalog price
+func (r *PriceCatalogRepository) Delete(ctx context.Context, id int64) error {
+	tag, err := r.pool.Exec(ctx, "DELETE FROM price_catalog WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete price catalog item: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrPriceCatalogItemNotFound
+	}
+	return nil
+}
+
+// scanPriceCatalogItem scans a price_catalog row
+func scanPriceCatalogItem(row pgx.Row) (*models.PriceCatalogItem, error) {
+	item := &models.PriceCatalogItem{}
+	err := row.Scan(
+		&item.ID,
+		&item.Category,
+		&item.Spec,
+		&item.Description,
+		&item.Unit,
+		&item.UnitPrice,
+		&item.Currency,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// listPriceCatalogItems runs priceCatalogSelect with the given clause
+func listPriceCatalogItems(ctx context.Context, q querier, clause string, args ...interface{}) ([]models.PriceCatalogItem, error) {
+	rows, err := q.Query(ctx, priceCatalogSelect+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list price catalog: %w", err)
+	}
+	defer rows.Close()
+
+	items := []models.PriceCatalogItem{}
+	for rows.Next() {
+		item, err := scanPriceCatalogItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan price catalog item: %w", err)
+		}
+		items = append(items, *item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list price catalog: %w", err)
+	}
+
+	return items, nil
+}
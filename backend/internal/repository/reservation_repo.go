@@ -0,0 +1,478 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"spectra-backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrReservationNotFound is returned when a reservation ID is unknown
+	ErrReservationNotFound = errors.New("reservation not found")
+	// ErrReservationNotActive is returned when changing a reservation that was released or has expired
+	ErrReservationNotActive = errors.New("reservation is not active")
+	// ErrInvalidReservation is returned when a reservation request is unusable
+	ErrInvalidReservation = errors.New("invalid reservation")
+)
+
+// ReservationConflictError is returned when elements to be reserved are taken
+type ReservationConflictError struct {
+	Elements []models.ReservationElement
+}
+
+// Error implements the error interface
+func (e *ReservationConflictError) Error() string {
+	return fmt.Sprintf("%d elements are in use or already reserved", len(e.Elements))
+}
+
+// reservationSelect selects reservations with the cable and index of reserved cores
+const reservationSelect = `
+	SELECT r.id, r.element_type, r.element_id, r.location_node_id, r.owner, r.reason, r.work_order, r.expires_at, r.status,
+		r.released_at, r.created_at, r.updated_at, cc.cable_id, cc.core_index
+	FROM reservations r
+	LEFT JOIN cable_cores cc ON r.element_type = 'CORE' AND cc.id = r.element_id
+`
+
+// ReservationRepository handles database operations for core and port reservations
+type ReservationRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewReservationRepository creates a new ReservationRepository
+func NewReservationRepository(pool *pgxpool.Pool) *ReservationRepository {
+	return &ReservationRepository{pool: pool}
+}
+
+// Create reserves all requested elements in one transaction, or none of them.
+// Cores must be VACANT and become RESERVED; ports, given by node and number, must exist and be free of
+// connections and reservations.
+func (r *ReservationRepository) Create(ctx context.Context, req *models.CreateReservationRequest) ([]models.Reservation, error) {
+	now := time.Now()
+	expiresAt := req.Expiry(now)
+	if !expiresAt.After(now) {
+		return nil, fmt.Errorf("%w: expiry must be in the future", ErrInvalidReservation)
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var coreIDs []int64
+	var ports []models.ReservationElement
+	seen := map[models.ReservationElement]bool{}
+	for _, e := range req.Elements {
+		if seen[e] {
+			continue
+		}
+		seen[e] = true
+		switch e.Type {
+		case models.ConnectionTypeCore:
+			coreIDs = append(coreIDs, e.ID)
+		case models.ConnectionTypePort:
+			if e.LocationNodeID <= 0 {
+				return nil, fmt.Errorf("%w: port %d needs the location_node_id it sits on", ErrInvalidReservation, e.ID)
+			}
+			ports = append(ports, e)
+		default:
+			return nil, fmt.Errorf("%w: element type must be CORE or PORT", ErrInvalidReservation)
+		}
+	}
+
+	var conflicts []models.ReservationElement
+	if len(coreIDs) > 0 {
+		reserved, err := collectIDs(ctx, tx, `
+			UPDATE cable_cores SET status = $1
+			WHERE id = ANY($2) AND status = $3
+			RETURNING id
+		`, models.CoreStatusReserved, coreIDs, models.CoreStatusVacant)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reserve cores: %w", err)
+		}
+		for _, id := range coreIDs {
+			if !reserved[id] {
+				conflicts = append(conflicts, models.ReservationElement{Type: models.ConnectionTypeCore, ID: id})
+			}
+		}
+	}
+	if len(ports) > 0 {
+		taken, err := takenPorts(ctx, tx, ports)
+		if err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, taken...)
+	}
+	if len(conflicts) > 0 {
+		return nil, &ReservationConflictError{Elements: conflicts}
+	}
+
+	var ids []int64
+	for _, e := range req.Elements {
+		if !seen[e] {
+			continue
+		}
+		delete(seen, e)
+
+		var id int64
+		err := tx.QueryRow(ctx, `
+			INSERT INTO reservations (element_type, element_id, location_node_id, owner, reason, work_order, expires_at)
+			VALUES ($1, $2, NULLIF($3, 0), $4, $5, $6, $7)
+			RETURNING id
+		`, e.Type, e.ID, e.LocationNodeID, req.Owner, req.Reason, req.WorkOrder, expiresAt).Scan(&id)
+		if isUniqueViolation(err) {
+			// A concurrent request reserved the element after the checks above
+			return nil, &ReservationConflictError{Elements: []models.ReservationElement{e}}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create reservation: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	reservations, err := listReservations(ctx, tx, " WHERE r.id = ANY($1) ORDER BY r.id", ids)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return reservations, nil
+}
+
+// GetByID retrieves a reservation by ID
+func (r *ReservationRepository) GetByID(ctx context.Context, id int64) (*models.Reservation, error) {
+	reservations, err := listReservations(ctx, r.pool, " WHERE r.id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(reservations) == 0 {
+		return nil, nil
+	}
+	return &reservations[0], nil
+}
+
+// List retrieves reservations with filters and pagination, soonest expiry first
+func (r *ReservationRepository) List(ctx context.Context, filter *models.ReservationFilter) ([]models.Reservation, int64, error) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.Owner != nil {
+		where += fmt.Sprintf(" AND r.owner = $%d", argIndex)
+		args = append(args, *filter.Owner)
+		argIndex++
+	}
+	if filter.WorkOrder != nil {
+		where += fmt.Sprintf(" AND r.work_order = $%d", argIndex)
+		args = append(args, *filter.WorkOrder)
+		argIndex++
+	}
+	if filter.Status != nil {
+		where += fmt.Sprintf(" AND r.status = $%d", argIndex)
+		args = append(args, *filter.Status)
+		argIndex++
+	}
+	if filter.ElementType != nil {
+		where += fmt.Sprintf(" AND r.element_type = $%d", argIndex)
+		args = append(args, *filter.ElementType)
+		argIndex++
+	}
+	if filter.CableID != nil {
+		where += fmt.Sprintf(" AND cc.cable_id = $%d", argIndex)
+		args = append(args, *filter.CableID)
+		argIndex++
+	}
+	if filter.AreaID != nil {
+		where += " AND cc.cable_id IN " + areaCablesSQL(argIndex)
+		args = append(args, *filter.AreaID)
+		argIndex++
+	}
+	if filter.ExpiresBy != nil {
+		where += fmt.Sprintf(" AND r.expires_at <= $%d", argIndex)
+		args = append(args, *filter.ExpiresBy)
+		argIndex++
+	}
+
+	var total int64
+	countQuery := `
+		SELECT COUNT(*)
+		FROM reservations r
+		LEFT JOIN cable_cores cc ON r.element_type = 'CORE' AND cc.id = r.element_id
+	` + where
+	if err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count reservations: %w", err)
+	}
+
+	limit := 100
+	offset := 0
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+	if filter.Offset > 0 {
+		offset = filter.Offset
+	}
+	args = append(args, limit, offset)
+
+	reservations, err := listReservations(ctx, r.pool,
+		fmt.Sprintf("%s ORDER BY r.expires_at ASC, r.id ASC LIMIT $%d OFFSET $%d", where, argIndex, argIndex+1), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return reservations, total, nil
+}
+
+// Extend moves the expiry of an active reservation to expires_at, or later by duration_hours
+func (r *ReservationRepository) Extend(ctx context.Context, id int64, req *models.ExtendReservationRequest) (*models.Reservation, error) {
+	if req.ExpiresAt == nil && req.DurationHours == nil {
+		return nil, fmt.Errorf("%w: expires_at or duration_hours is required", ErrInvalidReservation)
+	}
+	if req.DurationHours != nil && *req.DurationHours <= 0 {
+		return nil, fmt.Errorf("%w: duration_hours must be positive", ErrInvalidReservation)
+	}
+
+	current, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, ErrReservationNotFound
+	}
+	if current.Status != models.ReservationStatusActive {
+		return nil, ErrReservationNotActive
+	}
+
+	expiresAt := current.ExpiresAt
+	if req.ExpiresAt != nil {
+		expiresAt = *req.ExpiresAt
+	} else {
+		expiresAt = expiresAt.Add(time.Duration(*req.DurationHours * float64(time.Hour)))
+	}
+	if !expiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: expiry must be in the future", ErrInvalidReservation)
+	}
+
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE reservations SET expires_at = $1
+		WHERE id = $2 AND status = $3
+	`, expiresAt, id, models.ReservationStatusActive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extend reservation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrReservationNotActive
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// Release ends an active reservation and frees its core
+func (r *ReservationRepository) Release(ctx context.Context, id int64) (*models.Reservation, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	released, err := endReservations(ctx, tx, models.ReservationStatusReleased, " AND id = $2", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(released) == 0 {
+		var status models.ReservationStatus
+		err := tx.QueryRow(ctx, "SELECT status FROM reservations WHERE id = $1", id).Scan(&status)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrReservationNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get reservation: %w", err)
+		}
+		return nil, ErrReservationNotActive
+	}
+
+	reservations, err := listReservations(ctx, tx, " WHERE r.id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &reservations[0], nil
+}
+
+// ReleaseExpired marks every active reservation past its expiry as EXPIRED and frees its core.
+// It returns how many reservations expired.
+func (r *ReservationRepository) ReleaseExpired(ctx context.Context) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	expired, err := endReservations(ctx, tx, models.ReservationStatusExpired, " AND expires_at <= NOW()")
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(expired), nil
+}
+
+// endReservations moves matching active reservations to a final status and returns reserved cores
+// to VACANT unless they have been put in service since
+func endReservations(ctx context.Context, q querier, status models.ReservationStatus, condition string, args ...interface{}) ([]int64, error) {
+	rows, err := q.Query(ctx, `
+		UPDATE reservations SET status = $1, released_at = NOW()
+		WHERE status = 'ACTIVE'`+condition+`
+		RETURNING id, element_type, element_id
+	`, append([]interface{}{status}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to end reservations: %w", err)
+	}
+	defer rows.Close()
+
+	var ids, coreIDs []int64
+	for rows.Next() {
+		var id, elementID int64
+		var elementType models.ConnectionType
+		if err := rows.Scan(&id, &elementType, &elementID); err != nil {
+			return nil, fmt.Errorf("failed to scan reservation: %w", err)
+		}
+		ids = append(ids, id)
+		if elementType == models.ConnectionTypeCore {
+			coreIDs = append(coreIDs, elementID)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to end reservations: %w", err)
+	}
+
+	if len(coreIDs) > 0 {
+		_, err := q.Exec(ctx, `
+			UPDATE cable_cores SET status = $1
+			WHERE id = ANY($2) AND status = $3
+		`, models.CoreStatusVacant, coreIDs, models.CoreStatusReserved)
+		if err != nil {
+			return nil, fmt.Errorf("failed to free reserved cores: %w", err)
+		}
+	}
+
+	return ids, nil
+}
+
+// takenPorts returns the ports that already have a connection at their node or an active reservation,
+// whichever tenant made it.
+// Ports of unknown nodes or beyond the node's capacity are refused.
+func takenPorts(ctx context.Context, q querier, ports []models.ReservationElement) ([]models.ReservationElement, error) {
+	nodeIDs := make([]int64, len(ports))
+	numbers := make([]int64, len(ports))
+	for i, p := range ports {
+		nodeIDs[i], numbers[i] = p.LocationNodeID, p.ID
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT p.node_id, p.port, n.capacity_ports,
+			p.port IN (SELECT ports_in_use(p.node_id))
+		FROM unnest($1::bigint[], $2::bigint[]) AS p(node_id, port)
+		LEFT JOIN nodes n ON n.id = p.node_id
+	`, nodeIDs, numbers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check ports: %w", err)
+	}
+	defer rows.Close()
+
+	var taken []models.ReservationElement
+	for rows.Next() {
+		var nodeID, port int64
+		var capacity *int
+		var inUse bool
+		if err := rows.Scan(&nodeID, &port, &capacity, &inUse); err != nil {
+			return nil, fmt.Errorf("failed to scan port: %w", err)
+		}
+		if capacity == nil {
+			return nil, fmt.Errorf("%w: node %d not found", ErrInvalidReservation, nodeID)
+		}
+		if port < 1 || port > int64(*capacity) {
+			return nil, fmt.Errorf("%w: port %d of node %d must be between 1 and %d", ErrInvalidReservation, port, nodeID, *capacity)
+		}
+		if inUse {
+			taken = append(taken, models.ReservationElement{Type: models.ConnectionTypePort, ID: port, LocationNodeID: nodeID})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to check ports: %w", err)
+	}
+	return taken, nil
+}
+
+// listReservations runs reservationSelect with the given WHERE/ORDER clause
+func listReservations(ctx context.Context, q querier, clause string, args ...interface{}) ([]models.Reservation, error) {
+	rows, err := q.Query(ctx, reservationSelect+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reservations: %w", err)
+	}
+	defer rows.Close()
+
+	reservations := []models.Reservation{}
+	for rows.Next() {
+		var res models.Reservation
+		err := rows.Scan(
+			&res.ID,
+			&res.ElementType,
+			&res.ElementID,
+			&res.LocationNodeID,
+			&res.Owner,
+			&res.Reason,
+			&res.WorkOrder,
+			&res.ExpiresAt,
+			&res.Status,
+			&res.ReleasedAt,
+			&res.CreatedAt,
+			&res.UpdatedAt,
+			&res.CableID,
+			&res.CoreIndex,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reservation: %w", err)
+		}
+		reservations = append(reservations, res)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list reservations: %w", err)
+	}
+
+	return reservations, nil
+}
+
+// collectIDs runs a query returning a single id column
+func collectIDs(ctx context.Context, q querier, query string, args ...interface{}) (map[int64]bool, error) {
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := map[int64]bool{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
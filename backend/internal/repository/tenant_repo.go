@@ -0,0 +1,374 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"spectra-backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrTenantNotFound is returned when a tenant ID or code is unknown
+	ErrTenantNotFound = errors.New("tenant not found")
+	// ErrInvalidTenant is returned when a tenant request is missing or has unusable fields
+	ErrInvalidTenant = errors.New("invalid tenant")
+	// ErrTenantExists is returned when another tenant already has the code
+	ErrTenantExists = errors.New("tenant code already in use")
+	// ErrTenantInUse is returned when deleting a tenant that still owns records
+	ErrTenantInUse = errors.New("tenant still owns records")
+)
+
+// tenantSelect selects tenants
+const tenantSelect = `
+	SELECT id, code, name, contact_email, active, description, created_at, updated_at
+	FROM tenants
+`
+
+// TenantRepository handles database operations for tenants. Its methods act for the operator and
+// expect a context without a tenant; tenant-scoped contexts would not see other tenants' records.
+type TenantRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewTenantRepository creates a new TenantRepository
+func NewTenantRepository(pool *pgxpool.Pool) *TenantRepository {
+	return &TenantRepository{pool: pool}
+}
+
+// Create adds a tenant
+func (r *TenantRepository) Create(ctx context.Context, req *models.CreateTenantRequest) (*models.Tenant, error) {
+	req.Code = strings.TrimSpace(req.Code)
+	if req.Code == "" || strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("%w: code and name are required", ErrInvalidTenant)
+	}
+	if _, err := strconv.ParseInt(req.Code, 10, 64); err == nil {
+		return nil, fmt.Errorf("%w: code cannot be a number, it would be mistaken for a tenant ID", ErrInvalidTenant)
+	}
+
+	existing, err := r.GetByCode(ctx, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%w: %s", ErrTenantExists, req.Code)
+	}
+
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	tenant, err := scanTenant(r.pool.QueryRow(ctx, `
+		INSERT INTO tenants (code, name, contact_email, active, description)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, code, name, contact_email, active, description, created_at, updated_at
+	`, req.Code, req.Name, req.ContactEmail, active, req.Description))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tenant: %w", err)
+	}
+
+	return tenant, nil
+}
+
+// GetByID retrieves a tenant with the number of records it owns
+func (r *TenantRepository) GetByID(ctx context.Context, id int64) (*models.Tenant, error) {
+	tenant, err := scanTenant(r.pool.QueryRow(ctx, tenantSelect+" WHERE id = $1", id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	if tenant.Owned, err = ownedRecords(ctx, r.pool, id); err != nil {
+		return nil, err
+	}
+
+	return tenant, nil
+}
+
+// GetByCode retrieves a tenant by its code
+func (r *TenantRepository) GetByCode(ctx context.Context, code string) (*models.Tenant, error) {
+	tenant, err := scanTenant(r.pool.QueryRow(ctx, tenantSelect+" WHERE code = $1", code))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	return tenant, nil
+}
+
+// Resolve finds the tenant an X-Tenant-ID header refers to, by ID or by code
+func (r *TenantRepository) Resolve(ctx context.Context, ref string) (*models.Tenant, error) {
+	ref = strings.TrimSpace(ref)
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		tenant, err := scanTenant(r.pool.QueryRow(ctx, tenantSelect+" WHERE id = $1", id))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tenant: %w", err)
+		}
+		return tenant, nil
+	}
+	return r.GetByCode(ctx, ref)
+}
+
+// List retrieves tenants ordered by code
+func (r *TenantRepository) List(ctx context.Context, filter *models.TenantFilter) ([]models.Tenant, int64, error) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.Active != nil {
+		where += fmt.Sprintf(" AND active = $%d", argIndex)
+		args = append(args, *filter.Active)
+		argIndex++
+	}
+	if filter.Search != nil {
+		where += fmt.Sprintf(" AND (code ILIKE $%d OR name ILIKE $%d)", argIndex, argIndex)
+		args = append(args, "%"+*filter.Search+"%")
+		argIndex++
+	}
+
+	var total int64
+	if err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM tenants"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count tenants: %w", err)
+	}
+
+	limit := 100
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+	offset := 0
+	if filter.Offset > 0 {
+		offset = filter.Offset
+	}
+	args = append(args, limit, offset)
+
+	rows, err := r.pool.Query(ctx, fmt.Sprintf("%s%s ORDER BY code LIMIT $%d OFFSET $%d", tenantSelect, where, argIndex, argIndex+1), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	defer rows.Close()
+
+	tenants := []models.Tenant{}
+	for rows.Next() {
+		tenant, err := scanTenant(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan tenant: %w", err)
+		}
+		tenants = append(tenants, *tenant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	return tenants, total, nil
+}
+
+// Update edits a tenant
+func (r *TenantRepository) Update(ctx context.Context, id int64, req *models.UpdateTenantRequest) (*models.Tenant, error) {
+	if req.Name != nil && strings.TrimSpace(*req.Name) == "" {
+		return nil, fmt.Errorf("%w: name cannot be empty", ErrInvalidTenant)
+	}
+
+	tenant, err := scanTenant(r.pool.QueryRow(ctx, `
+		UPDATE tenants SET name = COALESCE($1, name), contact_email = COALESCE($2, contact_email),
+			active = COALESCE($3, active), description = COALESCE($4, description)
+		WHERE id = $5
+		RETURNING id, code, name, contact_email, active, description, created_at, updated_at
+	`, req.Name, req.ContactEmail, req.Active, req.Description, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrTenantNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update tenant: %w", err)
+	}
+
+	return tenant, nil
+}
+
+// Delete removes a tenant that no longer owns any records
+func (r *TenantRepository) Delete(ctx context.Context, id int64) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, "SELECT id FROM tenants WHERE id = $1 FOR UPDATE", id).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrTenantNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	owned, err := ownedRecords(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if len(owned) > 0 {
+		var parts []string
+		for _, t := range sortedElementTypes(owned) {
+			parts = append(parts, fmt.Sprintf("%d %s", owned[t], strings.ToLower(string(t))))
+		}
+		return fmt.Errorf("%w: %s", ErrTenantInUse, strings.Join(parts, ", "))
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM tenants WHERE id = $1", id); err != nil {
+		return fmt.Errorf("failed to delete tenant: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit tenant deletion: %w", err)
+	}
+	return nil
+}
+
+// Assign hands records to a tenant, or back to the operator when no tenant is given
+func (r *TenantRepository) Assign(ctx context.Context, req *models.TenantAssignRequest) (*models.TenantAssignment, error) {
+	table, ok := models.TenantElementTables[req.ElementType]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown element_type %q", ErrInvalidTenant, req.ElementType)
+	}
+	if len(req.ElementIDs) == 0 {
+		return nil, fmt.Errorf("%w: element_ids is required", ErrInvalidTenant)
+	}
+	if req.IncludeCores && req.ElementType != models.TenantElementCable {
+		return nil, fmt.Errorf("%w: include_cores only applies to cables", ErrInvalidTenant)
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if req.TenantID != nil {
+		var exists bool
+		if err := tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM tenants WHERE id = $1)", *req.TenantID).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("failed to get tenant: %w", err)
+		}
+		if !exists {
+			return nil, ErrTenantNotFound
+		}
+	}
+
+	rows, err := tx.Query(ctx, "UPDATE "+table+" SET tenant_id = $1 WHERE id = ANY($2) RETURNING id", req.TenantID, req.ElementIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign %s: %w", table, err)
+	}
+	assigned := map[int64]bool{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to assign %s: %w", table, err)
+		}
+		assigned[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to assign %s: %w", table, err)
+	}
+
+	result := &models.TenantAssignment{
+		TenantID:    req.TenantID,
+		ElementType: req.ElementType,
+		Assigned:    len(assigned),
+		Missing:     []int64{},
+	}
+	for _, id := range req.ElementIDs {
+		if !assigned[id] {
+			result.Missing = append(result.Missing, id)
+		}
+	}
+
+	if req.IncludeCores {
+		tag, err := tx.Exec(ctx, "UPDATE cable_cores SET tenant_id = $1 WHERE cable_id = ANY($2)", req.TenantID, req.ElementIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to assign cable cores: %w", err)
+		}
+		result.Cores = int(tag.RowsAffected())
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit tenant assignment: %w", err)
+	}
+
+	return result, nil
+}
+
+// ownedRecords counts the records of each type a tenant owns, leaving out types it owns none of
+func ownedRecords(ctx context.Context, q querier, tenantID int64) (map[models.TenantElementType]int, error) {
+	all := map[models.TenantElementType]int{}
+	for t := range models.TenantElementTables {
+		all[t] = 0
+	}
+
+	var parts []string
+	for _, t := range sortedElementTypes(all) {
+		parts = append(parts, fmt.Sprintf("SELECT '%s', COUNT(*) FROM %s WHERE tenant_id = $1", t, models.TenantElementTables[t]))
+	}
+
+	rows, err := q.Query(ctx, strings.Join(parts, " UNION ALL "), tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tenant records: %w", err)
+	}
+	defer rows.Close()
+
+	owned := map[models.TenantElementType]int{}
+	for rows.Next() {
+		var t models.TenantElementType
+		var count int
+		if err := rows.Scan(&t, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant records: %w", err)
+		}
+		if count > 0 {
+			owned[t] = count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count tenant records: %w", err)
+	}
+
+	return owned, nil
+}
+
+// sortedElementTypes returns the element types of a count map in order
+func sortedElementTypes(counts map[models.TenantElementType]int) []models.TenantElementType {
+	types := make([]models.TenantElementType, 0, len(counts))
+	for t := range counts {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// scanTenant scans a tenants row
+func scanTenant(row pgx.Row) (*models.Tenant, error) {
+	tenant := &models.Tenant{}
+	err := row.Scan(
+		&tenant.ID,
+		&tenant.Code,
+		&tenant.Name,
+		&tenant.ContactEmail,
+		&tenant.Active,
+		&tenant.Description,
+		&tenant.CreatedAt,
+		&tenant.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return tenant, nil
+}
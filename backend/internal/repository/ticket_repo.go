@@ -0,0 +1,646 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"spectra-backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrTicketNotFound is returned when a ticket ID is unknown
+	ErrTicketNotFound = errors.New("ticket not found")
+	// ErrTicketLinkNotFound is returned when a link does not belong to the ticket
+	ErrTicketLinkNotFound = errors.New("ticket link not found")
+	// ErrInvalidTicket is returned when a ticket request is unusable
+	ErrInvalidTicket = errors.New("invalid ticket")
+	// ErrTicketTransition is returned when the state machine does not allow a status change
+	ErrTicketTransition = errors.New("status change not allowed")
+)
+
+// ticketSelect selects tickets; SLA fields are evaluated after scanning
+const ticketSelect = `
+	SELECT t.id, t.title, t.description, t.category, t.priority, t.status, t.assigned_team, t.assignee,
+		t.reported_by, t.resolution, t.response_due_at, t.resolution_due_at, t.responded_at, t.resolved_at,
+		t.closed_at, t.created_at, t.updated_at
+	FROM tickets t
+`
+
+// ticketQueueOrder puts the most urgent tickets first, then the closest resolution deadline
+const ticketQueueOrder = `
+	ORDER BY CASE t.priority WHEN 'CRITICAL' THEN 0 WHEN 'HIGH' THEN 1 WHEN 'MEDIUM' THEN 2 ELSE 3 END,
+		t.resolution_due_at ASC, t.id ASC
+`
+
+// elementNameQueries look up the names of nodes, cables and customers by element type
+var elementNameQueries = map[string]string{
+	"NODE":     "SELECT id, name FROM nodes WHERE id = ANY($1)",
+	"CABLE":    "SELECT id, name FROM cables WHERE id = ANY($1)",
+	"CUSTOMER": "SELECT id, name FROM customers WHERE id = ANY($1)",
+}
+
+// TicketRepository handles database operations for trouble tickets
+type TicketRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewTicketRepository creates a new TicketRepository
+func NewTicketRepository(pool *pgxpool.Pool) *TicketRepository {
+	return &TicketRepository{pool: pool}
+}
+
+// Create opens a ticket with its links. SLA deadlines follow from the priority.
+func (r *TicketRepository) Create(ctx context.Context, req *models.CreateTicketRequest) (*models.Ticket, error) {
+	priority := req.Priority
+	if priority == "" {
+		priority = models.TicketPriorityMedium
+	}
+	if !priority.IsValid() {
+		return nil, fmt.Errorf("%w: unknown priority %q", ErrInvalidTicket, priority)
+	}
+	now := time.Now()
+	target := models.TicketSLATargets[priority]
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	status := models.TicketStatusOpen
+	var respondedAt *time.Time
+	if req.AssignedTeam != nil || req.Assignee != nil {
+		status = models.TicketStatusAssigned
+		respondedAt = &now
+	}
+
+	var id int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO tickets (title, description, category, priority, status, assigned_team, assignee, reported_by,
+			response_due_at, resolution_due_at, responded_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id
+	`, req.Title, req.Description, req.Category, priority, status, req.AssignedTeam, req.Assignee, req.ReportedBy,
+		now.Add(target.Response), now.Add(target.Resolution), respondedAt, now).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ticket: %w", err)
+	}
+
+	if err := addTicketHistory(ctx, tx, id, nil, models.TicketStatusOpen, req.ReportedBy, nil); err != nil {
+		return nil, err
+	}
+	if status != models.TicketStatusOpen {
+		from := models.TicketStatusOpen
+		if err := addTicketHistory(ctx, tx, id, &from, status, req.ReportedBy, nil); err != nil {
+			return nil, err
+		}
+	}
+	if err := addTicketLinks(ctx, tx, id, req.Links); err != nil {
+		return nil, err
+	}
+
+	ticket, err := getTicket(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return ticket, nil
+}
+
+// GetByID retrieves a ticket with its links, comments and status history
+func (r *TicketRepository) GetByID(ctx context.Context, id int64) (*models.Ticket, error) {
+	return getTicket(ctx, r.pool, id)
+}
+
+// List retrieves tickets for the NOC queue, most urgent first
+func (r *TicketRepository) List(ctx context.Context, filter *models.TicketFilter) ([]models.Ticket, int64, error) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	argIndex := 1
+
+	if len(filter.Statuses) > 0 {
+		where += fmt.Sprintf(" AND t.status = ANY($%d)", argIndex)
+		args = append(args, filter.Statuses)
+		argIndex++
+	}
+	if filter.Active {
+		where += " AND t.status NOT IN ('RESOLVED', 'CLOSED')"
+	}
+	if filter.Priority != nil {
+		where += fmt.Sprintf(" AND t.priority = $%d", argIndex)
+		args = append(args, *filter.Priority)
+		argIndex++
+	}
+	if filter.AssignedTeam != nil {
+		where += fmt.Sprintf(" AND t.assigned_team = $%d", argIndex)
+		args = append(args, *filter.AssignedTeam)
+		argIndex++
+	}
+	if filter.Assignee != nil {
+		where += fmt.Sprintf(" AND t.assignee = $%d", argIndex)
+		args = append(args, *filter.Assignee)
+		argIndex++
+	}
+	if filter.Category != nil {
+		where += fmt.Sprintf(" AND t.category = $%d", argIndex)
+		args = append(args, *filter.Category)
+		argIndex++
+	}
+	if filter.Link != nil {
+		where += fmt.Sprintf(` AND EXISTS (
+			SELECT 1 FROM ticket_links l WHERE l.ticket_id = t.id AND l.element_type = $%d AND l.element_id = $%d
+		)`, argIndex, argIndex+1)
+		args = append(args, filter.Link.ElementType, filter.Link.ElementID)
+		argIndex += 2
+	}
+	if filter.AreaID != nil {
+		where += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM ticket_links l WHERE l.ticket_id = t.id AND %s)", areaElementSQL("l", argIndex))
+		args = append(args, *filter.AreaID)
+		argIndex++
+	}
+	if filter.SLABreached {
+		where += ` AND (COALESCE(t.responded_at, NOW()) > t.response_due_at
+			OR COALESCE(t.resolved_at, t.closed_at, NOW()) > t.resolution_due_at)`
+	}
+	if filter.Search != nil {
+		where += fmt.Sprintf(" AND t.title ILIKE $%d", argIndex)
+		args = append(args, "%"+*filter.Search+"%")
+		argIndex++
+	}
+
+	var total int64
+	if err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM tickets t"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count tickets: %w", err)
+	}
+
+	limit := 100
+	offset := 0
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+	if filter.Offset > 0 {
+		offset = filter.Offset
+	}
+	args = append(args, limit, offset)
+
+	tickets, err := listTickets(ctx, r.pool,
+		fmt.Sprintf("%s %s LIMIT $%d OFFSET $%d", where, ticketQueueOrder, argIndex, argIndex+1), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return tickets, total, nil
+}
+
+// Update edits a ticket. A new priority moves both SLA deadlines relative to when the ticket was opened.
+func (r *TicketRepository) Update(ctx context.Context, id int64, req *models.UpdateTicketRequest) (*models.Ticket, error) {
+	setParts := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	if req.Title != nil {
+		setParts = append(setParts, fmt.Sprintf("title = $%d", argIndex))
+		args = append(args, *req.Title)
+		argIndex++
+	}
+	if req.Description != nil {
+		setParts = append(setParts, fmt.Sprintf("description = $%d", argIndex))
+		args = append(args, *req.Description)
+		argIndex++
+	}
+	if req.Category != nil {
+		setParts = append(setParts, fmt.Sprintf("category = $%d", argIndex))
+		args = append(args, *req.Category)
+		argIndex++
+	}
+	if req.Priority != nil {
+		if !req.Priority.IsValid() {
+			return nil, fmt.Errorf("%w: unknown priority %q", ErrInvalidTicket, *req.Priority)
+		}
+		target := models.TicketSLATargets[*req.Priority]
+		setParts = append(setParts,
+			fmt.Sprintf("priority = $%d", argIndex),
+			fmt.Sprintf("response_due_at = created_at + $%d * INTERVAL '1 second'", argIndex+1),
+			fmt.Sprintf("resolution_due_at = created_at + $%d * INTERVAL '1 second'", argIndex+2),
+		)
+		args = append(args, *req.Priority, target.Response.Seconds(), target.Resolution.Seconds())
+		argIndex += 3
+	}
+
+	if len(setParts) > 0 {
+		args = append(args, id)
+		query := fmt.Sprintf("UPDATE tickets SET %s WHERE id = $%d", joinStrings(setParts, ", "), argIndex)
+		tag, err := r.pool.Exec(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update ticket: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return nil, ErrTicketNotFound
+		}
+	}
+
+	ticket, err := r.GetByID(ctx, id)
+	if err == nil && ticket == nil {
+		return nil, ErrTicketNotFound
+	}
+	return ticket, err
+}
+
+// Assign hands a ticket to a team or technician. An OPEN ticket becomes ASSIGNED.
+func (r *TicketRepository) Assign(ctx context.Context, id int64, req *models.AssignTicketRequest) (*models.Ticket, error) {
+	if req.AssignedTeam == nil && req.Assignee == nil {
+		return nil, fmt.Errorf("%w: assigned_team or assignee is required", ErrInvalidTicket)
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	status, err := lockTicketStatus(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !status.IsActive() {
+		return nil, fmt.Errorf("%w: a %s ticket cannot be reassigned", ErrTicketTransition, status)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE tickets SET assigned_team = COALESCE($1, assigned_team), assignee = COALESCE($2, assignee)
+		WHERE id = $3
+	`, req.AssignedTeam, req.Assignee, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign ticket: %w", err)
+	}
+	if status == models.TicketStatusOpen {
+		if err := moveTicket(ctx, tx, id, status, models.TicketStatusAssigned, req.ChangedBy, nil, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	ticket, err := getTicket(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return ticket, nil
+}
+
+// ChangeStatus moves a ticket through the state machine and records the change
+func (r *TicketRepository) ChangeStatus(ctx context.Context, id int64, req *models.TicketStatusRequest) (*models.Ticket, error) {
+	if !req.Status.IsValid() {
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidTicket, req.Status)
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	status, err := lockTicketStatus(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !status.CanMoveTo(req.Status) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrTicketTransition, status, req.Status)
+	}
+
+	switch req.Status {
+	case models.TicketStatusAssigned:
+		var assigned bool
+		err := tx.QueryRow(ctx, "SELECT assigned_team IS NOT NULL OR assignee IS NOT NULL FROM tickets WHERE id = $1", id).Scan(&assigned)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get ticket: %w", err)
+		}
+		if !assigned {
+			return nil, fmt.Errorf("%w: assign a team or technician first", ErrInvalidTicket)
+		}
+	case models.TicketStatusResolved:
+		if req.Resolution == nil || *req.Resolution == "" {
+			return nil, fmt.Errorf("%w: resolution is required", ErrInvalidTicket)
+		}
+	}
+
+	if err := moveTicket(ctx, tx, id, status, req.Status, req.ChangedBy, req.Note, req.Resolution); err != nil {
+		return nil, err
+	}
+
+	ticket, err := getTicket(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return ticket, nil
+}
+
+// AddComment adds a comment to a ticket
+func (r *TicketRepository) AddComment(ctx context.Context, id int64, req *models.CreateTicketCommentRequest) (*models.TicketComment, error) {
+	comment := &models.TicketComment{}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO ticket_comments (ticket_id, author, body)
+		SELECT id, $2, $3 FROM tickets WHERE id = $1
+		RETURNING id, ticket_id, author, body, created_at
+	`, id, req.Author, req.Body).Scan(&comment.ID, &comment.TicketID, &comment.Author, &comment.Body, &comment.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrTicketNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to add comment: %w", err)
+	}
+	return comment, nil
+}
+
+// AddLinks links a ticket to more elements; existing links are kept
+func (r *TicketRepository) AddLinks(ctx context.Context, id int64, links []models.TicketLinkRequest) (*models.Ticket, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := lockTicketStatus(ctx, tx, id); err != nil {
+		return nil, err
+	}
+	if err := addTicketLinks(ctx, tx, id, links); err != nil {
+		return nil, err
+	}
+
+	ticket, err := getTicket(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return ticket, nil
+}
+
+// DeleteLink removes a link from a ticket
+func (r *TicketRepository) DeleteLink(ctx context.Context, ticketID, linkID int64) error {
+	result, err := r.pool.Exec(ctx, "DELETE FROM ticket_links WHERE id = $1 AND ticket_id = $2", linkID, ticketID)
+	if err != nil {
+		return fmt.Errorf("failed to delete ticket link: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrTicketLinkNotFound
+	}
+
+	return nil
+}
+
+// lockTicketStatus locks a ticket row for a status change and returns its status
+func lockTicketStatus(ctx context.Context, q querier, id int64) (models.TicketStatus, error) {
+	var status models.TicketStatus
+	err := q.QueryRow(ctx, "SELECT status FROM tickets WHERE id = $1 FOR UPDATE", id).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrTicketNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get ticket: %w", err)
+	}
+	return status, nil
+}
+
+// moveTicket sets a new status with its timestamps and records it in the history.
+// Leaving OPEN for the first time counts as the response; reopening clears the resolution time.
+func moveTicket(ctx context.Context, q querier, id int64, from, to models.TicketStatus, changedBy, note, resolution *string) error {
+	_, err := q.Exec(ctx, `
+		UPDATE tickets SET
+			status = $1,
+			responded_at = CASE WHEN $1 <> 'OPEN' THEN COALESCE(responded_at, NOW()) ELSE responded_at END,
+			resolved_at = CASE WHEN $1 = 'RESOLVED' THEN NOW() WHEN $1 = 'CLOSED' THEN resolved_at ELSE NULL END,
+			closed_at = CASE WHEN $1 = 'CLOSED' THEN NOW() ELSE NULL END,
+			resolution = COALESCE($2, resolution)
+		WHERE id = $3
+	`, to, resolution, id)
+	if err != nil {
+		return fmt.Errorf("failed to change ticket status: %w", err)
+	}
+	return addTicketHistory(ctx, q, id, &from, to, changedBy, note)
+}
+
+func addTicketHistory(ctx context.Context, q querier, id int64, from *models.TicketStatus, to models.TicketStatus, changedBy, note *string) error {
+	_, err := q.Exec(ctx, `
+		INSERT INTO ticket_status_history (ticket_id, from_status, to_status, changed_by, note)
+		VALUES ($1, $2, $3, $4, $5)
+	`, id, from, to, changedBy, note)
+	if err != nil {
+		return fmt.Errorf("failed to record ticket status: %w", err)
+	}
+	return nil
+}
+
+// addTicketLinks checks that linked nodes, cables and customers exist and links them
+func addTicketLinks(ctx context.Context, q querier, id int64, links []models.TicketLinkRequest) error {
+	idsByType := map[models.TicketLinkType][]int64{}
+	for _, link := range links {
+		if !link.ElementType.IsValid() {
+			return fmt.Errorf("%w: unknown link type %q", ErrInvalidTicket, link.ElementType)
+		}
+		idsByType[link.ElementType] = append(idsByType[link.ElementType], link.ElementID)
+	}
+	for linkType, ids := range idsByType {
+		query, ok := elementNameQueries[string(linkType)]
+		if !ok {
+			continue
+		}
+		names, err := collectNames(ctx, q, query, ids)
+		if err != nil {
+			return fmt.Errorf("failed to check ticket links: %w", err)
+		}
+		for _, elementID := range ids {
+			if _, ok := names[elementID]; !ok {
+				return fmt.Errorf("%w: %s %d does not exist", ErrInvalidTicket, linkType, elementID)
+			}
+		}
+	}
+
+	for _, link := range links {
+		_, err := q.Exec(ctx, `
+			INSERT INTO ticket_links (ticket_id, element_type, element_id)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (ticket_id, element_type, element_id) DO NOTHING
+		`, id, link.ElementType, link.ElementID)
+		if err != nil {
+			return fmt.Errorf("failed to link ticket: %w", err)
+		}
+	}
+	return nil
+}
+
+// collectNames runs a query returning (id, name) rows
+func collectNames(ctx context.Context, q querier, query string, args ...interface{}) (map[int64]*string, error) {
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := map[int64]*string{}
+	for rows.Next() {
+		var id int64
+		var name *string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		names[id] = name
+	}
+	return names, rows.Err()
+}
+
+// getTicket retrieves a ticket with its links, comments and history, or nil
+func getTicket(ctx context.Context, q querier, id int64) (*models.Ticket, error) {
+	tickets, err := listTickets(ctx, q, " WHERE t.id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(tickets) == 0 {
+		return nil, nil
+	}
+	ticket := &tickets[0]
+
+	if ticket.Links, err = listTicketLinks(ctx, q, id); err != nil {
+		return nil, err
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT id, ticket_id, author, body, created_at
+		FROM ticket_comments WHERE ticket_id = $1 ORDER BY created_at, id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ticket comments: %w", err)
+	}
+	ticket.Comments = []models.TicketComment{}
+	for rows.Next() {
+		var c models.TicketComment
+		if err := rows.Scan(&c.ID, &c.TicketID, &c.Author, &c.Body, &c.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan ticket comment: %w", err)
+		}
+		ticket.Comments = append(ticket.Comments, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get ticket comments: %w", err)
+	}
+
+	rows, err = q.Query(ctx, `
+		SELECT id, ticket_id, from_status, to_status, changed_by, note, created_at
+		FROM ticket_status_history WHERE ticket_id = $1 ORDER BY created_at, id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ticket history: %w", err)
+	}
+	defer rows.Close()
+	ticket.History = []models.TicketStatusHistory{}
+	for rows.Next() {
+		var h models.TicketStatusHistory
+		if err := rows.Scan(&h.ID, &h.TicketID, &h.FromStatus, &h.ToStatus, &h.ChangedBy, &h.Note, &h.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan ticket history: %w", err)
+		}
+		ticket.History = append(ticket.History, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get ticket history: %w", err)
+	}
+
+	return ticket, nil
+}
+
+// listTicketLinks retrieves a ticket's links with the names of linked nodes, cables and customers
+func listTicketLinks(ctx context.Context, q querier, id int64) ([]models.TicketLink, error) {
+	rows, err := q.Query(ctx, `
+		SELECT l.id, l.ticket_id, l.element_type, l.element_id, l.created_at,
+			CASE l.element_type
+				WHEN 'NODE' THEN (SELECT name FROM nodes WHERE id = l.element_id)
+				WHEN 'CABLE' THEN (SELECT name FROM cables WHERE id = l.element_id)
+				WHEN 'CUSTOMER' THEN (SELECT name FROM customers WHERE id = l.element_id)
+			END
+		FROM ticket_links l
+		WHERE l.ticket_id = $1
+		ORDER BY l.id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ticket links: %w", err)
+	}
+	defer rows.Close()
+
+	links := []models.TicketLink{}
+	for rows.Next() {
+		var l models.TicketLink
+		if err := rows.Scan(&l.ID, &l.TicketID, &l.ElementType, &l.ElementID, &l.CreatedAt, &l.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan ticket link: %w", err)
+		}
+		links = append(links, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get ticket links: %w", err)
+	}
+	return links, nil
+}
+
+// listTickets runs ticketSelect with the given WHERE/ORDER clause and evaluates SLA timers
+func listTickets(ctx context.Context, q querier, clause string, args ...interface{}) ([]models.Ticket, error) {
+	rows, err := q.Query(ctx, ticketSelect+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tickets: %w", err)
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	tickets := []models.Ticket{}
+	for rows.Next() {
+		var t models.Ticket
+		err := rows.Scan(
+			&t.ID,
+			&t.Title,
+			&t.Description,
+			&t.Category,
+			&t.Priority,
+			&t.Status,
+			&t.AssignedTeam,
+			&t.Assignee,
+			&t.ReportedBy,
+			&t.Resolution,
+			&t.ResponseDueAt,
+			&t.ResolutionDueAt,
+			&t.RespondedAt,
+			&t.ResolvedAt,
+			&t.ClosedAt,
+			&t.CreatedAt,
+			&t.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ticket: %w", err)
+		}
+		t.EvaluateSLA(now)
+		tickets = append(tickets, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tickets: %w", err)
+	}
+
+	return tickets, nil
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"spectra-backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TopologyRepository loads the plant tables the topology graph is built from
+type TopologyRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewTopologyRepository creates a new TopologyRepository
+func NewTopologyRepository(pool *pgxpool.Pool) *TopologyRepository {
+	return &TopologyRepository{pool: pool}
+}
+
+// Snapshot reads nodes, cables, cores, spans, breakouts, connections, customers and active
+// maintenance windows in one read-only repeatable-read transaction, so the result is consistent across tables
+func (r *TopologyRepository) Snapshot(ctx context.Context) (*models.NetworkSnapshot, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	snapshot := &models.NetworkSnapshot{}
+	if snapshot.Nodes, err = listNodes(ctx, tx); err != nil {
+		return nil, err
+	}
+	if snapshot.Cables, err = listCablesWithPaths(ctx, tx, nil); err != nil {
+		return nil, err
+	}
+	if snapshot.Cores, err = listAllCores(ctx, tx); err != nil {
+		return nil, err
+	}
+	if snapshot.Spans, err = listAllSpans(ctx, tx); err != nil {
+		return nil, err
+	}
+	if snapshot.Breakouts, err = listAllBreakouts(ctx, tx); err != nil {
+		return nil, err
+	}
+	if snapshot.Connections, err = listAllConnections(ctx, tx); err != nil {
+		return nil, err
+	}
+	if snapshot.Customers, err = listCustomers(ctx, tx); err != nil {
+		return nil, err
+	}
+	if snapshot.Maintenance, err = listActiveMaintenanceCustomers(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+func listAllCores(ctx context.Context, q querier) ([]models.CableCore, error) {
+	rows, err := q.Query(ctx, `
+		SELECT id, cable_id, core_index, tube_color, core_color, status, created_at, updated_at
+		FROM cable_cores
+		ORDER BY cable_id ASC, core_index ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cores: %w", err)
+	}
+	defer rows.Close()
+
+	var cores []models.CableCore
+	for rows.Next() {
+		var core models.CableCore
+		err := rows.Scan(
+			&core.ID,
+			&core.CableID,
+			&core.CoreIndex,
+			&core.TubeColor,
+			&core.CoreColor,
+			&core.Status,
+			&core.CreatedAt,
+			&core.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan core: %w", err)
+		}
+		cores = append(cores, core)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list cores: %w", err)
+	}
+
+	return cores, nil
+}
+
+func listAllSpans(ctx context.Context, q querier) ([]models.CableSpan, error) {
+	rows, err := q.Query(ctx, `
+		SELECT id, cable_id, from_node_id, to_node_id, sequence, span_length_meter, created_at, updated_at
+		FROM cable_spans
+		ORDER BY cable_id ASC, sequence ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cable spans: %w", err)
+	}
+	defer rows.Close()
+
+	var spans []models.CableSpan
+	for rows.Next() {
+		var span models.CableSpan
+		err := rows.Scan(
+			&span.ID,
+			&span.CableID,
+			&span.FromNodeID,
+			&span.ToNodeID,
+			&span.Sequence,
+			&span.SpanLengthMeter,
+			&span.CreatedAt,
+			&span.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan cable span: %w", err)
+		}
+		spans = append(spans, span)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list cable spans: %w", err)
+	}
+
+	return spans, nil
+}
+
+func listAllBreakouts(ctx context.Context, q querier) ([]models.CoreBreakout, error) {
+	rows, err := q.Query(ctx, `
+		SELECT id, core_id, node_id, notes, created_at
+		FROM cable_core_breakouts
+		ORDER BY id ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list core breakouts: %w", err)
+	}
+	defer rows.Close()
+
+	var breakouts []models.CoreBreakout
+	for rows.Next() {
+		var b models.CoreBreakout
+		if err := rows.Scan(&b.ID, &b.CoreID, &b.NodeID, &b.Notes, &b.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan core breakout: %w", err)
+		}
+		breakouts = append(breakouts, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list core breakouts: %w", err)
+	}
+
+	return breakouts, nil
+}
+
+func listAllConnections(ctx context.Context, q querier) ([]models.Connection, error) {
+	rows, err := q.Query(ctx, `
+		SELECT id, location_node_id, input_type, input_id, output_type, output_id, loss_db, notes, created_at, updated_at
+		FROM connections
+		ORDER BY id ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list connections: %w", err)
+	}
+	defer rows.Close()
+
+	var connections []models.Connection
+	for rows.Next() {
+		var c models.Connection
+		err := rows.Scan(
+			&c.ID,
+			&c.LocationNodeID,
+			&c.InputType,
+			&c.InputID,
+			&c.OutputType,
+			&c.OutputID,
+			&c.LossDB,
+			&c.Notes,
+			&c.CreatedAt,
+			&c.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan connection: %w", err)
+		}
+		connections = append(connections, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list connections: %w", err)
+	}
+
+	return connections, nil
+}
@@ -0,0 +1,856 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"spectra-backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrWorkOrderNotFound is returned when a work order ID is unknown
+	ErrWorkOrderNotFound = errors.New("work order not found")
+	// ErrWorkOrderTaskNotFound is returned when a task does not belong to the work order
+	ErrWorkOrderTaskNotFound = errors.New("work order task not found")
+	// ErrInvalidWorkOrder is returned when a work order request is unusable
+	ErrInvalidWorkOrder = errors.New("invalid work order")
+	// ErrWorkOrderClosed is returned when changing a work order that was completed or cancelled
+	ErrWorkOrderClosed = errors.New("work order is completed or cancelled")
+	// ErrWorkOrderTransition is returned when a status change is not allowed
+	ErrWorkOrderTransition = errors.New("status change not allowed")
+	// ErrWorkOrderTasksOpen is returned when completing a work order with unchecked tasks
+	ErrWorkOrderTasksOpen = errors.New("work order has unfinished tasks")
+)
+
+// DesignChangeError is returned when a design change cannot be applied to the live inventory.
+// Nothing is applied when any change fails.
+type DesignChangeError struct {
+	Position int
+	Reason   string
+}
+
+// Error implements the error interface
+func (e *DesignChangeError) Error() string {
+	return fmt.Sprintf("design change %d cannot be applied: %s", e.Position, e.Reason)
+}
+
+// workOrderSelect selects work orders without their checklists, materials, assets and changes
+const workOrderSelect = `
+	SELECT w.id, w.code, w.title, w.description, w.type, w.status, w.crew, w.scheduled_start, w.scheduled_end,
+		w.started_at, w.completed_at, w.completed_by, w.created_at, w.updated_at
+	FROM work_orders w
+`
+
+// WorkOrderRepository handles database operations for work orders
+type WorkOrderRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewWorkOrderRepository creates a new WorkOrderRepository
+func NewWorkOrderRepository(pool *pgxpool.Pool) *WorkOrderRepository {
+	return &WorkOrderRepository{pool: pool}
+}
+
+// Create plans a work order with its checklist, materials, target assets and design changes
+func (r *WorkOrderRepository) Create(ctx context.Context, req *models.CreateWorkOrderRequest) (*models.WorkOrder, error) {
+	if !req.Type.IsValid() {
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidWorkOrder, req.Type)
+	}
+	if err := checkSchedule(req.ScheduledStart, req.ScheduledEnd); err != nil {
+		return nil, err
+	}
+	if err := checkDesignChanges(req.Changes); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Codes are unique per tenant, so the operator, who sees every tenant's work orders, checks its own only
+	var taken bool
+	err = tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM work_orders WHERE code = $1 AND tenant_id IS NOT DISTINCT FROM current_tenant_id())",
+		req.Code).Scan(&taken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check work order code: %w", err)
+	}
+	if taken {
+		return nil, fmt.Errorf("%w: code %q is already used", ErrInvalidWorkOrder, req.Code)
+	}
+
+	var id int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO work_orders (code, title, description, type, crew, scheduled_start, scheduled_end)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`, req.Code, req.Title, req.Description, req.Type, req.Crew, req.ScheduledStart, req.ScheduledEnd).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create work order: %w", err)
+	}
+
+	if err := replaceWorkOrderItems(ctx, tx, id, req.Tasks, req.Materials, req.Assets, req.Changes); err != nil {
+		return nil, err
+	}
+
+	workOrder, err := getWorkOrder(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return workOrder, nil
+}
+
+// GetByID retrieves a work order with its checklist, materials, assets and design changes
+func (r *WorkOrderRepository) GetByID(ctx context.Context, id int64) (*models.WorkOrder, error) {
+	return getWorkOrder(ctx, r.pool, id)
+}
+
+// List retrieves work orders ordered by scheduled start
+func (r *WorkOrderRepository) List(ctx context.Context, filter *models.WorkOrderFilter) ([]models.WorkOrder, int64, error) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.Status != nil {
+		where += fmt.Sprintf(" AND w.status = $%d", argIndex)
+		args = append(args, *filter.Status)
+		argIndex++
+	}
+	if filter.Type != nil {
+		where += fmt.Sprintf(" AND w.type = $%d", argIndex)
+		args = append(args, *filter.Type)
+		argIndex++
+	}
+	if filter.Crew != nil {
+		where += fmt.Sprintf(" AND w.crew = $%d", argIndex)
+		args = append(args, *filter.Crew)
+		argIndex++
+	}
+	if filter.ScheduledFrom != nil {
+		where += fmt.Sprintf(" AND COALESCE(w.scheduled_end, w.scheduled_start) >= $%d", argIndex)
+		args = append(args, *filter.ScheduledFrom)
+		argIndex++
+	}
+	if filter.ScheduledTo != nil {
+		where += fmt.Sprintf(" AND w.scheduled_start <= $%d", argIndex)
+		args = append(args, *filter.ScheduledTo)
+		argIndex++
+	}
+	if filter.Asset != nil {
+		where += fmt.Sprintf(` AND EXISTS (
+			SELECT 1 FROM work_order_assets a WHERE a.work_order_id = w.id AND a.element_type = $%d AND a.element_id = $%d
+		)`, argIndex, argIndex+1)
+		args = append(args, filter.Asset.ElementType, filter.Asset.ElementID)
+		argIndex += 2
+	}
+	if filter.AreaID != nil {
+		where += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM work_order_assets a WHERE a.work_order_id = w.id AND %s)", areaElementSQL("a", argIndex))
+		args = append(args, *filter.AreaID)
+		argIndex++
+	}
+	if filter.Search != nil {
+		where += fmt.Sprintf(" AND (w.code ILIKE $%d OR w.title ILIKE $%d)", argIndex, argIndex)
+		args = append(args, "%"+*filter.Search+"%")
+		argIndex++
+	}
+
+	var total int64
+	if err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM work_orders w"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count work orders: %w", err)
+	}
+
+	limit := 100
+	offset := 0
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+	if filter.Offset > 0 {
+		offset = filter.Offset
+	}
+	args = append(args, limit, offset)
+
+	workOrders, err := listWorkOrders(ctx, r.pool,
+		fmt.Sprintf("%s ORDER BY w.scheduled_start ASC NULLS LAST, w.id ASC LIMIT $%d OFFSET $%d", where, argIndex, argIndex+1),
+		args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return workOrders, total, nil
+}
+
+// Update edits an open work order. Lists present in the request replace the existing ones.
+func (r *WorkOrderRepository) Update(ctx context.Context, id int64, req *models.UpdateWorkOrderRequest) (*models.WorkOrder, error) {
+	if req.Type != nil && !req.Type.IsValid() {
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidWorkOrder, *req.Type)
+	}
+	if err := checkDesignChanges(req.Changes); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := lockWorkOrder(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !current.Status.IsOpen() {
+		return nil, ErrWorkOrderClosed
+	}
+
+	setParts := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	if req.Title != nil {
+		setParts = append(setParts, fmt.Sprintf("title = $%d", argIndex))
+		args = append(args, *req.Title)
+		argIndex++
+	}
+	if req.Description != nil {
+		setParts = append(setParts, fmt.Sprintf("description = $%d", argIndex))
+		args = append(args, *req.Description)
+		argIndex++
+	}
+	if req.Type != nil {
+		setParts = append(setParts, fmt.Sprintf("type = $%d", argIndex))
+		args = append(args, *req.Type)
+		argIndex++
+	}
+	if req.Crew != nil {
+		setParts = append(setParts, fmt.Sprintf("crew = $%d", argIndex))
+		args = append(args, *req.Crew)
+		argIndex++
+	}
+	start, end := current.ScheduledStart, current.ScheduledEnd
+	if req.ScheduledStart != nil {
+		start = req.ScheduledStart
+		setParts = append(setParts, fmt.Sprintf("scheduled_start = $%d", argIndex))
+		args = append(args, *req.ScheduledStart)
+		argIndex++
+	}
+	if req.ScheduledEnd != nil {
+		end = req.ScheduledEnd
+		setParts = append(setParts, fmt.Sprintf("scheduled_end = $%d", argIndex))
+		args = append(args, *req.ScheduledEnd)
+		argIndex++
+	}
+	if err := checkSchedule(start, end); err != nil {
+		return nil, err
+	}
+
+	if len(setParts) > 0 {
+		args = append(args, id)
+		query := fmt.Sprintf("UPDATE work_orders SET %s WHERE id = $%d", joinStrings(setParts, ", "), argIndex)
+		if _, err := tx.Exec(ctx, query, args...); err != nil {
+			return nil, fmt.Errorf("failed to update work order: %w", err)
+		}
+	}
+
+	if err := replaceWorkOrderItems(ctx, tx, id, req.Tasks, req.Materials, req.Assets, req.Changes); err != nil {
+		return nil, err
+	}
+
+	workOrder, err := getWorkOrder(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return workOrder, nil
+}
+
+// UpdateTask ticks a checklist item off or on again
+func (r *WorkOrderRepository) UpdateTask(ctx context.Context, id, taskID int64, req *models.WorkOrderTaskUpdate) (*models.WorkOrderTask, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := lockWorkOrder(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !current.Status.IsOpen() {
+		return nil, ErrWorkOrderClosed
+	}
+
+	task := &models.WorkOrderTask{}
+	err = tx.QueryRow(ctx, `
+		UPDATE work_order_tasks SET
+			done = $1,
+			done_by = CASE WHEN $1 THEN $2 END,
+			done_at = CASE WHEN $1 THEN NOW() END
+		WHERE id = $3 AND work_order_id = $4
+		RETURNING id, work_order_id, position, description, done, done_by, done_at
+	`, req.Done, req.DoneBy, taskID, id).Scan(
+		&task.ID, &task.WorkOrderID, &task.Position, &task.Description, &task.Done, &task.DoneBy, &task.DoneAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrWorkOrderTaskNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update work order task: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return task, nil
+}
+
+// ChangeStatus starts, pauses or cancels a work order. Cancelling releases the job's reservations.
+func (r *WorkOrderRepository) ChangeStatus(ctx context.Context, id int64, status models.WorkOrderStatus) (*models.WorkOrder, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := lockWorkOrder(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !current.Status.CanMoveTo(status) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrWorkOrderTransition, current.Status, status)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE work_orders SET
+			status = $1,
+			started_at = CASE WHEN $1 = 'IN_PROGRESS' THEN COALESCE(started_at, NOW()) ELSE started_at END
+		WHERE id = $2
+	`, status, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to change work order status: %w", err)
+	}
+	if status == models.WorkOrderStatusCancelled {
+		if _, err := endReservations(ctx, tx, models.ReservationStatusReleased, " AND work_order = $2", current.Code); err != nil {
+			return nil, err
+		}
+	}
+
+	workOrder, err := getWorkOrder(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return workOrder, nil
+}
+
+// Complete closes a work order once its checklist is done. Its design changes are applied to the
+// live inventory and its reservations released in the same transaction, so either all of it
+// happens or none of it does.
+func (r *WorkOrderRepository) Complete(ctx context.Context, id int64, req *models.CompleteWorkOrderRequest) (*models.WorkOrder, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := lockWorkOrder(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !current.Status.IsOpen() {
+		return nil, ErrWorkOrderClosed
+	}
+
+	var openTasks int
+	err = tx.QueryRow(ctx, "SELECT COUNT(*) FROM work_order_tasks WHERE work_order_id = $1 AND NOT done", id).Scan(&openTasks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check work order tasks: %w", err)
+	}
+	if openTasks > 0 {
+		return nil, fmt.Errorf("%w: %d unchecked", ErrWorkOrderTasksOpen, openTasks)
+	}
+
+	if req.ApplyChanges == nil || *req.ApplyChanges {
+		changes, err := listDesignChanges(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, change := range changes {
+			if err := applyDesignChange(ctx, tx, current.Code, change); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if _, err := endReservations(ctx, tx, models.ReservationStatusReleased, " AND work_order = $2", current.Code); err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE work_orders SET status = $1, started_at = COALESCE(started_at, NOW()), completed_at = NOW(), completed_by = $2
+		WHERE id = $3
+	`, models.WorkOrderStatusCompleted, req.CompletedBy, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete work order: %w", err)
+	}
+
+	workOrder, err := getWorkOrder(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return workOrder, nil
+}
+
+// applyDesignChange applies one design change to the live inventory and marks it applied.
+// Cores reserved for the work order's code may be spliced or have their status set; cores reserved for
+// anything else may not.
+func applyDesignChange(ctx context.Context, q querier, code string, change models.DesignChange) error {
+	fail := func(format string, args ...interface{}) error {
+		return &DesignChangeError{Position: change.Position, Reason: fmt.Sprintf(format, args...)}
+	}
+	// lockCore locks a core the change touches and refuses it when it is reserved for another job
+	lockCore := func(coreID int64) (models.CoreStatus, error) {
+		var status models.CoreStatus
+		var reservedFor *string
+		err := q.QueryRow(ctx, `
+			SELECT cc.status, r.work_order
+			FROM cable_cores cc
+			LEFT JOIN reservations r ON r.element_type = 'CORE' AND r.element_id = cc.id AND r.status = 'ACTIVE'
+			WHERE cc.id = $1
+			FOR UPDATE OF cc
+		`, coreID).Scan(&status, &reservedFor)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", fail("core %d does not exist", coreID)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to check core %d: %w", coreID, err)
+		}
+		if status == models.CoreStatusReserved && (reservedFor == nil || *reservedFor != code) {
+			return "", fail("core %d is reserved for another job", coreID)
+		}
+		return status, nil
+	}
+
+	connectionID := change.ConnectionID
+	switch change.ChangeType {
+	case models.DesignChangeAddSplice:
+		endpoints := []struct {
+			kind models.ConnectionType
+			id   int64
+		}{{*change.InputType, *change.InputID}, {*change.OutputType, *change.OutputID}}
+		for _, e := range endpoints {
+			if e.kind != models.ConnectionTypeCore {
+				continue
+			}
+			status, err := lockCore(e.id)
+			if err != nil {
+				return err
+			}
+			if status == models.CoreStatusDamaged {
+				return fail("core %d is damaged", e.id)
+			}
+		}
+
+		var id int64
+		err := q.QueryRow(ctx, `
+			INSERT INTO connections (location_node_id, input_type, input_id, output_type, output_id, loss_db, notes)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id
+		`, change.LocationNodeID, change.InputType, change.InputID, change.OutputType, change.OutputID,
+			change.LossDB, change.Notes).Scan(&id)
+		if err != nil {
+			return fmt.Errorf("failed to add splice: %w", err)
+		}
+		connectionID = &id
+
+		for _, e := range endpoints {
+			if e.kind != models.ConnectionTypeCore {
+				continue
+			}
+			if _, err := q.Exec(ctx, "UPDATE cable_cores SET status = $1 WHERE id = $2", models.CoreStatusUsed, e.id); err != nil {
+				return fmt.Errorf("failed to update core status: %w", err)
+			}
+		}
+
+	case models.DesignChangeRemoveSplice:
+		var inputType, outputType models.ConnectionType
+		var inputID, outputID int64
+		err := q.QueryRow(ctx, `
+			DELETE FROM connections WHERE id = $1
+			RETURNING input_type, input_id, output_type, output_id
+		`, *change.ConnectionID).Scan(&inputType, &inputID, &outputType, &outputID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fail("connection %d does not exist", *change.ConnectionID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to remove splice: %w", err)
+		}
+
+		// A core spliced at both ends stays in use until its last connection is removed
+		var coreIDs []int64
+		if inputType == models.ConnectionTypeCore {
+			coreIDs = append(coreIDs, inputID)
+		}
+		if outputType == models.ConnectionTypeCore {
+			coreIDs = append(coreIDs, outputID)
+		}
+		if len(coreIDs) > 0 {
+			_, err := q.Exec(ctx, `
+				UPDATE cable_cores cc SET status = $1
+				WHERE cc.id = ANY($2) AND cc.status = $3 AND NOT EXISTS (
+					SELECT 1 FROM connections c
+					WHERE (c.input_type = 'CORE' AND c.input_id = cc.id) OR (c.output_type = 'CORE' AND c.output_id = cc.id)
+				)
+			`, models.CoreStatusVacant, coreIDs, models.CoreStatusUsed)
+			if err != nil {
+				return fmt.Errorf("failed to free cores: %w", err)
+			}
+		}
+
+	case models.DesignChangeSetCoreStatus:
+		if _, err := lockCore(*change.CoreID); err != nil {
+			return err
+		}
+		if _, err := q.Exec(ctx, "UPDATE cable_cores SET status = $1 WHERE id = $2", *change.CoreStatus, *change.CoreID); err != nil {
+			return fmt.Errorf("failed to update core status: %w", err)
+		}
+	}
+
+	_, err := q.Exec(ctx, "UPDATE work_order_changes SET applied_at = NOW(), connection_id = $1 WHERE id = $2", connectionID, change.ID)
+	if err != nil {
+		return fmt.Errorf("failed to mark design change applied: %w", err)
+	}
+	return nil
+}
+
+// checkSchedule rejects windows that end before they start
+func checkSchedule(start, end *time.Time) error {
+	if start != nil && end != nil && end.Before(*start) {
+		return fmt.Errorf("%w: scheduled_end is before scheduled_start", ErrInvalidWorkOrder)
+	}
+	return nil
+}
+
+// checkDesignChanges rejects design changes missing the fields their type needs or naming unknown
+// endpoint types or core statuses
+func checkDesignChanges(changes []models.DesignChangeRequest) error {
+	for i, c := range changes {
+		var missing string
+		switch c.ChangeType {
+		case models.DesignChangeAddSplice:
+			if c.InputType == nil || c.InputID == nil || c.OutputType == nil || c.OutputID == nil {
+				missing = "input_type, input_id, output_type and output_id"
+			} else if !c.InputType.IsValid() || !c.OutputType.IsValid() {
+				return fmt.Errorf("%w: change %d (%s) endpoint types must be CORE or PORT", ErrInvalidWorkOrder, i+1, c.ChangeType)
+			} else if (*c.InputType == models.ConnectionTypePort || *c.OutputType == models.ConnectionTypePort) && c.LocationNodeID == nil {
+				missing = "location_node_id for PORT endpoints"
+			}
+		case models.DesignChangeRemoveSplice:
+			if c.ConnectionID == nil {
+				missing = "connection_id"
+			}
+		case models.DesignChangeSetCoreStatus:
+			if c.CoreID == nil || c.CoreStatus == nil {
+				missing = "core_id and core_status"
+			} else if !c.CoreStatus.IsValid() {
+				return fmt.Errorf("%w: change %d (%s) has unknown core_status %q", ErrInvalidWorkOrder, i+1, c.ChangeType, *c.CoreStatus)
+			}
+		default:
+			return fmt.Errorf("%w: change %d has unknown change_type %q", ErrInvalidWorkOrder, i+1, c.ChangeType)
+		}
+		if missing != "" {
+			return fmt.Errorf("%w: change %d (%s) requires %s", ErrInvalidWorkOrder, i+1, c.ChangeType, missing)
+		}
+	}
+	return nil
+}
+
+// replaceWorkOrderItems replaces each list that is non-nil
+func replaceWorkOrderItems(ctx context.Context, q querier, id int64, tasks []models.WorkOrderTaskRequest,
+	materials []models.WorkOrderMaterialRequest, assets []models.WorkOrderAssetRequest, changes []models.DesignChangeRequest) error {
+	if tasks != nil {
+		if _, err := q.Exec(ctx, "DELETE FROM work_order_tasks WHERE work_order_id = $1", id); err != nil {
+			return fmt.Errorf("failed to replace work order tasks: %w", err)
+		}
+		for i, t := range tasks {
+			_, err := q.Exec(ctx, `
+				INSERT INTO work_order_tasks (work_order_id, position, description) VALUES ($1, $2, $3)
+			`, id, i+1, t.Description)
+			if err != nil {
+				return fmt.Errorf("failed to add work order task: %w", err)
+			}
+		}
+	}
+
+	if materials != nil {
+		if _, err := q.Exec(ctx, "DELETE FROM work_order_materials WHERE work_order_id = $1", id); err != nil {
+			return fmt.Errorf("failed to replace work order materials: %w", err)
+		}
+		for _, m := range materials {
+			if m.Quantity <= 0 {
+				return fmt.Errorf("%w: quantity of %s must be positive", ErrInvalidWorkOrder, m.Item)
+			}
+			_, err := q.Exec(ctx, `
+				INSERT INTO work_order_materials (work_order_id, item, quantity, unit, notes) VALUES ($1, $2, $3, $4, $5)
+			`, id, m.Item, m.Quantity, m.Unit, m.Notes)
+			if err != nil {
+				return fmt.Errorf("failed to add work order material: %w", err)
+			}
+		}
+	}
+
+	if assets != nil {
+		if _, err := q.Exec(ctx, "DELETE FROM work_order_assets WHERE work_order_id = $1", id); err != nil {
+			return fmt.Errorf("failed to replace work order assets: %w", err)
+		}
+		idsByType := map[models.WorkOrderAssetType][]int64{}
+		for _, a := range assets {
+			if !a.ElementType.IsValid() {
+				return fmt.Errorf("%w: unknown asset type %q", ErrInvalidWorkOrder, a.ElementType)
+			}
+			idsByType[a.ElementType] = append(idsByType[a.ElementType], a.ElementID)
+		}
+		for assetType, ids := range idsByType {
+			names, err := collectNames(ctx, q, elementNameQueries[string(assetType)], ids)
+			if err != nil {
+				return fmt.Errorf("failed to check work order assets: %w", err)
+			}
+			for _, elementID := range ids {
+				if _, ok := names[elementID]; !ok {
+					return fmt.Errorf("%w: %s %d does not exist", ErrInvalidWorkOrder, assetType, elementID)
+				}
+			}
+		}
+		for _, a := range assets {
+			_, err := q.Exec(ctx, `
+				INSERT INTO work_order_assets (work_order_id, element_type, element_id) VALUES ($1, $2, $3)
+				ON CONFLICT (work_order_id, element_type, element_id) DO NOTHING
+			`, id, a.ElementType, a.ElementID)
+			if err != nil {
+				return fmt.Errorf("failed to add work order asset: %w", err)
+			}
+		}
+	}
+
+	if changes != nil {
+		if _, err := q.Exec(ctx, "DELETE FROM work_order_changes WHERE work_order_id = $1", id); err != nil {
+			return fmt.Errorf("failed to replace design changes: %w", err)
+		}
+		for i, c := range changes {
+			_, err := q.Exec(ctx, `
+				INSERT INTO work_order_changes (work_order_id, position, change_type, location_node_id, input_type, input_id,
+					output_type, output_id, loss_db, connection_id, core_id, core_status, notes)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			`, id, i+1, c.ChangeType, c.LocationNodeID, c.InputType, c.InputID, c.OutputType, c.OutputID, c.LossDB,
+				c.ConnectionID, c.CoreID, c.CoreStatus, c.Notes)
+			if err != nil {
+				return fmt.Errorf("failed to add design change: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// lockWorkOrder locks a work order row for a change and returns it without its lists
+func lockWorkOrder(ctx context.Context, q querier, id int64) (*models.WorkOrder, error) {
+	workOrders, err := listWorkOrders(ctx, q, " WHERE w.id = $1 FOR UPDATE", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(workOrders) == 0 {
+		return nil, ErrWorkOrderNotFound
+	}
+	return &workOrders[0], nil
+}
+
+// getWorkOrder retrieves a work order with its lists, or nil
+func getWorkOrder(ctx context.Context, q querier, id int64) (*models.WorkOrder, error) {
+	workOrders, err := listWorkOrders(ctx, q, " WHERE w.id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(workOrders) == 0 {
+		return nil, nil
+	}
+	w := &workOrders[0]
+
+	rows, err := q.Query(ctx, `
+		SELECT id, work_order_id, position, description, done, done_by, done_at
+		FROM work_order_tasks WHERE work_order_id = $1 ORDER BY position
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get work order tasks: %w", err)
+	}
+	w.Tasks = []models.WorkOrderTask{}
+	for rows.Next() {
+		var t models.WorkOrderTask
+		if err := rows.Scan(&t.ID, &t.WorkOrderID, &t.Position, &t.Description, &t.Done, &t.DoneBy, &t.DoneAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan work order task: %w", err)
+		}
+		w.Tasks = append(w.Tasks, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get work order tasks: %w", err)
+	}
+
+	rows, err = q.Query(ctx, `
+		SELECT id, work_order_id, item, quantity, unit, notes
+		FROM work_order_materials WHERE work_order_id = $1 ORDER BY id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get work order materials: %w", err)
+	}
+	w.Materials = []models.WorkOrderMaterial{}
+	for rows.Next() {
+		var m models.WorkOrderMaterial
+		if err := rows.Scan(&m.ID, &m.WorkOrderID, &m.Item, &m.Quantity, &m.Unit, &m.Notes); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan work order material: %w", err)
+		}
+		w.Materials = append(w.Materials, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get work order materials: %w", err)
+	}
+
+	rows, err = q.Query(ctx, `
+		SELECT a.id, a.work_order_id, a.element_type, a.element_id,
+			CASE a.element_type
+				WHEN 'NODE' THEN (SELECT name FROM nodes WHERE id = a.element_id)
+				WHEN 'CABLE' THEN (SELECT name FROM cables WHERE id = a.element_id)
+				WHEN 'CUSTOMER' THEN (SELECT name FROM customers WHERE id = a.element_id)
+			END
+		FROM work_order_assets a WHERE a.work_order_id = $1 ORDER BY a.id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get work order assets: %w", err)
+	}
+	w.Assets = []models.WorkOrderAsset{}
+	for rows.Next() {
+		var a models.WorkOrderAsset
+		if err := rows.Scan(&a.ID, &a.WorkOrderID, &a.ElementType, &a.ElementID, &a.Name); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan work order asset: %w", err)
+		}
+		w.Assets = append(w.Assets, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get work order assets: %w", err)
+	}
+
+	if w.Changes, err = listDesignChanges(ctx, q, id); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// listDesignChanges retrieves a work order's design changes in the order they are applied
+func listDesignChanges(ctx context.Context, q querier, id int64) ([]models.DesignChange, error) {
+	rows, err := q.Query(ctx, `
+		SELECT id, work_order_id, position, change_type, location_node_id, input_type, input_id, output_type, output_id,
+			loss_db, connection_id, core_id, core_status, notes, applied_at
+		FROM work_order_changes WHERE work_order_id = $1 ORDER BY position
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get design changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []models.DesignChange{}
+	for rows.Next() {
+		var c models.DesignChange
+		err := rows.Scan(
+			&c.ID,
+			&c.WorkOrderID,
+			&c.Position,
+			&c.ChangeType,
+			&c.LocationNodeID,
+			&c.InputType,
+			&c.InputID,
+			&c.OutputType,
+			&c.OutputID,
+			&c.LossDB,
+			&c.ConnectionID,
+			&c.CoreID,
+			&c.CoreStatus,
+			&c.Notes,
+			&c.AppliedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan design change: %w", err)
+		}
+		changes = append(changes, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get design changes: %w", err)
+	}
+	return changes, nil
+}
+
+// listWorkOrders runs workOrderSelect with the given WHERE/ORDER clause
+func listWorkOrders(ctx context.Context, q querier, clause string, args ...interface{}) ([]models.WorkOrder, error) {
+	rows, err := q.Query(ctx, workOrderSelect+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list work orders: %w", err)
+	}
+	defer rows.Close()
+
+	workOrders := []models.WorkOrder{}
+	for rows.Next() {
+		var w models.WorkOrder
+		err := rows.Scan(
+			&w.ID,
+			&w.Code,
+			&w.Title,
+			&w.Description,
+			&w.Type,
+			&w.Status,
+			&w.Crew,
+			&w.ScheduledStart,
+			&w.ScheduledEnd,
+			&w.StartedAt,
+			&w.CompletedAt,
+			&w.CompletedBy,
+			&w.CreatedAt,
+			&w.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan work order: %w", err)
+		}
+		workOrders = append(workOrders, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list work orders: %w", err)
+	}
+
+	return workOrders, nil
+}
@@ -0,0 +1,1087 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"spectra-backend/internal/geo"
+	"spectra-backend/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrWorkspaceNotFound is returned when a workspace ID is unknown
+	ErrWorkspaceNotFound = errors.New("design workspace not found")
+	// ErrDesignItemNotFound is returned when an item does not belong to the workspace
+	ErrDesignItemNotFound = errors.New("design item not found")
+	// ErrInvalidDesignItem is returned when a drafted item is unusable
+	ErrInvalidDesignItem = errors.New("invalid design item")
+	// ErrDesignItemInUse is returned when deleting an item other drafted items reference
+	ErrDesignItemInUse = errors.New("design item is referenced by other items")
+	// ErrWorkspaceNotDraft is returned when editing the items of a workspace under review or closed
+	ErrWorkspaceNotDraft = errors.New("workspace is not a draft")
+	// ErrWorkspaceClosed is returned when changing a workspace that was merged or discarded
+	ErrWorkspaceClosed = errors.New("workspace is merged or discarded")
+	// ErrWorkspaceTransition is returned when a review step is not allowed
+	ErrWorkspaceTransition = errors.New("review step not allowed")
+)
+
+// WorkspaceConflictError is returned when a workspace cannot be submitted or merged because its
+// drafted items clash with the live inventory. Nothing is merged when there is any conflict.
+type WorkspaceConflictError struct {
+	Conflicts []models.DesignConflict
+}
+
+// Error implements the error interface
+func (e *WorkspaceConflictError) Error() string {
+	reasons := make([]string, 0, len(e.Conflicts))
+	for _, c := range e.Conflicts {
+		reasons = append(reasons, fmt.Sprintf("item %d: %s", c.ItemID, c.Reason))
+	}
+	return "workspace has conflicts: " + strings.Join(reasons, "; ")
+}
+
+// workspaceSelect selects workspaces with their item count but without the items
+const workspaceSelect = `
+	SELECT w.id, w.name, w.description, w.status, w.created_by, w.submitted_by, w.submitted_at,
+		w.reviewed_by, w.reviewed_at, w.review_note, w.merged_by, w.merged_at, w.created_at, w.updated_at,
+		(SELECT COUNT(*) FROM design_items i WHERE i.workspace_id = w.id)
+	FROM design_workspaces w
+`
+
+// designItemColumns are the design_items columns scanned by scanDesignItem
+const designItemColumns = "id, workspace_id, item_type, payload, live_id, created_at, updated_at"
+
+// WorkspaceRepository handles database operations for design workspaces
+type WorkspaceRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewWorkspaceRepository creates a new WorkspaceRepository
+func NewWorkspaceRepository(pool *pgxpool.Pool) *WorkspaceRepository {
+	return &WorkspaceRepository{pool: pool}
+}
+
+// Create opens an empty draft workspace
+func (r *WorkspaceRepository) Create(ctx context.Context, req *models.CreateWorkspaceRequest) (*models.DesignWorkspace, error) {
+	var id int64
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO design_workspaces (name, description, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, req.Name, req.Description, req.CreatedBy).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+
+	return getWorkspace(ctx, r.pool, id)
+}
+
+// GetByID retrieves a workspace with its drafted items
+func (r *WorkspaceRepository) GetByID(ctx context.Context, id int64) (*models.DesignWorkspace, error) {
+	return getWorkspace(ctx, r.pool, id)
+}
+
+// List retrieves workspaces, most recently changed first
+func (r *WorkspaceRepository) List(ctx context.Context, filter *models.WorkspaceFilter) ([]models.DesignWorkspace, int64, error) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.Status != nil {
+		where += fmt.Sprintf(" AND w.status = $%d", argIndex)
+		args = append(args, *filter.Status)
+		argIndex++
+	}
+	if filter.CreatedBy != nil {
+		where += fmt.Sprintf(" AND w.created_by = $%d", argIndex)
+		args = append(args, *filter.CreatedBy)
+		argIndex++
+	}
+	if filter.Search != nil && *filter.Search != "" {
+		where += fmt.Sprintf(" AND w.name ILIKE $%d", argIndex)
+		args = append(args, "%"+*filter.Search+"%")
+		argIndex++
+	}
+	if filter.AreaID != nil {
+		where += " AND w.id IN " + areaWorkspacesSQL(argIndex)
+		args = append(args, *filter.AreaID)
+		argIndex++
+	}
+
+	var total int64
+	if err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM design_workspaces w"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count workspaces: %w", err)
+	}
+
+	limit := 100
+	offset := 0
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+	if filter.Offset > 0 {
+		offset = filter.Offset
+	}
+	args = append(args, limit, offset)
+
+	workspaces, err := listWorkspaces(ctx, r.pool,
+		fmt.Sprintf("%s ORDER BY w.updated_at DESC, w.id DESC LIMIT $%d OFFSET $%d", where, argIndex, argIndex+1), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return workspaces, total, nil
+}
+
+// Update renames or redescribes a workspace that is still open
+func (r *WorkspaceRepository) Update(ctx context.Context, id int64, req *models.UpdateWorkspaceRequest) (*models.DesignWorkspace, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := lockWorkspace(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !current.Status.IsOpen() {
+		return nil, ErrWorkspaceClosed
+	}
+
+	_, err = tx.Exec(ctx, "UPDATE design_workspaces SET name = COALESCE($1, name), description = COALESCE($2, description) WHERE id = $3",
+		req.Name, req.Description, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update workspace: %w", err)
+	}
+
+	workspace, err := getWorkspace(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return workspace, nil
+}
+
+// AddItem drafts a node, cable, customer or splice in a draft workspace
+func (r *WorkspaceRepository) AddItem(ctx context.Context, id int64, req *models.DesignItemRequest) (*models.DesignItem, error) {
+	if err := checkDesignItem(req); err != nil {
+		return nil, err
+	}
+	payload, err := encodeDesignPayload(req)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := lockDraftWorkspace(ctx, tx, id); err != nil {
+		return nil, err
+	}
+
+	item, err := scanDesignItem(tx.QueryRow(ctx, `
+		INSERT INTO design_items (workspace_id, item_type, payload)
+		VALUES ($1, $2, $3)
+		RETURNING `+designItemColumns,
+		id, req.ItemType, payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to add design item: %w", err)
+	}
+	if _, err := tx.Exec(ctx, "UPDATE design_workspaces SET updated_at = NOW() WHERE id = $1", id); err != nil {
+		return nil, fmt.Errorf("failed to touch workspace: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return item, nil
+}
+
+// UpdateItem redrafts an item of a draft workspace. The item type cannot change, so references
+// from other drafted items stay meaningful.
+func (r *WorkspaceRepository) UpdateItem(ctx context.Context, id, itemID int64, req *models.DesignItemRequest) (*models.DesignItem, error) {
+	if err := checkDesignItem(req); err != nil {
+		return nil, err
+	}
+	payload, err := encodeDesignPayload(req)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := lockDraftWorkspace(ctx, tx, id); err != nil {
+		return nil, err
+	}
+
+	var itemType models.DesignItemType
+	err = tx.QueryRow(ctx, "SELECT item_type FROM design_items WHERE id = $1 AND workspace_id = $2", itemID, id).Scan(&itemType)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrDesignItemNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get design item: %w", err)
+	}
+	if itemType != req.ItemType {
+		return nil, fmt.Errorf("%w: item %d is a %s and cannot become a %s", ErrInvalidDesignItem, itemID, itemType, req.ItemType)
+	}
+
+	item, err := scanDesignItem(tx.QueryRow(ctx,
+		"UPDATE design_items SET payload = $1 WHERE id = $2 RETURNING "+designItemColumns, payload, itemID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update design item: %w", err)
+	}
+	if _, err := tx.Exec(ctx, "UPDATE design_workspaces SET updated_at = NOW() WHERE id = $1", id); err != nil {
+		return nil, fmt.Errorf("failed to touch workspace: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return item, nil
+}
+
+// DeleteItem removes an item from a draft workspace unless other drafted items reference it
+func (r *WorkspaceRepository) DeleteItem(ctx context.Context, id, itemID int64) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := lockDraftWorkspace(ctx, tx, id); err != nil {
+		return err
+	}
+
+	items, err := listDesignItems(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, item := range items {
+		if item.ID == itemID {
+			found = true
+			continue
+		}
+		for _, ref := range draftRefs(&item) {
+			if ref == itemID {
+				return fmt.Errorf("%w: item %d references it", ErrDesignItemInUse, item.ID)
+			}
+		}
+	}
+	if !found {
+		return ErrDesignItemNotFound
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM design_items WHERE id = $1", itemID); err != nil {
+		return fmt.Errorf("failed to delete design item: %w", err)
+	}
+	if _, err := tx.Exec(ctx, "UPDATE design_workspaces SET updated_at = NOW() WHERE id = $1", id); err != nil {
+		return fmt.Errorf("failed to touch workspace: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Diff compares a workspace with the live inventory
+func (r *WorkspaceRepository) Diff(ctx context.Context, id int64) (*models.WorkspaceDiff, error) {
+	workspace, err := getWorkspace(ctx, r.pool, id)
+	if err != nil {
+		return nil, err
+	}
+	if workspace == nil {
+		return nil, ErrWorkspaceNotFound
+	}
+
+	return diffWorkspace(ctx, r.pool, workspace)
+}
+
+// Submit sends a draft for review. A workspace with conflicts or without items cannot be submitted.
+func (r *WorkspaceRepository) Submit(ctx context.Context, id int64, req *models.WorkspaceReviewRequest) (*models.DesignWorkspace, error) {
+	return r.review(ctx, id, models.WorkspaceStatusSubmitted, req, `
+		UPDATE design_workspaces SET status = $1, submitted_by = $2, submitted_at = NOW(),
+			reviewed_by = NULL, reviewed_at = NULL, review_note = $3
+		WHERE id = $4
+	`)
+}
+
+// Approve approves a submitted workspace for merging
+func (r *WorkspaceRepository) Approve(ctx context.Context, id int64, req *models.WorkspaceReviewRequest) (*models.DesignWorkspace, error) {
+	return r.review(ctx, id, models.WorkspaceStatusApproved, req, `
+		UPDATE design_workspaces SET status = $1, reviewed_by = $2, reviewed_at = NOW(), review_note = $3
+		WHERE id = $4
+	`)
+}
+
+// Reject sends a submitted or approved workspace back to DRAFT with the reviewer's note
+func (r *WorkspaceRepository) Reject(ctx context.Context, id int64, req *models.WorkspaceReviewRequest) (*models.DesignWorkspace, error) {
+	return r.review(ctx, id, models.WorkspaceStatusDraft, req, `
+		UPDATE design_workspaces SET status = $1, reviewed_by = $2, reviewed_at = NOW(), review_note = $3
+		WHERE id = $4
+	`)
+}
+
+// Discard abandons a workspace. Its items never reached the live inventory, so nothing needs
+// cleaning up there; they are kept with the workspace for reference.
+func (r *WorkspaceRepository) Discard(ctx context.Context, id int64, req *models.WorkspaceReviewRequest) (*models.DesignWorkspace, error) {
+	return r.review(ctx, id, models.WorkspaceStatusDiscarded, req, `
+		UPDATE design_workspaces SET status = $1, reviewed_by = COALESCE($2, reviewed_by), reviewed_at = NOW(),
+			review_note = COALESCE($3, review_note)
+		WHERE id = $4
+	`)
+}
+
+// review moves a workspace to the next status with the given update, which takes the status,
+// the reviewer, the note and the workspace ID
+func (r *WorkspaceRepository) review(ctx context.Context, id int64, next models.WorkspaceStatus, req *models.WorkspaceReviewRequest, update string) (*models.DesignWorkspace, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := lockWorkspace(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !current.Status.IsOpen() {
+		return nil, ErrWorkspaceClosed
+	}
+	if !current.Status.CanMoveTo(next) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrWorkspaceTransition, current.Status, next)
+	}
+
+	if next == models.WorkspaceStatusSubmitted {
+		if current.Items, err = listDesignItems(ctx, tx, id); err != nil {
+			return nil, err
+		}
+		if len(current.Items) == 0 {
+			return nil, fmt.Errorf("%w: the workspace has no items", ErrWorkspaceTransition)
+		}
+		diff, err := diffWorkspace(ctx, tx, current)
+		if err != nil {
+			return nil, err
+		}
+		if !diff.Mergeable {
+			return nil, &WorkspaceConflictError{Conflicts: diff.Conflicts}
+		}
+	}
+
+	if _, err := tx.Exec(ctx, update, next, req.By, req.Note, id); err != nil {
+		return nil, fmt.Errorf("failed to change workspace status: %w", err)
+	}
+
+	workspace, err := getWorkspace(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return workspace, nil
+}
+
+// Merge creates the drafted items of an approved workspace in the live inventory. Nodes go in
+// first, then cables with their cores, customers and splices, with references between drafted
+// items resolved to the new IDs. The conflicts are checked again against the live inventory as
+// it stands, and either every item is merged or none is.
+func (r *WorkspaceRepository) Merge(ctx context.Context, id int64, req *models.WorkspaceReviewRequest) (*models.DesignWorkspace, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := lockWorkspace(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !current.Status.IsOpen() {
+		return nil, ErrWorkspaceClosed
+	}
+	if !current.Status.CanMoveTo(models.WorkspaceStatusMerged) {
+		return nil, fmt.Errorf("%w: only approved workspaces can be merged", ErrWorkspaceTransition)
+	}
+
+	if current.Items, err = listDesignItems(ctx, tx, id); err != nil {
+		return nil, err
+	}
+	diff, err := diffWorkspace(ctx, tx, current)
+	if err != nil {
+		return nil, err
+	}
+	if !diff.Mergeable {
+		return nil, &WorkspaceConflictError{Conflicts: diff.Conflicts}
+	}
+
+	areas, err := loadAreaIndex(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	liveIDs := map[int64]int64{}
+	for _, itemType := range models.DesignItemMergeOrder {
+		for i := range current.Items {
+			item := &current.Items[i]
+			if item.ItemType != itemType {
+				continue
+			}
+			liveID, err := mergeDesignItem(ctx, tx, item, liveIDs, areas)
+			if err != nil {
+				return nil, fmt.Errorf("failed to merge item %d: %w", item.ID, err)
+			}
+			liveIDs[item.ID] = liveID
+			if _, err := tx.Exec(ctx, "UPDATE design_items SET live_id = $1 WHERE id = $2", liveID, item.ID); err != nil {
+				return nil, fmt.Errorf("failed to record merged item: %w", err)
+			}
+		}
+	}
+
+	_, err = tx.Exec(ctx, "UPDATE design_workspaces SET status = $1, merged_by = $2, merged_at = NOW() WHERE id = $3",
+		models.WorkspaceStatusMerged, req.By, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge workspace: %w", err)
+	}
+
+	workspace, err := getWorkspace(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return workspace, nil
+}
+
+// mergeDesignItem creates a drafted item in the live inventory and returns its new ID.
+// liveIDs maps the drafted items merged so far to their live IDs; new nodes are placed by areas.
+func mergeDesignItem(ctx context.Context, q querier, item *models.DesignItem, liveIDs map[int64]int64, areas *areaIndex) (int64, error) {
+	live := func(ref *int64) *int64 {
+		if ref == nil || *ref >= 0 {
+			return ref
+		}
+		id := liveIDs[-*ref]
+		return &id
+	}
+
+	switch item.ItemType {
+	case models.DesignItemNode:
+		node, err := insertNode(ctx, q, item.Node, areas)
+		if err != nil {
+			return 0, err
+		}
+		return node.ID, nil
+
+	case models.DesignItemCable:
+		req := *item.Cable
+		req.OriginNodeID = live(req.OriginNodeID)
+		req.DestNodeID = live(req.DestNodeID)
+
+		schemeCode := models.DefaultColorScheme
+		if req.ColorScheme != nil && *req.ColorScheme != "" {
+			schemeCode = *req.ColorScheme
+		}
+		scheme, err := resolveColorScheme(ctx, q, schemeCode)
+		if err != nil {
+			return 0, err
+		}
+		req.ColorScheme = &scheme.Code
+
+		cable, err := insertCable(ctx, q, &req)
+		if err != nil {
+			return 0, err
+		}
+		if err := generateCores(ctx, q, cable.ID, req.CoreCount, scheme); err != nil {
+			return 0, err
+		}
+		return cable.ID, nil
+
+	case models.DesignItemCustomer:
+		req := *item.Customer
+		req.NodeID = live(req.NodeID)
+		customer, err := insertCustomer(ctx, q, &req)
+		if err != nil {
+			return 0, err
+		}
+		return customer.ID, nil
+
+	case models.DesignItemSplice:
+		req := item.Splice.CreateConnectionRequest
+		req.LocationNodeID = live(req.LocationNodeID)
+		var err error
+		if req.InputID, err = liveCoreID(ctx, q, req.InputType, req.InputID, item.Splice.InputCoreIndex, liveIDs); err != nil {
+			return 0, err
+		}
+		if req.OutputID, err = liveCoreID(ctx, q, req.OutputType, req.OutputID, item.Splice.OutputCoreIndex, liveIDs); err != nil {
+			return 0, err
+		}
+
+		conn, err := insertConnection(ctx, q, &req)
+		if err != nil {
+			return 0, err
+		}
+		for _, e := range []struct {
+			kind models.ConnectionType
+			id   int64
+		}{{req.InputType, req.InputID}, {req.OutputType, req.OutputID}} {
+			if e.kind != models.ConnectionTypeCore {
+				continue
+			}
+			if _, err := q.Exec(ctx, "UPDATE cable_cores SET status = $1 WHERE id = $2", models.CoreStatusUsed, e.id); err != nil {
+				return 0, fmt.Errorf("failed to update core status: %w", err)
+			}
+		}
+		return conn.ID, nil
+	}
+
+	return 0, fmt.Errorf("%w: unknown item type %q", ErrInvalidDesignItem, item.ItemType)
+}
+
+// liveCoreID resolves a splice endpoint on a drafted cable to the core generated when the cable
+// was merged. Other endpoints are returned unchanged.
+func liveCoreID(ctx context.Context, q querier, kind models.ConnectionType, id int64, coreIndex *int, liveIDs map[int64]int64) (int64, error) {
+	if kind != models.ConnectionTypeCore || id >= 0 {
+		return id, nil
+	}
+
+	var coreID int64
+	err := q.QueryRow(ctx, "SELECT id FROM cable_cores WHERE cable_id = $1 AND core_index = $2", liveIDs[-id], *coreIndex).Scan(&coreID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find core %d of cable item %d: %w", *coreIndex, -id, err)
+	}
+	return coreID, nil
+}
+
+// diffWorkspace lists what a workspace adds, the live nodes and cores it touches, what it adds up
+// to and every reason it cannot be merged as things stand
+func diffWorkspace(ctx context.Context, q querier, workspace *models.DesignWorkspace) (*models.WorkspaceDiff, error) {
+	diff := &models.WorkspaceDiff{
+		WorkspaceID: workspace.ID,
+		Status:      workspace.Status,
+		Nodes:       []models.DesignItem{},
+		Cables:      []models.DesignItem{},
+		Customers:   []models.DesignItem{},
+		Splices:     []models.DesignItem{},
+		LiveNodes:   []models.Node{},
+		LiveCores:   []models.CableCore{},
+		Conflicts:   []models.DesignConflict{},
+		Summary: models.DesignSummary{
+			NodesByType:       map[string]int{},
+			CableMetersByType: map[string]float64{},
+		},
+	}
+
+	drafted := map[int64]*models.DesignItem{}
+	for i := range workspace.Items {
+		drafted[workspace.Items[i].ID] = &workspace.Items[i]
+	}
+	conflict := func(item *models.DesignItem, format string, args ...interface{}) {
+		diff.Conflicts = append(diff.Conflicts, models.DesignConflict{
+			ItemID:   item.ID,
+			ItemType: item.ItemType,
+			Reason:   fmt.Sprintf(format, args...),
+		})
+	}
+
+	type liveRef struct {
+		item *models.DesignItem
+		id   int64
+	}
+	var nodeRefs, coreRefs []liveRef
+	nodeRef := func(item *models.DesignItem, ref *int64) {
+		if ref == nil {
+			return
+		}
+		if *ref >= 0 {
+			nodeRefs = append(nodeRefs, liveRef{item, *ref})
+		} else if target := drafted[-*ref]; target == nil || target.ItemType != models.DesignItemNode {
+			conflict(item, "node item %d is not drafted in this workspace", -*ref)
+		}
+	}
+	coreEnd := func(item *models.DesignItem, kind models.ConnectionType, id int64, coreIndex *int) {
+		if kind != models.ConnectionTypeCore {
+			return
+		}
+		if id >= 0 {
+			coreRefs = append(coreRefs, liveRef{item, id})
+			return
+		}
+		target := drafted[-id]
+		if target == nil || target.ItemType != models.DesignItemCable {
+			conflict(item, "cable item %d is not drafted in this workspace", -id)
+		} else if *coreIndex > target.Cable.CoreCount {
+			conflict(item, "cable item %d has only %d cores", -id, target.Cable.CoreCount)
+		}
+	}
+
+	ontSNs := map[string]*models.DesignItem{}
+	for i := range workspace.Items {
+		item := &workspace.Items[i]
+		switch item.ItemType {
+		case models.DesignItemNode:
+			diff.Nodes = append(diff.Nodes, *item)
+			diff.Summary.Nodes++
+			diff.Summary.NodesByType[string(item.Node.Type)]++
+
+		case models.DesignItemCable:
+			diff.Cables = append(diff.Cables, *item)
+			nodeRef(item, item.Cable.OriginNodeID)
+			nodeRef(item, item.Cable.DestNodeID)
+			meters := designCableMeters(item.Cable)
+			diff.Summary.Cables++
+			diff.Summary.CableMeters += meters
+			diff.Summary.CableMetersByType[string(item.Cable.Type)] += meters
+			diff.Summary.FiberMeters += meters * float64(item.Cable.CoreCount)
+
+			if code := item.Cable.ColorScheme; code != nil && *code != "" {
+				if _, err := resolveColorScheme(ctx, q, *code); errors.Is(err, ErrColorSchemeNotFound) {
+					conflict(item, "color scheme %s does not exist", *code)
+				} else if err != nil {
+					return nil, err
+				}
+			}
+
+		case models.DesignItemCustomer:
+			diff.Customers = append(diff.Customers, *item)
+			diff.Summary.Customers++
+			nodeRef(item, item.Customer.NodeID)
+			if sn := item.Customer.ONTSN; sn != nil && *sn != "" {
+				if other := ontSNs[*sn]; other != nil {
+					conflict(item, "ONT serial %s is also drafted as item %d", *sn, other.ID)
+				} else {
+					ontSNs[*sn] = item
+				}
+			}
+
+		case models.DesignItemSplice:
+			diff.Splices = append(diff.Splices, *item)
+			diff.Summary.Splices++
+			s := item.Splice
+			nodeRef(item, s.LocationNodeID)
+			coreEnd(item, s.InputType, s.InputID, s.InputCoreIndex)
+			coreEnd(item, s.OutputType, s.OutputID, s.OutputCoreIndex)
+		}
+	}
+
+	nodeIDs := map[int64]bool{}
+	for _, ref := range nodeRefs {
+		nodeIDs[ref.id] = true
+	}
+	liveNodes := map[int64]bool{}
+	for _, id := range sortedKeys(nodeIDs) {
+		node, err := getNode(ctx, q, id)
+		if err != nil {
+			return nil, err
+		}
+		if node != nil {
+			liveNodes[id] = true
+			diff.LiveNodes = append(diff.LiveNodes, *node)
+		}
+	}
+	for _, ref := range nodeRefs {
+		if !liveNodes[ref.id] {
+			conflict(ref.item, "node %d does not exist", ref.id)
+		}
+	}
+
+	coreIDs := map[int64]bool{}
+	for _, ref := range coreRefs {
+		coreIDs[ref.id] = true
+	}
+	liveCores := map[int64]models.CoreStatus{}
+	if len(coreIDs) > 0 {
+		rows, err := q.Query(ctx, `
+			SELECT id, cable_id, core_index, tube_color, core_color, status, created_at, updated_at
+			FROM cable_cores
+			WHERE id = ANY($1)
+			ORDER BY cable_id, core_index
+		`, sortedKeys(coreIDs))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get live cores: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var c models.CableCore
+			err := rows.Scan(&c.ID, &c.CableID, &c.CoreIndex, &c.TubeColor, &c.CoreColor, &c.Status, &c.CreatedAt, &c.UpdatedAt)
+			if err != nil {
+				return nil, fmt.Errorf("failed to scan live core: %w", err)
+			}
+			liveCores[c.ID] = c.Status
+			diff.LiveCores = append(diff.LiveCores, c)
+			if c.Status == models.CoreStatusVacant {
+				diff.Summary.LiveCoresUsed++
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to get live cores: %w", err)
+		}
+	}
+	for _, ref := range coreRefs {
+		status, ok := liveCores[ref.id]
+		switch {
+		case !ok:
+			conflict(ref.item, "core %d does not exist", ref.id)
+		case status == models.CoreStatusDamaged:
+			conflict(ref.item, "core %d is damaged", ref.id)
+		case status == models.CoreStatusReserved:
+			conflict(ref.item, "core %d is reserved", ref.id)
+		}
+	}
+
+	if len(ontSNs) > 0 {
+		serials := make([]string, 0, len(ontSNs))
+		for sn := range ontSNs {
+			serials = append(serials, sn)
+		}
+		rows, err := q.Query(ctx, "SELECT ont_sn FROM customers WHERE ont_sn = ANY($1) AND tenant_id IS NOT DISTINCT FROM current_tenant_id()", serials)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check ONT serials: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var sn string
+			if err := rows.Scan(&sn); err != nil {
+				return nil, fmt.Errorf("failed to scan ONT serial: %w", err)
+			}
+			conflict(ontSNs[sn], "ONT serial %s is already registered", sn)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to check ONT serials: %w", err)
+		}
+	}
+
+	sort.SliceStable(diff.Conflicts, func(i, j int) bool { return diff.Conflicts[i].ItemID < diff.Conflicts[j].ItemID })
+	diff.Mergeable = len(diff.Conflicts) == 0
+	return diff, nil
+}
+
+// designCableMeters returns the drafted length of a cable, or the length of its drawn path
+func designCableMeters(cable *models.CreateCableRequest) float64 {
+	if cable.LengthMeter != nil {
+		return *cable.LengthMeter
+	}
+	return geo.PathLengthMeters(cable.PathCoordinates)
+}
+
+// sortedKeys returns the keys of an ID set in ascending order
+func sortedKeys(set map[int64]bool) []int64 {
+	ids := make([]int64, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// draftRefs returns the IDs of the drafted items an item references
+func draftRefs(item *models.DesignItem) []int64 {
+	var refs []int64
+	add := func(ref *int64) {
+		if ref != nil && *ref < 0 {
+			refs = append(refs, -*ref)
+		}
+	}
+	switch item.ItemType {
+	case models.DesignItemCable:
+		add(item.Cable.OriginNodeID)
+		add(item.Cable.DestNodeID)
+	case models.DesignItemCustomer:
+		add(item.Customer.NodeID)
+	case models.DesignItemSplice:
+		add(item.Splice.LocationNodeID)
+		if item.Splice.InputType == models.ConnectionTypeCore {
+			add(&item.Splice.InputID)
+		}
+		if item.Splice.OutputType == models.ConnectionTypeCore {
+			add(&item.Splice.OutputID)
+		}
+	}
+	return refs
+}
+
+// checkDesignItem validates a drafted item on its own. References to live elements and other
+// drafted items are checked by the diff.
+func checkDesignItem(req *models.DesignItemRequest) error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrInvalidDesignItem, fmt.Sprintf(format, args...))
+	}
+
+	payloads := 0
+	for _, set := range []bool{req.Node != nil, req.Cable != nil, req.Customer != nil, req.Splice != nil} {
+		if set {
+			payloads++
+		}
+	}
+	if payloads != 1 {
+		return invalid("exactly one of node, cable, customer or splice must be set")
+	}
+
+	switch req.ItemType {
+	case models.DesignItemNode:
+		n := req.Node
+		if n == nil {
+			return invalid("NODE items need a node")
+		}
+		if strings.TrimSpace(n.Name) == "" {
+			return invalid("node name is required")
+		}
+		if !n.Type.IsValid() {
+			return invalid("unknown node type %q", n.Type)
+		}
+		if n.Status != "" && !n.Status.IsValid() {
+			return invalid("unknown node status %q", n.Status)
+		}
+		if n.Latitude < -90 || n.Latitude > 90 || n.Longitude < -180 || n.Longitude > 180 {
+			return invalid("node coordinates are out of range")
+		}
+
+	case models.DesignItemCable:
+		c := req.Cable
+		if c == nil {
+			return invalid("CABLE items need a cable")
+		}
+		if !c.Type.IsValid() {
+			return invalid("unknown cable type %q", c.Type)
+		}
+		if c.CoreCount < 1 || c.CoreCount > 288 {
+			return invalid("core count must be between 1 and 288")
+		}
+		if c.Status != "" && !c.Status.IsValid() {
+			return invalid("unknown cable status %q", c.Status)
+		}
+		if _, err := encodePathCoordinates(c.PathCoordinates); err != nil {
+			return invalid("%v", err)
+		}
+
+	case models.DesignItemCustomer:
+		c := req.Customer
+		if c == nil {
+			return invalid("CUSTOMER items need a customer")
+		}
+		if strings.TrimSpace(c.Name) == "" {
+			return invalid("customer name is required")
+		}
+
+	case models.DesignItemSplice:
+		s := req.Splice
+		if s == nil {
+			return invalid("SPLICE items need a splice")
+		}
+		endpoints := []struct {
+			side      string
+			kind      models.ConnectionType
+			id        int64
+			coreIndex *int
+		}{{"input", s.InputType, s.InputID, s.InputCoreIndex}, {"output", s.OutputType, s.OutputID, s.OutputCoreIndex}}
+		for _, e := range endpoints {
+			switch {
+			case e.kind != models.ConnectionTypeCore && e.kind != models.ConnectionTypePort:
+				return invalid("%s_type must be CORE or PORT", e.side)
+			case e.id == 0:
+				return invalid("%s_id is required", e.side)
+			case e.id < 0 && e.kind != models.ConnectionTypeCore:
+				return invalid("only cores of drafted cables can be referenced by a negative %s_id", e.side)
+			case e.id < 0 && (e.coreIndex == nil || *e.coreIndex < 1):
+				return invalid("%s_core_index is required for a drafted cable", e.side)
+			}
+		}
+		if s.HasUnlocatedPort() {
+			return invalid("location_node_id is required for PORT endpoints")
+		}
+		if s.InputType == s.OutputType && s.InputID == s.OutputID &&
+			(s.InputID > 0 || *s.InputCoreIndex == *s.OutputCoreIndex) {
+			return invalid("a splice cannot join an endpoint to itself")
+		}
+
+	default:
+		return invalid("unknown item type %q", req.ItemType)
+	}
+
+	return nil
+}
+
+// encodeDesignPayload encodes the payload of a drafted item for the payload column
+func encodeDesignPayload(req *models.DesignItemRequest) ([]byte, error) {
+	var payload interface{}
+	switch req.ItemType {
+	case models.DesignItemNode:
+		payload = req.Node
+	case models.DesignItemCable:
+		payload = req.Cable
+	case models.DesignItemCustomer:
+		payload = req.Customer
+	case models.DesignItemSplice:
+		payload = req.Splice
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode design item: %w", err)
+	}
+	return data, nil
+}
+
+// scanDesignItem scans a design_items row and decodes its payload
+func scanDesignItem(row pgx.Row) (*models.DesignItem, error) {
+	item := &models.DesignItem{}
+	var payload []byte
+	if err := row.Scan(&item.ID, &item.WorkspaceID, &item.ItemType, &payload, &item.LiveID, &item.CreatedAt, &item.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	var target interface{}
+	switch item.ItemType {
+	case models.DesignItemNode:
+		item.Node = &models.CreateNodeRequest{}
+		target = item.Node
+	case models.DesignItemCable:
+		item.Cable = &models.CreateCableRequest{}
+		target = item.Cable
+	case models.DesignItemCustomer:
+		item.Customer = &models.CreateCustomerRequest{}
+		target = item.Customer
+	case models.DesignItemSplice:
+		item.Splice = &models.DesignSplice{}
+		target = item.Splice
+	default:
+		return nil, fmt.Errorf("unknown design item type %q", item.ItemType)
+	}
+	if err := json.Unmarshal(payload, target); err != nil {
+		return nil, fmt.Errorf("failed to decode design item %d: %w", item.ID, err)
+	}
+
+	return item, nil
+}
+
+// listDesignItems retrieves the items of a workspace in the order they were drafted
+func listDesignItems(ctx context.Context, q querier, workspaceID int64) ([]models.DesignItem, error) {
+	rows, err := q.Query(ctx, "SELECT "+designItemColumns+" FROM design_items WHERE workspace_id = $1 ORDER BY id", workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list design items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []models.DesignItem{}
+	for rows.Next() {
+		item, err := scanDesignItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan design item: %w", err)
+		}
+		items = append(items, *item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list design items: %w", err)
+	}
+
+	return items, nil
+}
+
+// lockDraftWorkspace locks a workspace whose items are about to change
+func lockDraftWorkspace(ctx context.Context, q querier, id int64) (*models.DesignWorkspace, error) {
+	workspace, err := lockWorkspace(ctx, q, id)
+	if err != nil {
+		return nil, err
+	}
+	if workspace.Status != models.WorkspaceStatusDraft {
+		return nil, fmt.Errorf("%w: the workspace is %s", ErrWorkspaceNotDraft, workspace.Status)
+	}
+	return workspace, nil
+}
+
+// lockWorkspace locks a workspace row for update and returns it without its items
+func lockWorkspace(ctx context.Context, q querier, id int64) (*models.DesignWorkspace, error) {
+	workspaces, err := listWorkspaces(ctx, q, " WHERE w.id = $1 FOR UPDATE OF w", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(workspaces) == 0 {
+		return nil, ErrWorkspaceNotFound
+	}
+	return &workspaces[0], nil
+}
+
+// getWorkspace retrieves a workspace with its items, or nil
+func getWorkspace(ctx context.Context, q querier, id int64) (*models.DesignWorkspace, error) {
+	workspaces, err := listWorkspaces(ctx, q, " WHERE w.id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(workspaces) == 0 {
+		return nil, nil
+	}
+	workspace := &workspaces[0]
+
+	if workspace.Items, err = listDesignItems(ctx, q, id); err != nil {
+		return nil, err
+	}
+
+	return workspace, nil
+}
+
+// listWorkspaces runs workspaceSelect with the given clause
+func listWorkspaces(ctx context.Context, q querier, clause string, args ...interface{}) ([]models.DesignWorkspace, error) {
+	rows, err := q.Query(ctx, workspaceSelect+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+	defer rows.Close()
+
+	workspaces := []models.DesignWorkspace{}
+	for rows.Next() {
+		var w models.DesignWorkspace
+		err := rows.Scan(
+			&w.ID,
+			&w.Name,
+			&w.Description,
+			&w.Status,
+			&w.CreatedBy,
+			&w.SubmittedBy,
+			&w.SubmittedAt,
+			&w.ReviewedBy,
+			&w.ReviewedAt,
+			&w.ReviewNote,
+			&w.MergedBy,
+			&w.MergedAt,
+			&w.CreatedAt,
+			&w.UpdatedAt,
+			&w.ItemCount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan workspace: %w", err)
+		}
+		workspaces = append(workspaces, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+
+	return workspaces, nil
+}
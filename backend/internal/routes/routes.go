@@ -1,11 +1,14 @@
 package routes
 
 import (
+	"context"
 	"net/http"
 
 	"spectra-backend/internal/handlers"
+	"spectra-backend/internal/jobs"
 	"spectra-backend/internal/middleware"
 	"spectra-backend/internal/repository"
+	"spectra-backend/internal/topology"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -37,8 +40,9 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	r.mux.ServeHTTP(w, req)
 }
 
-// SetupRoutes configures all API routes
-func SetupRoutes(pool *pgxpool.Pool) http.Handler {
+// SetupRoutes configures all API routes and starts the background jobs they depend on,
+// which stop when ctx is cancelled. operatorKey is the bearer token that authenticates the operator.
+func SetupRoutes(ctx context.Context, pool *pgxpool.Pool, operatorKey string) http.Handler {
 	mux := http.NewServeMux()
 
 	// Initialize repositories
@@ -46,12 +50,49 @@ func SetupRoutes(pool *pgxpool.Pool) http.Handler {
 	cableRepo := repository.NewCableRepository(pool)
 	customerRepo := repository.NewCustomerRepository(pool)
 	connectionRepo := repository.NewConnectionRepository(pool)
+	cableSpanRepo := repository.NewCableSpanRepository(pool)
+	colorSchemeRepo := repository.NewColorSchemeRepository(pool)
+	importRepo := repository.NewImportRepository(pool)
+	topologyRepo := repository.NewTopologyRepository(pool)
+	reservationRepo := repository.NewReservationRepository(pool)
+	otdrRepo := repository.NewOTDRRepository(pool)
+	ticketRepo := repository.NewTicketRepository(pool)
+	workOrderRepo := repository.NewWorkOrderRepository(pool)
+	maintenanceRepo := repository.NewMaintenanceRepository(pool)
+	workspaceRepo := repository.NewWorkspaceRepository(pool)
+	priceCatalogRepo := repository.NewPriceCatalogRepository(pool)
+	dropRepo := repository.NewDropRepository(pool)
+	areaRepo := repository.NewAreaRepository(pool)
+	tenantRepo := repository.NewTenantRepository(pool)
+
+	// Initialize services
+	topologyService := topology.NewService(topologyRepo)
+
+	// Start background jobs
+	go jobs.NewReservationSweeper(reservationRepo, jobs.DefaultSweepInterval, topologyService.Invalidate).Run(ctx)
+	go jobs.NewMaintenanceScheduler(maintenanceRepo, topologyService, jobs.DefaultSweepInterval).Run(ctx)
 
 	// Initialize handlers
 	nodeHandler := handlers.NewNodeHandler(nodeRepo)
 	cableHandler := handlers.NewCableHandler(cableRepo)
 	customerHandler := handlers.NewCustomerHandler(customerRepo)
 	connectionHandler := handlers.NewConnectionHandler(connectionRepo)
+	cableSpanHandler := handlers.NewCableSpanHandler(cableSpanRepo)
+	colorSchemeHandler := handlers.NewColorSchemeHandler(colorSchemeRepo)
+	importExportHandler := handlers.NewImportExportHandler(importRepo, nodeRepo, cableRepo, customerRepo, areaRepo)
+	topologyHandler := handlers.NewTopologyHandler(topologyService)
+	fiberPathHandler := handlers.NewFiberPathHandler(topologyService, reservationRepo)
+	reservationHandler := handlers.NewReservationHandler(reservationRepo)
+	otdrHandler := handlers.NewOTDRHandler(otdrRepo, topologyService)
+	ticketHandler := handlers.NewTicketHandler(ticketRepo)
+	workOrderHandler := handlers.NewWorkOrderHandler(workOrderRepo)
+	maintenanceHandler := handlers.NewMaintenanceHandler(maintenanceRepo, topologyService)
+	workspaceHandler := handlers.NewWorkspaceHandler(workspaceRepo, priceCatalogRepo)
+	bomHandler := handlers.NewBOMHandler(priceCatalogRepo, workspaceRepo, topologyService)
+	serviceabilityHandler := handlers.NewServiceabilityHandler(nodeRepo, topologyService)
+	dropHandler := handlers.NewDropHandler(dropRepo, topologyService)
+	areaHandler := handlers.NewAreaHandler(areaRepo)
+	tenantHandler := handlers.NewTenantHandler(tenantRepo, operatorKey)
 
 	// Health check
 	mux.HandleFunc("GET /api/health", func(w http.ResponseWriter, r *http.Request) {
@@ -73,8 +114,29 @@ func SetupRoutes(pool *pgxpool.Pool) http.Handler {
 	mux.HandleFunc("GET /api/cables/{id}", cableHandler.GetByID)
 	mux.HandleFunc("PUT /api/cables/{id}", cableHandler.Update)
 	mux.HandleFunc("DELETE /api/cables/{id}", cableHandler.Delete)
+	mux.HandleFunc("POST /api/cables/{id}/split", cableHandler.Split)
+	mux.HandleFunc("GET /api/cables/{id}/resize-preview", cableHandler.PreviewResize)
+	mux.HandleFunc("GET /api/cables/{id}/locate", topologyHandler.LocateOnCable)
+	mux.HandleFunc("GET /api/cables/{id}/measure", topologyHandler.MeasureOnCable)
 	mux.HandleFunc("GET /api/cables/{id}/cores", cableHandler.GetCores)
 	mux.HandleFunc("PUT /api/cables/{id}/cores/{coreId}", cableHandler.UpdateCore)
+	mux.HandleFunc("PUT /api/cables/{id}/color-scheme", colorSchemeHandler.ApplyToCable)
+
+	// Cable span routes (physical route and express/breakout cores)
+	mux.HandleFunc("GET /api/cables/{id}/spans", cableSpanHandler.GetRoute)
+	mux.HandleFunc("PUT /api/cables/{id}/spans", cableSpanHandler.ReplaceSpans)
+	mux.HandleFunc("GET /api/cables/{id}/cores/{coreId}/segments", cableSpanHandler.GetCoreSegments)
+	mux.HandleFunc("GET /api/cables/{id}/cores/{coreId}/breakouts", cableSpanHandler.ListBreakouts)
+	mux.HandleFunc("POST /api/cables/{id}/cores/{coreId}/breakouts", cableSpanHandler.CreateBreakout)
+	mux.HandleFunc("DELETE /api/cables/{id}/cores/{coreId}/breakouts/{breakoutId}", cableSpanHandler.DeleteBreakout)
+
+	// Color scheme routes
+	mux.HandleFunc("GET /api/color-schemes", colorSchemeHandler.List)
+	mux.HandleFunc("POST /api/color-schemes", colorSchemeHandler.Create)
+	mux.HandleFunc("GET /api/color-schemes/{code}", colorSchemeHandler.GetByCode)
+	mux.HandleFunc("PUT /api/color-schemes/{code}", colorSchemeHandler.Update)
+	mux.HandleFunc("DELETE /api/color-schemes/{code}", colorSchemeHandler.Delete)
+	mux.HandleFunc("GET /api/color-schemes/{code}/preview", colorSchemeHandler.Preview)
 
 	// Connection routes
 	mux.HandleFunc("GET /api/connections", connectionHandler.List)
@@ -83,6 +145,7 @@ func SetupRoutes(pool *pgxpool.Pool) http.Handler {
 	mux.HandleFunc("DELETE /api/connections/{id}", connectionHandler.Delete)
 	mux.HandleFunc("GET /api/connections/matrix/{nodeId}", connectionHandler.GetSpliceMatrix)
 	mux.HandleFunc("GET /api/connections/location/{nodeId}", connectionHandler.GetByLocation)
+	mux.HandleFunc("GET /api/connections/trace/{coreId}", connectionHandler.TraceCore)
 
 	// Customer routes
 	mux.HandleFunc("GET /api/customers", customerHandler.List)
@@ -97,6 +160,119 @@ func SetupRoutes(pool *pgxpool.Pool) http.Handler {
 	mux.HandleFunc("GET /api/geojson/nodes", nodeHandler.GetGeoJSON)
 	mux.HandleFunc("GET /api/geojson/cables", cableHandler.GetGeoJSON)
 
+	// Import / export routes
+	mux.HandleFunc("POST /api/import/kml", importExportHandler.ImportKML)
+	mux.HandleFunc("POST /api/import/geojson", importExportHandler.ImportGeoJSON)
+	mux.HandleFunc("POST /api/import/nodes", importExportHandler.ImportNodesSheet)
+	mux.HandleFunc("POST /api/import/customers", importExportHandler.ImportCustomersSheet)
+	mux.HandleFunc("GET /api/export/kmz", importExportHandler.ExportKMZ)
+	mux.HandleFunc("GET /api/export/gpkg", importExportHandler.ExportGeoPackage)
+	mux.HandleFunc("GET /api/export/shapefile", importExportHandler.ExportShapefile)
+
+	// Topology routes
+	mux.HandleFunc("GET /api/topology", topologyHandler.Get)
+	mux.HandleFunc("GET /api/topology/graphml", topologyHandler.ExportGraphML)
+	mux.HandleFunc("GET /api/topology/dot", topologyHandler.ExportDOT)
+	mux.HandleFunc("POST /api/impact", topologyHandler.Impact)
+	mux.HandleFunc("POST /api/coverage", topologyHandler.Coverage)
+	mux.HandleFunc("GET /api/redundancy/diversity", topologyHandler.Diversity)
+	mux.HandleFunc("GET /api/redundancy/feeds", topologyHandler.FeedDependencies)
+
+	// Fiber path routes
+	mux.HandleFunc("POST /api/fiber-paths", fiberPathHandler.Find)
+
+	// Reservation routes
+	mux.HandleFunc("GET /api/reservations", reservationHandler.List)
+	mux.HandleFunc("POST /api/reservations", reservationHandler.Create)
+	mux.HandleFunc("GET /api/reservations/{id}", reservationHandler.GetByID)
+	mux.HandleFunc("POST /api/reservations/{id}/extend", reservationHandler.Extend)
+	mux.HandleFunc("POST /api/reservations/{id}/release", reservationHandler.Release)
+
+	// OTDR routes
+	mux.HandleFunc("GET /api/otdr/traces", otdrHandler.List)
+	mux.HandleFunc("POST /api/otdr/traces", otdrHandler.Upload)
+	mux.HandleFunc("GET /api/otdr/traces/{id}", otdrHandler.GetByID)
+	mux.HandleFunc("GET /api/otdr/traces/{id}/file", otdrHandler.Download)
+	mux.HandleFunc("DELETE /api/otdr/traces/{id}", otdrHandler.Delete)
+	mux.HandleFunc("GET /api/otdr/locate", otdrHandler.Locate)
+
+	// Ticket routes
+	mux.HandleFunc("GET /api/tickets", ticketHandler.List)
+	mux.HandleFunc("POST /api/tickets", ticketHandler.Create)
+	mux.HandleFunc("GET /api/tickets/{id}", ticketHandler.GetByID)
+	mux.HandleFunc("PUT /api/tickets/{id}", ticketHandler.Update)
+	mux.HandleFunc("POST /api/tickets/{id}/assign", ticketHandler.Assign)
+	mux.HandleFunc("POST /api/tickets/{id}/status", ticketHandler.ChangeStatus)
+	mux.HandleFunc("POST /api/tickets/{id}/comments", ticketHandler.AddComment)
+	mux.HandleFunc("POST /api/tickets/{id}/links", ticketHandler.AddLinks)
+	mux.HandleFunc("DELETE /api/tickets/{id}/links/{linkId}", ticketHandler.DeleteLink)
+
+	// Work order routes
+	mux.HandleFunc("GET /api/work-orders", workOrderHandler.List)
+	mux.HandleFunc("POST /api/work-orders", workOrderHandler.Create)
+	mux.HandleFunc("GET /api/work-orders/{id}", workOrderHandler.GetByID)
+	mux.HandleFunc("PUT /api/work-orders/{id}", workOrderHandler.Update)
+	mux.HandleFunc("PATCH /api/work-orders/{id}/tasks/{taskId}", workOrderHandler.UpdateTask)
+	mux.HandleFunc("POST /api/work-orders/{id}/status", workOrderHandler.ChangeStatus)
+	mux.HandleFunc("POST /api/work-orders/{id}/complete", workOrderHandler.Complete)
+
+	// Maintenance window routes
+	mux.HandleFunc("GET /api/maintenance-windows", maintenanceHandler.List)
+	mux.HandleFunc("POST /api/maintenance-windows", maintenanceHandler.Create)
+	mux.HandleFunc("GET /api/maintenance-windows/{id}", maintenanceHandler.GetByID)
+	mux.HandleFunc("PUT /api/maintenance-windows/{id}", maintenanceHandler.Update)
+	mux.HandleFunc("GET /api/maintenance-windows/{id}/customers", maintenanceHandler.Customers)
+	mux.HandleFunc("POST /api/maintenance-windows/{id}/start", maintenanceHandler.Start)
+	mux.HandleFunc("POST /api/maintenance-windows/{id}/end", maintenanceHandler.End)
+	mux.HandleFunc("POST /api/maintenance-windows/{id}/cancel", maintenanceHandler.Cancel)
+
+	// Design workspace routes
+	mux.HandleFunc("GET /api/workspaces", workspaceHandler.List)
+	mux.HandleFunc("POST /api/workspaces", workspaceHandler.Create)
+	mux.HandleFunc("GET /api/workspaces/{id}", workspaceHandler.GetByID)
+	mux.HandleFunc("PUT /api/workspaces/{id}", workspaceHandler.Update)
+	mux.HandleFunc("GET /api/workspaces/{id}/diff", workspaceHandler.Diff)
+	mux.HandleFunc("POST /api/workspaces/{id}/items", workspaceHandler.AddItem)
+	mux.HandleFunc("PUT /api/workspaces/{id}/items/{itemId}", workspaceHandler.UpdateItem)
+	mux.HandleFunc("DELETE /api/workspaces/{id}/items/{itemId}", workspaceHandler.DeleteItem)
+	mux.HandleFunc("POST /api/workspaces/{id}/submit", workspaceHandler.Submit)
+	mux.HandleFunc("POST /api/workspaces/{id}/approve", workspaceHandler.Approve)
+	mux.HandleFunc("POST /api/workspaces/{id}/reject", workspaceHandler.Reject)
+	mux.HandleFunc("POST /api/workspaces/{id}/merge", workspaceHandler.Merge)
+	mux.HandleFunc("POST /api/workspaces/{id}/discard", workspaceHandler.Discard)
+
+	// Bill of materials routes
+	mux.HandleFunc("GET /api/bom", bomHandler.Generate)
+	mux.HandleFunc("GET /api/price-catalog", bomHandler.ListCatalog)
+	mux.HandleFunc("POST /api/price-catalog", bomHandler.CreateCatalogItem)
+	mux.HandleFunc("PUT /api/price-catalog/{id}", bomHandler.UpdateCatalogItem)
+	mux.HandleFunc("DELETE /api/price-catalog/{id}", bomHandler.DeleteCatalogItem)
+
+	// Serviceability routes
+	mux.HandleFunc("GET /api/serviceability", serviceabilityHandler.Check)
+
+	// Drop design routes
+	mux.HandleFunc("POST /api/drops/preview", dropHandler.Preview)
+	mux.HandleFunc("POST /api/drops", dropHandler.Commit)
+
+	// Service area routes
+	mux.HandleFunc("GET /api/areas", areaHandler.List)
+	mux.HandleFunc("POST /api/areas", areaHandler.Create)
+	mux.HandleFunc("POST /api/areas/assign", areaHandler.Assign)
+	mux.HandleFunc("GET /api/areas/{id}", areaHandler.GetByID)
+	mux.HandleFunc("PUT /api/areas/{id}", areaHandler.Update)
+	mux.HandleFunc("DELETE /api/areas/{id}", areaHandler.Delete)
+	mux.HandleFunc("GET /api/areas/{id}/stats", areaHandler.Stats)
+
+	// Tenant routes
+	mux.HandleFunc("GET /api/tenants", tenantHandler.List)
+	mux.HandleFunc("POST /api/tenants", tenantHandler.Create)
+	mux.HandleFunc("GET /api/tenants/current", tenantHandler.Current)
+	mux.HandleFunc("POST /api/tenants/assign", tenantHandler.Assign)
+	mux.HandleFunc("GET /api/tenants/{id}", tenantHandler.GetByID)
+	mux.HandleFunc("PUT /api/tenants/{id}", tenantHandler.Update)
+	mux.HandleFunc("DELETE /api/tenants/{id}", tenantHandler.Delete)
+
 	// Apply middleware
 	handler := middleware.Chain(
 		mux,
@@ -104,6 +280,8 @@ func SetupRoutes(pool *pgxpool.Pool) http.Handler {
 		middleware.Logger,
 		middleware.CORS,
 		middleware.ContentType,
+		topologyService.TrackWrites,
+		tenantHandler.Scope,
 	)
 
 	return handler
@@ -0,0 +1,224 @@
+package tabular
+
+import (
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"spectra-backend/internal/models"
+)
+
+// NodeFields are the columns of a node sheet
+var NodeFields = []Field{
+	{Name: "name", Aliases: []string{"nama", "node_name"}, Required: true},
+	{Name: "type", Aliases: []string{"node_type", "jenis"}, Required: true},
+	{Name: "latitude", Aliases: []string{"lat"}, Required: true},
+	{Name: "longitude", Aliases: []string{"lng", "lon", "long"}, Required: true},
+	{Name: "capacity_ports", Aliases: []string{"capacity", "ports", "kapasitas"}},
+	{Name: "address", Aliases: []string{"alamat"}},
+	{Name: "model"},
+	{Name: "status"},
+}
+
+// CustomerFields are the columns of a customer sheet
+var CustomerFields = []Field{
+	{Name: "name", Aliases: []string{"nama", "customer_name"}, Required: true},
+	{Name: "phone", Aliases: []string{"phone_number", "telp", "no_hp", "hp"}},
+	{Name: "email", Aliases: []string{"e_mail"}},
+	{Name: "ont_sn", Aliases: []string{"sn", "ont_serial", "serial_number"}},
+	{Name: "odp", Aliases: []string{"odp_name", "odp_id"}},
+	{Name: "subscription_type", Aliases: []string{"subscription", "package", "paket", "plan"}},
+	{Name: "status", Aliases: []string{"current_status"}},
+}
+
+// MapNodes maps table rows to nodes, returning row-level validation failures separately.
+// The error is only set when the header does not fit the columns.
+func MapNodes(table *Table, overrides map[string]string) ([]models.ImportNode, []models.ImportItemResult, error) {
+	mapping, err := ResolveMapping(table.Header, NodeFields, overrides)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var nodes []models.ImportNode
+	var rejected []models.ImportItemResult
+	for i, record := range table.Rows {
+		if isBlank(record) {
+			continue
+		}
+		ref := fmt.Sprintf("row %d", table.RowNumber(i))
+		name := mapping.Value(record, "name")
+
+		node, problems := mapNodeRow(mapping, record)
+		if len(problems) > 0 {
+			rejected = append(rejected, models.ImportItemResult{
+				Ref:     ref,
+				Kind:    models.ImportItemNode,
+				Action:  models.ImportActionError,
+				Name:    name,
+				Type:    strings.ToUpper(mapping.Value(record, "type")),
+				Message: strings.Join(problems, "; "),
+			})
+			continue
+		}
+		nodes = append(nodes, models.ImportNode{Ref: ref, Node: *node})
+	}
+
+	return nodes, rejected, nil
+}
+
+func mapNodeRow(mapping Mapping, record []string) (*models.CreateNodeRequest, []string) {
+	var problems []string
+	req := &models.CreateNodeRequest{
+		Name: mapping.Value(record, "name"),
+		Type: models.NodeType(strings.ToUpper(mapping.Value(record, "type"))),
+	}
+
+	if req.Name == "" {
+		problems = append(problems, "name is required")
+	} else if len(req.Name) > 100 {
+		problems = append(problems, "name is longer than 100 characters")
+	}
+	if !req.Type.IsValid() {
+		problems = append(problems, fmt.Sprintf("invalid node type %q", mapping.Value(record, "type")))
+	}
+
+	lat, latErr := parseCoordinate(mapping.Value(record, "latitude"))
+	lng, lngErr := parseCoordinate(mapping.Value(record, "longitude"))
+	switch {
+	case latErr != nil:
+		problems = append(problems, "latitude "+latErr.Error())
+	case lat < -90 || lat > 90:
+		problems = append(problems, "latitude out of range")
+	}
+	switch {
+	case lngErr != nil:
+		problems = append(problems, "longitude "+lngErr.Error())
+	case lng < -180 || lng > 180:
+		problems = append(problems, "longitude out of range")
+	}
+	if latErr == nil && lngErr == nil && lat == 0 && lng == 0 {
+		problems = append(problems, "coordinates are 0,0")
+	}
+	req.Latitude, req.Longitude = lat, lng
+
+	if value := mapping.Value(record, "capacity_ports"); value != "" {
+		capacity, err := strconv.Atoi(value)
+		if err != nil || capacity < 0 {
+			problems = append(problems, fmt.Sprintf("invalid capacity %q", value))
+		} else {
+			req.CapacityPorts = &capacity
+		}
+	}
+	if value := mapping.Value(record, "status"); value != "" {
+		req.Status = models.NodeStatus(strings.ToUpper(value))
+		if !req.Status.IsValid() {
+			problems = append(problems, fmt.Sprintf("invalid status %q", value))
+		}
+	}
+	if value := mapping.Value(record, "address"); value != "" {
+		req.Address = &value
+	}
+	if value := mapping.Value(record, "model"); value != "" {
+		req.Model = &value
+	}
+
+	return req, problems
+}
+
+// MapCustomers maps table rows to customers, returning row-level validation failures separately.
+// Checks that need the database (duplicate ONT SN, unknown ODP) are left to the repository.
+func MapCustomers(table *Table, overrides map[string]string) ([]models.CustomerImportRow, []models.ImportItemResult, error) {
+	mapping, err := ResolveMapping(table.Header, CustomerFields, overrides)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var customers []models.CustomerImportRow
+	var rejected []models.ImportItemResult
+	for i, record := range table.Rows {
+		if isBlank(record) {
+			continue
+		}
+		ref := fmt.Sprintf("row %d", table.RowNumber(i))
+
+		row, problems := mapCustomerRow(mapping, record)
+		row.Ref = ref
+		if len(problems) > 0 {
+			rejected = append(rejected, models.ImportItemResult{
+				Ref:     ref,
+				Kind:    models.ImportItemCustomer,
+				Action:  models.ImportActionError,
+				Name:    row.Customer.Name,
+				Message: strings.Join(problems, "; "),
+			})
+			continue
+		}
+		customers = append(customers, *row)
+	}
+
+	return customers, rejected, nil
+}
+
+func mapCustomerRow(mapping Mapping, record []string) (*models.CustomerImportRow, []string) {
+	var problems []string
+	row := &models.CustomerImportRow{
+		ODPName: mapping.Value(record, "odp"),
+		Customer: models.CreateCustomerRequest{
+			Name: mapping.Value(record, "name"),
+		},
+	}
+	req := &row.Customer
+
+	if req.Name == "" {
+		problems = append(problems, "name is required")
+	} else if len(req.Name) > 100 {
+		problems = append(problems, "name is longer than 100 characters")
+	}
+
+	if value := mapping.Value(record, "ont_sn"); value != "" {
+		sn := strings.ToUpper(value)
+		if len(sn) > 50 {
+			problems = append(problems, "ont_sn is longer than 50 characters")
+		}
+		req.ONTSN = &sn
+	}
+	if value := mapping.Value(record, "phone"); value != "" {
+		if len(value) > 20 {
+			problems = append(problems, "phone is longer than 20 characters")
+		}
+		req.Phone = &value
+	}
+	if value := mapping.Value(record, "email"); value != "" {
+		if address, err := mail.ParseAddress(value); err != nil || address.Address != value {
+			problems = append(problems, fmt.Sprintf("invalid email %q", value))
+		}
+		req.Email = &value
+	}
+	if value := mapping.Value(record, "subscription_type"); value != "" {
+		req.SubscriptionType = &value
+	}
+	if value := mapping.Value(record, "status"); value != "" {
+		status := models.CustomerStatus(strings.ToUpper(value))
+		switch status {
+		case models.CustomerStatusOnline, models.CustomerStatusOffline, models.CustomerStatusLOS, models.CustomerStatusPowerOff:
+			req.CurrentStatus = &status
+		default:
+			problems = append(problems, fmt.Sprintf("invalid status %q", value))
+		}
+	}
+
+	return row, problems
+}
+
+// parseCoordinate parses a decimal degree, accepting a comma as the decimal separator
+func parseCoordinate(value string) (float64, error) {
+	if value == "" {
+		return 0, fmt.Errorf("is required")
+	}
+	f, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", value)
+	}
+	return f, nil
+}
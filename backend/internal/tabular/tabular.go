@@ -0,0 +1,191 @@
+// Package tabular reads CSV and XLSX spreadsheets and maps their rows to plant import rows,
+// and writes report tables back out in either format.
+package tabular
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Table is a spreadsheet with a header row
+type Table struct {
+	// Format is "CSV" or "XLSX"
+	Format string
+	Header []string
+	// Rows excludes the header
+	Rows [][]string
+	// headerRow is the 1-based spreadsheet row of the header
+	headerRow int
+}
+
+// RowNumber returns the 1-based spreadsheet row number of Rows[i]
+func (t *Table) RowNumber(i int) int {
+	return t.headerRow + i + 1
+}
+
+// Read parses CSV or XLSX data, detecting XLSX by its zip signature.
+// For XLSX the named sheet is read, or the first sheet when sheet is empty.
+func Read(data []byte, sheet string) (*Table, error) {
+	var records [][]string
+	var err error
+	format := "CSV"
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		format = "XLSX"
+		records, err = readXLSX(data, sheet)
+	} else {
+		records, err = readCSV(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Leading blank rows are common in hand-made sheets
+	headerRow := 1
+	for len(records) > 0 && isBlank(records[0]) {
+		records = records[1:]
+		headerRow++
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("spreadsheet is empty")
+	}
+
+	return &Table{Format: format, Header: records[0], Rows: records[1:], headerRow: headerRow}, nil
+}
+
+func readXLSX(data []byte, sheet string) ([][]string, error) {
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid XLSX file: %w", err)
+	}
+	defer f.Close()
+
+	if sheet == "" {
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, fmt.Errorf("XLSX file has no sheets")
+		}
+		sheet = sheets[0]
+	}
+
+	rows, err := f.GetRows(sheet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sheet %q: %w", sheet, err)
+	}
+	return rows, nil
+}
+
+// readCSV parses comma- or semicolon-separated data; the delimiter is taken from the header line
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	headerLine := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		headerLine = data[:i]
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	if bytes.Count(headerLine, []byte(";")) > bytes.Count(headerLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV file: %w", err)
+	}
+	return records, nil
+}
+
+func isBlank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Field describes a target field and the header names it is recognised by
+type Field struct {
+	Name     string
+	Aliases  []string
+	Required bool
+}
+
+// Mapping maps field names to column indexes
+type Mapping map[string]int
+
+// Value returns the trimmed value of a field in a record, or "" when unmapped or missing
+func (m Mapping) Value(record []string, field string) string {
+	index, ok := m[field]
+	if !ok || index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[index])
+}
+
+// ResolveMapping matches header columns to fields. overrides maps field names to header names and
+// wins over the automatic match, which compares normalized headers with field names and aliases.
+func ResolveMapping(header []string, fields []Field, overrides map[string]string) (Mapping, error) {
+	normalized := make([]string, len(header))
+	for i, h := range header {
+		normalized[i] = normalizeHeader(h)
+	}
+	findColumn := func(name string) int {
+		name = normalizeHeader(name)
+		for i, h := range normalized {
+			if h == name {
+				return i
+			}
+		}
+		return -1
+	}
+
+	known := map[string]bool{}
+	for _, f := range fields {
+		known[f.Name] = true
+	}
+	for field := range overrides {
+		if !known[field] {
+			return nil, fmt.Errorf("unknown field %q in column mapping", field)
+		}
+	}
+
+	mapping := Mapping{}
+	for _, f := range fields {
+		if column, ok := overrides[f.Name]; ok {
+			index := findColumn(column)
+			if index < 0 {
+				return nil, fmt.Errorf("column %q mapped to %s not found", column, f.Name)
+			}
+			mapping[f.Name] = index
+			continue
+		}
+		for _, candidate := range append([]string{f.Name}, f.Aliases...) {
+			if index := findColumn(candidate); index >= 0 {
+				mapping[f.Name] = index
+				break
+			}
+		}
+		if _, ok := mapping[f.Name]; !ok && f.Required {
+			return nil, fmt.Errorf("required column %s not found", f.Name)
+		}
+	}
+
+	return mapping, nil
+}
+
+// normalizeHeader lower-cases a header and joins its words with underscores, so
+// "ONT SN", "ont-sn" and "Ont_SN" all become "ont_sn"
+func normalizeHeader(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, "_")
+}
@@ -0,0 +1,73 @@
+package tabular
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// WriteCSV writes a header and rows as CSV. Nil cells are left empty.
+func WriteCSV(w io.Writer, header []string, rows [][]interface{}) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	for _, row := range rows {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			record[i] = formatCell(cell)
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteXLSX writes a header and rows to a single named sheet. Numbers stay numeric cells.
+func WriteXLSX(w io.Writer, sheet string, header []string, rows [][]interface{}) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName(f.GetSheetName(0), sheet); err != nil {
+		return fmt.Errorf("failed to name sheet: %w", err)
+	}
+	headerRow := make([]interface{}, len(header))
+	for i, name := range header {
+		headerRow[i] = name
+	}
+	if err := f.SetSheetRow(sheet, "A1", &headerRow); err != nil {
+		return fmt.Errorf("failed to write XLSX header: %w", err)
+	}
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+			return fmt.Errorf("failed to write XLSX row %d: %w", i+2, err)
+		}
+	}
+
+	if _, err := f.WriteTo(w); err != nil {
+		return fmt.Errorf("failed to write XLSX: %w", err)
+	}
+	return nil
+}
+
+func formatCell(cell interface{}) string {
+	switch v := cell.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
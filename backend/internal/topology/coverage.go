@@ -0,0 +1,259 @@
+package topology
+
+import (
+	"math"
+
+	"spectra-backend/internal/geo"
+	"spectra-backend/internal/models"
+)
+
+// coverageCircleSegments is the number of sides of the polygons drawn around ODPs
+const coverageCircleSegments = 48
+
+// metersPerDegreeLat is the length of a degree of latitude, used to skip far ODPs cheaply
+const metersPerDegreeLat = 111320.0
+
+// Coverage works out which premises can be served from the ODPs around them.
+//
+// An ODP reaches the premises within the drop-length limit in a straight line. ODPs count when
+// ACTIVE or under MAINTENANCE, and PLAN ones too when asked. A premises at a CUSTOMER node with
+// customers is CONNECTED and credited to the ODP its drop cable comes from, or else the nearest
+// ODP in reach. Other premises are SERVICEABLE from the nearest ODP in reach with free ports,
+// NO_CAPACITY when every ODP in reach is full, and OUT_OF_REACH otherwise.
+func (g *Graph) Coverage(req *models.CoverageRequest) *models.CoverageReport {
+	maxDrop := req.MaxDropMeters
+	if maxDrop <= 0 {
+		maxDrop = models.DefaultMaxDropMeters
+	}
+
+	report := &models.CoverageReport{
+		MaxDropMeters: maxDrop,
+		ODPs:          []models.ODPCoverage{},
+		Results:       []models.PremisesCoverage{},
+	}
+
+	var odps []*models.Node
+	odpIndex := map[int64]int{}
+	for _, id := range sortedIDs(g.Nodes) {
+		n := g.Nodes[id]
+		if n.Type != models.NodeTypeODP {
+			continue
+		}
+		switch n.Status {
+		case models.NodeStatusActive, models.NodeStatusMaintenance:
+		case models.NodeStatusPlan:
+			if !req.IncludePlanned {
+				continue
+			}
+		default:
+			continue
+		}
+		odpIndex[id] = len(odps)
+		odps = append(odps, n)
+		report.ODPs = append(report.ODPs, models.ODPCoverage{
+			NodeID:        id,
+			Name:          n.Name,
+			Latitude:      n.Latitude,
+			Longitude:     n.Longitude,
+			CapacityPorts: n.CapacityPorts,
+			UsedPorts:     n.UsedPorts,
+			FreePorts:     max(0, n.CapacityPorts-n.UsedPorts),
+		})
+	}
+
+	premises := req.Premises
+	if len(premises) == 0 {
+		for _, id := range sortedIDs(g.Nodes) {
+			n := g.Nodes[id]
+			if n.Type == models.NodeTypeCustomer {
+				nodeID := id
+				premises = append(premises, models.Premises{NodeID: &nodeID, Name: &n.Name, Latitude: n.Latitude, Longitude: n.Longitude})
+			}
+		}
+	}
+
+	for _, a := range req.Areas {
+		report.Areas = append(report.Areas, models.AreaCoverage{Name: a.Name})
+	}
+
+	feeders := g.dropFeeders()
+	for _, p := range premises {
+		result := models.PremisesCoverage{Premises: p, Status: models.PremisesOutOfReach}
+
+		var nearest, nearestFree *models.Node
+		var nearestMeters, nearestFreeMeters float64
+		for i, odp := range odps {
+			if math.Abs(odp.Latitude-p.Latitude)*metersPerDegreeLat > maxDrop {
+				continue
+			}
+			d := geo.HaversineMeters(p.Latitude, p.Longitude, odp.Latitude, odp.Longitude)
+			if d > maxDrop {
+				continue
+			}
+			report.ODPs[i].HomesPassed++
+			if nearest == nil || d < nearestMeters {
+				nearest, nearestMeters = odp, d
+			}
+			if report.ODPs[i].FreePorts > 0 && (nearestFree == nil || d < nearestFreeMeters) {
+				nearestFree, nearestFreeMeters = odp, d
+			}
+		}
+
+		var odp *models.Node
+		switch {
+		case p.NodeID != nil && len(g.CustomersByNode[*p.NodeID]) > 0:
+			result.Status = models.PremisesConnected
+			odp = nearest
+			if feeder := g.Nodes[feeders[*p.NodeID].odpID]; feeder != nil {
+				odp = feeder
+			}
+			report.HomesConnected++
+			if i, ok := odpIndex[odpID(odp)]; ok {
+				report.ODPs[i].HomesConnected++
+			}
+		case nearestFree != nil:
+			result.Status = models.PremisesServiceable
+			odp = nearestFree
+			report.Serviceable++
+			report.ODPs[odpIndex[odp.ID]].Demand++
+		case nearest != nil:
+			result.Status = models.PremisesNoCapacity
+			odp = nearest
+			report.NoCapacity++
+		default:
+			report.OutOfReach++
+		}
+		passed := nearest != nil || result.Status == models.PremisesConnected
+		if passed {
+			report.HomesPassed++
+		}
+		if odp != nil {
+			d := math.Round(geo.HaversineMeters(p.Latitude, p.Longitude, odp.Latitude, odp.Longitude)*10) / 10
+			result.ODPID, result.ODPName, result.DropMeters = &odp.ID, &odp.Name, &d
+		}
+
+		for i, a := range req.Areas {
+			if !geo.PointInPolygon(p.Longitude, p.Latitude, a.Polygon) {
+				continue
+			}
+			result.Area = &req.Areas[i].Name
+			area := &report.Areas[i]
+			area.Premises++
+			if passed {
+				area.HomesPassed++
+			}
+			switch result.Status {
+			case models.PremisesConnected:
+				area.HomesConnected++
+			case models.PremisesServiceable:
+				area.Serviceable++
+			case models.PremisesNoCapacity:
+				area.NoCapacity++
+			case models.PremisesOutOfReach:
+				area.OutOfReach++
+			}
+			break
+		}
+
+		report.Results = append(report.Results, result)
+	}
+
+	for i := range report.ODPs {
+		report.ODPs[i].Shortfall = max(0, report.ODPs[i].Demand-report.ODPs[i].FreePorts)
+	}
+	report.Premises = len(report.Results)
+	if report.HomesPassed > 0 {
+		report.Penetration = math.Round(float64(report.HomesConnected)/float64(report.HomesPassed)*1000) / 1000
+	}
+
+	report.GeoJSON = coverageGeoJSON(report)
+	return report
+}
+
+// dropFeed is the drop cable a CUSTOMER node hangs off and the ODP it comes from
+type dropFeed struct {
+	odpID   int64
+	cableID int64
+}
+
+// dropFeeders maps CUSTOMER nodes to the ODP their drop cable comes from
+func (g *Graph) dropFeeders() map[int64]dropFeed {
+	feeders := map[int64]dropFeed{}
+	for _, id := range sortedIDs(g.Cables) {
+		c := g.Cables[id]
+		if c.OriginNodeID == nil || c.DestNodeID == nil {
+			continue
+		}
+		a, b := g.Nodes[*c.OriginNodeID], g.Nodes[*c.DestNodeID]
+		if a == nil || b == nil {
+			continue
+		}
+		if a.Type == models.NodeTypeCustomer {
+			a, b = b, a
+		}
+		if a.Type == models.NodeTypeODP && b.Type == models.NodeTypeCustomer {
+			if _, ok := feeders[b.ID]; !ok {
+				feeders[b.ID] = dropFeed{odpID: a.ID, cableID: id}
+			}
+		}
+	}
+	return feeders
+}
+
+func odpID(n *models.Node) int64 {
+	if n == nil {
+		return 0
+	}
+	return n.ID
+}
+
+// coverageGeoJSON draws the reach of every ODP as a polygon and every premises as a point.
+// Polygons carry a "coverage" property of SERVICEABLE or FULL; points carry their status.
+func coverageGeoJSON(report *models.CoverageReport) models.GeoJSONFeatureCollection {
+	var features []interface{}
+	for _, odp := range report.ODPs {
+		coverage := "SERVICEABLE"
+		if odp.FreePorts == 0 {
+			coverage = "FULL"
+		}
+		features = append(features, map[string]interface{}{
+			"type": "Feature",
+			"geometry": map[string]interface{}{
+				"type":        "Polygon",
+				"coordinates": [][][]float64{geo.Circle(odp.Latitude, odp.Longitude, report.MaxDropMeters, coverageCircleSegments)},
+			},
+			"properties": map[string]interface{}{
+				"coverage":        coverage,
+				"node_id":         odp.NodeID,
+				"name":            odp.Name,
+				"free_ports":      odp.FreePorts,
+				"homes_passed":    odp.HomesPassed,
+				"homes_connected": odp.HomesConnected,
+			},
+		})
+	}
+
+	for _, r := range report.Results {
+		properties := map[string]interface{}{"status": r.Status}
+		if r.Ref != nil {
+			properties["ref"] = *r.Ref
+		}
+		if r.NodeID != nil {
+			properties["node_id"] = *r.NodeID
+		}
+		if r.ODPID != nil {
+			properties["odp_id"] = *r.ODPID
+			properties["drop_meters"] = *r.DropMeters
+		}
+		features = append(features, map[string]interface{}{
+			"type": "Feature",
+			"geometry": map[string]interface{}{
+				"type":        "Point",
+				"coordinates": []float64{r.Longitude, r.Latitude},
+			},
+			"properties": properties,
+		})
+	}
+
+	return models.NewGeoJSONFeatureCollection(features)
+}
@@ -0,0 +1,233 @@
+package topology
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+
+	"spectra-backend/internal/geo"
+	"spectra-backend/internal/models"
+	"spectra-backend/internal/repository"
+)
+
+// premisesPoint stands for the premises among the node IDs of a drop route search
+const premisesPoint int64 = 0
+
+// DropRoute proposes a drop cable route from a node to a premises. It takes the shortest way along
+// POLE nodes that are not INACTIVE where every span, the last one included, is within maxSpan
+// meters. Without such a way the drop runs straight to the premises.
+func (g *Graph) DropRoute(fromNodeID int64, lat, lng, maxSpan float64) (*models.DropRoute, error) {
+	from := g.Nodes[fromNodeID]
+	if from == nil {
+		return nil, &UnknownElementsError{Missing: []string{VertexID(models.TopologyVertexNode, fromNodeID)}}
+	}
+	if maxSpan <= 0 {
+		maxSpan = models.DefaultMaxSpanMeters
+	}
+	straight := geo.HaversineMeters(from.Latitude, from.Longitude, lat, lng)
+
+	// Only poles in an ellipse around the straight line can be on a sensible route
+	points := map[int64][2]float64{
+		fromNodeID:    {from.Latitude, from.Longitude},
+		premisesPoint: {lat, lng},
+	}
+	for _, id := range sortedIDs(g.Nodes) {
+		n := g.Nodes[id]
+		if n.Type != models.NodeTypePole || n.Status == models.NodeStatusInactive || id == fromNodeID {
+			continue
+		}
+		detour := geo.HaversineMeters(from.Latitude, from.Longitude, n.Latitude, n.Longitude) +
+			geo.HaversineMeters(n.Latitude, n.Longitude, lat, lng)
+		if detour <= 2*straight+maxSpan {
+			points[id] = [2]float64{n.Latitude, n.Longitude}
+		}
+	}
+	ids := sortedIDs(points)
+
+	dist := map[int64]float64{fromNodeID: 0}
+	prev := map[int64]int64{}
+	done := map[int64]bool{}
+	queue := &pathQueue{{state: pathState{nodeID: fromNodeID}}}
+	for queue.Len() > 0 {
+		at := heap.Pop(queue).(pathItem).state.nodeID
+		if done[at] {
+			continue
+		}
+		done[at] = true
+		if at == premisesPoint {
+			break
+		}
+		p := points[at]
+		for _, id := range ids {
+			if done[id] {
+				continue
+			}
+			q := points[id]
+			d := dist[at] + geo.HaversineMeters(p[0], p[1], q[0], q[1])
+			if d-dist[at] > maxSpan {
+				continue
+			}
+			if existing, ok := dist[id]; ok && existing <= d {
+				continue
+			}
+			dist[id] = d
+			prev[id] = at
+			heap.Push(queue, pathItem{state: pathState{nodeID: id}, cost: d})
+		}
+	}
+
+	chain := []int64{fromNodeID, premisesPoint}
+	if done[premisesPoint] {
+		chain = []int64{premisesPoint}
+		for at := premisesPoint; at != fromNodeID; {
+			at = prev[at]
+			chain = append([]int64{at}, chain...)
+		}
+	}
+
+	route := &models.DropRoute{
+		FromNodeID:        fromNodeID,
+		PoleIDs:           []int64{},
+		StraightLineMeter: math.Round(straight*10) / 10,
+	}
+	for i, id := range chain {
+		p := points[id]
+		route.PathCoordinates = append(route.PathCoordinates, []float64{p[1], p[0]})
+		if i == 0 {
+			continue
+		}
+		if id != premisesPoint {
+			route.PoleIDs = append(route.PoleIDs, id)
+		}
+		q := points[chain[i-1]]
+		span := geo.HaversineMeters(q[0], q[1], p[0], p[1])
+		route.LengthMeter += span
+		route.LongestSpanMeter = math.Max(route.LongestSpanMeter, span)
+	}
+	route.FollowsPoles = len(route.PoleIDs) > 0
+	route.WithinSpanLimit = route.LongestSpanMeter <= maxSpan
+	route.LengthMeter = math.Round(route.LengthMeter*10) / 10
+	route.LongestSpanMeter = math.Round(route.LongestSpanMeter*10) / 10
+	return route, nil
+}
+
+// DesignDrop proposes a DROP cable from an ODP to a customer node along poles, on the requested or
+// lowest free port. Ports count as taken when a connection at the ODP refers to them or when they are
+// among inUse, the ports connected or reserved at the ODP by any tenant. The design is only a preview;
+// DropRepository.Commit creates it.
+func (g *Graph) DesignDrop(req *models.DropDesignRequest, inUse map[int64]bool) (*models.DropDesign, error) {
+	var missing []string
+	for _, id := range []int64{req.ODPID, req.CustomerNodeID} {
+		if g.Nodes[id] == nil {
+			missing = append(missing, VertexID(models.TopologyVertexNode, id))
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &UnknownElementsError{Missing: missing}
+	}
+	odp, customer := g.Nodes[req.ODPID], g.Nodes[req.CustomerNodeID]
+	if odp.Type != models.NodeTypeODP {
+		return nil, fmt.Errorf("%w: node %d is a %s, not an ODP", repository.ErrInvalidDropDesign, odp.ID, odp.Type)
+	}
+	if customer.Type != models.NodeTypeCustomer {
+		return nil, fmt.Errorf("%w: node %d is a %s, not a CUSTOMER", repository.ErrInvalidDropDesign, customer.ID, customer.Type)
+	}
+	for _, id := range sortedIDs(g.Cables) {
+		c := g.Cables[id]
+		if c.Type != models.CableTypeDrop || c.Status == models.CableStatusInactive {
+			continue
+		}
+		if (c.OriginNodeID != nil && *c.OriginNodeID == customer.ID) || (c.DestNodeID != nil && *c.DestNodeID == customer.ID) {
+			return nil, fmt.Errorf("%w: cable %d", repository.ErrCustomerHasDrop, id)
+		}
+	}
+	coreCount := req.CoreCount
+	if coreCount <= 0 {
+		coreCount = models.DefaultDropCoreCount
+	}
+	maxSpan := req.MaxSpanMeters
+	if maxSpan <= 0 {
+		maxSpan = models.DefaultMaxSpanMeters
+	}
+
+	taken := map[int64]bool{}
+	for port := range inUse {
+		taken[port] = true
+	}
+	for _, c := range g.Connections {
+		if c.LocationNodeID == nil || *c.LocationNodeID != odp.ID {
+			continue
+		}
+		if c.InputType == models.ConnectionTypePort {
+			taken[c.InputID] = true
+		}
+		if c.OutputType == models.ConnectionTypePort {
+			taken[c.OutputID] = true
+		}
+	}
+	freePorts := []int{}
+	if odp.UsedPorts < odp.CapacityPorts {
+		for port := 1; port <= odp.CapacityPorts; port++ {
+			if !taken[int64(port)] {
+				freePorts = append(freePorts, port)
+			}
+		}
+	}
+	if len(freePorts) == 0 {
+		return nil, fmt.Errorf("%w: %d of %d ports used", repository.ErrNoFreePort, odp.UsedPorts, odp.CapacityPorts)
+	}
+
+	port := freePorts[0]
+	if req.Port != nil {
+		port = *req.Port
+		if port < 1 || port > odp.CapacityPorts {
+			return nil, fmt.Errorf("%w: port must be between 1 and %d", repository.ErrInvalidDropDesign, odp.CapacityPorts)
+		}
+		if taken[int64(port)] {
+			return nil, fmt.Errorf("%w: port %d", repository.ErrPortInUse, port)
+		}
+	}
+
+	route, err := g.DropRoute(odp.ID, customer.Latitude, customer.Longitude, maxSpan)
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("DROP %s - %s", odp.Name, customer.Name)
+	if req.Name != nil && *req.Name != "" {
+		name = *req.Name
+	}
+	length := route.LengthMeter
+	odpID, customerID := odp.ID, customer.ID
+	design := &models.DropDesign{
+		ODP:          *odp,
+		CustomerNode: *customer,
+		Route:        *route,
+		Port:         port,
+		FreePorts:    freePorts,
+		Cable: models.CreateCableRequest{
+			Name:            &name,
+			Type:            models.CableTypeDrop,
+			CoreCount:       coreCount,
+			LengthMeter:     &length,
+			OriginNodeID:    &odpID,
+			DestNodeID:      &customerID,
+			PathCoordinates: route.PathCoordinates,
+			Status:          models.CableStatusActive,
+		},
+		Warnings: []string{},
+	}
+
+	if !route.WithinSpanLimit {
+		design.Warnings = append(design.Warnings, fmt.Sprintf("No pole route within %.0f m spans; the drop runs straight with a %.0f m span",
+			maxSpan, route.LongestSpanMeter))
+	}
+	if route.LengthMeter > models.DefaultMaxDropMeters {
+		design.Warnings = append(design.Warnings, fmt.Sprintf("Drop of %.0f m is longer than the usual %.0f m",
+			route.LengthMeter, models.DefaultMaxDropMeters))
+	}
+	if odp.Status != models.NodeStatusActive {
+		design.Warnings = append(design.Warnings, fmt.Sprintf("ODP is %s", odp.Status))
+	}
+	return design, nil
+}
@@ -0,0 +1,193 @@
+package topology
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"spectra-backend/internal/models"
+)
+
+// graphMLKey declares a vertex or edge attribute
+type graphMLKey struct {
+	id       string
+	domain   string // "node" or "edge"
+	name     string
+	attrType string
+}
+
+// WriteGraphML writes a topology graph as GraphML. Every attribute becomes a typed <key>;
+// attributes whose values mix types across elements are declared as strings.
+func WriteGraphML(w io.Writer, g *models.TopologyGraph) error {
+	vertexAttrs := map[string]string{"kind": "string", "label": "string"}
+	for _, v := range g.Vertices {
+		collectAttrTypes(vertexAttrs, v.Attributes)
+	}
+	edgeAttrs := map[string]string{"kind": "string"}
+	for _, e := range g.Edges {
+		collectAttrTypes(edgeAttrs, e.Attributes)
+	}
+
+	keys := map[string]string{}
+	var declared []graphMLKey
+	for _, domain := range []struct {
+		name  string
+		attrs map[string]string
+	}{{"node", vertexAttrs}, {"edge", edgeAttrs}} {
+		for _, name := range sortedKeys(domain.attrs) {
+			key := graphMLKey{id: fmt.Sprintf("%s_%s", domain.name[:1], name), domain: domain.name, name: name, attrType: domain.attrs[name]}
+			keys[domain.name+"|"+name] = key.id
+			declared = append(declared, key)
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(xml.Header)
+	bw.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://graphml.graphdrawing.org/xmlns http://graphml.graphdrawing.org/xmlns/1.0/graphml.xsd">` + "\n")
+	for _, k := range declared {
+		fmt.Fprintf(bw, "  <key id=%q for=%q attr.name=%q attr.type=%q/>\n", k.id, k.domain, k.name, k.attrType)
+	}
+	bw.WriteString(`  <graph id="topology" edgedefault="undirected">` + "\n")
+
+	writeData := func(domain, name string, value interface{}) {
+		fmt.Fprintf(bw, "      <data key=%q>%s</data>\n", keys[domain+"|"+name], escapeXML(formatValue(value)))
+	}
+
+	for _, v := range g.Vertices {
+		fmt.Fprintf(bw, "    <node id=\"%s\">\n", escapeXML(v.ID))
+		writeData("node", "kind", string(v.Kind))
+		writeData("node", "label", v.Label)
+		for _, name := range sortedKeys(v.Attributes) {
+			writeData("node", name, v.Attributes[name])
+		}
+		bw.WriteString("    </node>\n")
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(bw, "    <edge id=\"%s\" source=\"%s\" target=\"%s\">\n", escapeXML(e.ID), escapeXML(e.Source), escapeXML(e.Target))
+		writeData("edge", "kind", string(e.Kind))
+		for _, name := range sortedKeys(e.Attributes) {
+			writeData("edge", name, e.Attributes[name])
+		}
+		bw.WriteString("    </edge>\n")
+	}
+
+	bw.WriteString("  </graph>\n</graphml>\n")
+	return bw.Flush()
+}
+
+// dotShapes are the Graphviz shapes of each vertex kind
+var dotShapes = map[models.TopologyVertexKind]string{
+	models.TopologyVertexNode:     "box",
+	models.TopologyVertexCable:    "ellipse",
+	models.TopologyVertexCore:     "point",
+	models.TopologyVertexPort:     "diamond",
+	models.TopologyVertexCustomer: "house",
+}
+
+// dotEdgeStyles are the Graphviz styles of each edge kind
+var dotEdgeStyles = map[models.TopologyEdgeKind]string{
+	models.TopologyEdgeCableEnd:  "solid",
+	models.TopologyEdgeCablePass: "dashed",
+	models.TopologyEdgeCoreOf:    "dotted",
+	models.TopologyEdgeBreakout:  "dotted",
+	models.TopologyEdgeSplice:    "bold",
+	models.TopologyEdgePortAt:    "dotted",
+	models.TopologyEdgeServes:    "solid",
+}
+
+// WriteDOT writes a topology graph as an undirected Graphviz graph, with nodes and cables
+// colored by the map symbology
+func WriteDOT(w io.Writer, g *models.TopologyGraph) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("graph topology {\n")
+	bw.WriteString("  graph [overlap=false, splines=true];\n")
+	bw.WriteString("  node [fontname=\"Helvetica\", fontsize=10];\n")
+	bw.WriteString("  edge [fontname=\"Helvetica\", fontsize=8];\n")
+
+	for _, v := range g.Vertices {
+		attrs := []string{
+			"label=" + quoteDOT(v.Label),
+			"shape=" + dotShapes[v.Kind],
+		}
+		if color := vertexColor(v); color != "" {
+			attrs = append(attrs, "color="+quoteDOT(color))
+		}
+		fmt.Fprintf(bw, "  %s [%s];\n", quoteDOT(v.ID), strings.Join(attrs, ", "))
+	}
+	for _, e := range g.Edges {
+		attrs := []string{"style=" + dotEdgeStyles[e.Kind]}
+		if loss, ok := e.Attributes["loss_db"].(float64); ok {
+			attrs = append(attrs, "label="+quoteDOT(strconv.FormatFloat(loss, 'f', 2, 64)+" dB"))
+		}
+		fmt.Fprintf(bw, "  %s -- %s [%s];\n", quoteDOT(e.Source), quoteDOT(e.Target), strings.Join(attrs, ", "))
+	}
+
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+func vertexColor(v models.TopologyVertex) string {
+	t, _ := v.Attributes["type"].(string)
+	switch v.Kind {
+	case models.TopologyVertexNode:
+		return models.NodeTypeColors[models.NodeType(t)]
+	case models.TopologyVertexCable:
+		return models.CableTypeColors[models.CableType(t)]
+	}
+	return ""
+}
+
+func collectAttrTypes(types map[string]string, attrs map[string]interface{}) {
+	for name, value := range attrs {
+		t := graphMLType(value)
+		if existing, ok := types[name]; ok && existing != t {
+			t = "string"
+		}
+		types[name] = t
+	}
+}
+
+func graphMLType(value interface{}) string {
+	switch value.(type) {
+	case bool:
+		return "boolean"
+	case int, int64:
+		return "long"
+	case float64:
+		return "double"
+	}
+	return "string"
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	}
+	return fmt.Sprint(value)
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func quoteDOT(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,468 @@
+// Package topology keeps an in-process graph of how the plant connects: nodes, cables, cores,
+// splices, ports and customers, so connectivity questions need no recursive SQL.
+package topology
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"spectra-backend/internal/geo"
+	"spectra-backend/internal/models"
+)
+
+// ErrVertexNotFound is returned when a root vertex is not in the graph
+var ErrVertexNotFound = errors.New("vertex not found")
+
+// Graph is an immutable topology graph built from a network snapshot.
+// Besides the generic vertices and edges it keeps typed indexes for the graph algorithms.
+type Graph struct {
+	vertices map[string]*models.TopologyVertex
+	order    []string
+	edges    []models.TopologyEdge
+	incident map[string][]int
+
+	Nodes           map[int64]*models.Node
+	Cables          map[int64]*models.Cable
+	Cores           map[int64]*models.CableCore
+	CoresByCable    map[int64][]*models.CableCore
+	Routes          map[int64]*models.CableRoute
+	Breakouts       map[int64]map[int64]bool // core ID -> node IDs
+	Connections     []models.Connection
+	CustomersByNode map[int64][]*models.Customer
+	Maintenance     map[int64]int64 // Customer ID -> active maintenance window ID
+	LoadedAt        time.Time
+}
+
+// VertexID returns the vertex ID of a network element, e.g. "node:12"
+func VertexID(kind models.TopologyVertexKind, id int64) string {
+	return strings.ToLower(string(kind)) + ":" + strconv.FormatInt(id, 10)
+}
+
+// PortVertexID returns the vertex ID of a port. Port numbers are local to the node the port sits on,
+// so the vertex is keyed by both, e.g. "port:12/3" for port 3 of node 12.
+func PortVertexID(nodeID, port int64) string {
+	return VertexID(models.TopologyVertexPort, nodeID) + "/" + strconv.FormatInt(port, 10)
+}
+
+// endpointVertex returns the vertex ID of a connection endpoint: a core, or a port of the connection's
+// location. Ports of connections without a location keep the bare "port:<id>" form.
+func endpointVertex(kind models.ConnectionType, id int64, locationNodeID *int64) string {
+	if kind == models.ConnectionTypePort && locationNodeID != nil {
+		return PortVertexID(*locationNodeID, id)
+	}
+	return VertexID(models.TopologyVertexKind(kind), id)
+}
+
+// NormalizeVertexID validates a vertex ID given by a client and returns it in canonical form,
+// accepting "kind:id" and the "port:node/port" form of ports
+func NormalizeVertexID(s string) (string, error) {
+	if head, port, ok := strings.Cut(strings.TrimSpace(s), "/"); ok {
+		kind, nodeID, err := ParseVertexID(head)
+		n, perr := strconv.ParseInt(port, 10, 64)
+		if err != nil || perr != nil || kind != models.TopologyVertexPort {
+			return "", fmt.Errorf("invalid vertex id %q, expected port:node/port such as port:12/3", s)
+		}
+		return PortVertexID(nodeID, n), nil
+	}
+	kind, id, err := ParseVertexID(s)
+	if err != nil {
+		return "", err
+	}
+	return VertexID(kind, id), nil
+}
+
+// ParseVertexID splits a vertex ID such as "node:12" into its kind and database ID
+func ParseVertexID(s string) (models.TopologyVertexKind, int64, error) {
+	kind, id, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return "", 0, fmt.Errorf("invalid vertex id %q, expected kind:id such as node:12", s)
+	}
+	k := models.TopologyVertexKind(strings.ToUpper(kind))
+	n, err := strconv.ParseInt(id, 10, 64)
+	if !k.IsValid() || err != nil {
+		return "", 0, fmt.Errorf("invalid vertex id %q, expected kind:id such as node:12", s)
+	}
+	return k, n, nil
+}
+
+// Build creates the graph of a snapshot. Connections whose cores no longer exist are left out.
+func Build(s *models.NetworkSnapshot) *Graph {
+	g := &Graph{
+		vertices:        map[string]*models.TopologyVertex{},
+		incident:        map[string][]int{},
+		Nodes:           map[int64]*models.Node{},
+		Cables:          map[int64]*models.Cable{},
+		Cores:           map[int64]*models.CableCore{},
+		CoresByCable:    map[int64][]*models.CableCore{},
+		Routes:          map[int64]*models.CableRoute{},
+		Breakouts:       map[int64]map[int64]bool{},
+		Connections:     s.Connections,
+		CustomersByNode: map[int64][]*models.Customer{},
+		Maintenance:     s.Maintenance,
+		LoadedAt:        time.Now(),
+	}
+	if g.Maintenance == nil {
+		g.Maintenance = map[int64]int64{}
+	}
+
+	for i := range s.Nodes {
+		n := &s.Nodes[i]
+		g.Nodes[n.ID] = n
+		attrs := map[string]interface{}{
+			"type":           string(n.Type),
+			"status":         string(n.Status),
+			"latitude":       n.Latitude,
+			"longitude":      n.Longitude,
+			"capacity_ports": n.CapacityPorts,
+			"used_ports":     n.UsedPorts,
+		}
+		g.addVertex(VertexID(models.TopologyVertexNode, n.ID), models.TopologyVertexNode, n.Name, attrs)
+	}
+
+	for i := range s.Cables {
+		c := &s.Cables[i]
+		g.Cables[c.ID] = c
+		attrs := map[string]interface{}{
+			"type":       string(c.Type),
+			"status":     string(c.Status),
+			"core_count": c.CoreCount,
+		}
+		if c.LengthMeter != nil {
+			attrs["length_meter"] = *c.LengthMeter
+		}
+		g.addVertex(VertexID(models.TopologyVertexCable, c.ID), models.TopologyVertexCable, cableLabel(c), attrs)
+	}
+	g.buildRoutes(s.Spans)
+
+	for _, c := range s.Cables {
+		route := g.Routes[c.ID]
+		if route == nil {
+			continue
+		}
+		cableVertex := VertexID(models.TopologyVertexCable, route.CableID)
+		for i, nodeID := range route.NodeIDs {
+			nodeVertex := VertexID(models.TopologyVertexNode, nodeID)
+			switch i {
+			case 0, len(route.NodeIDs) - 1:
+				end := "origin"
+				if i > 0 {
+					end = "dest"
+				}
+				g.addEdge(cableVertex+"-"+end, models.TopologyEdgeCableEnd, cableVertex, nodeVertex,
+					map[string]interface{}{"end": end})
+			default:
+				g.addEdge(fmt.Sprintf("%s-pass-%d", cableVertex, i), models.TopologyEdgeCablePass, cableVertex, nodeVertex,
+					map[string]interface{}{"sequence": i})
+			}
+		}
+	}
+
+	for i := range s.Cores {
+		core := &s.Cores[i]
+		cable := g.Cables[core.CableID]
+		if cable == nil {
+			continue
+		}
+		g.Cores[core.ID] = core
+		g.CoresByCable[core.CableID] = append(g.CoresByCable[core.CableID], core)
+
+		attrs := map[string]interface{}{
+			"cable_id":   core.CableID,
+			"core_index": core.CoreIndex,
+			"status":     string(core.Status),
+		}
+		if core.TubeColor != nil {
+			attrs["tube_color"] = *core.TubeColor
+		}
+		if core.CoreColor != nil {
+			attrs["core_color"] = *core.CoreColor
+		}
+		coreVertex := VertexID(models.TopologyVertexCore, core.ID)
+		g.addVertex(coreVertex, models.TopologyVertexCore, fmt.Sprintf("%s #%d", cableLabel(cable), core.CoreIndex), attrs)
+		g.addEdge(coreVertex+"-of", models.TopologyEdgeCoreOf, coreVertex, VertexID(models.TopologyVertexCable, core.CableID), nil)
+	}
+
+	for _, b := range s.Breakouts {
+		if g.Cores[b.CoreID] == nil || g.Nodes[b.NodeID] == nil {
+			continue
+		}
+		if g.Breakouts[b.CoreID] == nil {
+			g.Breakouts[b.CoreID] = map[int64]bool{}
+		}
+		g.Breakouts[b.CoreID][b.NodeID] = true
+		g.addEdge(fmt.Sprintf("breakout:%d", b.ID), models.TopologyEdgeBreakout,
+			VertexID(models.TopologyVertexCore, b.CoreID), VertexID(models.TopologyVertexNode, b.NodeID), nil)
+	}
+
+	for _, c := range s.Connections {
+		input, ok := g.connectionEndpoint(c.InputType, c.InputID, c.LocationNodeID)
+		if !ok {
+			continue
+		}
+		output, ok := g.connectionEndpoint(c.OutputType, c.OutputID, c.LocationNodeID)
+		if !ok {
+			continue
+		}
+		attrs := map[string]interface{}{"connection_id": c.ID}
+		if c.LocationNodeID != nil {
+			attrs["location_node_id"] = *c.LocationNodeID
+		}
+		if c.LossDB != nil {
+			attrs["loss_db"] = *c.LossDB
+		}
+		g.addEdge(fmt.Sprintf("connection:%d", c.ID), models.TopologyEdgeSplice, input, output, attrs)
+	}
+
+	for i := range s.Customers {
+		c := &s.Customers[i]
+		attrs := map[string]interface{}{"status": string(c.CurrentStatus)}
+		if c.SubscriptionType != nil {
+			attrs["subscription_type"] = *c.SubscriptionType
+		}
+		if c.ONTSN != nil {
+			attrs["ont_sn"] = *c.ONTSN
+		}
+		customerVertex := VertexID(models.TopologyVertexCustomer, c.ID)
+		g.addVertex(customerVertex, models.TopologyVertexCustomer, c.Name, attrs)
+		if c.NodeID != nil && g.Nodes[*c.NodeID] != nil {
+			g.CustomersByNode[*c.NodeID] = append(g.CustomersByNode[*c.NodeID], c)
+			g.addEdge(customerVertex+"-at", models.TopologyEdgeServes, VertexID(models.TopologyVertexNode, *c.NodeID), customerVertex, nil)
+		}
+	}
+
+	return g
+}
+
+// buildRoutes orders each cable's spans into a route like the cable span API does.
+// Cables without spans run straight from origin to destination; without a recorded length they
+// are measured along their drawn path or as the crow flies.
+func (g *Graph) buildRoutes(spans []models.CableSpan) {
+	for _, span := range spans {
+		if g.Cables[span.CableID] == nil {
+			continue
+		}
+		route := g.Routes[span.CableID]
+		if route == nil {
+			route = &models.CableRoute{CableID: span.CableID, NodeIDs: []int64{span.FromNodeID}, Spans: []models.CableSpan{}}
+			g.Routes[span.CableID] = route
+		}
+		if span.SpanLengthMeter == nil {
+			from, to := g.Nodes[span.FromNodeID], g.Nodes[span.ToNodeID]
+			length := 0.0
+			if from != nil && to != nil {
+				length = geo.HaversineMeters(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+			}
+			span.SpanLengthMeter = &length
+		}
+		route.NodeIDs = append(route.NodeIDs, span.ToNodeID)
+		route.TotalLengthMeter += *span.SpanLengthMeter
+		route.Spans = append(route.Spans, span)
+	}
+
+	for id, c := range g.Cables {
+		if g.Routes[id] != nil || c.OriginNodeID == nil || c.DestNodeID == nil {
+			continue
+		}
+		route := &models.CableRoute{CableID: id, NodeIDs: []int64{*c.OriginNodeID, *c.DestNodeID}, Spans: []models.CableSpan{}}
+		origin, dest := g.Nodes[*c.OriginNodeID], g.Nodes[*c.DestNodeID]
+		switch {
+		case c.LengthMeter != nil:
+			route.TotalLengthMeter = *c.LengthMeter
+		case len(c.PathCoordinates) >= 2:
+			route.TotalLengthMeter = geo.PathLengthMeters(c.PathCoordinates)
+		case origin != nil && dest != nil:
+			route.TotalLengthMeter = geo.HaversineMeters(origin.Latitude, origin.Longitude, dest.Latitude, dest.Longitude)
+		}
+		g.Routes[id] = route
+	}
+}
+
+// connectionEndpoint returns the vertex of a connection endpoint. Ports have no table of their own,
+// so a port vertex is created the first time a connection refers to it; it is keyed by the
+// connection's location, as port numbers repeat from node to node.
+func (g *Graph) connectionEndpoint(kind models.ConnectionType, id int64, locationNodeID *int64) (string, bool) {
+	vertex := endpointVertex(kind, id, locationNodeID)
+	if kind == models.ConnectionTypeCore {
+		return vertex, g.vertices[vertex] != nil
+	}
+
+	if g.vertices[vertex] == nil {
+		attrs := map[string]interface{}{"port_id": id}
+		if locationNodeID != nil {
+			attrs["node_id"] = *locationNodeID
+		}
+		g.addVertex(vertex, models.TopologyVertexPort, fmt.Sprintf("Port #%d", id), attrs)
+	}
+	if locationNodeID != nil && g.Nodes[*locationNodeID] != nil {
+		edgeID := fmt.Sprintf("%s-at-%d", vertex, *locationNodeID)
+		if !g.hasEdge(vertex, edgeID) {
+			g.addEdge(edgeID, models.TopologyEdgePortAt, vertex, VertexID(models.TopologyVertexNode, *locationNodeID), nil)
+		}
+	}
+	return vertex, true
+}
+
+func (g *Graph) addVertex(id string, kind models.TopologyVertexKind, label string, attrs map[string]interface{}) {
+	g.vertices[id] = &models.TopologyVertex{ID: id, Kind: kind, Label: label, Attributes: attrs}
+	g.order = append(g.order, id)
+}
+
+// addEdge links two vertices; edges to vertices that are not in the graph are dropped
+func (g *Graph) addEdge(id string, kind models.TopologyEdgeKind, source, target string, attrs map[string]interface{}) {
+	if g.vertices[source] == nil || g.vertices[target] == nil {
+		return
+	}
+	g.edges = append(g.edges, models.TopologyEdge{ID: id, Kind: kind, Source: source, Target: target, Attributes: attrs})
+	index := len(g.edges) - 1
+	g.incident[source] = append(g.incident[source], index)
+	if target != source {
+		g.incident[target] = append(g.incident[target], index)
+	}
+}
+
+func (g *Graph) hasEdge(vertex, edgeID string) bool {
+	for _, i := range g.incident[vertex] {
+		if g.edges[i].ID == edgeID {
+			return true
+		}
+	}
+	return false
+}
+
+// Vertex returns a vertex by ID, or nil
+func (g *Graph) Vertex(id string) *models.TopologyVertex {
+	return g.vertices[id]
+}
+
+// Edges returns the edges incident to a vertex
+func (g *Graph) Edges(vertex string) []models.TopologyEdge {
+	edges := make([]models.TopologyEdge, 0, len(g.incident[vertex]))
+	for _, i := range g.incident[vertex] {
+		edges = append(edges, g.edges[i])
+	}
+	return edges
+}
+
+// Neighbor returns the vertex at the other end of an edge
+func Neighbor(e models.TopologyEdge, vertex string) string {
+	if e.Source == vertex {
+		return e.Target
+	}
+	return e.Source
+}
+
+// All returns the whole graph
+func (g *Graph) All() *models.TopologyGraph {
+	result := &models.TopologyGraph{
+		Vertices: make([]models.TopologyVertex, 0, len(g.order)),
+		Edges:    append([]models.TopologyEdge{}, g.edges...),
+		LoadedAt: g.LoadedAt,
+	}
+	for _, id := range g.order {
+		result.Vertices = append(result.Vertices, *g.vertices[id])
+	}
+	result.VertexCount = len(result.Vertices)
+	result.EdgeCount = len(result.Edges)
+	return result
+}
+
+// Neighborhood returns the vertices within depth hops of root and the edges between them.
+// When kinds is not empty, the walk only enters vertices of those kinds (the root is always included).
+func (g *Graph) Neighborhood(root string, depth int, kinds map[models.TopologyVertexKind]bool) (*models.TopologyGraph, error) {
+	if g.vertices[root] == nil {
+		return nil, ErrVertexNotFound
+	}
+
+	distance := map[string]int{root: 0}
+	queue := []string{root}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if distance[current] == depth {
+			continue
+		}
+		for _, i := range g.incident[current] {
+			next := Neighbor(g.edges[i], current)
+			if _, seen := distance[next]; seen {
+				continue
+			}
+			if len(kinds) > 0 && !kinds[g.vertices[next].Kind] {
+				continue
+			}
+			distance[next] = distance[current] + 1
+			queue = append(queue, next)
+		}
+	}
+
+	result := &models.TopologyGraph{
+		Root:     &root,
+		Depth:    depth,
+		Vertices: []models.TopologyVertex{},
+		Edges:    []models.TopologyEdge{},
+		LoadedAt: g.LoadedAt,
+	}
+	for _, id := range g.order {
+		if _, ok := distance[id]; ok {
+			result.Vertices = append(result.Vertices, *g.vertices[id])
+		}
+	}
+	for _, e := range g.edges {
+		_, sourceIn := distance[e.Source]
+		_, targetIn := distance[e.Target]
+		if sourceIn && targetIn {
+			result.Edges = append(result.Edges, e)
+		}
+	}
+	result.VertexCount = len(result.Vertices)
+	result.EdgeCount = len(result.Edges)
+	return result, nil
+}
+
+func cableLabel(c *models.Cable) string {
+	if c.Name != nil && *c.Name != "" {
+		return *c.Name
+	}
+	return fmt.Sprintf("Cable #%d", c.ID)
+}
+
+// CoreSegments splits a core's cable route at the nodes where the core is broken out
+func (g *Graph) CoreSegments(coreID int64) []models.CoreSegment {
+	core := g.Cores[coreID]
+	if core == nil {
+		return nil
+	}
+	route := g.Routes[core.CableID]
+	if route == nil {
+		return []models.CoreSegment{}
+	}
+
+	spanLengths := make([]float64, len(route.Spans))
+	for i, span := range route.Spans {
+		spanLengths[i] = *span.SpanLengthMeter
+	}
+	if len(route.Spans) == 0 {
+		spanLengths = []float64{route.TotalLengthMeter}
+	}
+	return models.SplitRouteAtBreakouts(coreID, core.CableID, route.NodeIDs, spanLengths, g.Breakouts[coreID])
+}
+
+// CablePath returns the drawn path of a cable, or the straight lines between the nodes of its route
+func (g *Graph) CablePath(cableID int64) [][]float64 {
+	if c := g.Cables[cableID]; c != nil && len(c.PathCoordinates) >= 2 {
+		return c.PathCoordinates
+	}
+	route := g.Routes[cableID]
+	if route == nil {
+		return nil
+	}
+	var path [][]float64
+	for _, nodeID := range route.NodeIDs {
+		if n := g.Nodes[nodeID]; n != nil {
+			path = append(path, []float64{n.Longitude, n.Latitude})
+		}
+	}
+	return path
+}
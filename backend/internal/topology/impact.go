@@ -0,0 +1,265 @@
+package topology
+
+import (
+	"sort"
+	"strings"
+
+	"spectra-backend/internal/geo"
+	"spectra-backend/internal/models"
+)
+
+// UnknownElementsError is returned when an impact request names elements that do not exist
+type UnknownElementsError struct {
+	Missing []string
+}
+
+func (e *UnknownElementsError) Error() string {
+	return "unknown elements: " + strings.Join(e.Missing, ", ")
+}
+
+// Impact works out what loses service when the requested cables, cores and nodes fail.
+//
+// A failed node takes down every cable routed through it. A failed cable takes down all its cores.
+// Every fiber path (core segments and ports chained by connections) that contains a failed core, or a
+// connection located at a failed node, is broken end to end. Paths are followed segment by segment: a
+// broken segment only reaches the connections at its own ends, so a core broken out mid-span keeps its
+// other segments, and whatever they feed, in service. Cores that are VACANT, RESERVED or DAMAGED and
+// carry no connections are dark and affect nobody. The nodes where broken segments terminate (cable
+// ends and breakouts) and the failed nodes lose their feed, along with their customers.
+func (g *Graph) Impact(req *models.ImpactRequest) (*models.ImpactReport, error) {
+	var missing []string
+	for _, id := range req.CableIDs {
+		if g.Cables[id] == nil {
+			missing = append(missing, VertexID(models.TopologyVertexCable, id))
+		}
+	}
+	for _, id := range req.CoreIDs {
+		if g.Cores[id] == nil {
+			missing = append(missing, VertexID(models.TopologyVertexCore, id))
+		}
+	}
+	for _, id := range req.NodeIDs {
+		if g.Nodes[id] == nil {
+			missing = append(missing, VertexID(models.TopologyVertexNode, id))
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &UnknownElementsError{Missing: missing}
+	}
+
+	failedNodes := map[int64]bool{}
+	for _, id := range req.NodeIDs {
+		failedNodes[id] = true
+	}
+	failedCables := map[int64]bool{}
+	for _, id := range req.CableIDs {
+		failedCables[id] = true
+	}
+	for cableID, route := range g.Routes {
+		for _, nodeID := range route.NodeIDs {
+			if failedNodes[nodeID] {
+				failedCables[cableID] = true
+			}
+		}
+	}
+
+	byEndpoint := g.connectionsByEndpoint()
+	walk := g.newSegmentWalk(byEndpoint)
+	for id := range failedCables {
+		for _, core := range g.CoresByCable[id] {
+			if g.lit(core, byEndpoint) {
+				walk.addCore(core.ID)
+			}
+		}
+	}
+	for _, id := range req.CoreIDs {
+		if g.lit(g.Cores[id], byEndpoint) {
+			walk.addCore(id)
+		}
+	}
+	for i, c := range g.Connections {
+		if c.LocationNodeID != nil && failedNodes[*c.LocationNodeID] {
+			walk.connections[i] = true
+			walk.enter(c.InputType, c.InputID, c.LocationNodeID, []int64{*c.LocationNodeID})
+			walk.enter(c.OutputType, c.OutputID, c.LocationNodeID, []int64{*c.LocationNodeID})
+		}
+	}
+	walk.run()
+	splices := walk.connections
+
+	affectedNodes := map[int64]bool{}
+	for id := range failedNodes {
+		affectedNodes[id] = true
+	}
+	affectedCores := map[int64]bool{}
+	coresPerCable := map[int64]int{}
+	for _, segment := range walk.segments {
+		if !affectedCores[segment.CoreID] {
+			affectedCores[segment.CoreID] = true
+			coresPerCable[segment.CableID]++
+		}
+		affectedNodes[segment.FromNodeID] = true
+		affectedNodes[segment.ToNodeID] = true
+	}
+
+	report := &models.ImpactReport{
+		Request:                 *req,
+		FailedCableIDs:          sortedIDs(failedCables),
+		Cables:                  []models.ImpactCable{},
+		AffectedODCs:            []models.Node{},
+		AffectedODPs:            []models.Node{},
+		Customers:               []models.ImpactCustomer{},
+		CustomersBySubscription: map[string]int{},
+		Splices:                 []models.ImpactSplice{},
+	}
+
+	cableIDs := map[int64]bool{}
+	for id := range failedCables {
+		cableIDs[id] = true
+	}
+	for id := range coresPerCable {
+		cableIDs[id] = true
+	}
+	for _, id := range sortedIDs(cableIDs) {
+		cable := g.Cables[id]
+		report.Cables = append(report.Cables, models.ImpactCable{
+			CableID:       id,
+			Name:          cable.Name,
+			Type:          cable.Type,
+			Failed:        failedCables[id],
+			AffectedCores: coresPerCable[id],
+		})
+	}
+
+	for _, id := range sortedIDs(affectedNodes) {
+		node := g.Nodes[id]
+		if node == nil {
+			continue
+		}
+		switch node.Type {
+		case models.NodeTypeODC:
+			report.AffectedODCs = append(report.AffectedODCs, *node)
+		case models.NodeTypeODP:
+			report.AffectedODPs = append(report.AffectedODPs, *node)
+		}
+		for _, c := range g.CustomersByNode[id] {
+			customer := models.ImpactCustomer{
+				ID:               c.ID,
+				Name:             c.Name,
+				Phone:            c.Phone,
+				Email:            c.Email,
+				ONTSN:            c.ONTSN,
+				SubscriptionType: c.SubscriptionType,
+				CurrentStatus:    c.CurrentStatus,
+				NodeID:           id,
+				NodeName:         node.Name,
+			}
+			if windowID, ok := g.Maintenance[c.ID]; ok {
+				customer.MaintenanceWindowID = &windowID
+				report.MaintenanceCustomers++
+			}
+			report.Customers = append(report.Customers, customer)
+			subscription := models.UnspecifiedSubscription
+			if c.SubscriptionType != nil && *c.SubscriptionType != "" {
+				subscription = *c.SubscriptionType
+			}
+			report.CustomersBySubscription[subscription]++
+		}
+	}
+	report.CustomerCount = len(report.Customers)
+
+	spliceIndexes := make([]int, 0, len(splices))
+	for i := range splices {
+		spliceIndexes = append(spliceIndexes, i)
+	}
+	sort.Ints(spliceIndexes)
+	for _, i := range spliceIndexes {
+		c := g.Connections[i]
+		splice := models.ImpactSplice{
+			ConnectionID:   c.ID,
+			LocationNodeID: c.LocationNodeID,
+			InputType:      c.InputType,
+			InputID:        c.InputID,
+			OutputType:     c.OutputType,
+			OutputID:       c.OutputID,
+			LossDB:         c.LossDB,
+		}
+		if c.LocationNodeID != nil && g.Nodes[*c.LocationNodeID] != nil {
+			splice.LocationNodeName = &g.Nodes[*c.LocationNodeID].Name
+		}
+		report.Splices = append(report.Splices, splice)
+	}
+
+	report.GeoJSON = g.impactGeoJSON(report, failedNodes, affectedNodes)
+	return report, nil
+}
+
+// impactGeoJSON draws the failed and affected cables and nodes, and the convex hull around them
+// as the impacted area. Features carry an "impact" property: FAILED, AFFECTED or AREA.
+func (g *Graph) impactGeoJSON(report *models.ImpactReport, failedNodes, affectedNodes map[int64]bool) models.GeoJSONFeatureCollection {
+	var features []interface{}
+	var points [][]float64
+
+	for _, ic := range report.Cables {
+		path := g.CablePath(ic.CableID)
+		if len(path) < 2 {
+			continue
+		}
+		cable := *g.Cables[ic.CableID]
+		cable.PathCoordinates = path
+		feature := cable.ToGeoJSON()
+		feature.Properties["impact"] = impactLabel(ic.Failed)
+		feature.Properties["affected_cores"] = ic.AffectedCores
+		features = append(features, feature)
+		points = append(points, path...)
+	}
+
+	customersByNode := map[int64]int{}
+	for _, c := range report.Customers {
+		customersByNode[c.NodeID]++
+	}
+	for _, id := range sortedIDs(affectedNodes) {
+		node := g.Nodes[id]
+		if node == nil {
+			continue
+		}
+		feature := node.ToGeoJSON()
+		feature.Properties["impact"] = impactLabel(failedNodes[id])
+		feature.Properties["customer_count"] = customersByNode[id]
+		features = append(features, feature)
+		points = append(points, []float64{node.Longitude, node.Latitude})
+	}
+
+	if hull := geo.ConvexHull(points); hull != nil {
+		features = append(features, map[string]interface{}{
+			"type": "Feature",
+			"geometry": map[string]interface{}{
+				"type":        "Polygon",
+				"coordinates": [][][]float64{hull},
+			},
+			"properties": map[string]interface{}{
+				"impact":         "AREA",
+				"customer_count": report.CustomerCount,
+			},
+		})
+	}
+
+	return models.NewGeoJSONFeatureCollection(features)
+}
+
+func impactLabel(failed bool) string {
+	if failed {
+		return "FAILED"
+	}
+	return "AFFECTED"
+}
+
+// sortedIDs returns the keys of an ID-keyed map in ascending order
+func sortedIDs[V any](set map[int64]V) []int64 {
+	ids := make([]int64, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,150 @@
+package topology
+
+import (
+	"reflect"
+	"testing"
+
+	"spectra-backend/internal/models"
+)
+
+// breakoutNetwork builds a feeder F that runs from closure 1 over pole 6 and closure 2 to closure 3.
+// Core 200 of F is broken out at closure 2: its first segment (1-2) is fed from the OLT by cable X and
+// spliced to drop cable D, its second segment (2-3) feeds cable G. Core 201 runs express through
+// closure 2 and feeds cable H. Customers 1, 2 and 3 hang off the far ends of D, G and H.
+func breakoutNetwork() *Graph {
+	id := func(v int64) *int64 { return &v }
+	length := func(v float64) *float64 { return &v }
+	node := func(nodeID int64, nodeType models.NodeType) models.Node {
+		return models.Node{ID: nodeID, Type: nodeType, Latitude: -6.2 + float64(nodeID)*0.001, Longitude: 106.8}
+	}
+	cable := func(cableID, origin, dest int64) models.Cable {
+		return models.Cable{ID: cableID, OriginNodeID: id(origin), DestNodeID: id(dest), LengthMeter: length(100)}
+	}
+	core := func(coreID, cableID int64, index int) models.CableCore {
+		return models.CableCore{ID: coreID, CableID: cableID, CoreIndex: index, Status: models.CoreStatusUsed}
+	}
+	splice := func(connectionID, nodeID, input, output int64) models.Connection {
+		return models.Connection{
+			ID:             connectionID,
+			LocationNodeID: id(nodeID),
+			InputType:      models.ConnectionTypeCore,
+			InputID:        input,
+			OutputType:     models.ConnectionTypeCore,
+			OutputID:       output,
+		}
+	}
+	customer := func(customerID, nodeID int64) models.Customer {
+		return models.Customer{ID: customerID, NodeID: id(nodeID), CurrentStatus: models.CustomerStatusOnline}
+	}
+
+	const x, f, d, g, h = 10, 11, 12, 13, 14
+	return Build(&models.NetworkSnapshot{
+		Nodes: []models.Node{
+			node(1, models.NodeTypeClosure), node(2, models.NodeTypeClosure), node(3, models.NodeTypeClosure),
+			node(4, models.NodeTypeODP), node(5, models.NodeTypeODP), node(6, models.NodeTypePole),
+			node(7, models.NodeTypeODP), node(9, models.NodeTypeOLT),
+		},
+		Cables: []models.Cable{cable(x, 9, 1), cable(f, 1, 3), cable(d, 2, 4), cable(g, 3, 5), cable(h, 3, 7)},
+		Cores: []models.CableCore{
+			core(100, x, 1), core(101, x, 2), core(200, f, 1), core(201, f, 2),
+			core(300, d, 1), core(400, g, 1), core(500, h, 1),
+		},
+		Spans: []models.CableSpan{
+			{CableID: f, FromNodeID: 1, ToNodeID: 6, Sequence: 1, SpanLengthMeter: length(40)},
+			{CableID: f, FromNodeID: 6, ToNodeID: 2, Sequence: 2, SpanLengthMeter: length(40)},
+			{CableID: f, FromNodeID: 2, ToNodeID: 3, Sequence: 3, SpanLengthMeter: length(40)},
+		},
+		Breakouts: []models.CoreBreakout{{ID: 1, CoreID: 200, NodeID: 2}},
+		Connections: []models.Connection{
+			splice(1, 1, 100, 200),
+			splice(2, 2, 200, 300),
+			splice(3, 3, 200, 400),
+			splice(4, 1, 101, 201),
+			splice(5, 3, 201, 500),
+		},
+		Customers: []models.Customer{customer(1, 4), customer(2, 5), customer(3, 7)},
+	})
+}
+
+func TestImpactMidSpanBreakout(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       models.ImpactRequest
+		customers []int64
+		odps      []int64
+		splices   []int64
+	}{
+		{
+			name:      "cut upstream of the breakout only reaches the fed segment",
+			req:       models.ImpactRequest{CableIDs: []int64{10}},
+			customers: []int64{1, 3},
+			odps:      []int64{4, 7},
+			splices:   []int64{1, 2, 4, 5},
+		},
+		{
+			name:      "failed OLT takes down the feeder cable",
+			req:       models.ImpactRequest{NodeIDs: []int64{9}},
+			customers: []int64{1, 3},
+			odps:      []int64{4, 7},
+			splices:   []int64{1, 2, 4, 5},
+		},
+		{
+			name:      "failed core breaks both of its segments",
+			req:       models.ImpactRequest{CoreIDs: []int64{200}},
+			customers: []int64{1, 2},
+			odps:      []int64{4, 5},
+			splices:   []int64{1, 2, 3},
+		},
+		{
+			name:      "failed express core passes the breakout closure",
+			req:       models.ImpactRequest{CoreIDs: []int64{201}},
+			customers: []int64{3},
+			odps:      []int64{7},
+			splices:   []int64{4, 5},
+		},
+	}
+
+	g := breakoutNetwork()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := g.Impact(&tt.req)
+			if err != nil {
+				t.Fatalf("Impact() error = %v", err)
+			}
+
+			customers := []int64{}
+			for _, c := range report.Customers {
+				customers = append(customers, c.ID)
+			}
+			odps := []int64{}
+			for _, n := range report.AffectedODPs {
+				odps = append(odps, n.ID)
+			}
+			splices := []int64{}
+			for _, s := range report.Splices {
+				splices = append(splices, s.ConnectionID)
+			}
+
+			if !reflect.DeepEqual(customers, tt.customers) {
+				t.Errorf("customers = %v, want %v", customers, tt.customers)
+			}
+			if !reflect.DeepEqual(odps, tt.odps) {
+				t.Errorf("affected ODPs = %v, want %v", odps, tt.odps)
+			}
+			if !reflect.DeepEqual(splices, tt.splices) {
+				t.Errorf("splices = %v, want %v", splices, tt.splices)
+			}
+		})
+	}
+}
+
+func TestImpactUnknownElements(t *testing.T) {
+	_, err := breakoutNetwork().Impact(&models.ImpactRequest{CableIDs: []int64{99}, NodeIDs: []int64{1}})
+	unknown, ok := err.(*UnknownElementsError)
+	if !ok {
+		t.Fatalf("Impact() error = %v, want *UnknownElementsError", err)
+	}
+	if want := []string{"cable:99"}; !reflect.DeepEqual(unknown.Missing, want) {
+		t.Errorf("missing = %v, want %v", unknown.Missing, want)
+	}
+}
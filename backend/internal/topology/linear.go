@@ -0,0 +1,146 @@
+package topology
+
+import (
+	"errors"
+	"math"
+
+	"spectra-backend/internal/geo"
+	"spectra-backend/internal/models"
+)
+
+// ErrNoCableRoute is returned when a cable has neither end nodes nor a drawn path to measure along
+var ErrNoCableRoute = errors.New("cable has no route")
+
+// cableRoute lays out a whole cable from one of its ends, with the same slack model as fiber routes.
+// A cable drawn on the map without both end nodes is laid out as one span along its drawn path.
+func (g *Graph) cableRoute(cableID int64, from models.CableEnd, slack models.FiberSlack) (*fiberRoute, error) {
+	cable := g.Cables[cableID]
+	if cable == nil {
+		return nil, &UnknownElementsError{Missing: []string{VertexID(models.TopologyVertexCable, cableID)}}
+	}
+
+	r := &fiberRoute{slackFactor: 1 + models.DefaultOTDRSlackPct/100}
+	if slack.SlackPct != nil {
+		r.slackFactor = 1 + math.Max(0, *slack.SlackPct)/100
+	}
+	if slack.NodeSlackMeter != nil {
+		r.nodeSlackMeter = math.Max(0, *slack.NodeSlackMeter)
+	}
+
+	route := g.Routes[cableID]
+	if route == nil || len(route.NodeIDs) < 2 {
+		if len(cable.PathCoordinates) < 2 {
+			return nil, ErrNoCableRoute
+		}
+		span := fiberSpan{
+			cableID:     cableID,
+			lengthMeter: geo.PathLengthMeters(cable.PathCoordinates),
+			path:        cable.PathCoordinates,
+		}
+		if cable.LengthMeter != nil {
+			span.lengthMeter = *cable.LengthMeter
+		}
+		if cable.OriginNodeID != nil {
+			span.from = *cable.OriginNodeID
+		}
+		if cable.DestNodeID != nil {
+			span.to = *cable.DestNodeID
+		}
+		if from == models.CableEndDestination {
+			span.from, span.to = span.to, span.from
+			span.path = reversePath(span.path)
+		}
+		r.spans = []fiberSpan{span}
+		r.lengthMeter = span.lengthMeter
+		r.opticalLengthMeter = span.lengthMeter * r.slackFactor
+		return r, nil
+	}
+
+	last := len(route.NodeIDs) - 1
+	whole := models.CoreSegment{
+		CableID:            cableID,
+		FromNodeID:         route.NodeIDs[0],
+		ToNodeID:           route.NodeIDs[last],
+		PassThroughNodeIDs: route.NodeIDs[1:last],
+		LengthMeter:        route.TotalLengthMeter,
+	}
+	at := whole.FromNodeID
+	if from == models.CableEndDestination {
+		at = whole.ToNodeID
+	}
+	g.addFiberSegment(r, whole, at)
+	return r, nil
+}
+
+// LocateOnCable converts a distance along a cable, measured from either end as cable length or as
+// fiber length, into a point and the nearest node of its route
+func (g *Graph) LocateOnCable(req *models.CableLocateRequest) (*models.FiberLocation, error) {
+	r, err := g.cableRoute(req.CableID, req.From, req.FiberSlack)
+	if err != nil {
+		return nil, err
+	}
+	if req.Optical {
+		return g.locate(r, req.DistanceMeter), nil
+	}
+
+	d := math.Max(0, req.DistanceMeter)
+	for _, span := range r.spans {
+		if d <= span.cableStartMeter+span.lengthMeter {
+			along := d - span.cableStartMeter
+			return g.fiberLocation(span, along, span.opticalStartMeter+along*r.slackFactor), nil
+		}
+	}
+	last := r.spans[len(r.spans)-1]
+	location := g.fiberLocation(last, last.lengthMeter, r.opticalLengthMeter+(d-r.lengthMeter)*r.slackFactor)
+	location.CableDistanceMeter = d
+	location.BeyondEnd = d > r.lengthMeter
+	return location, nil
+}
+
+// MeasureOnCable projects a point onto the nearest span of a cable and measures it from both ends
+func (g *Graph) MeasureOnCable(cableID int64, lat, lng float64, slack models.FiberSlack) (*models.CableMeasure, error) {
+	r, err := g.cableRoute(cableID, models.CableEndOrigin, slack)
+	if err != nil {
+		return nil, err
+	}
+
+	var best *models.CableMeasure
+	for _, span := range r.spans {
+		pos, ok := geo.NearestPointOnPath(span.path, lat, lng)
+		if !ok || (best != nil && pos.OffsetMeters >= best.OffsetMeter) {
+			continue
+		}
+		along := 0.0
+		if pathLength := geo.PathLengthMeters(span.path); pathLength > 0 {
+			along = pos.DistanceAlongMeters / pathLength * span.lengthMeter
+		}
+		best = &models.CableMeasure{
+			CableID:                  cableID,
+			Latitude:                 pos.Point[1],
+			Longitude:                pos.Point[0],
+			OffsetMeter:              pos.OffsetMeters,
+			FromOriginMeter:          span.cableStartMeter + along,
+			OpticalFromOriginMeter:   span.opticalStartMeter + along*r.slackFactor,
+			SpanFromNodeID:           span.from,
+			SpanToNodeID:             span.to,
+			NearestNodeID:            span.from,
+			NearestNodeDistanceMeter: along,
+		}
+		if rest := span.lengthMeter - along; rest < along {
+			best.NearestNodeID = span.to
+			best.NearestNodeDistanceMeter = rest
+		}
+	}
+	if best == nil {
+		return nil, ErrNoCableRoute
+	}
+
+	best.LengthMeter = r.lengthMeter
+	best.OpticalLengthMeter = r.opticalLengthMeter
+	best.FromDestinationMeter = r.lengthMeter - best.FromOriginMeter
+	best.OpticalFromDestinationMeter = r.opticalLengthMeter - best.OpticalFromOriginMeter
+	if node := g.Nodes[best.NearestNodeID]; node != nil {
+		best.NearestNodeName = node.Name
+	}
+	return best, nil
+}
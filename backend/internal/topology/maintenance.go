@@ -0,0 +1,28 @@
+package topology
+
+import "spectra-backend/internal/models"
+
+// MaintenanceCustomers lists the customers that lose service while a maintenance window's assets
+// are down, treating them as failed elements. Assets deleted since the window was planned are skipped.
+func (g *Graph) MaintenanceCustomers(assets []models.MaintenanceAsset) []int64 {
+	window := &models.MaintenanceWindow{}
+	for _, a := range assets {
+		if (a.ElementType == models.MaintenanceAssetNode && g.Nodes[a.ElementID] != nil) ||
+			(a.ElementType == models.MaintenanceAssetCable && g.Cables[a.ElementID] != nil) {
+			window.Assets = append(window.Assets, a)
+		}
+	}
+	if len(window.Assets) == 0 {
+		return []int64{}
+	}
+
+	report, err := g.Impact(window.ImpactRequest())
+	if err != nil {
+		return []int64{}
+	}
+	ids := make([]int64, 0, len(report.Customers))
+	for _, c := range report.Customers {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
@@ -0,0 +1,46 @@
+package topology
+
+import (
+	"reflect"
+	"testing"
+
+	"spectra-backend/internal/models"
+)
+
+func TestMaintenanceCustomersBreakout(t *testing.T) {
+	tests := []struct {
+		name   string
+		assets []models.MaintenanceAsset
+		want   []int64
+	}{
+		{
+			name:   "feeder cable upstream of the breakout",
+			assets: []models.MaintenanceAsset{{ElementType: models.MaintenanceAssetCable, ElementID: 10}},
+			want:   []int64{1, 3},
+		},
+		{
+			name:   "closure the broken-out core runs through",
+			assets: []models.MaintenanceAsset{{ElementType: models.MaintenanceAssetNode, ElementID: 2}},
+			want:   []int64{1, 2, 3},
+		},
+		{
+			name:   "drop cable fed by the express core",
+			assets: []models.MaintenanceAsset{{ElementType: models.MaintenanceAssetCable, ElementID: 14}},
+			want:   []int64{3},
+		},
+		{
+			name:   "deleted assets are skipped",
+			assets: []models.MaintenanceAsset{{ElementType: models.MaintenanceAssetCable, ElementID: 99}},
+			want:   []int64{},
+		},
+	}
+
+	g := breakoutNetwork()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := g.MaintenanceCustomers(tt.assets); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MaintenanceCustomers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
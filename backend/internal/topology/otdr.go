@@ -0,0 +1,437 @@
+package topology
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"spectra-backend/internal/geo"
+	"spectra-backend/internal/models"
+)
+
+// ErrNotFiberEnd is returned when a trace is placed at a node where its core does not end or break out
+var ErrNotFiberEnd = errors.New("core does not end or break out at the node")
+
+// fiberSpan is one node-to-node stretch of a fiber route, in shooting direction
+type fiberSpan struct {
+	coreID            int64
+	cableID           int64
+	from, to          int64
+	lengthMeter       float64
+	cableStartMeter   float64 // Route length before this span
+	opticalStartMeter float64 // Fiber length before this span, slack included
+	path              [][]float64
+}
+
+// fiberSplice joins two cores of a route after a span
+type fiberSplice struct {
+	connection   models.Connection
+	opticalMeter float64
+}
+
+// fiberRoute is the path of a fiber from one node, followed across core-to-core splices
+type fiberRoute struct {
+	coreIDs            []int64
+	spans              []fiberSpan
+	splices            []fiberSplice
+	slackFactor        float64
+	nodeSlackMeter     float64
+	lengthMeter        float64
+	opticalLengthMeter float64
+}
+
+// fiberRoute follows a core from one of its end nodes and on through existing core-to-core splices
+func (g *Graph) fiberRoute(coreID, fromNodeID int64, slack models.FiberSlack) (*fiberRoute, error) {
+	var missing []string
+	if g.Cores[coreID] == nil {
+		missing = append(missing, VertexID(models.TopologyVertexCore, coreID))
+	}
+	if g.Nodes[fromNodeID] == nil {
+		missing = append(missing, VertexID(models.TopologyVertexNode, fromNodeID))
+	}
+	if len(missing) > 0 {
+		return nil, &UnknownElementsError{Missing: missing}
+	}
+
+	r := &fiberRoute{slackFactor: 1 + models.DefaultOTDRSlackPct/100}
+	if slack.SlackPct != nil {
+		r.slackFactor = 1 + math.Max(0, *slack.SlackPct)/100
+	}
+	if slack.NodeSlackMeter != nil {
+		r.nodeSlackMeter = math.Max(0, *slack.NodeSlackMeter)
+	}
+
+	pinned := map[pathState]models.Connection{}
+	for _, c := range g.Connections {
+		if c.LocationNodeID != nil && c.InputType == models.ConnectionTypeCore && c.OutputType == models.ConnectionTypeCore {
+			pinned[pathState{*c.LocationNodeID, c.InputID}] = c
+			pinned[pathState{*c.LocationNodeID, c.OutputID}] = c
+		}
+	}
+
+	visited := map[pathState]bool{}
+	segmentFrom := func(core, at int64) (models.CoreSegment, bool) {
+		for _, segment := range g.CoreSegments(core) {
+			if segment.HasEndpoint(at) && !visited[pathState{segment.OtherEnd(at), core}] {
+				return segment, true
+			}
+		}
+		return models.CoreSegment{}, false
+	}
+
+	segment, ok := segmentFrom(coreID, fromNodeID)
+	if !ok {
+		return nil, ErrNotFiberEnd
+	}
+	at, core := fromNodeID, coreID
+	for {
+		visited[pathState{at, core}] = true
+		r.coreIDs = append(r.coreIDs, core)
+		g.addFiberSegment(r, segment, at)
+		at = segment.OtherEnd(at)
+		visited[pathState{at, core}] = true
+
+		c, ok := pinned[pathState{at, core}]
+		if !ok {
+			break
+		}
+		next := c.OutputID
+		if next == core {
+			next = c.InputID
+		}
+		if segment, ok = segmentFrom(next, at); !ok {
+			break
+		}
+		r.splices = append(r.splices, fiberSplice{
+			connection:   c,
+			opticalMeter: r.opticalLengthMeter + r.nodeSlackMeter/2,
+		})
+		core = next
+	}
+	return r, nil
+}
+
+// addFiberSegment appends the spans of a core segment, entered at a node, to the route
+func (g *Graph) addFiberSegment(r *fiberRoute, segment models.CoreSegment, at int64) {
+	route := g.Routes[segment.CableID]
+	hops := len(segment.PassThroughNodeIDs) + 1
+	start := 0
+	for i := 0; i+hops < len(route.NodeIDs); i++ {
+		if route.NodeIDs[i] == segment.FromNodeID && route.NodeIDs[i+hops] == segment.ToNodeID {
+			start = i
+			break
+		}
+	}
+
+	positions := g.routePositions(segment.CableID)
+	spans := make([]fiberSpan, 0, hops)
+	for i := start; i < start+hops; i++ {
+		span := fiberSpan{
+			coreID:      segment.CoreID,
+			cableID:     segment.CableID,
+			from:        route.NodeIDs[i],
+			to:          route.NodeIDs[i+1],
+			lengthMeter: route.TotalLengthMeter,
+		}
+		if len(route.Spans) > 0 {
+			span.lengthMeter = *route.Spans[i].SpanLengthMeter
+		}
+		if positions != nil && positions[i] <= positions[i+1] {
+			span.path = geo.SubPath(g.Cables[segment.CableID].PathCoordinates, positions[i], positions[i+1])
+		} else if positions != nil {
+			span.path = reversePath(geo.SubPath(g.Cables[segment.CableID].PathCoordinates, positions[i+1], positions[i]))
+		}
+		if len(span.path) < 2 {
+			span.path = nil
+			for _, id := range []int64{span.from, span.to} {
+				if n := g.Nodes[id]; n != nil {
+					span.path = append(span.path, []float64{n.Longitude, n.Latitude})
+				}
+			}
+		}
+		spans = append(spans, span)
+	}
+
+	if at != segment.FromNodeID {
+		for i, j := 0, len(spans)-1; i < j; i, j = i+1, j-1 {
+			spans[i], spans[j] = spans[j], spans[i]
+		}
+		for i := range spans {
+			spans[i].from, spans[i].to = spans[i].to, spans[i].from
+			spans[i].path = reversePath(spans[i].path)
+		}
+	}
+
+	for _, span := range spans {
+		span.cableStartMeter = r.lengthMeter
+		span.opticalStartMeter = r.opticalLengthMeter
+		if len(r.spans) > 0 {
+			span.opticalStartMeter += r.nodeSlackMeter
+		}
+		r.lengthMeter += span.lengthMeter
+		r.opticalLengthMeter = span.opticalStartMeter + span.lengthMeter*r.slackFactor
+		r.spans = append(r.spans, span)
+	}
+}
+
+// routePositions projects the nodes of a cable's route onto its drawn path, returning the
+// distance along the path of each; nil when the cable has no drawn path
+func (g *Graph) routePositions(cableID int64) []float64 {
+	cable, route := g.Cables[cableID], g.Routes[cableID]
+	if cable == nil || route == nil || len(cable.PathCoordinates) < 2 {
+		return nil
+	}
+	positions := make([]float64, len(route.NodeIDs))
+	for i, id := range route.NodeIDs {
+		node := g.Nodes[id]
+		if node == nil {
+			return nil
+		}
+		pos, ok := geo.NearestPointOnPath(cable.PathCoordinates, node.Latitude, node.Longitude)
+		if !ok {
+			return nil
+		}
+		positions[i] = pos.DistanceAlongMeters
+	}
+	return positions
+}
+
+// locate finds where an optical distance from the start of the route falls. Distances inside a
+// node's slack coil are placed at the node.
+func (g *Graph) locate(r *fiberRoute, opticalMeter float64) *models.FiberLocation {
+	d := math.Max(0, opticalMeter)
+	for _, span := range r.spans {
+		if d < span.opticalStartMeter {
+			return g.fiberLocation(span, 0, opticalMeter)
+		}
+		if d <= span.opticalStartMeter+span.lengthMeter*r.slackFactor {
+			return g.fiberLocation(span, (d-span.opticalStartMeter)/r.slackFactor, opticalMeter)
+		}
+	}
+	last := r.spans[len(r.spans)-1]
+	location := g.fiberLocation(last, last.lengthMeter, opticalMeter)
+	location.BeyondEnd = true
+	return location
+}
+
+func (g *Graph) fiberLocation(span fiberSpan, alongMeter, opticalMeter float64) *models.FiberLocation {
+	location := &models.FiberLocation{
+		OpticalDistanceMeter:     opticalMeter,
+		CableDistanceMeter:       span.cableStartMeter + alongMeter,
+		CoreID:                   span.coreID,
+		CableID:                  span.cableID,
+		SpanFromNodeID:           span.from,
+		SpanToNodeID:             span.to,
+		NearestNodeID:            span.from,
+		NearestNodeDistanceMeter: alongMeter,
+	}
+	if cable := g.Cables[span.cableID]; cable != nil {
+		location.CableName = cable.Name
+	}
+	if rest := span.lengthMeter - alongMeter; rest < alongMeter {
+		location.NearestNodeID = span.to
+		location.NearestNodeDistanceMeter = rest
+	}
+	if node := g.Nodes[location.NearestNodeID]; node != nil {
+		location.NearestNodeName = node.Name
+	}
+
+	fraction := 0.0
+	if span.lengthMeter > 0 {
+		fraction = alongMeter / span.lengthMeter
+	}
+	if point := geo.PointAlongPath(span.path, fraction*geo.PathLengthMeters(span.path)); point != nil {
+		location.Longitude, location.Latitude = point[0], point[1]
+	}
+	return location
+}
+
+// LocateOnFiber converts an optical distance measured from a node into a point on the plant
+func (g *Graph) LocateOnFiber(req *models.OTDRLocateRequest) (*models.FiberLocation, error) {
+	r, err := g.fiberRoute(req.CoreID, req.FromNodeID, req.FiberSlack)
+	if err != nil {
+		return nil, err
+	}
+	return g.locate(r, req.DistanceMeter), nil
+}
+
+// AnalyzeOTDR lays a trace over the route of its fiber: every event is placed on the map, events near
+// a splice are compared with the loss recorded on the connection, and an end event short of the
+// route is reported as a break. Slack not given falls back to what was stored with the trace.
+func (g *Graph) AnalyzeOTDR(trace *models.OTDRTrace, slack models.FiberSlack) (*models.OTDRAnalysis, error) {
+	if slack.SlackPct == nil {
+		slack.SlackPct = &trace.SlackPct
+	}
+	if slack.NodeSlackMeter == nil {
+		slack.NodeSlackMeter = &trace.NodeSlackMeter
+	}
+	r, err := g.fiberRoute(trace.CoreID, trace.FromNodeID, slack)
+	if err != nil {
+		return nil, err
+	}
+
+	analysis := &models.OTDRAnalysis{
+		Trace:                    *trace,
+		SlackPct:                 (r.slackFactor - 1) * 100,
+		NodeSlackMeter:           r.nodeSlackMeter,
+		RouteCoreIDs:             r.coreIDs,
+		RouteLengthMeter:         r.lengthMeter,
+		ExpectedFiberLengthMeter: r.opticalLengthMeter,
+		Events:                   make([]models.OTDREventMatch, 0, len(trace.Events)),
+		Splices:                  make([]models.OTDRSpliceCheck, 0, len(r.splices)),
+	}
+	analysis.Trace.Events = nil
+	tolerance := models.OTDRMatchToleranceMeter + r.nodeSlackMeter/2
+
+	// Each splice takes the nearest unclaimed splice or connector event within tolerance
+	matched := map[int]int64{}
+	flagged := 0
+	for _, s := range r.splices {
+		check := models.OTDRSpliceCheck{
+			ConnectionID:         s.connection.ID,
+			NodeID:               *s.connection.LocationNodeID,
+			OpticalDistanceMeter: s.opticalMeter,
+			ExpectedLossDB:       s.connection.LossDB,
+		}
+		if node := g.Nodes[check.NodeID]; node != nil {
+			check.NodeName = node.Name
+		}
+		best := -1
+		for i, e := range trace.Events {
+			if _, taken := matched[i]; taken || e.Type == models.OTDREventEnd {
+				continue
+			}
+			gap := math.Abs(e.DistanceMeter - s.opticalMeter)
+			if gap <= tolerance && (best < 0 || gap < math.Abs(trace.Events[best].DistanceMeter-s.opticalMeter)) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			e := trace.Events[best]
+			matched[best] = check.ConnectionID
+			check.EventNumber = &e.EventNumber
+			check.MeasuredLossDB = &e.LossDB
+			if check.ExpectedLossDB != nil {
+				delta := e.LossDB - *check.ExpectedLossDB
+				check.LossDeltaDB = &delta
+				check.ExceedsExpected = delta > models.OTDRLossToleranceDB
+			}
+		}
+		if check.ExceedsExpected {
+			flagged++
+		}
+		analysis.Splices = append(analysis.Splices, check)
+	}
+
+	endMeter, hasEnd := trace.FiberLengthMeter, false
+	unexpected := 0
+	for i, e := range trace.Events {
+		match := models.OTDREventMatch{OTDREvent: e, Location: g.locate(r, e.DistanceMeter)}
+		if id, ok := matched[i]; ok {
+			match.ConnectionID = &id
+			match.Expected = true
+		}
+		switch {
+		case e.Type == models.OTDREventEnd:
+			if !hasEnd {
+				endMeter, hasEnd = e.DistanceMeter, true
+			}
+			match.Expected = math.Abs(e.DistanceMeter-r.opticalLengthMeter) <= tolerance
+		case e.DistanceMeter <= tolerance:
+			match.Expected = true // Launch connector
+		case !match.Expected && math.Abs(e.DistanceMeter-r.opticalLengthMeter) <= tolerance:
+			match.Expected = true // Far-end connector
+		}
+		if !match.Expected && e.Type != models.OTDREventEnd {
+			unexpected++
+		}
+		analysis.Events = append(analysis.Events, match)
+	}
+
+	var summary []string
+	if endMeter > 0 && endMeter < r.opticalLengthMeter-tolerance {
+		analysis.Break = g.locate(r, endMeter)
+		summary = append(summary, fmt.Sprintf("Fiber ends at %.0f m, %.0f m short of the expected %.0f m: break near %s",
+			endMeter, r.opticalLengthMeter-endMeter, r.opticalLengthMeter, analysis.Break.NearestNodeName))
+	} else if endMeter > r.opticalLengthMeter+tolerance {
+		summary = append(summary, fmt.Sprintf("Fiber measures %.0f m, %.0f m longer than the recorded route allows: check slack or route",
+			endMeter, endMeter-r.opticalLengthMeter))
+	}
+	if flagged > 0 {
+		summary = append(summary, fmt.Sprintf("%d of %d splices measure above their recorded loss", flagged, len(r.splices)))
+	}
+	if unexpected > 0 {
+		summary = append(summary, fmt.Sprintf("%d events away from any known splice", unexpected))
+	}
+	if len(summary) == 0 {
+		summary = append(summary, "Trace matches the recorded route")
+	}
+	analysis.Summary = strings.Join(summary, "; ")
+	analysis.GeoJSON = otdrGeoJSON(r, analysis)
+	return analysis, nil
+}
+
+func otdrGeoJSON(r *fiberRoute, analysis *models.OTDRAnalysis) models.GeoJSONFeatureCollection {
+	var features []interface{}
+	for _, span := range r.spans {
+		if len(span.path) < 2 {
+			continue
+		}
+		features = append(features, map[string]interface{}{
+			"type": "Feature",
+			"geometry": map[string]interface{}{
+				"type":        "LineString",
+				"coordinates": span.path,
+			},
+			"properties": map[string]interface{}{
+				"otdr":         "ROUTE",
+				"core_id":      span.coreID,
+				"cable_id":     span.cableID,
+				"from_node_id": span.from,
+				"to_node_id":   span.to,
+			},
+		})
+	}
+	point := func(location *models.FiberLocation, properties map[string]interface{}) {
+		features = append(features, map[string]interface{}{
+			"type": "Feature",
+			"geometry": models.GeoJSONPoint{
+				Type:        "Point",
+				Coordinates: []float64{location.Longitude, location.Latitude},
+			},
+			"properties": properties,
+		})
+	}
+	for _, e := range analysis.Events {
+		properties := map[string]interface{}{
+			"otdr":           "EVENT",
+			"event_number":   e.EventNumber,
+			"type":           string(e.Type),
+			"distance_meter": e.DistanceMeter,
+			"loss_db":        e.LossDB,
+			"expected":       e.Expected,
+		}
+		if e.ConnectionID != nil {
+			properties["connection_id"] = *e.ConnectionID
+		}
+		point(e.Location, properties)
+	}
+	if analysis.Break != nil {
+		point(analysis.Break, map[string]interface{}{
+			"otdr":              "BREAK",
+			"distance_meter":    analysis.Break.OpticalDistanceMeter,
+			"nearest_node_id":   analysis.Break.NearestNodeID,
+			"nearest_node_name": analysis.Break.NearestNodeName,
+		})
+	}
+	return models.NewGeoJSONFeatureCollection(features)
+}
+
+func reversePath(path [][]float64) [][]float64 {
+	reversed := make([][]float64, len(path))
+	for i, p := range path {
+		reversed[len(path)-1-i] = p
+	}
+	return reversed
+}
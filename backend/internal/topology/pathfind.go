@@ -0,0 +1,259 @@
+package topology
+
+import (
+	"container/heap"
+	"errors"
+
+	"spectra-backend/internal/models"
+	"spectra-backend/internal/repository"
+)
+
+// ErrNoFiberPath is returned when no chain of vacant cores joins the two nodes
+var ErrNoFiberPath = errors.New("no fiber path found")
+
+// pathState is arriving at a node on a core segment; core 0 is the start
+type pathState struct {
+	nodeID int64
+	coreID int64
+}
+
+// pathStep records how a state was reached
+type pathStep struct {
+	prev    pathState
+	segment models.CoreSegment
+	splice  *models.FiberPathSplice
+}
+
+// FindFiberPath finds the shortest or lowest-loss chain of VACANT cores between two nodes.
+//
+// A core segment can only be entered at its ends (cable ends and breakouts). Where a vacant core is
+// already spliced at a node the path must follow that splice; otherwise a new splice may join two
+// free core ends at an ODC, ODP or closure. Cables that are INACTIVE are skipped.
+func (g *Graph) FindFiberPath(req *models.FiberPathRequest) (*models.FiberPath, error) {
+	var missing []string
+	for _, id := range []int64{req.FromNodeID, req.ToNodeID} {
+		if g.Nodes[id] == nil {
+			missing = append(missing, VertexID(models.TopologyVertexNode, id))
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &UnknownElementsError{Missing: missing}
+	}
+
+	metric := req.Metric
+	if metric == "" {
+		metric = models.FiberPathMetricDistance
+	}
+	attenuation := models.DefaultAttenuationDBPerKm
+	if req.AttenuationDBPerKm != nil {
+		attenuation = *req.AttenuationDBPerKm
+	}
+	spliceLoss := repository.DefaultSpliceLossDB
+	if req.SpliceLossDB != nil {
+		spliceLoss = *req.SpliceLossDB
+	}
+
+	// Free segment ends of vacant cores by node, and existing core-to-core splices by core end
+	segmentsAt := map[int64][]models.CoreSegment{}
+	for _, id := range sortedIDs(g.Cables) {
+		cable := g.Cables[id]
+		if cable.Status == models.CableStatusInactive {
+			continue
+		}
+		for _, core := range g.CoresByCable[cable.ID] {
+			if core.Status != models.CoreStatusVacant {
+				continue
+			}
+			for _, segment := range g.CoreSegments(core.ID) {
+				segmentsAt[segment.FromNodeID] = append(segmentsAt[segment.FromNodeID], segment)
+				if segment.ToNodeID != segment.FromNodeID {
+					segmentsAt[segment.ToNodeID] = append(segmentsAt[segment.ToNodeID], segment)
+				}
+			}
+		}
+	}
+	occupied := map[pathState]bool{}
+	pinned := map[pathState]models.Connection{}
+	for _, c := range g.Connections {
+		if c.LocationNodeID == nil {
+			continue
+		}
+		at := *c.LocationNodeID
+		if c.InputType == models.ConnectionTypeCore {
+			occupied[pathState{at, c.InputID}] = true
+		}
+		if c.OutputType == models.ConnectionTypeCore {
+			occupied[pathState{at, c.OutputID}] = true
+		}
+		if c.InputType == models.ConnectionTypeCore && c.OutputType == models.ConnectionTypeCore {
+			pinned[pathState{at, c.InputID}] = c
+			pinned[pathState{at, c.OutputID}] = c
+		}
+	}
+
+	cost := func(lengthMeter, lossDB float64) float64 {
+		if metric == models.FiberPathMetricLoss {
+			return lossDB
+		}
+		return lengthMeter
+	}
+
+	start := pathState{nodeID: req.FromNodeID}
+	dist := map[pathState]float64{start: 0}
+	steps := map[pathState]pathStep{}
+	done := map[pathState]bool{}
+	queue := &pathQueue{{state: start}}
+
+	relax := func(from pathState, segment models.CoreSegment, splice *models.FiberPathSplice) {
+		next := pathState{nodeID: segment.OtherEnd(from.nodeID), coreID: segment.CoreID}
+		loss := segment.LengthMeter / 1000 * attenuation
+		if splice != nil {
+			loss += splice.LossDB
+		}
+		d := dist[from] + cost(segment.LengthMeter, loss)
+		if existing, ok := dist[next]; ok && existing <= d {
+			return
+		}
+		dist[next] = d
+		steps[next] = pathStep{prev: from, segment: segment, splice: splice}
+		heap.Push(queue, pathItem{state: next, cost: d})
+	}
+
+	var goal *pathState
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(pathItem)
+		current := item.state
+		if done[current] {
+			continue
+		}
+		done[current] = true
+
+		pin, isPinned := pinned[current]
+		if current.nodeID == req.ToNodeID && current.coreID != 0 && !occupied[current] {
+			goal = &current
+			break
+		}
+
+		if current.coreID != 0 && isPinned {
+			// An existing splice decides where this core continues
+			otherCore := pin.OutputID
+			if otherCore == current.coreID {
+				otherCore = pin.InputID
+			}
+			loss := repository.DefaultSpliceLossDB
+			if pin.LossDB != nil {
+				loss = *pin.LossDB
+			}
+			connectionID := pin.ID
+			for _, segment := range segmentsAt[current.nodeID] {
+				if segment.CoreID == otherCore {
+					relax(current, segment, &models.FiberPathSplice{NodeID: current.nodeID, ConnectionID: &connectionID, LossDB: loss})
+				}
+			}
+			continue
+		}
+		if current.coreID != 0 && (occupied[current] || !g.spliceable(current.nodeID)) {
+			continue
+		}
+
+		for _, segment := range segmentsAt[current.nodeID] {
+			if segment.CoreID == current.coreID || occupied[pathState{current.nodeID, segment.CoreID}] {
+				continue
+			}
+			var splice *models.FiberPathSplice
+			if current.coreID != 0 {
+				splice = &models.FiberPathSplice{NodeID: current.nodeID, New: true, LossDB: spliceLoss}
+			}
+			relax(current, segment, splice)
+		}
+	}
+	if goal == nil {
+		return nil, ErrNoFiberPath
+	}
+
+	var chain []pathStep
+	for s := *goal; s != start; s = steps[s].prev {
+		chain = append(chain, steps[s])
+	}
+
+	path := &models.FiberPath{
+		FromNodeID: req.FromNodeID,
+		ToNodeID:   req.ToNodeID,
+		Metric:     metric,
+		Hops:       make([]models.FiberPathHop, 0, len(chain)),
+		CoreIDs:    []int64{},
+	}
+	seenCores := map[int64]bool{}
+	for i := len(chain) - 1; i >= 0; i-- {
+		step := chain[i]
+		from := step.prev.nodeID
+		to := step.segment.OtherEnd(from)
+		core := g.Cores[step.segment.CoreID]
+		cable := g.Cables[step.segment.CableID]
+
+		passThrough := append([]int64{}, step.segment.PassThroughNodeIDs...)
+		if from != step.segment.FromNodeID {
+			for a, b := 0, len(passThrough)-1; a < b; a, b = a+1, b-1 {
+				passThrough[a], passThrough[b] = passThrough[b], passThrough[a]
+			}
+		}
+
+		hop := models.FiberPathHop{
+			Sequence:           len(path.Hops) + 1,
+			CoreID:             core.ID,
+			CoreIndex:          core.CoreIndex,
+			CableID:            cable.ID,
+			CableName:          cable.Name,
+			FromNodeID:         from,
+			FromNodeName:       g.Nodes[from].Name,
+			ToNodeID:           to,
+			ToNodeName:         g.Nodes[to].Name,
+			PassThroughNodeIDs: passThrough,
+			LengthMeter:        step.segment.LengthMeter,
+			FiberLossDB:        step.segment.LengthMeter / 1000 * attenuation,
+			Splice:             step.splice,
+		}
+		path.Hops = append(path.Hops, hop)
+		path.TotalLengthMeter += hop.LengthMeter
+		path.FiberLossDB += hop.FiberLossDB
+		if hop.Splice != nil {
+			path.SpliceCount++
+			path.SpliceLossDB += hop.Splice.LossDB
+			if hop.Splice.New {
+				path.NewSpliceCount++
+			}
+		}
+		if !seenCores[core.ID] {
+			seenCores[core.ID] = true
+			path.CoreIDs = append(path.CoreIDs, core.ID)
+		}
+	}
+	path.TotalLossDB = path.FiberLossDB + path.SpliceLossDB
+
+	return path, nil
+}
+
+func (g *Graph) spliceable(nodeID int64) bool {
+	node := g.Nodes[nodeID]
+	return node != nil && models.SpliceableNodeTypes[node.Type]
+}
+
+// pathItem is a state waiting in the search frontier
+type pathItem struct {
+	state pathState
+	cost  float64
+}
+
+// pathQueue is a min-heap of pathItems by cost
+type pathQueue []pathItem
+
+func (q pathQueue) Len() int            { return len(q) }
+func (q pathQueue) Less(i, j int) bool  { return q[i].cost < q[j].cost }
+func (q pathQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *pathQueue) Push(x interface{}) { *q = append(*q, x.(pathItem)) }
+func (q *pathQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,354 @@
+package topology
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"spectra-backend/internal/models"
+)
+
+// ErrCustomerNotFound is returned when a diversity check names an unknown customer
+var ErrCustomerNotFound = errors.New("customer not found")
+
+// footprint is where a traced fiber path physically runs
+type footprint struct {
+	trace     models.FiberPathTrace
+	cables    map[int64]bool
+	nodes     map[int64]bool
+	spans     map[[2]int64]map[int64]bool // adjacent node pair (lower ID first) -> cables along it
+	terminals map[int64]bool
+}
+
+// footprintOf maps the cores of a traced path onto cables, nodes and spans. Nodes where an odd
+// number of core segments end are where the path terminates.
+func (g *Graph) footprintOf(cores map[int64]bool) *footprint {
+	f := &footprint{
+		cables:    map[int64]bool{},
+		nodes:     map[int64]bool{},
+		spans:     map[[2]int64]map[int64]bool{},
+		terminals: map[int64]bool{},
+	}
+	segmentEnds := map[int64]int{}
+	for _, coreID := range sortedIDs(cores) {
+		for _, segment := range g.CoreSegments(coreID) {
+			f.cables[segment.CableID] = true
+			f.trace.LengthMeter += segment.LengthMeter
+			segmentEnds[segment.FromNodeID]++
+			segmentEnds[segment.ToNodeID]++
+
+			route := append([]int64{segment.FromNodeID}, segment.PassThroughNodeIDs...)
+			route = append(route, segment.ToNodeID)
+			for i, nodeID := range route {
+				f.nodes[nodeID] = true
+				if i == 0 {
+					continue
+				}
+				key := spanKey(route[i-1], nodeID)
+				if f.spans[key] == nil {
+					f.spans[key] = map[int64]bool{}
+				}
+				f.spans[key][segment.CableID] = true
+			}
+		}
+	}
+	for nodeID, count := range segmentEnds {
+		if count%2 == 1 {
+			f.terminals[nodeID] = true
+		}
+	}
+
+	f.trace.CoreIDs = sortedIDs(cores)
+	f.trace.CableIDs = sortedIDs(f.cables)
+	f.trace.NodeIDs = sortedIDs(f.nodes)
+	f.trace.TerminalNodeIDs = sortedIDs(f.terminals)
+	return f
+}
+
+// tracePath follows the fiber through a core across splices and ports
+func (g *Graph) tracePath(coreID int64, byEndpoint map[string][]int) map[int64]bool {
+	cores, _ := g.walkFiber(byEndpoint, []string{VertexID(models.TopologyVertexCore, coreID)})
+	return cores
+}
+
+// Diversity compares the fiber paths through two cores and reports what they physically share
+func (g *Graph) Diversity(coreA, coreB int64) (*models.DiversityReport, error) {
+	var missing []string
+	for _, id := range []int64{coreA, coreB} {
+		if g.Cores[id] == nil {
+			missing = append(missing, VertexID(models.TopologyVertexCore, id))
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &UnknownElementsError{Missing: missing}
+	}
+
+	byEndpoint := g.connectionsByEndpoint()
+	a := g.footprintOf(g.tracePath(coreA, byEndpoint))
+	b := g.footprintOf(g.tracePath(coreB, byEndpoint))
+	return g.compareFootprints(a, b), nil
+}
+
+// CustomerDiversity compares the working and protection feeds of a customer: the distinct traced
+// paths of lit cores that end or break out at the customer's node. With fewer than two feeds the
+// customer is not protected.
+func (g *Graph) CustomerDiversity(customerID int64) (*models.DiversityReport, error) {
+	var customer *models.Customer
+	for _, customers := range g.CustomersByNode {
+		for _, c := range customers {
+			if c.ID == customerID {
+				customer = c
+			}
+		}
+	}
+	if customer == nil {
+		return nil, ErrCustomerNotFound
+	}
+	nodeID := *customer.NodeID
+
+	byEndpoint := g.connectionsByEndpoint()
+	var feeds []*footprint
+	covered := map[int64]bool{}
+	for _, coreID := range sortedIDs(g.Cores) {
+		core := g.Cores[coreID]
+		if covered[coreID] || !g.lit(core, byEndpoint) || !g.coreEndsAt(coreID, nodeID) {
+			continue
+		}
+		cores := g.tracePath(coreID, byEndpoint)
+		for id := range cores {
+			covered[id] = true
+		}
+		feeds = append(feeds, g.footprintOf(cores))
+	}
+
+	var report *models.DiversityReport
+	if len(feeds) >= 2 {
+		report = g.compareFootprints(feeds[0], feeds[1])
+	} else {
+		report = &models.DiversityReport{
+			SharedCables:   []models.CableRef{},
+			SharedSpans:    []models.SharedSpan{},
+			SharedPoles:    []models.SharedNode{},
+			SharedClosures: []models.SharedNode{},
+			Summary:        fmt.Sprintf("Customer has %d feed, so there is no protection path", len(feeds)),
+		}
+		if len(feeds) == 1 {
+			report.PathA = &feeds[0].trace
+		}
+	}
+	report.CustomerID = &customerID
+	report.Feeds = make([]models.FiberPathTrace, 0, len(feeds))
+	for _, f := range feeds {
+		report.Feeds = append(report.Feeds, f.trace)
+	}
+	return report, nil
+}
+
+func (g *Graph) compareFootprints(a, b *footprint) *models.DiversityReport {
+	report := &models.DiversityReport{
+		PathA:          &a.trace,
+		PathB:          &b.trace,
+		SharedCables:   []models.CableRef{},
+		SharedSpans:    []models.SharedSpan{},
+		SharedPoles:    []models.SharedNode{},
+		SharedClosures: []models.SharedNode{},
+	}
+
+	for _, id := range a.trace.CableIDs {
+		if b.cables[id] {
+			report.SharedCables = append(report.SharedCables, g.cableRef(id))
+		}
+	}
+
+	var keys [][2]int64
+	for key := range a.spans {
+		if b.spans[key] != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	for _, key := range keys {
+		report.SharedSpans = append(report.SharedSpans, models.SharedSpan{
+			FromNodeID: key[0],
+			ToNodeID:   key[1],
+			CableIDsA:  sortedIDs(a.spans[key]),
+			CableIDsB:  sortedIDs(b.spans[key]),
+		})
+	}
+
+	sharedPoles, sharedClosures := 0, 0
+	for _, id := range a.trace.NodeIDs {
+		node := g.Nodes[id]
+		if !b.nodes[id] || node == nil {
+			continue
+		}
+		shared := models.SharedNode{
+			NodeID:   id,
+			Name:     node.Name,
+			Type:     node.Type,
+			Terminal: a.terminals[id] && b.terminals[id],
+		}
+		if node.Type == models.NodeTypePole {
+			report.SharedPoles = append(report.SharedPoles, shared)
+			if !shared.Terminal {
+				sharedPoles++
+			}
+		} else {
+			report.SharedClosures = append(report.SharedClosures, shared)
+			if !shared.Terminal {
+				sharedClosures++
+			}
+		}
+	}
+
+	report.Diverse = len(report.SharedCables) == 0 && len(report.SharedSpans) == 0 && sharedPoles == 0 && sharedClosures == 0
+	if report.Diverse {
+		report.Summary = "Paths are physically diverse"
+	} else {
+		report.Summary = fmt.Sprintf("Paths are not diverse: %d shared cables, %d shared spans, %d shared poles and %d shared closures besides the end points",
+			len(report.SharedCables), len(report.SharedSpans), sharedPoles, sharedClosures)
+	}
+	return report
+}
+
+// FeedDependencies finds ODCs whose feed from every OLT runs through a single cable. Feeds follow
+// cables that are not INACTIVE and carry at least one lit core.
+func (g *Graph) FeedDependencies() *models.FeedReport {
+	byEndpoint := g.connectionsByEndpoint()
+
+	type link struct {
+		nodeID  int64
+		cableID int64
+	}
+	adjacent := map[int64][]link{}
+	for _, cableID := range sortedIDs(g.Cables) {
+		cable := g.Cables[cableID]
+		route := g.Routes[cableID]
+		if cable.Status == models.CableStatusInactive || route == nil {
+			continue
+		}
+		lit := false
+		for _, core := range g.CoresByCable[cableID] {
+			if g.lit(core, byEndpoint) {
+				lit = true
+				break
+			}
+		}
+		if !lit {
+			continue
+		}
+		for i := 1; i < len(route.NodeIDs); i++ {
+			from, to := route.NodeIDs[i-1], route.NodeIDs[i]
+			adjacent[from] = append(adjacent[from], link{to, cableID})
+			adjacent[to] = append(adjacent[to], link{from, cableID})
+		}
+	}
+
+	// reachOLT searches from a node without using one cable and returns the cables on the
+	// first path found to an OLT
+	reachOLT := func(start, without int64) ([]int64, bool) {
+		prev := map[int64]link{start: {}}
+		queue := []int64{start}
+		for len(queue) > 0 {
+			nodeID := queue[0]
+			queue = queue[1:]
+			if node := g.Nodes[nodeID]; node != nil && node.Type == models.NodeTypeOLT {
+				var cables []int64
+				for at := nodeID; at != start; at = prev[at].nodeID {
+					cables = append(cables, prev[at].cableID)
+				}
+				return cables, true
+			}
+			for _, l := range adjacent[nodeID] {
+				if l.cableID == without {
+					continue
+				}
+				if _, ok := prev[l.nodeID]; !ok {
+					prev[l.nodeID] = link{nodeID, l.cableID}
+					queue = append(queue, l.nodeID)
+				}
+			}
+		}
+		return nil, false
+	}
+
+	report := &models.FeedReport{
+		SinglyFed: []models.FeedDependency{},
+		Unfed:     []models.Node{},
+	}
+	customersAtRisk := map[int64]int{}
+	for _, nodeID := range sortedIDs(g.Nodes) {
+		node := g.Nodes[nodeID]
+		if node.Type != models.NodeTypeODC {
+			continue
+		}
+		report.ODCCount++
+
+		path, ok := reachOLT(nodeID, 0)
+		if !ok {
+			report.Unfed = append(report.Unfed, *node)
+			continue
+		}
+
+		dependency := models.FeedDependency{ODC: *node, Cables: []models.CableRef{}}
+		tried := map[int64]bool{}
+		for _, cableID := range path {
+			if tried[cableID] {
+				continue
+			}
+			tried[cableID] = true
+			if _, ok := reachOLT(nodeID, cableID); ok {
+				continue
+			}
+			dependency.Cables = append(dependency.Cables, g.cableRef(cableID))
+
+			if _, ok := customersAtRisk[cableID]; !ok {
+				impact, err := g.Impact(&models.ImpactRequest{CableIDs: []int64{cableID}})
+				if err == nil {
+					customersAtRisk[cableID] = impact.CustomerCount
+				}
+			}
+			if customersAtRisk[cableID] > dependency.CustomerCount {
+				dependency.CustomerCount = customersAtRisk[cableID]
+			}
+		}
+		if len(dependency.Cables) > 0 {
+			report.SinglyFed = append(report.SinglyFed, dependency)
+		}
+	}
+	report.SinglyFedCount = len(report.SinglyFed)
+	report.UnfedCount = len(report.Unfed)
+	report.RedundantODCCount = report.ODCCount - report.SinglyFedCount - report.UnfedCount
+	return report
+}
+
+// coreEndsAt reports whether a segment of the core starts or ends at the node
+func (g *Graph) coreEndsAt(coreID, nodeID int64) bool {
+	for _, segment := range g.CoreSegments(coreID) {
+		if segment.HasEndpoint(nodeID) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Graph) cableRef(id int64) models.CableRef {
+	ref := models.CableRef{CableID: id}
+	if cable := g.Cables[id]; cable != nil {
+		ref.Name = cable.Name
+		ref.Type = cable.Type
+	}
+	return ref
+}
+
+func spanKey(a, b int64) [2]int64 {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]int64{a, b}
+}
@@ -0,0 +1,132 @@
+package topology
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"spectra-backend/internal/database"
+	"spectra-backend/internal/repository"
+)
+
+// Service holds the current topology graph of the operator and of each tenant, every one built from
+// what that tenant can see. Writes through the API invalidate them all and the next reader of each
+// rebuilds it from a fresh snapshot, so bursts of mutations cost a single reload.
+type Service struct {
+	repo *repository.TopologyRepository
+
+	mu      sync.Mutex
+	graphs  map[int64]*builtGraph // by tenant ID, 0 for the operator
+	version uint64                // bumped by every invalidation
+}
+
+// builtGraph is a graph and the version it was loaded at. ready is closed once the load finished
+// and graph or err is set.
+type builtGraph struct {
+	graph *Graph
+	err   error
+	built uint64
+	ready chan struct{}
+}
+
+// NewService creates a new topology Service
+func NewService(repo *repository.TopologyRepository) *Service {
+	return &Service{repo: repo, graphs: map[int64]*builtGraph{}}
+}
+
+// Graph returns the current graph of the context's tenant, reloading it when it is missing or stale.
+// The snapshot is loaded without holding the lock: readers of the same tenant wait for a single load
+// and other tenants, and invalidations, are not held up by it.
+func (s *Service) Graph(ctx context.Context) (*Graph, error) {
+	tenantID, _ := database.TenantID(ctx)
+
+	s.mu.Lock()
+	b := s.graphs[tenantID]
+	if b == nil || b.built != s.version {
+		b = &builtGraph{built: s.version, ready: make(chan struct{})}
+		s.graphs[tenantID] = b
+		s.mu.Unlock()
+		s.load(ctx, tenantID, b)
+	} else {
+		s.mu.Unlock()
+	}
+
+	select {
+	case <-b.ready:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.graph, nil
+}
+
+// load fills b from a fresh snapshot. Other readers share the result, so the load is not cut short
+// when the request that started it goes away; a failed load is dropped so the next reader retries.
+func (s *Service) load(ctx context.Context, tenantID int64, b *builtGraph) {
+	defer close(b.ready)
+
+	snapshot, err := s.repo.Snapshot(context.WithoutCancel(ctx))
+	if err != nil {
+		b.err = err
+		s.mu.Lock()
+		if s.graphs[tenantID] == b {
+			delete(s.graphs, tenantID)
+		}
+		s.mu.Unlock()
+		return
+	}
+	b.graph = Build(snapshot)
+}
+
+// Invalidate marks the graph stale after the plant changed
+func (s *Service) Invalidate() {
+	s.mu.Lock()
+	s.version++
+	s.mu.Unlock()
+}
+
+// readOnlyPosts are POST endpoints that leave the graph valid; those that may still write
+// invalidate it themselves
+var readOnlyPosts = map[string]bool{
+	"/api/impact":        true,
+	"/api/coverage":      true,
+	"/api/fiber-paths":   true,
+	"/api/otdr/traces":   true,
+	"/api/drops/preview": true,
+}
+
+// TrackWrites is middleware that invalidates the graph after every successful
+// POST, PUT, PATCH or DELETE request
+func (s *Service) TrackWrites(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.Method == http.MethodPost && readOnlyPosts[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		wrapped := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(wrapped, r)
+		if wrapped.statusCode < http.StatusBadRequest {
+			s.Invalidate()
+		}
+	})
+}
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (rw *statusRecorder) WriteHeader(code int) {
+	rw.statusCode = code
+	rw.ResponseWriter.WriteHeader(code)
+}
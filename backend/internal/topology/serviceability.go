@@ -0,0 +1,234 @@
+package topology
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"sort"
+
+	"spectra-backend/internal/models"
+	"spectra-backend/internal/repository"
+)
+
+// Serviceability ranks the ODPs near an address by whether they could serve it. An ODP can when it
+// is ACTIVE or under MAINTENANCE, has a free port, the drop along poles is within the drop-length
+// limit, and the predicted Rx power is above the critical level. The ODPs come from a nearby
+// search; their port counts are taken as given.
+func (g *Graph) Serviceability(req *models.ServiceabilityRequest, odps []models.Node) *models.ServiceabilityResult {
+	maxDrop := req.MaxDropMeters
+	if maxDrop <= 0 {
+		maxDrop = models.DefaultMaxDropMeters
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = models.DefaultServiceabilityOptions
+	}
+
+	result := &models.ServiceabilityResult{
+		Latitude:      req.Latitude,
+		Longitude:     req.Longitude,
+		MaxDropMeters: maxDrop,
+		Options:       []models.ServiceabilityOption{},
+	}
+
+	feeders := g.dropFeeders()
+	for _, odp := range odps {
+		if odp.Type != models.NodeTypeODP {
+			continue
+		}
+		drop, err := g.DropRoute(odp.ID, req.Latitude, req.Longitude, req.MaxSpanMeters)
+		if err != nil {
+			// Created since the graph was loaded
+			continue
+		}
+
+		option := models.ServiceabilityOption{
+			ODPID:         odp.ID,
+			ODPName:       odp.Name,
+			ODPStatus:     odp.Status,
+			CapacityPorts: odp.CapacityPorts,
+			FreePorts:     max(0, odp.CapacityPorts-odp.UsedPorts),
+			Drop:          *drop,
+			Budget:        g.lossBudget(&odp, drop.LengthMeter, req, feeders),
+		}
+		switch {
+		case odp.Status != models.NodeStatusActive && odp.Status != models.NodeStatusMaintenance:
+			option.Reason = fmt.Sprintf("ODP is %s", odp.Status)
+		case option.FreePorts == 0:
+			option.Reason = "ODP has no free ports"
+		case drop.LengthMeter > maxDrop:
+			option.Reason = fmt.Sprintf("Drop of %.0f m exceeds the %.0f m limit", drop.LengthMeter, maxDrop)
+		case option.Budget == nil:
+			option.Reason = "ODP has no feed from an OLT to predict Rx power"
+		case option.Budget.PredictedRxDBm < models.RxPowerCritical:
+			option.Reason = fmt.Sprintf("Predicted Rx power of %.1f dBm is below %.1f dBm",
+				option.Budget.PredictedRxDBm, models.RxPowerCritical)
+		}
+		option.Feasible = option.Reason == ""
+		result.Options = append(result.Options, option)
+	}
+
+	sort.SliceStable(result.Options, func(i, j int) bool {
+		a, b := result.Options[i], result.Options[j]
+		if a.Feasible != b.Feasible {
+			return a.Feasible
+		}
+		return a.Drop.LengthMeter < b.Drop.LengthMeter
+	})
+	if len(result.Options) > limit {
+		result.Options = result.Options[:limit]
+	}
+	for i := range result.Options {
+		result.Options[i].Rank = i + 1
+	}
+
+	switch {
+	case len(result.Options) == 0:
+		result.Reason = fmt.Sprintf("No ODP within %.0f m", maxDrop)
+	case result.Options[0].Feasible:
+		result.Serviceable = true
+		result.Best = &result.Options[0]
+	default:
+		result.Reason = result.Options[0].ODPName + ": " + result.Options[0].Reason
+	}
+	return result
+}
+
+// lossBudget predicts the Rx power at the end of a new drop from an ODP, from the online customers
+// already on the ODP when there are any, and otherwise from the shortest feed from an OLT. It
+// returns nil when the ODP has neither.
+func (g *Graph) lossBudget(odp *models.Node, dropMeter float64, req *models.ServiceabilityRequest, feeders map[int64]dropFeed) *models.LossBudget {
+	attenuation := models.DefaultAttenuationDBPerKm
+	if req.AttenuationDBPerKm != nil {
+		attenuation = *req.AttenuationDBPerKm
+	}
+	budget := &models.LossBudget{DropLossDB: round2(dropMeter / 1000 * attenuation)}
+
+	// Measured customers: their Rx plus the loss of their own drop is the level at the ODP
+	var level float64
+	for _, nodeID := range sortedIDs(feeders) {
+		feed := feeders[nodeID]
+		if feed.odpID != odp.ID {
+			continue
+		}
+		dropLoss := 0.0
+		if route := g.Routes[feed.cableID]; route != nil {
+			dropLoss = route.TotalLengthMeter / 1000 * attenuation
+		}
+		for _, c := range g.CustomersByNode[nodeID] {
+			if c.CurrentStatus == models.CustomerStatusOnline && c.LastRxPower != nil {
+				level += *c.LastRxPower + dropLoss
+				budget.MeasuredCustomers++
+			}
+		}
+	}
+
+	if budget.MeasuredCustomers > 0 {
+		budget.Basis = models.RxBasisMeasured
+		budget.ODPLevelDBm = round2(level / float64(budget.MeasuredCustomers))
+	} else {
+		feed, ok := g.feedFromOLT(odp.ID)
+		if !ok {
+			return nil
+		}
+		txPower := models.DefaultOLTTxPowerDBm
+		if req.OLTTxPowerDBm != nil {
+			txPower = *req.OLTTxPowerDBm
+		}
+		length := round2(feed.lengthMeter)
+		fiberLoss := round2(feed.lengthMeter / 1000 * attenuation)
+		spliceLoss := round2(float64(feed.splices) * repository.DefaultSpliceLossDB)
+		splitterLoss := float64(feed.odcs)*models.SplitterLossDB(models.DefaultODCSplitPorts) + models.SplitterLossDB(odp.CapacityPorts)
+		connectorLoss := models.DefaultConnectorLossDB
+
+		budget.Basis = models.RxBasisBudget
+		budget.OLTTxPowerDBm = &txPower
+		budget.FeedLengthMeter = &length
+		budget.FeedFiberLossDB = &fiberLoss
+		budget.SpliceLossDB = &spliceLoss
+		budget.SplitterLossDB = &splitterLoss
+		budget.ConnectorLossDB = &connectorLoss
+		budget.ODPLevelDBm = round2(txPower - fiberLoss - spliceLoss - splitterLoss - connectorLoss)
+	}
+
+	budget.PredictedRxDBm = round2(budget.ODPLevelDBm - budget.DropLossDB)
+	budget.RxStatus = models.GetRxPowerStatus(budget.PredictedRxDBm)
+	return budget
+}
+
+// feedPath is the shortest cable route from an OLT to a node
+type feedPath struct {
+	lengthMeter float64
+	splices     int // Changes of cable along the way
+	odcs        int // ODCs passed, each assumed to split the feed
+}
+
+// feedFromOLT finds the shortest route along cable spans from a node to any OLT. Cables that are
+// PLAN or INACTIVE carry no light and are skipped.
+func (g *Graph) feedFromOLT(nodeID int64) (*feedPath, bool) {
+	type link struct {
+		nodeID  int64
+		cableID int64
+		meter   float64
+	}
+	adjacent := map[int64][]link{}
+	for _, cableID := range sortedIDs(g.Routes) {
+		cable := g.Cables[cableID]
+		if cable == nil || cable.Status == models.CableStatusPlan || cable.Status == models.CableStatusInactive {
+			continue
+		}
+		route := g.Routes[cableID]
+		for i := 1; i < len(route.NodeIDs); i++ {
+			meter := route.TotalLengthMeter
+			if len(route.Spans) > 0 && route.Spans[i-1].SpanLengthMeter != nil {
+				meter = *route.Spans[i-1].SpanLengthMeter
+			}
+			from, to := route.NodeIDs[i-1], route.NodeIDs[i]
+			adjacent[from] = append(adjacent[from], link{to, cableID, meter})
+			adjacent[to] = append(adjacent[to], link{from, cableID, meter})
+		}
+	}
+
+	dist := map[int64]float64{nodeID: 0}
+	prev := map[int64]link{}
+	done := map[int64]bool{}
+	queue := &pathQueue{{state: pathState{nodeID: nodeID}}}
+	for queue.Len() > 0 {
+		at := heap.Pop(queue).(pathItem).state.nodeID
+		if done[at] {
+			continue
+		}
+		done[at] = true
+
+		if node := g.Nodes[at]; node != nil && node.Type == models.NodeTypeOLT {
+			feed := &feedPath{lengthMeter: dist[at]}
+			for ; at != nodeID; at = prev[at].nodeID {
+				step := prev[at]
+				if step.nodeID != nodeID {
+					if n := g.Nodes[step.nodeID]; n != nil && n.Type == models.NodeTypeODC {
+						feed.odcs++
+					}
+					if prev[step.nodeID].cableID != step.cableID {
+						feed.splices++
+					}
+				}
+			}
+			return feed, true
+		}
+
+		for _, l := range adjacent[at] {
+			d := dist[at] + l.meter
+			if existing, ok := dist[l.nodeID]; ok && existing <= d {
+				continue
+			}
+			dist[l.nodeID] = d
+			prev[l.nodeID] = link{at, l.cableID, l.meter}
+			heap.Push(queue, pathItem{state: pathState{nodeID: l.nodeID}, cost: d})
+		}
+	}
+	return nil, false
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,163 @@
+package topology
+
+import (
+	"fmt"
+
+	"spectra-backend/internal/models"
+)
+
+// connectionsByEndpoint indexes connections by the vertex of each endpoint ("core:7", "port:12/3")
+func (g *Graph) connectionsByEndpoint() map[string][]int {
+	byEndpoint := map[string][]int{}
+	for i, c := range g.Connections {
+		input := endpointVertex(c.InputType, c.InputID, c.LocationNodeID)
+		output := endpointVertex(c.OutputType, c.OutputID, c.LocationNodeID)
+		byEndpoint[input] = append(byEndpoint[input], i)
+		byEndpoint[output] = append(byEndpoint[output], i)
+	}
+	return byEndpoint
+}
+
+// lit reports whether a core carries light: it is USED or spliced to something
+func (g *Graph) lit(core *models.CableCore, byEndpoint map[string][]int) bool {
+	return core.Status == models.CoreStatusUsed || len(byEndpoint[VertexID(models.TopologyVertexCore, core.ID)]) > 0
+}
+
+// walkFiber follows connections from the seed endpoints across splices and ports, returning the
+// cores reached and the indexes of the connections crossed
+func (g *Graph) walkFiber(byEndpoint map[string][]int, seeds []string) (map[int64]bool, map[int]bool) {
+	var queue []string
+	seen := map[string]bool{}
+	push := func(endpoint string) {
+		if !seen[endpoint] {
+			seen[endpoint] = true
+			queue = append(queue, endpoint)
+		}
+	}
+	for _, seed := range seeds {
+		push(seed)
+	}
+
+	cores := map[int64]bool{}
+	connections := map[int]bool{}
+	for len(queue) > 0 {
+		endpoint := queue[0]
+		queue = queue[1:]
+
+		if kind, id, err := ParseVertexID(endpoint); err == nil && kind == models.TopologyVertexCore && g.Cores[id] != nil {
+			cores[id] = true
+		}
+		for _, i := range byEndpoint[endpoint] {
+			connections[i] = true
+			c := g.Connections[i]
+			push(endpointVertex(c.InputType, c.InputID, c.LocationNodeID))
+			push(endpointVertex(c.OutputType, c.OutputID, c.LocationNodeID))
+		}
+	}
+	return cores, connections
+}
+
+// fiberPiece is a stretch of fiber reached by a segmentWalk: a core segment, or a port when segment is nil
+type fiberPiece struct {
+	endpoint string
+	segment  *models.CoreSegment
+}
+
+// segmentWalk follows fiber segment by segment rather than core by core. From a core segment it only
+// crosses the connections at the segment's own ends, so the other segments of a core broken out
+// mid-span are not reached through it.
+type segmentWalk struct {
+	g           *Graph
+	byEndpoint  map[string][]int
+	segments    []models.CoreSegment // Segments reached, in walk order
+	connections map[int]bool         // Indexes of the connections crossed
+	seen        map[string]bool
+	queue       []fiberPiece
+}
+
+func (g *Graph) newSegmentWalk(byEndpoint map[string][]int) *segmentWalk {
+	return &segmentWalk{
+		g:           g,
+		byEndpoint:  byEndpoint,
+		connections: map[int]bool{},
+		seen:        map[string]bool{},
+	}
+}
+
+// addSegment queues a core segment
+func (w *segmentWalk) addSegment(s models.CoreSegment) {
+	key := fmt.Sprintf("segment:%d:%d:%d", s.CoreID, s.FromNodeID, s.ToNodeID)
+	if w.seen[key] {
+		return
+	}
+	w.seen[key] = true
+	w.segments = append(w.segments, s)
+	w.queue = append(w.queue, fiberPiece{endpoint: VertexID(models.TopologyVertexCore, s.CoreID), segment: &s})
+}
+
+// addCore queues every segment of a core
+func (w *segmentWalk) addCore(coreID int64) {
+	for _, s := range w.g.CoreSegments(coreID) {
+		w.addSegment(s)
+	}
+}
+
+// enter queues the fiber on one side of a connection located at locationNodeID: the segments of a
+// core that end at one of the joint nodes (every segment when the joint is unknown), or a port
+func (w *segmentWalk) enter(kind models.ConnectionType, id int64, locationNodeID *int64, joints []int64) {
+	if kind != models.ConnectionTypeCore {
+		port := endpointVertex(kind, id, locationNodeID)
+		if !w.seen[port] {
+			w.seen[port] = true
+			w.queue = append(w.queue, fiberPiece{endpoint: port})
+		}
+		return
+	}
+	for _, s := range w.g.CoreSegments(id) {
+		if len(joints) == 0 {
+			w.addSegment(s)
+			continue
+		}
+		for _, nodeID := range joints {
+			if s.HasEndpoint(nodeID) {
+				w.addSegment(s)
+				break
+			}
+		}
+	}
+}
+
+// run walks until no new segment or port is reached. Each connection is crossed once, away from the
+// side reached first. Connections of a core segment are crossed when they are located at one of its
+// ends or have no location; a port crosses all its connections. A splice does not record which end of
+// a broken-out core it uses, so entering a core at one of its breakouts reaches the segments on both sides.
+func (w *segmentWalk) run() {
+	for len(w.queue) > 0 {
+		piece := w.queue[0]
+		w.queue = w.queue[1:]
+
+		for _, i := range w.byEndpoint[piece.endpoint] {
+			if w.connections[i] {
+				continue
+			}
+			c := w.g.Connections[i]
+			var joints []int64
+			switch {
+			case c.LocationNodeID != nil:
+				if piece.segment != nil && !piece.segment.HasEndpoint(*c.LocationNodeID) {
+					continue
+				}
+				joints = []int64{*c.LocationNodeID}
+			case piece.segment != nil:
+				joints = []int64{piece.segment.FromNodeID, piece.segment.ToNodeID}
+			}
+			w.connections[i] = true
+
+			if endpointVertex(c.InputType, c.InputID, c.LocationNodeID) == piece.endpoint {
+				w.enter(c.OutputType, c.OutputID, c.LocationNodeID, joints)
+			} else {
+				w.enter(c.InputType, c.InputID, c.LocationNodeID, joints)
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+-- Script: app_role.sql
+-- Description: Creates spectra_app, the role the API connects as. Tenants are isolated by row-level security,
+-- which superusers and roles with BYPASSRLS skip, so the API refuses to start as such a role.
+-- Run it once as a superuser against the SPECTRA database, before the first start or to move an existing
+-- database off the postgres role, then set DATABASE_URL / DATABASE_USER to spectra_app:
+--
+--   psql -U postgres -d spectra -v app_password=<password> -f scripts/app_role.sql
+--
+-- spectra_app owns the schema so it can run the migrations; tenant tables FORCE row-level security, so
+-- owning them does not exempt it from the policies.
+\set ON_ERROR_STOP on
+
+SELECT 'CREATE ROLE spectra_app'
+WHERE NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'spectra_app') \gexec
+ALTER ROLE spectra_app WITH LOGIN NOSUPERUSER NOBYPASSRLS NOCREATEDB NOCREATEROLE NOREPLICATION PASSWORD :'app_password';
+
+-- Extensions need a superuser, so PostGIS is installed here rather than by the migrations
+DO $$ BEGIN CREATE EXTENSION IF NOT EXISTS postgis;
+EXCEPTION
+WHEN OTHERS THEN RAISE NOTICE 'PostGIS extension not available, using fallback spatial queries';
+END $$;
+
+SELECT format('ALTER DATABASE %I OWNER TO spectra_app', current_database()) \gexec
+GRANT USAGE, CREATE ON SCHEMA public TO spectra_app;
+
+-- Hand over the tables, sequences and functions earlier migrations created as another role. Sequences
+-- owned by a column follow their table; objects belonging to an extension stay with it.
+DO $$
+DECLARE obj RECORD;
+BEGIN
+    FOR obj IN
+        SELECT CASE c.relkind WHEN 'v' THEN 'VIEW' WHEN 'm' THEN 'MATERIALIZED VIEW' WHEN 'S' THEN 'SEQUENCE' ELSE 'TABLE' END AS kind,
+            c.oid::regclass::text AS name
+        FROM pg_class c
+        JOIN pg_namespace n ON n.oid = c.relnamespace
+        WHERE n.nspname = 'public'
+            AND c.relkind IN ('r', 'p', 'v', 'm', 'S')
+            AND pg_get_userbyid(c.relowner) <> 'spectra_app'
+            AND NOT EXISTS (
+                SELECT 1 FROM pg_depend d
+                WHERE d.classid = 'pg_class'::regclass AND d.objid = c.oid AND d.deptype IN ('a', 'i', 'e')
+            )
+    LOOP
+        EXECUTE format('ALTER %s %s OWNER TO spectra_app', obj.kind, obj.name);
+    END LOOP;
+
+    FOR obj IN
+        SELECT p.oid::regprocedure::text AS name
+        FROM pg_proc p
+        JOIN pg_namespace n ON n.oid = p.pronamespace
+        WHERE n.nspname = 'public'
+            AND pg_get_userbyid(p.proowner) <> 'spectra_app'
+            AND NOT EXISTS (
+                SELECT 1 FROM pg_depend d
+                WHERE d.classid = 'pg_proc'::regclass AND d.objid = p.oid AND d.deptype = 'e'
+            )
+    LOOP
+        EXECUTE format('ALTER ROUTINE %s OWNER TO spectra_app', obj.name);
+    END LOOP;
+END $$;